@@ -0,0 +1,90 @@
+package apikeys
+
+import (
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/hex"
+	"errors"
+	"fmt"
+
+	"encore.dev/beta/auth"
+	"encore.dev/beta/errs"
+
+	"encore.app/models"
+)
+
+// keyPrefixLength is how much of a key is kept in clear text to tell keys apart
+const keyPrefixLength = len(models.APIKeyPrefix) + 8
+
+func validateCreateAPIKeyRequest(req *models.CreateAPIKeyRequest) error {
+	if len(req.Scopes) == 0 {
+		return fmt.Errorf("at least one scope is required")
+	}
+	admin := false
+	for _, scope := range req.Scopes {
+		if !scope.IsValid() {
+			return fmt.Errorf("invalid scope: %s (supported: read, write, admin)", scope)
+		}
+		admin = admin || scope == models.ScopeAdmin
+	}
+	if admin && req.CustomerID != "" {
+		return fmt.Errorf("admin keys act on every customer and cannot be bound to customer_id")
+	}
+	if !admin && req.CustomerID == "" {
+		return fmt.Errorf("customer_id is required unless the admin scope is requested")
+	}
+	return nil
+}
+
+// newAPIKey generates a key, its digest for storage and the prefix shown when listing keys
+func newAPIKey() (key string, keyHash string, prefix string, err error) {
+	secret := make([]byte, 32)
+	if _, err := rand.Read(secret); err != nil {
+		return "", "", "", fmt.Errorf("failed to generate API key: %w", err)
+	}
+	key = models.APIKeyPrefix + hex.EncodeToString(secret)
+	return key, models.HashAPIKey(key), key[:keyPrefixLength], nil
+}
+
+// isSecretKey compares the token to a key set as a secret in constant time, an unset secret never matches
+func isSecretKey(token string, secretKey string) bool {
+	if secretKey == "" {
+		return false
+	}
+	return subtle.ConstantTimeCompare([]byte(token), []byte(secretKey)) == 1
+}
+
+// callerTenant returns the tenant of the calling API key, falling back to the requested one for the operator key
+func callerTenant(requested string) (string, error) {
+	data, _ := auth.Data().(*models.AuthData)
+	if data == nil {
+		return "", &errs.Error{Code: errs.Unauthenticated, Message: "missing API key"}
+	}
+	if requested != "" {
+		return requested, nil
+	}
+	if data.TenantID == "" {
+		return "", &errs.Error{Code: errs.InvalidArgument, Message: "tenant_id is required for the operator key"}
+	}
+	return data.TenantID, nil
+}
+
+// authorizeCustomer checks that the calling API key may act on the customer of the tenant with the given scope
+func authorizeCustomer(tenantID string, customerID string, scope models.APIKeyScope) error {
+	data, _ := auth.Data().(*models.AuthData)
+	if data == nil {
+		return &errs.Error{Code: errs.Unauthenticated, Message: "missing API key"}
+	}
+	if err := data.Authorize(tenantID, customerID, scope); err != nil {
+		return &errs.Error{Code: errs.PermissionDenied, Message: err.Error()}
+	}
+	return nil
+}
+
+// apiKeyError reports a missing or revoked key as not found, other store failures keep their cause
+func apiKeyError(err error, format string, args ...interface{}) error {
+	if errors.Is(err, errAPIKeyNotFound) {
+		return models.NewAPIErrorWithCause(errs.NotFound, models.ReasonAPIKeyNotFound, err, format, args...)
+	}
+	return errs.Wrap(err, fmt.Sprintf(format, args...))
+}
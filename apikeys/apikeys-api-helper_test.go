@@ -0,0 +1,56 @@
+package apikeys
+
+import (
+	"testing"
+
+	"encore.app/models"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestValidateCreateAPIKeyRequest(t *testing.T) {
+	t.Parallel()
+	cases := []struct {
+		name    string
+		req     models.CreateAPIKeyRequest
+		wantErr string
+	}{
+		{"customer key", models.CreateAPIKeyRequest{CustomerID: "cust-1", Scopes: []models.APIKeyScope{models.ScopeRead, models.ScopeWrite}}, ""},
+		{"admin key", models.CreateAPIKeyRequest{Scopes: []models.APIKeyScope{models.ScopeAdmin}}, ""},
+		{"no scopes", models.CreateAPIKeyRequest{CustomerID: "cust-1"}, "at least one scope is required"},
+		{"unknown scope", models.CreateAPIKeyRequest{CustomerID: "cust-1", Scopes: []models.APIKeyScope{"delete"}}, "invalid scope"},
+		{"admin bound to customer", models.CreateAPIKeyRequest{CustomerID: "cust-1", Scopes: []models.APIKeyScope{models.ScopeAdmin}}, "cannot be bound to customer_id"},
+		{"customer key without customer", models.CreateAPIKeyRequest{Scopes: []models.APIKeyScope{models.ScopeRead}}, "customer_id is required"},
+	}
+	for _, tc := range cases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+			err := validateCreateAPIKeyRequest(&tc.req)
+			if tc.wantErr == "" {
+				assert.NoError(t, err)
+				return
+			}
+			assert.Error(t, err)
+			assert.Contains(t, err.Error(), tc.wantErr)
+		})
+	}
+}
+
+func TestNewAPIKey(t *testing.T) {
+	key, keyHash, prefix, err := newAPIKey()
+	assert.NoError(t, err)
+	assert.Regexp(t, `^bk_[0-9a-f]{64}$`, key)
+	assert.Equal(t, models.HashAPIKey(key), keyHash)
+	assert.Equal(t, key[:11], prefix)
+
+	other, _, _, err := newAPIKey()
+	assert.NoError(t, err)
+	assert.NotEqual(t, key, other)
+}
+
+func TestIsSecretKey(t *testing.T) {
+	assert.True(t, isSecretKey("bk_bootstrap", "bk_bootstrap"))
+	assert.False(t, isSecretKey("bk_other", "bk_bootstrap"))
+	// An unset secret must not turn empty tokens into admin keys
+	assert.False(t, isSecretKey("", ""))
+}
@@ -0,0 +1,147 @@
+package apikeys
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"encore.dev/beta/auth"
+	"encore.dev/beta/errs"
+	"encore.dev/rlog"
+	"github.com/google/uuid"
+
+	"encore.app/customers"
+	"encore.app/models"
+	"encore.app/tenants"
+)
+
+const (
+	bootstrapAdminUID auth.UID = "bootstrap-admin" // Identifies requests made with the bootstrap key
+	metricsScraperUID auth.UID = "metrics-scraper" // Identifies requests made with the metrics scrape token
+)
+
+var secrets struct {
+	// BootstrapAdminAPIKey is accepted as an admin key so the first keys can be issued, leave it unset afterwards
+	BootstrapAdminAPIKey string
+	// MetricsScrapeToken is the bearer token Prometheus scrapes /metrics with, it grants nothing else
+	MetricsScrapeToken string
+}
+
+// AuthHandler resolves the bearer token of every authenticated request to the key it belongs to
+//
+//encore:authhandler
+func AuthHandler(ctx context.Context, token string) (auth.UID, *models.AuthData, error) {
+	if isSecretKey(token, secrets.BootstrapAdminAPIKey) {
+		// Not bound to a tenant, so it acts as the operator managing all tenants
+		return bootstrapAdminUID, &models.AuthData{
+			KeyID:  string(bootstrapAdminUID),
+			Scopes: []models.APIKeyScope{models.ScopeAdmin},
+		}, nil
+	}
+	if isSecretKey(token, secrets.MetricsScrapeToken) {
+		return metricsScraperUID, &models.AuthData{
+			KeyID:  string(metricsScraperUID),
+			Scopes: []models.APIKeyScope{models.ScopeMetrics},
+		}, nil
+	}
+	key, err := findActiveAPIKeyByHash(ctx, models.HashAPIKey(token))
+	if errors.Is(err, errAPIKeyNotFound) {
+		return "", nil, &errs.Error{Code: errs.Unauthenticated, Message: "invalid API key"}
+	}
+	if err != nil {
+		return "", nil, err
+	}
+	return auth.UID(key.KeyID), &models.AuthData{
+		KeyID:      key.KeyID,
+		TenantID:   key.TenantID,
+		CustomerID: key.CustomerID,
+		Scopes:     key.Scopes,
+	}, nil
+}
+
+// VerifyAPIKey resolves a key the same way the auth handler does, for services accepting keys over other protocols
+//
+//encore:api private method=POST path=/apikeys/verify
+func VerifyAPIKey(ctx context.Context, req *models.VerifyAPIKeyRequest) (*models.AuthData, error) {
+	if req.Key == "" {
+		return nil, &errs.Error{Code: errs.Unauthenticated, Message: "missing API key"}
+	}
+	_, data, err := AuthHandler(ctx, req.Key)
+	return data, err
+}
+
+//encore:api auth method=POST path=/apikeys
+func CreateAPIKey(ctx context.Context, req *models.CreateAPIKeyRequest) (*models.CreateAPIKeyResponse, error) {
+	tenantID, err := callerTenant(req.TenantID)
+	if err != nil {
+		return nil, err
+	}
+	if err := authorizeCustomer(tenantID, req.CustomerID, models.ScopeAdmin); err != nil {
+		return nil, err
+	}
+	if err := validateCreateAPIKeyRequest(req); err != nil {
+		return nil, models.InvalidRequest(err)
+	}
+	if _, err := tenants.FindTenant(ctx, tenantID); err != nil {
+		return nil, err
+	}
+	if req.CustomerID != "" {
+		if _, err := customers.FindCustomer(ctx, tenantID, req.CustomerID); err != nil {
+			return nil, err
+		}
+	}
+	key, keyHash, prefix, err := newAPIKey()
+	if err != nil {
+		return nil, err
+	}
+
+	apiKey := &models.APIKey{
+		KeyID:       uuid.New().String(),
+		TenantID:    tenantID,
+		CustomerID:  req.CustomerID,
+		Description: req.Description,
+		KeyPrefix:   prefix,
+		Scopes:      req.Scopes,
+		CreatedAt:   time.Now(),
+	}
+	if err := insertAPIKey(ctx, apiKey, keyHash); err != nil {
+		return nil, err
+	}
+
+	rlog.Info("created API key", "key_id", apiKey.KeyID, "tenant_id", apiKey.TenantID, "customer_id", apiKey.CustomerID, "scopes", apiKey.Scopes)
+	// Only the digest is stored, the key cannot be shown again
+	return &models.CreateAPIKeyResponse{APIKey: apiKey, Key: key}, nil
+}
+
+//encore:api auth method=GET path=/apikeys/customers/:customerId
+func ListAPIKeys(ctx context.Context, customerId string, req *models.ListAPIKeysRequest) (*models.ListAPIKeysResponse, error) {
+	tenantID, err := callerTenant(req.TenantID)
+	if err != nil {
+		return nil, err
+	}
+	if err := authorizeCustomer(tenantID, customerId, models.ScopeRead); err != nil {
+		return nil, err
+	}
+	keys, err := listAPIKeys(ctx, tenantID, customerId)
+	if err != nil {
+		return nil, err
+	}
+	return &models.ListAPIKeysResponse{APIKeys: keys}, nil
+}
+
+//encore:api auth method=DELETE path=/apikeys/keys/:keyId
+func RevokeAPIKey(ctx context.Context, keyId string) error {
+	key, err := findActiveAPIKey(ctx, keyId)
+	if err != nil {
+		return apiKeyError(err, "failed to revoke API key %s", keyId)
+	}
+	// Admin keys have no customer, so only admins of the tenant can revoke them
+	if err := authorizeCustomer(key.TenantID, key.CustomerID, models.ScopeWrite); err != nil {
+		return err
+	}
+	if err := revokeAPIKey(ctx, keyId); err != nil {
+		return apiKeyError(err, "failed to revoke API key %s", keyId)
+	}
+	rlog.Info("revoked API key", "key_id", keyId, "customer_id", key.CustomerID)
+	return nil
+}
@@ -0,0 +1,135 @@
+package apikeys
+
+import (
+	"context"
+	"testing"
+
+	"encore.app/customers"
+	"encore.app/models"
+	"encore.dev/beta/auth"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+)
+
+func createTestCustomer(t *testing.T, customerId string) {
+	t.Helper()
+	_, err := customers.CreateCustomer(adminContext(), &models.CreateCustomerRequest{
+		CustomerID: customerId,
+		CustomerDetails: models.CustomerDetails{
+			LegalName:       "Test Customer",
+			BillingAddress:  models.Address{CountryCode: "GE"},
+			DefaultCurrency: models.USD,
+			Locale:          "en-US",
+			ContactEmails:   []string{"billing@example.com"},
+		},
+	})
+	assert.NoError(t, err)
+}
+
+// adminContext authenticates calls with an admin API key of the default tenant
+func adminContext() context.Context {
+	return auth.WithContext(context.Background(), "test-admin", &models.AuthData{
+		KeyID:    "test-admin",
+		TenantID: models.DefaultTenantID,
+		Scopes:   []models.APIKeyScope{models.ScopeAdmin},
+	})
+}
+
+func TestAPIKeyLifecycle(t *testing.T) {
+	ctx := adminContext()
+	testCustomerId := uuid.New().String()
+	createTestCustomer(t, testCustomerId)
+	var created *models.CreateAPIKeyResponse
+
+	t.Run("Create Key", func(t *testing.T) {
+		var err error
+		created, err = CreateAPIKey(ctx, &models.CreateAPIKeyRequest{
+			CustomerID:  testCustomerId,
+			Description: "ci",
+			Scopes:      []models.APIKeyScope{models.ScopeRead},
+		})
+		assert.NoError(t, err)
+		assert.Regexp(t, `^bk_[0-9a-f]{64}$`, created.Key)
+		assert.Equal(t, created.Key[:11], created.APIKey.KeyPrefix)
+		assert.Equal(t, models.DefaultTenantID, created.APIKey.TenantID)
+	})
+
+	t.Run("Authenticate With Key", func(t *testing.T) {
+		uid, data, err := AuthHandler(context.Background(), created.Key)
+		assert.NoError(t, err)
+		assert.Equal(t, auth.UID(created.APIKey.KeyID), uid)
+		assert.Equal(t, models.DefaultTenantID, data.TenantID)
+		assert.Equal(t, testCustomerId, data.CustomerID)
+		assert.Equal(t, []models.APIKeyScope{models.ScopeRead}, data.Scopes)
+
+		_, _, err = AuthHandler(context.Background(), "bk_unknown")
+		assert.Error(t, err)
+
+		verified, err := VerifyAPIKey(context.Background(), &models.VerifyAPIKeyRequest{Key: created.Key})
+		assert.NoError(t, err)
+		assert.Equal(t, data, verified)
+
+		_, err = VerifyAPIKey(context.Background(), &models.VerifyAPIKeyRequest{})
+		assert.Error(t, err)
+	})
+
+	t.Run("Customer Key Cannot Issue Keys", func(t *testing.T) {
+		customerCtx := auth.WithContext(context.Background(), auth.UID(created.APIKey.KeyID), &models.AuthData{
+			KeyID:      created.APIKey.KeyID,
+			TenantID:   models.DefaultTenantID,
+			CustomerID: testCustomerId,
+			Scopes:     []models.APIKeyScope{models.ScopeWrite},
+		})
+		_, err := CreateAPIKey(customerCtx, &models.CreateAPIKeyRequest{
+			CustomerID: testCustomerId,
+			Scopes:     []models.APIKeyScope{models.ScopeWrite},
+		})
+		assert.Error(t, err)
+		assert.Contains(t, err.Error(), "lacks the admin scope")
+	})
+
+	t.Run("Revoke Key", func(t *testing.T) {
+		err := RevokeAPIKey(ctx, created.APIKey.KeyID)
+		assert.NoError(t, err)
+
+		_, _, err = AuthHandler(context.Background(), created.Key)
+		assert.Error(t, err)
+
+		resp, err := ListAPIKeys(ctx, testCustomerId, &models.ListAPIKeysRequest{})
+		assert.NoError(t, err)
+		assert.Len(t, resp.APIKeys, 1)
+		assert.NotNil(t, resp.APIKeys[0].RevokedAt)
+	})
+}
+
+func TestAPIKeyTenants(t *testing.T) {
+	testCustomerId := uuid.New().String()
+	createTestCustomer(t, testCustomerId)
+
+	t.Run("Admin Cannot Issue Keys For Another Tenant", func(t *testing.T) {
+		_, err := CreateAPIKey(adminContext(), &models.CreateAPIKeyRequest{
+			TenantID:   "other-" + uuid.New().String()[:8],
+			CustomerID: testCustomerId,
+			Scopes:     []models.APIKeyScope{models.ScopeRead},
+		})
+		assert.Error(t, err)
+		assert.Contains(t, err.Error(), "may not act on tenant")
+	})
+
+	t.Run("Operator Must Name The Tenant", func(t *testing.T) {
+		operatorCtx := auth.WithContext(context.Background(), bootstrapAdminUID, &models.AuthData{
+			KeyID:  string(bootstrapAdminUID),
+			Scopes: []models.APIKeyScope{models.ScopeAdmin},
+		})
+		_, err := CreateAPIKey(operatorCtx, &models.CreateAPIKeyRequest{Scopes: []models.APIKeyScope{models.ScopeAdmin}})
+		assert.Error(t, err)
+		assert.Contains(t, err.Error(), "tenant_id is required")
+
+		resp, err := CreateAPIKey(operatorCtx, &models.CreateAPIKeyRequest{
+			TenantID: models.DefaultTenantID,
+			Scopes:   []models.APIKeyScope{models.ScopeAdmin},
+		})
+		assert.NoError(t, err)
+		assert.Equal(t, models.DefaultTenantID, resp.APIKey.TenantID)
+	})
+}
@@ -0,0 +1,119 @@
+package apikeys
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"encore.dev/storage/sqldb"
+
+	"encore.app/models"
+)
+
+// API keys live in their own database, only their SHA-256 digests are stored
+var db = sqldb.NewDatabase("apikeys", sqldb.DatabaseConfig{
+	Migrations: "./migrations",
+})
+
+// errAPIKeyNotFound is returned when a key does not exist or has been revoked
+var errAPIKeyNotFound = errors.New("API key not found")
+
+const apiKeyColumns = `key_id, tenant_id, customer_id, description, key_prefix, scopes, created_at, revoked_at`
+
+type rowScanner interface {
+	Scan(dest ...interface{}) error
+}
+
+func insertAPIKey(ctx context.Context, key *models.APIKey, keyHash string) error {
+	scopes, err := json.Marshal(key.Scopes)
+	if err != nil {
+		return fmt.Errorf("failed to encode scopes: %w", err)
+	}
+	_, err = db.Exec(ctx, `
+		INSERT INTO api_keys (key_id, key_hash, key_prefix, tenant_id, customer_id, description, scopes, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+	`, key.KeyID, keyHash, key.KeyPrefix, key.TenantID, key.CustomerID, key.Description, scopes, key.CreatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to insert API key: %w", err)
+	}
+	return nil
+}
+
+// findActiveAPIKeyByHash resolves a presented key, revoked keys are not found
+func findActiveAPIKeyByHash(ctx context.Context, keyHash string) (*models.APIKey, error) {
+	row := db.QueryRow(ctx, `
+		SELECT `+apiKeyColumns+`
+		FROM api_keys
+		WHERE key_hash = $1 AND revoked_at IS NULL
+	`, keyHash)
+	key, err := scanAPIKey(row)
+	if errors.Is(err, sqldb.ErrNoRows) {
+		return nil, errAPIKeyNotFound
+	}
+	return key, err
+}
+
+func findActiveAPIKey(ctx context.Context, keyID string) (*models.APIKey, error) {
+	row := db.QueryRow(ctx, `
+		SELECT `+apiKeyColumns+`
+		FROM api_keys
+		WHERE key_id = $1 AND revoked_at IS NULL
+	`, keyID)
+	key, err := scanAPIKey(row)
+	if errors.Is(err, sqldb.ErrNoRows) {
+		return nil, errAPIKeyNotFound
+	}
+	return key, err
+}
+
+// listAPIKeys returns every key issued to the customer of the tenant, revoked ones included
+func listAPIKeys(ctx context.Context, tenantID string, customerID string) ([]*models.APIKey, error) {
+	rows, err := db.Query(ctx, `
+		SELECT `+apiKeyColumns+`
+		FROM api_keys
+		WHERE tenant_id = $1 AND customer_id = $2
+		ORDER BY created_at, key_id
+	`, tenantID, customerID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list API keys: %w", err)
+	}
+	defer rows.Close()
+
+	keys := make([]*models.APIKey, 0)
+	for rows.Next() {
+		key, err := scanAPIKey(rows)
+		if err != nil {
+			return nil, err
+		}
+		keys = append(keys, key)
+	}
+	return keys, rows.Err()
+}
+
+func revokeAPIKey(ctx context.Context, keyID string) error {
+	result, err := db.Exec(ctx, `
+		UPDATE api_keys SET revoked_at = NOW()
+		WHERE key_id = $1 AND revoked_at IS NULL
+	`, keyID)
+	if err != nil {
+		return fmt.Errorf("failed to revoke API key: %w", err)
+	}
+	if result.RowsAffected() == 0 {
+		return errAPIKeyNotFound
+	}
+	return nil
+}
+
+func scanAPIKey(row rowScanner) (*models.APIKey, error) {
+	var key models.APIKey
+	var scopes []byte
+	err := row.Scan(&key.KeyID, &key.TenantID, &key.CustomerID, &key.Description, &key.KeyPrefix, &scopes, &key.CreatedAt, &key.RevokedAt)
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(scopes, &key.Scopes); err != nil {
+		return nil, fmt.Errorf("failed to decode scopes: %w", err)
+	}
+	return &key, nil
+}
@@ -0,0 +1,13 @@
+CREATE TABLE api_keys (
+    key_id      TEXT PRIMARY KEY,
+    -- Only the SHA-256 digest of the key is stored, the key itself is shown once at creation
+    key_hash    TEXT NOT NULL UNIQUE,
+    key_prefix  TEXT NOT NULL,
+    customer_id TEXT NOT NULL DEFAULT '',
+    description TEXT NOT NULL DEFAULT '',
+    scopes      JSONB NOT NULL,
+    created_at  TIMESTAMPTZ NOT NULL,
+    revoked_at  TIMESTAMPTZ
+);
+
+CREATE INDEX api_keys_customer_id_idx ON api_keys (customer_id);
@@ -0,0 +1,5 @@
+-- Keys issued before tenants existed belong to the default tenant
+ALTER TABLE api_keys ADD COLUMN tenant_id TEXT NOT NULL DEFAULT 'default';
+
+DROP INDEX api_keys_customer_id_idx;
+CREATE INDEX api_keys_tenant_customer_idx ON api_keys (tenant_id, customer_id);
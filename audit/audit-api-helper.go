@@ -0,0 +1,47 @@
+package audit
+
+import (
+	"fmt"
+
+	"encore.dev/beta/auth"
+	"encore.dev/beta/errs"
+
+	"encore.app/models"
+)
+
+func validateAuditRecord(record *models.AuditRecord) error {
+	if record.RecordID == "" {
+		return fmt.Errorf("record_id is required")
+	}
+	if record.TenantID == "" || record.CustomerID == "" {
+		return fmt.Errorf("tenant_id and customer_id are required")
+	}
+	if !record.Action.IsValid() {
+		return fmt.Errorf("invalid audit action: %s", record.Action)
+	}
+	if record.Source != models.AuditSourceAPI && record.Source != models.AuditSourceWorkflow {
+		return fmt.Errorf("invalid audit source: %s", record.Source)
+	}
+	if record.Actor == "" {
+		return fmt.Errorf("actor is required")
+	}
+	if record.OccurredAt.IsZero() {
+		return fmt.Errorf("occurred_at is required")
+	}
+	return nil
+}
+
+// authorizeCustomer checks that the calling API key may read the customer's audit log and returns the key's tenant
+func authorizeCustomer(customerID string) (string, error) {
+	data, _ := auth.Data().(*models.AuthData)
+	if data == nil {
+		return "", &errs.Error{Code: errs.Unauthenticated, Message: "missing API key"}
+	}
+	if data.TenantID == "" {
+		return "", &errs.Error{Code: errs.PermissionDenied, Message: "the operator key cannot read audit records, use an admin key of the tenant"}
+	}
+	if err := data.Authorize(data.TenantID, customerID, models.ScopeRead); err != nil {
+		return "", &errs.Error{Code: errs.PermissionDenied, Message: err.Error()}
+	}
+	return data.TenantID, nil
+}
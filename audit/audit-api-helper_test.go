@@ -0,0 +1,52 @@
+package audit
+
+import (
+	"testing"
+	"time"
+
+	"encore.app/models"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestValidateAuditRecord(t *testing.T) {
+	t.Parallel()
+	valid := func() models.AuditRecord {
+		return models.AuditRecord{
+			RecordID:   "record-1",
+			TenantID:   models.DefaultTenantID,
+			CustomerID: "cust-1",
+			Action:     models.AuditAddLineItem,
+			Source:     models.AuditSourceAPI,
+			Actor:      "key-1",
+			OccurredAt: time.Now(),
+		}
+	}
+	cases := []struct {
+		name    string
+		mutate  func(*models.AuditRecord)
+		wantErr string
+	}{
+		{"valid record", func(r *models.AuditRecord) {}, ""},
+		{"missing record id", func(r *models.AuditRecord) { r.RecordID = "" }, "record_id is required"},
+		{"missing customer", func(r *models.AuditRecord) { r.CustomerID = "" }, "customer_id are required"},
+		{"unknown action", func(r *models.AuditRecord) { r.Action = "bill.delete" }, "invalid audit action"},
+		{"unknown source", func(r *models.AuditRecord) { r.Source = "cron" }, "invalid audit source"},
+		{"missing actor", func(r *models.AuditRecord) { r.Actor = "" }, "actor is required"},
+		{"missing time", func(r *models.AuditRecord) { r.OccurredAt = time.Time{} }, "occurred_at is required"},
+	}
+	for _, tc := range cases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+			record := valid()
+			tc.mutate(&record)
+			err := validateAuditRecord(&record)
+			if tc.wantErr == "" {
+				assert.NoError(t, err)
+				return
+			}
+			assert.Error(t, err)
+			assert.Contains(t, err.Error(), tc.wantErr)
+		})
+	}
+}
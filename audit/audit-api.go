@@ -0,0 +1,47 @@
+package audit
+
+import (
+	"context"
+
+	"encore.app/models"
+)
+
+// AppendRecord appends a bill mutation to the audit log, appending a record ID twice keeps the first record
+//
+//encore:api private method=POST path=/audit/records
+func AppendRecord(ctx context.Context, record *models.AuditRecord) error {
+	if err := validateAuditRecord(record); err != nil {
+		return models.InvalidRequest(err)
+	}
+	return insertRecord(ctx, record)
+}
+
+// ListBillRecords returns who changed the bill and when, oldest first
+//
+//encore:api auth method=GET path=/audit/customers/:customerId/bills/:billId
+func ListBillRecords(ctx context.Context, customerId string, billId string) (*models.ListAuditRecordsResponse, error) {
+	tenantID, err := authorizeCustomer(customerId)
+	if err != nil {
+		return nil, err
+	}
+	return listAuditRecords(ctx, tenantID, customerId, billId)
+}
+
+// ListCustomerRecords returns the audit log of every bill and billing period of the customer, oldest first
+//
+//encore:api auth method=GET path=/audit/customers/:customerId
+func ListCustomerRecords(ctx context.Context, customerId string) (*models.ListAuditRecordsResponse, error) {
+	tenantID, err := authorizeCustomer(customerId)
+	if err != nil {
+		return nil, err
+	}
+	return listAuditRecords(ctx, tenantID, customerId, "")
+}
+
+func listAuditRecords(ctx context.Context, tenantID, customerID, billID string) (*models.ListAuditRecordsResponse, error) {
+	records, err := listRecords(ctx, tenantID, customerID, billID)
+	if err != nil {
+		return nil, err
+	}
+	return &models.ListAuditRecordsResponse{Records: records, Total: int64(len(records))}, nil
+}
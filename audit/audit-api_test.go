@@ -0,0 +1,66 @@
+package audit
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"encore.app/models"
+	"encore.dev/beta/auth"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+)
+
+// customerContext authenticates calls with a read API key bound to the customer of the default tenant
+func customerContext(customerId string) context.Context {
+	return auth.WithContext(context.Background(), auth.UID("test-"+customerId), &models.AuthData{
+		KeyID:      "test-" + customerId,
+		TenantID:   models.DefaultTenantID,
+		CustomerID: customerId,
+		Scopes:     []models.APIKeyScope{models.ScopeRead},
+	})
+}
+
+func TestAuditLog(t *testing.T) {
+	customerId := uuid.New().String()
+	total := 10.0
+	record := &models.AuditRecord{
+		RecordID:   models.WorkflowAuditRecordID("wf-"+customerId, models.AuditAddLineItem, "bill-1/item-1"),
+		TenantID:   models.DefaultTenantID,
+		CustomerID: customerId,
+		BillID:     "bill-1",
+		Action:     models.AuditAddLineItem,
+		Source:     models.AuditSourceWorkflow,
+		Actor:      "key-1",
+		TotalAfter: &total,
+		Currency:   models.USD,
+		OccurredAt: time.Now(),
+	}
+
+	t.Run("Appending Twice Keeps One Record", func(t *testing.T) {
+		assert.NoError(t, AppendRecord(context.Background(), record))
+		retried := *record
+		retried.Actor = "key-2"
+		assert.NoError(t, AppendRecord(context.Background(), &retried))
+
+		resp, err := ListBillRecords(customerContext(customerId), customerId, "bill-1")
+		assert.NoError(t, err)
+		assert.Len(t, resp.Records, 1)
+		assert.Equal(t, "key-1", resp.Records[0].Actor)
+		assert.Equal(t, 10.0, *resp.Records[0].TotalAfter)
+		assert.Nil(t, resp.Records[0].TotalBefore)
+	})
+
+	t.Run("Records Cannot Be Changed Or Removed", func(t *testing.T) {
+		_, err := db.Exec(context.Background(), `UPDATE audit_records SET actor = 'someone-else' WHERE record_id = $1`, record.RecordID)
+		assert.Error(t, err)
+		_, err = db.Exec(context.Background(), `DELETE FROM audit_records WHERE record_id = $1`, record.RecordID)
+		assert.Error(t, err)
+	})
+
+	t.Run("Other Customers Cannot Read The Records", func(t *testing.T) {
+		_, err := ListCustomerRecords(customerContext(uuid.New().String()), customerId)
+		assert.Error(t, err)
+		assert.Contains(t, err.Error(), "may not act on customer")
+	})
+}
@@ -0,0 +1,59 @@
+package audit
+
+import (
+	"context"
+	"fmt"
+
+	"encore.dev/storage/sqldb"
+
+	"encore.app/models"
+)
+
+// The audit log lives in its own append-only database, a trigger rejects updates and deletes
+var db = sqldb.NewDatabase("audit", sqldb.DatabaseConfig{
+	Migrations: "./migrations",
+})
+
+const recordColumns = `record_id, tenant_id, customer_id, bill_id, workflow_id, action, source, actor, request_id,
+	total_before, total_after, currency, reason, error, occurred_at`
+
+// insertRecord appends the record, a record whose ID is already stored is kept as it was
+func insertRecord(ctx context.Context, record *models.AuditRecord) error {
+	_, err := db.Exec(ctx, `
+		INSERT INTO audit_records (`+recordColumns+`)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15)
+		ON CONFLICT (record_id) DO NOTHING
+	`, record.RecordID, record.TenantID, record.CustomerID, record.BillID, record.WorkflowID, record.Action,
+		record.Source, record.Actor, record.RequestID, record.TotalBefore, record.TotalAfter, record.Currency,
+		record.Reason, record.Error, record.OccurredAt)
+	if err != nil {
+		return fmt.Errorf("failed to insert audit record: %w", err)
+	}
+	return nil
+}
+
+// listRecords returns the records of the customer, or of one of its bills when billID is set, oldest first
+func listRecords(ctx context.Context, tenantID, customerID, billID string) ([]*models.AuditRecord, error) {
+	rows, err := db.Query(ctx, `
+		SELECT `+recordColumns+`
+		FROM audit_records
+		WHERE tenant_id = $1 AND customer_id = $2 AND ($3 = '' OR bill_id = $3)
+		ORDER BY occurred_at, recorded_at, record_id
+	`, tenantID, customerID, billID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list audit records: %w", err)
+	}
+	defer rows.Close()
+
+	records := make([]*models.AuditRecord, 0)
+	for rows.Next() {
+		var record models.AuditRecord
+		if err := rows.Scan(&record.RecordID, &record.TenantID, &record.CustomerID, &record.BillID, &record.WorkflowID,
+			&record.Action, &record.Source, &record.Actor, &record.RequestID, &record.TotalBefore, &record.TotalAfter,
+			&record.Currency, &record.Reason, &record.Error, &record.OccurredAt); err != nil {
+			return nil, fmt.Errorf("failed to scan audit record: %w", err)
+		}
+		records = append(records, &record)
+	}
+	return records, rows.Err()
+}
@@ -0,0 +1,36 @@
+CREATE TABLE audit_records (
+    record_id    TEXT PRIMARY KEY,
+    tenant_id    TEXT NOT NULL,
+    customer_id  TEXT NOT NULL,
+    bill_id      TEXT NOT NULL DEFAULT '',
+    workflow_id  TEXT NOT NULL DEFAULT '',
+    action       TEXT NOT NULL,
+    source       TEXT NOT NULL,
+    actor        TEXT NOT NULL,
+    request_id   TEXT NOT NULL DEFAULT '',
+    total_before DOUBLE PRECISION,
+    total_after  DOUBLE PRECISION,
+    currency     TEXT NOT NULL DEFAULT '',
+    reason       TEXT NOT NULL DEFAULT '',
+    error        TEXT NOT NULL DEFAULT '',
+    occurred_at  TIMESTAMPTZ NOT NULL,
+    recorded_at  TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX audit_records_customer_idx ON audit_records (tenant_id, customer_id, occurred_at);
+CREATE INDEX audit_records_bill_idx ON audit_records (tenant_id, bill_id, occurred_at);
+
+-- The audit log is append-only, records can be neither changed nor removed
+CREATE FUNCTION reject_audit_record_change() RETURNS TRIGGER AS $$
+BEGIN
+    RAISE EXCEPTION 'audit records are append-only';
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER audit_records_append_only
+    BEFORE UPDATE OR DELETE ON audit_records
+    FOR EACH ROW EXECUTE FUNCTION reject_audit_record_change();
+
+CREATE TRIGGER audit_records_no_truncate
+    BEFORE TRUNCATE ON audit_records
+    FOR EACH STATEMENT EXECUTE FUNCTION reject_audit_record_change();
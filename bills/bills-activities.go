@@ -0,0 +1,108 @@
+package bills
+
+import (
+	"context"
+
+	"encore.dev/pubsub"
+	"encore.dev/rlog"
+
+	"encore.app/audit"
+	"encore.app/events"
+	"encore.app/models"
+	"encore.app/wallets"
+)
+
+// Activities holds the Temporal activities of the bill workflow, they call other Encore services
+// so they live next to the service rather than in the workflows package
+type Activities struct{}
+
+// DrawDownCredits applies the customer's prepaid credits to a bill through the wallets service, billing periods
+// started before tenants existed draw from wallets of the default tenant
+func (a *Activities) DrawDownCredits(ctx context.Context, input models.DrawDownCreditsInput) (*models.DrawDownResponse, error) {
+	tenantID := input.TenantID
+	if tenantID == "" {
+		tenantID = models.DefaultTenantID
+	}
+	return wallets.DrawDown(ctx, tenantID, input.CustomerID, &models.DrawDownRequest{
+		BillID:    input.BillID,
+		Reference: input.Reference,
+		Amount:    input.Amount,
+		Currency:  input.Currency,
+	})
+}
+
+// EmitSpendingAlert publishes a crossed spending threshold for downstream notification
+func (a *Activities) EmitSpendingAlert(ctx context.Context, event models.SpendingAlertEvent) error {
+	return publishOnce(ctx, pubsub.TopicRef[pubsub.Publisher[*models.SpendingAlertEvent]](events.SpendingAlerts), event.EventID, &event)
+}
+
+// PublishBillCreated publishes the creation of a bill
+func (a *Activities) PublishBillCreated(ctx context.Context, event models.BillCreatedEvent) error {
+	return publishOnce(ctx, pubsub.TopicRef[pubsub.Publisher[*models.BillCreatedEvent]](events.BillCreated), event.EventID, &event)
+}
+
+// PublishLineItemAdded publishes a line item added to a bill
+func (a *Activities) PublishLineItemAdded(ctx context.Context, event models.LineItemAddedEvent) error {
+	return publishOnce(ctx, pubsub.TopicRef[pubsub.Publisher[*models.LineItemAddedEvent]](events.LineItemAdded), event.EventID, &event)
+}
+
+// PublishBillClosed publishes the final state of a closed bill
+func (a *Activities) PublishBillClosed(ctx context.Context, event models.BillClosedEvent) error {
+	return publishOnce(ctx, pubsub.TopicRef[pubsub.Publisher[*models.BillClosedEvent]](events.BillClosed), event.EventID, &event)
+}
+
+// PublishBillingPeriodClosed publishes the end of a billing period
+func (a *Activities) PublishBillingPeriodClosed(ctx context.Context, event models.BillingPeriodClosedEvent) error {
+	return publishOnce(ctx, pubsub.TopicRef[pubsub.Publisher[*models.BillingPeriodClosedEvent]](events.BillingPeriodClosed), event.EventID, &event)
+}
+
+// AppendAuditRecord appends a mutation applied by the bill workflow to the audit log
+func (a *Activities) AppendAuditRecord(ctx context.Context, record models.AuditRecord) error {
+	return audit.AppendRecord(ctx, &record)
+}
+
+// ArchiveBillingPeriod writes a finished billing period to the bills database
+func (a *Activities) ArchiveBillingPeriod(ctx context.Context, period models.ArchivedBillingPeriod) error {
+	if err := insertArchivedPeriod(ctx, &period); err != nil {
+		return err
+	}
+	rlog.Info("archived billing period", "workflow_id", period.WorkflowID, "customer_id", period.CustomerID, "bills", len(period.Bills))
+	return nil
+}
+
+// ConsolidateBillingPeriod closes the billing periods of a parent account's child accounts when its own period
+// ends and rolls their bills up with the parent's. It returns nil when no child account was billed in the period.
+func (a *Activities) ConsolidateBillingPeriod(ctx context.Context, input models.ConsolidateBillingPeriodInput) (*models.ConsolidatedBill, error) {
+	tenantID := input.TenantID
+	if tenantID == "" {
+		tenantID = models.DefaultTenantID
+	}
+	childAccounts, err := finalizeChildAccounts(ctx, tenantID, input.CustomerID, input.ChildAccounts, input.StartedAt)
+	if err != nil {
+		return nil, err
+	}
+	childAccounts = append(input.ChildAccounts, childAccounts...)
+	if len(childAccounts) == 0 {
+		return nil, nil
+	}
+	return consolidateBillingPeriod(ctx, tenantID, input.CustomerID, input.WorkflowID, input.Bills, childAccounts, input.ClosedAt)
+}
+
+// publishOnce publishes the event unless its ID was already published by an earlier attempt.
+// A crash between publishing and recording the ID can still publish twice, subscribers dedupe on event_id.
+func publishOnce[T any](ctx context.Context, topic pubsub.Publisher[*T], eventID string, event *T) error {
+	published, err := isEventPublished(ctx, eventID)
+	if err != nil {
+		return err
+	}
+	if published {
+		rlog.Info("skipped already published event", "topic", topic.Meta().Name, "event_id", eventID)
+		return nil
+	}
+	messageID, err := topic.Publish(ctx, event)
+	if err != nil {
+		return err
+	}
+	rlog.Info("published event", "topic", topic.Meta().Name, "event_id", eventID, "message_id", messageID)
+	return markEventPublished(ctx, eventID, topic.Meta().Name, messageID)
+}
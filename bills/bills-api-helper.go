@@ -1,11 +1,41 @@
 package bills
 
 import (
+	"context"
+	"errors"
 	"fmt"
+	"strconv"
+	"strings"
 
+	"encore.dev/beta/auth"
+	"encore.dev/beta/errs"
+	enumspb "go.temporal.io/api/enums/v1"
+	"go.temporal.io/api/serviceerror"
+	workflowpb "go.temporal.io/api/workflow/v1"
+	"go.temporal.io/api/workflowservice/v1"
+	"go.temporal.io/sdk/converter"
+
+	"encore.app/constants"
 	"encore.app/models"
+	"encore.app/temporalconn"
 )
 
+// authorizeCustomer checks that the calling API key may act on the customer with the given scope and
+// returns the key's tenant, every bill operation runs within it
+func authorizeCustomer(customerID string, scope models.APIKeyScope) (string, error) {
+	data, _ := auth.Data().(*models.AuthData)
+	if data == nil {
+		return "", &errs.Error{Code: errs.Unauthenticated, Message: "missing API key"}
+	}
+	if data.TenantID == "" {
+		return "", &errs.Error{Code: errs.PermissionDenied, Message: "the operator key cannot act on bills, use an admin key of the tenant"}
+	}
+	if err := data.Authorize(data.TenantID, customerID, scope); err != nil {
+		return "", &errs.Error{Code: errs.PermissionDenied, Message: err.Error()}
+	}
+	return data.TenantID, nil
+}
+
 func validateCreateBillRequest(req *models.CreateBillRequest) error {
 	if req.CustomerID == "" {
 		return fmt.Errorf("customer_id is required")
@@ -29,6 +59,11 @@ func validateAddLineItemRequest(req *models.AddLineItemRequest) error {
 	if !models.Currency(req.Currency).IsValid() {
 		return fmt.Errorf("invalid currency: %s (supported: USD, GEL)", req.Currency)
 	}
+	if req.Recognition != nil {
+		if err := req.Recognition.Validate(); err != nil {
+			return err
+		}
+	}
 	return nil
 }
 
@@ -39,12 +74,280 @@ func validateStartBillingPeriodRequest(req *models.StartBillingPeriodRequest) er
 	if req.BillingPeriodDays <= 0 {
 		return fmt.Errorf("billing_period_days must be positive")
 	}
+	if req.SpendingLimit != nil {
+		if err := validateSpendingLimit(req.SpendingLimit); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func validateSpendingLimit(req *models.SpendingLimit) error {
+	if req.HardCap <= 0 {
+		return fmt.Errorf("hard_cap must be positive")
+	}
+	if req.Currency != "" && !req.Currency.IsValid() {
+		return fmt.Errorf("invalid currency: %s (supported: USD, GEL)", req.Currency)
+	}
+	seen := make(map[float64]bool, len(req.Thresholds))
+	for _, threshold := range req.Thresholds {
+		if threshold <= 0 || threshold > 1 {
+			return fmt.Errorf("thresholds must be fractions of the hard cap between 0 and 1, got %v", threshold)
+		}
+		if seen[threshold] {
+			return fmt.Errorf("duplicate threshold: %v", threshold)
+		}
+		seen[threshold] = true
+	}
 	return nil
 }
 
+// mergeArchivedBills lists archived bills before the bills of the active period. A period that ended on its
+// timer is both archived and still queryable, its bills are listed once.
+func mergeArchivedBills(archived []*models.Bill, active []*models.Bill) []*models.Bill {
+	merged := make([]*models.Bill, 0, len(archived)+len(active))
+	seen := make(map[string]bool, len(active))
+	for _, bill := range active {
+		seen[bill.ID] = true
+	}
+	for _, bill := range archived {
+		if !seen[bill.ID] {
+			merged = append(merged, bill)
+		}
+	}
+	return append(merged, active...)
+}
+
+// listBillsPage merges the pages of archived and active bills into one. Each holds up to one bill more than
+// the page size, the merged page ends where the next one starts.
+func listBillsPage(req *models.ListBillsRequest, archived []*models.Bill, active []*models.Bill) (*models.ListBillsResponse, error) {
+	page := *req
+	page.Cursor = ""
+	page.PageSize = req.PageSize + 1
+	bills, err := models.SelectBills(page, mergeArchivedBills(archived, active))
+	if err != nil {
+		return nil, err
+	}
+	response := &models.ListBillsResponse{Bills: bills}
+	if len(bills) > req.PageSize {
+		response.Bills = bills[:req.PageSize]
+		response.NextCursor = models.NewBillCursor(req.Sort, response.Bills[req.PageSize-1])
+	}
+	response.Total = int64(len(response.Bills))
+	return response, nil
+}
+
 func validateListBillsRequest(req *models.ListBillsRequest) error {
-	if !models.BillStatus(req.Status).IsValid() {
+	if req.Status != "" && !models.BillStatus(req.Status).IsValid() {
+		return fmt.Errorf("invalid status: %s (supported: OPEN, CLOSED)", req.Status)
+	}
+	if req.Currency != "" && !req.Currency.IsValid() {
+		return fmt.Errorf("invalid currency: %s (supported: USD, GEL)", req.Currency)
+	}
+	if req.CreatedFrom != nil && req.CreatedTo != nil && !req.CreatedFrom.Before(*req.CreatedTo) {
+		return fmt.Errorf("created_from must be before created_to")
+	}
+	if req.ClosedFrom != nil && req.ClosedTo != nil && !req.ClosedFrom.Before(*req.ClosedTo) {
+		return fmt.Errorf("closed_from must be before closed_to")
+	}
+	if (req.MinTotal != nil && *req.MinTotal < 0) || (req.MaxTotal != nil && *req.MaxTotal < 0) {
+		return fmt.Errorf("min_total and max_total must not be negative")
+	}
+	if req.MinTotal != nil && req.MaxTotal != nil && *req.MinTotal > *req.MaxTotal {
+		return fmt.Errorf("min_total must not exceed max_total")
+	}
+	if !req.Sort.IsValid() {
+		return fmt.Errorf("invalid sort: %s (supported: created_at, closed_at, total_amount, prefixed with - for descending)", req.Sort)
+	}
+	if req.PageSize < 0 || req.PageSize > models.MaxBillsPageSize {
+		return fmt.Errorf("page_size must be between 1 and %d", models.MaxBillsPageSize)
+	}
+	if req.Cursor != "" {
+		cursor, err := models.DecodeBillCursor(req.Cursor)
+		if err != nil {
+			return err
+		}
+		if cursor.Sort != req.Sort.OrDefault() {
+			return fmt.Errorf("cursor was issued for sort %s, not %s", cursor.Sort, req.Sort.OrDefault())
+		}
+	}
+	return nil
+}
+
+func validateLegalEntityProfile(req *models.LegalEntityProfile) error {
+	if req.LegalName == "" {
+		return fmt.Errorf("legal_name is required")
+	}
+	if req.VATID == "" {
+		return fmt.Errorf("vat_id is required")
+	}
+	if req.EndpointID == "" || req.EndpointScheme == "" {
+		return fmt.Errorf("endpoint_id and endpoint_scheme are required")
+	}
+	if !req.Address.IsComplete() {
+		return fmt.Errorf("address country_code must be an ISO 3166-1 alpha-2 code")
+	}
+	if req.VATRate < 0 || req.VATRate > 100 {
+		return fmt.Errorf("vat_rate must be between 0 and 100")
+	}
+	return nil
+}
+
+// billingPeriodNotFound is returned when the customer has no active billing period to act on
+func billingPeriodNotFound(customerID string) error {
+	return models.NewAPIError(errs.NotFound, models.ReasonBillingPeriodNotFound, "billing period not started for customer %s", customerID)
+}
+
+// temporalNotConnected is returned while the service has no Temporal connection, cause is the last failed attempt
+func temporalNotConnected(cause error) error {
+	if cause == nil || errors.Is(cause, temporalconn.ErrNotConnected) {
+		return models.NewAPIError(errs.Unavailable, models.ReasonWorkflowUnavailable, "not connected to Temporal yet")
+	}
+	return models.NewAPIErrorWithCause(errs.Unavailable, models.ReasonWorkflowUnavailable, cause, "not connected to Temporal")
+}
+
+// temporalError maps a failed call on a billing period workflow to an API error. A query handler failing
+// means what was asked for is not in the period, reported with the notFound reason.
+func temporalError(err error, notFound models.ErrorReason, format string, args ...interface{}) error {
+	message := fmt.Sprintf(format, args...)
+	var (
+		workflowNotFound *serviceerror.NotFound
+		queryFailed      *serviceerror.QueryFailed
+		alreadyStarted   *serviceerror.WorkflowExecutionAlreadyStarted
+		invalidArgument  *serviceerror.InvalidArgument
+		unavailable      *serviceerror.Unavailable
+		deadlineExceeded *serviceerror.DeadlineExceeded
+		exhausted        *serviceerror.ResourceExhausted
+	)
+	switch {
+	case errors.As(err, &queryFailed):
+		return models.NewAPIErrorWithCause(errs.NotFound, notFound, err, "%s: %s", message, queryFailed.Message)
+	case errors.As(err, &workflowNotFound):
+		return models.NewAPIErrorWithCause(errs.NotFound, models.ReasonBillingPeriodNotFound, err, "%s: the billing period has ended", message)
+	case errors.As(err, &alreadyStarted):
+		return models.NewAPIErrorWithCause(errs.AlreadyExists, models.ReasonBillingPeriodExists, err, "%s: the billing period is already running", message)
+	case errors.As(err, &invalidArgument):
+		return models.NewAPIErrorWithCause(errs.FailedPrecondition, models.ReasonWorkflowRequestRejected, err, "%s", message)
+	case errors.As(err, &unavailable), errors.As(err, &deadlineExceeded), errors.As(err, &exhausted),
+		errors.Is(err, context.DeadlineExceeded):
+		return models.NewAPIErrorWithCause(errs.Unavailable, models.ReasonWorkflowUnavailable, err, "%s: Temporal is unavailable", message)
+	}
+	return models.NewAPIErrorWithCause(errs.Internal, models.ReasonInternal, err, "%s", message)
+}
+
+// billSearchQuery returns the visibility query finding the tenant's billing periods matching the search
+func billSearchQuery(tenantID string, req *models.SearchBillsRequest) string {
+	// Only bill workflows set the tenant attribute
+	conditions := []string{fmt.Sprintf("%s = '%s'", constants.TenantIDSearchAttribute, tenantID)}
+	if req.CustomerID != "" {
+		conditions = append(conditions, fmt.Sprintf("%s = '%s'", constants.CustomerIDSearchAttribute, req.CustomerID))
+	}
+	if req.BillID != "" {
+		conditions = append(conditions, fmt.Sprintf("%s = '%s'", constants.BillIDsSearchAttribute, req.BillID))
+	}
+	if req.Currency != "" {
+		conditions = append(conditions, fmt.Sprintf("%s = '%s'", constants.CurrencySearchAttribute, req.Currency))
+	}
+	if req.Status != "" {
+		conditions = append(conditions, fmt.Sprintf("%s = '%s'", constants.PeriodStatusSearchAttribute, req.Status))
+	}
+	if req.MinOpenBills > 0 {
+		conditions = append(conditions, fmt.Sprintf("%s >= %d", constants.OpenBillCountSearchAttribute, req.MinOpenBills))
+	}
+	if req.MinTotal > 0 {
+		conditions = append(conditions, fmt.Sprintf("%s >= %s", constants.RunningTotalSearchAttribute, strconv.FormatFloat(req.MinTotal, 'f', -1, 64)))
+	}
+	if req.MaxTotal > 0 {
+		conditions = append(conditions, fmt.Sprintf("%s <= %s", constants.RunningTotalSearchAttribute, strconv.FormatFloat(req.MaxTotal, 'f', -1, 64)))
+	}
+	return strings.Join(conditions, " AND ")
+}
+
+// newBillingPeriodSearchResult reads a billing period back from the search attributes of its workflow
+func newBillingPeriodSearchResult(info *workflowpb.WorkflowExecutionInfo) (*models.BillingPeriodSearchResult, error) {
+	fields := info.GetSearchAttributes().GetIndexedFields()
+	dataConverter := converter.GetDefaultDataConverter()
+	decode := func(name string, value interface{}) error {
+		payload, ok := fields[name]
+		if !ok {
+			return nil
+		}
+		if err := dataConverter.FromPayload(payload, value); err != nil {
+			return fmt.Errorf("failed to decode search attribute %s: %w", name, err)
+		}
+		return nil
+	}
+
+	var (
+		customerID, currency, status string
+		openBills                    int64
+	)
+	period := &models.BillingPeriodSearchResult{
+		WorkflowID: info.GetExecution().GetWorkflowId(),
+		StartedAt:  info.GetStartTime().AsTime(),
+		BillIDs:    make([]string, 0),
+	}
+	for name, value := range map[string]interface{}{
+		constants.CustomerIDSearchAttribute:    &customerID,
+		constants.CurrencySearchAttribute:      &currency,
+		constants.PeriodStatusSearchAttribute:  &status,
+		constants.OpenBillCountSearchAttribute: &openBills,
+		constants.RunningTotalSearchAttribute:  &period.RunningTotal,
+		constants.BillIDsSearchAttribute:       &period.BillIDs,
+	} {
+		if err := decode(name, value); err != nil {
+			return nil, err
+		}
+	}
+	period.CustomerID = customerID
+	period.Currency = models.Currency(currency)
+	period.Status = models.BillStatus(status)
+	period.OpenBillCount = int(openBills)
+	if info.GetCloseTime() != nil {
+		closedAt := info.GetCloseTime().AsTime()
+		period.ClosedAt = &closedAt
+	}
+	return period, nil
+}
+
+func validateSearchBillsRequest(req *models.SearchBillsRequest) error {
+	for name, value := range map[string]string{"customer_id": req.CustomerID, "bill_id": req.BillID} {
+		if strings.ContainsAny(value, `'"\`) {
+			return fmt.Errorf("%s must not contain quotes or backslashes", name)
+		}
+	}
+	if req.Currency != "" && !req.Currency.IsValid() {
+		return fmt.Errorf("invalid currency: %s (supported: USD, GEL)", req.Currency)
+	}
+	if req.Status != "" && !models.BillStatus(req.Status).IsValid() {
 		return fmt.Errorf("invalid status: %s (supported: OPEN, CLOSED)", req.Status)
 	}
+	if req.MinOpenBills < 0 || req.MinTotal < 0 || req.MaxTotal < 0 {
+		return fmt.Errorf("min_open_bills, min_total and max_total must not be negative")
+	}
+	if req.MaxTotal > 0 && req.MinTotal > req.MaxTotal {
+		return fmt.Errorf("min_total must not exceed max_total")
+	}
+	if req.PageSize < 0 || req.PageSize > models.MaxSearchPageSize {
+		return fmt.Errorf("page_size must be between 1 and %d", models.MaxSearchPageSize)
+	}
 	return nil
 }
+
+// activeBillingPeriodsQuery counts the billing periods of all tenants that are still running
+const activeBillingPeriodsQuery = "WorkflowType = 'BillWorkflow' AND ExecutionStatus = 'Running'"
+
+// newTaskQueueHealth summarises an enhanced DescribeTaskQueue response of the unversioned workers
+func newTaskQueueHealth(taskQueue string, localWorker bool, resp *workflowservice.DescribeTaskQueueResponse) *models.TaskQueueHealth {
+	health := &models.TaskQueueHealth{TaskQueue: taskQueue, LocalWorker: localWorker}
+	unversioned := resp.GetVersionsInfo()[""]
+	if info := unversioned.GetTypesInfo()[int32(enumspb.TASK_QUEUE_TYPE_WORKFLOW)]; info != nil {
+		health.WorkflowPollers = len(info.GetPollers())
+		health.WorkflowBacklog = info.GetStats().GetApproximateBacklogCount()
+	}
+	if info := unversioned.GetTypesInfo()[int32(enumspb.TASK_QUEUE_TYPE_ACTIVITY)]; info != nil {
+		health.ActivityPollers = len(info.GetPollers())
+		health.ActivityBacklog = info.GetStats().GetApproximateBacklogCount()
+	}
+	return health
+}
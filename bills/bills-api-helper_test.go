@@ -1,11 +1,24 @@
 package bills
 
 import (
+	"context"
+	"errors"
+	"fmt"
 	"testing"
+	"time"
 
 	"encore.app/models"
+	"encore.dev/beta/errs"
 	"github.com/google/uuid"
 	"github.com/stretchr/testify/assert"
+	commonpb "go.temporal.io/api/common/v1"
+	enumspb "go.temporal.io/api/enums/v1"
+	"go.temporal.io/api/serviceerror"
+	taskqueuepb "go.temporal.io/api/taskqueue/v1"
+	workflowpb "go.temporal.io/api/workflow/v1"
+	"go.temporal.io/api/workflowservice/v1"
+	"go.temporal.io/sdk/converter"
+	"google.golang.org/protobuf/types/known/timestamppb"
 )
 
 func TestValidateCreateBillRequest(t *testing.T) {
@@ -127,4 +140,318 @@ func TestValidateListBillsRequest(t *testing.T) {
 		assert.Error(t, err)
 		assert.Contains(t, err.Error(), "invalid status")
 	})
+	t.Run("filters are optional", func(t *testing.T) {
+		assert.NoError(t, validateListBillsRequest(&models.ListBillsRequest{}))
+	})
+	t.Run("invalid filters", func(t *testing.T) {
+		minTotal, maxTotal := 50.0, 10.0
+		day := time.Date(2025, 8, 1, 0, 0, 0, 0, time.UTC)
+		cursor := models.NewBillCursor(models.BillSortTotalAsc, &models.Bill{ID: "bill-1"})
+		tests := []struct {
+			req     models.ListBillsRequest
+			message string
+		}{
+			{models.ListBillsRequest{Currency: "EUR"}, "invalid currency"},
+			{models.ListBillsRequest{CreatedFrom: &day, CreatedTo: &day}, "created_from must be before created_to"},
+			{models.ListBillsRequest{MinTotal: &minTotal, MaxTotal: &maxTotal}, "min_total must not exceed max_total"},
+			{models.ListBillsRequest{Sort: "amount"}, "invalid sort"},
+			{models.ListBillsRequest{PageSize: models.MaxBillsPageSize + 1}, "page_size must be between"},
+			{models.ListBillsRequest{Cursor: "not a cursor"}, "invalid cursor"},
+			{models.ListBillsRequest{Cursor: cursor}, "cursor was issued for sort total_amount"},
+		}
+		for _, tt := range tests {
+			err := validateListBillsRequest(&tt.req)
+			assert.Error(t, err)
+			assert.Contains(t, err.Error(), tt.message)
+		}
+	})
+}
+
+func TestListBillsPage(t *testing.T) {
+	day := time.Date(2025, 8, 1, 0, 0, 0, 0, time.UTC)
+	archived := []*models.Bill{{ID: "bill-1", CreatedAt: day}, {ID: "bill-3", CreatedAt: day.AddDate(0, 0, 2)}}
+	active := []*models.Bill{{ID: "bill-2", CreatedAt: day.AddDate(0, 0, 1)}, {ID: "bill-4", CreatedAt: day.AddDate(0, 0, 3)}}
+
+	page, err := listBillsPage(&models.ListBillsRequest{PageSize: 3}, archived, active)
+	assert.NoError(t, err)
+	ids := make([]string, 0, len(page.Bills))
+	for _, bill := range page.Bills {
+		ids = append(ids, bill.ID)
+	}
+	assert.Equal(t, []string{"bill-1", "bill-2", "bill-3"}, ids)
+	assert.Equal(t, int64(3), page.Total)
+	assert.Equal(t, models.NewBillCursor("", archived[1]), page.NextCursor)
+
+	page, err = listBillsPage(&models.ListBillsRequest{PageSize: 4}, archived, active)
+	assert.NoError(t, err)
+	assert.Len(t, page.Bills, 4)
+	assert.Empty(t, page.NextCursor)
+}
+
+func TestValidateLegalEntityProfile(t *testing.T) {
+	t.Parallel()
+	valid := func() *models.LegalEntityProfile {
+		return &models.LegalEntityProfile{
+			LegalName:      "Billing Co GmbH",
+			Address:        models.Address{CountryCode: "DE"},
+			VATID:          "DE123456789",
+			EndpointID:     "DE123456789",
+			EndpointScheme: "9930",
+			VATRate:        19,
+		}
+	}
+	t.Run("valid request", func(t *testing.T) {
+		assert.NoError(t, validateLegalEntityProfile(valid()))
+	})
+	t.Run("missing vat id", func(t *testing.T) {
+		req := valid()
+		req.VATID = ""
+		err := validateLegalEntityProfile(req)
+		assert.Error(t, err)
+		assert.Contains(t, err.Error(), "vat_id is required")
+	})
+	t.Run("invalid country", func(t *testing.T) {
+		req := valid()
+		req.Address.CountryCode = "DEU"
+		err := validateLegalEntityProfile(req)
+		assert.Error(t, err)
+		assert.Contains(t, err.Error(), "country_code")
+	})
+	t.Run("invalid vat rate", func(t *testing.T) {
+		req := valid()
+		req.VATRate = 120
+		err := validateLegalEntityProfile(req)
+		assert.Error(t, err)
+		assert.Contains(t, err.Error(), "vat_rate must be between 0 and 100")
+	})
+}
+
+func TestValidateSpendingLimit(t *testing.T) {
+	t.Parallel()
+	t.Run("valid request", func(t *testing.T) {
+		err := validateSpendingLimit(&models.SpendingLimit{HardCap: 500, Currency: models.USD, Thresholds: []float64{0.5, 0.9}})
+		assert.NoError(t, err)
+	})
+	t.Run("defaults apply", func(t *testing.T) {
+		assert.NoError(t, validateSpendingLimit(&models.SpendingLimit{HardCap: 500}))
+	})
+	t.Run("missing hard cap", func(t *testing.T) {
+		err := validateSpendingLimit(&models.SpendingLimit{Currency: models.USD})
+		assert.Error(t, err)
+		assert.Contains(t, err.Error(), "hard_cap must be positive")
+	})
+	t.Run("invalid currency", func(t *testing.T) {
+		err := validateSpendingLimit(&models.SpendingLimit{HardCap: 500, Currency: "EUR"})
+		assert.Error(t, err)
+		assert.Contains(t, err.Error(), "invalid currency")
+	})
+	t.Run("threshold above the cap", func(t *testing.T) {
+		err := validateSpendingLimit(&models.SpendingLimit{HardCap: 500, Thresholds: []float64{1.5}})
+		assert.Error(t, err)
+		assert.Contains(t, err.Error(), "thresholds must be fractions")
+	})
+	t.Run("duplicate threshold", func(t *testing.T) {
+		err := validateSpendingLimit(&models.SpendingLimit{HardCap: 500, Thresholds: []float64{0.5, 0.5}})
+		assert.Error(t, err)
+		assert.Contains(t, err.Error(), "duplicate threshold")
+	})
+}
+
+func TestMergeArchivedBills(t *testing.T) {
+	archived := []*models.Bill{{ID: "bill-1"}, {ID: "bill-2"}}
+	active := []*models.Bill{{ID: "bill-2"}, {ID: "bill-3"}}
+
+	merged := mergeArchivedBills(archived, active)
+	ids := make([]string, 0, len(merged))
+	for _, bill := range merged {
+		ids = append(ids, bill.ID)
+	}
+	assert.Equal(t, []string{"bill-1", "bill-2", "bill-3"}, ids)
+	assert.Same(t, active[0], merged[1], "The active period's state wins")
+	assert.Empty(t, mergeArchivedBills(nil, nil))
+}
+
+func TestTemporalError(t *testing.T) {
+	tests := []struct {
+		name   string
+		err    error
+		code   errs.ErrCode
+		reason models.ErrorReason
+	}{
+		{"query failed", serviceerror.NewQueryFailed("bill not found"), errs.NotFound, models.ReasonBillNotFound},
+		{"workflow not found", serviceerror.NewNotFound("workflow not found"), errs.NotFound, models.ReasonBillingPeriodNotFound},
+		{"already started", serviceerror.NewWorkflowExecutionAlreadyStarted("started", "", ""), errs.AlreadyExists, models.ReasonBillingPeriodExists},
+		{"rejected", serviceerror.NewInvalidArgument("bad signal"), errs.FailedPrecondition, models.ReasonWorkflowRequestRejected},
+		{"unavailable", serviceerror.NewUnavailable("connection refused"), errs.Unavailable, models.ReasonWorkflowUnavailable},
+		{"deadline", context.DeadlineExceeded, errs.Unavailable, models.ReasonWorkflowUnavailable},
+		{"other", errors.New("boom"), errs.Internal, models.ReasonInternal},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			apiErr, ok := temporalError(tt.err, models.ReasonBillNotFound, "failed to get bill %s", "bill-1").(*errs.Error)
+			assert.True(t, ok)
+			assert.Equal(t, tt.code, apiErr.Code)
+			assert.Contains(t, apiErr.Message, "failed to get bill bill-1")
+			details, ok := apiErr.Details.(models.ErrorResponse)
+			assert.True(t, ok)
+			assert.Equal(t, string(tt.reason), details.Code)
+		})
+	}
+}
+
+func TestBillSearchQuery(t *testing.T) {
+	query := billSearchQuery("retail", &models.SearchBillsRequest{})
+	assert.Equal(t, "BillingTenantId = 'retail'", query)
+
+	query = billSearchQuery("retail", &models.SearchBillsRequest{
+		CustomerID:   "cust-1",
+		BillID:       "bill-1",
+		Currency:     models.USD,
+		Status:       string(models.StatusOpen),
+		MinOpenBills: 2,
+		MinTotal:     10,
+		MaxTotal:     99.5,
+	})
+	assert.Equal(t, "BillingTenantId = 'retail' AND BillingCustomerId = 'cust-1' AND BillingBillIds = 'bill-1'"+
+		" AND BillingCurrency = 'USD' AND BillingPeriodStatus = 'OPEN' AND BillingOpenBillCount >= 2"+
+		" AND BillingRunningTotal >= 10 AND BillingRunningTotal <= 99.5", query)
+}
+
+func TestValidateSearchBillsRequest(t *testing.T) {
+	assert.NoError(t, validateSearchBillsRequest(&models.SearchBillsRequest{}))
+	tests := []struct {
+		req     models.SearchBillsRequest
+		message string
+	}{
+		{models.SearchBillsRequest{CustomerID: "cust' OR 'a' = 'a"}, "customer_id must not contain quotes"},
+		{models.SearchBillsRequest{Currency: "EUR"}, "invalid currency"},
+		{models.SearchBillsRequest{Status: "SUSPENDED"}, "invalid status"},
+		{models.SearchBillsRequest{MinTotal: 50, MaxTotal: 10}, "min_total must not exceed max_total"},
+		{models.SearchBillsRequest{PageSize: models.MaxSearchPageSize + 1}, "page_size must be between"},
+	}
+	for _, tt := range tests {
+		err := validateSearchBillsRequest(&tt.req)
+		assert.Error(t, err)
+		assert.Contains(t, err.Error(), tt.message)
+	}
+}
+
+func TestNewBillingPeriodSearchResult(t *testing.T) {
+	dataConverter := converter.GetDefaultDataConverter()
+	payload := func(value interface{}) *commonpb.Payload {
+		encoded, err := dataConverter.ToPayload(value)
+		assert.NoError(t, err)
+		return encoded
+	}
+	startedAt := time.Date(2025, 8, 21, 7, 0, 0, 0, time.UTC)
+	period, err := newBillingPeriodSearchResult(&workflowpb.WorkflowExecutionInfo{
+		Execution: &commonpb.WorkflowExecution{WorkflowId: "wf-1"},
+		StartTime: timestamppb.New(startedAt),
+		SearchAttributes: &commonpb.SearchAttributes{IndexedFields: map[string]*commonpb.Payload{
+			"BillingCustomerId":    payload("cust-1"),
+			"BillingCurrency":      payload("USD"),
+			"BillingPeriodStatus":  payload("OPEN"),
+			"BillingOpenBillCount": payload(int64(2)),
+			"BillingRunningTotal":  payload(42.5),
+			"BillingBillIds":       payload([]string{"bill-1", "bill-2"}),
+		}},
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, "wf-1", period.WorkflowID)
+	assert.Equal(t, "cust-1", period.CustomerID)
+	assert.Equal(t, models.USD, period.Currency)
+	assert.Equal(t, models.StatusOpen, period.Status)
+	assert.Equal(t, 2, period.OpenBillCount)
+	assert.Equal(t, 42.5, period.RunningTotal)
+	assert.Equal(t, []string{"bill-1", "bill-2"}, period.BillIDs)
+	assert.Equal(t, startedAt, period.StartedAt)
+	assert.Nil(t, period.ClosedAt)
+}
+
+func TestBillStreams(t *testing.T) {
+	streams := newBillStreams()
+	retail, unsubscribeRetail := streams.subscribe("retail", "cust-1")
+	wholesale, unsubscribeWholesale := streams.subscribe("wholesale", "cust-1")
+	defer unsubscribeWholesale()
+
+	streams.publish(&models.BillStreamEvent{ID: "event-1", TenantID: "retail", CustomerID: "cust-1"})
+	assert.Equal(t, "event-1", (<-retail).ID)
+	assert.Empty(t, wholesale, "Streams only receive events of their tenant")
+
+	unsubscribeRetail()
+	_, open := <-retail
+	assert.False(t, open)
+	unsubscribeRetail()
+
+	t.Run("closes streams that fall behind", func(t *testing.T) {
+		slow, unsubscribe := streams.subscribe("retail", "cust-2")
+		defer unsubscribe()
+		for i := 0; i <= billStreamBuffer; i++ {
+			streams.publish(&models.BillStreamEvent{ID: fmt.Sprintf("event-%d", i), TenantID: "retail", CustomerID: "cust-2"})
+		}
+		received := 0
+		for range slow {
+			received++
+		}
+		assert.Equal(t, billStreamBuffer, received)
+	})
+}
+
+func TestRelayedStreamEvents(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	after, err := latestStreamEventSeq(ctx)
+	assert.NoError(t, err)
+
+	// A second instance, listening on the database rather than receiving the Pub/Sub message
+	elsewhere := newBillStreams()
+	go elsewhere.follow(ctx, after)
+	customerId := uuid.New().String()
+	events, unsubscribe := elsewhere.subscribe("retail", customerId)
+	defer unsubscribe()
+
+	event := &models.BillStreamEvent{ID: uuid.New().String(), Type: models.WebhookBillCreated, TenantID: "retail", CustomerID: customerId, BillID: "bill-1"}
+	assert.NoError(t, relayStreamEvent(ctx, event))
+	assert.NoError(t, relayStreamEvent(ctx, event), "Redelivered events are relayed once")
+
+	select {
+	case received := <-events:
+		assert.Equal(t, event.ID, received.ID)
+		assert.Equal(t, "retail", received.TenantID)
+		assert.Equal(t, "bill-1", received.BillID)
+	case <-time.After(2 * billStreamPoll):
+		t.Fatal("relayed event not received")
+	}
+	select {
+	case received := <-events:
+		t.Fatalf("event %s received twice", received.ID)
+	case <-time.After(time.Second):
+	}
+}
+
+func TestNewTaskQueueHealth(t *testing.T) {
+	resp := &workflowservice.DescribeTaskQueueResponse{
+		VersionsInfo: map[string]*taskqueuepb.TaskQueueVersionInfo{
+			"": {TypesInfo: map[int32]*taskqueuepb.TaskQueueTypeInfo{
+				int32(enumspb.TASK_QUEUE_TYPE_WORKFLOW): {
+					Pollers: []*taskqueuepb.PollerInfo{{Identity: "worker-1"}, {Identity: "worker-2"}},
+					Stats:   &taskqueuepb.TaskQueueStats{ApproximateBacklogCount: 3},
+				},
+				int32(enumspb.TASK_QUEUE_TYPE_ACTIVITY): {
+					Pollers: []*taskqueuepb.PollerInfo{{Identity: "worker-1"}},
+				},
+			}},
+		},
+	}
+	health := newTaskQueueHealth("local-bills", true, resp)
+	assert.Equal(t, "local-bills", health.TaskQueue)
+	assert.True(t, health.LocalWorker)
+	assert.Equal(t, 2, health.WorkflowPollers)
+	assert.Equal(t, int64(3), health.WorkflowBacklog)
+	assert.Equal(t, 1, health.ActivityPollers)
+	assert.Equal(t, int64(0), health.ActivityBacklog)
+
+	empty := newTaskQueueHealth("local-bills-retail", false, &workflowservice.DescribeTaskQueueResponse{})
+	assert.Equal(t, 0, empty.WorkflowPollers)
+	assert.False(t, empty.LocalWorker)
 }
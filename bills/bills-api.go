@@ -2,83 +2,165 @@ package bills
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"time"
 
+	"encore.dev/beta/errs"
 	"encore.dev/rlog"
 	"github.com/google/uuid"
 	"go.temporal.io/sdk/client"
 
 	"encore.app/constants"
+	"encore.app/customers"
 	"encore.app/models"
+	"encore.app/tenants"
+	"encore.app/tracing"
+	"encore.app/wallets"
 	"encore.app/workflows"
 )
 
-//encore:api public method=POST path=/bills/startbillingperiod
-func StartBillingPeriod(ctx context.Context, req *models.StartBillingPeriodRequest) error {
+//encore:api auth method=POST path=/bills/startbillingperiod
+func StartBillingPeriod(ctx context.Context, req *models.StartBillingPeriodRequest) (err error) {
+	tenantID, err := authorizeCustomer(req.CustomerID, models.ScopeWrite)
+	if err != nil {
+		return err
+	}
+	record := newAPIAuditRecord(tenantID, req.CustomerID, models.AuditStartBillingPeriod)
+	defer func() { appendAPIAuditRecord(ctx, record, err) }()
 	// Validate request
 	if err := validateStartBillingPeriodRequest(req); err != nil {
+		return models.InvalidRequest(err)
+	}
+	// Keys act within their own tenant, the tenant in the request only makes that explicit
+	if req.TenantID != "" && req.TenantID != tenantID {
+		return &errs.Error{Code: errs.PermissionDenied, Message: fmt.Sprintf("API key may not act on tenant %s", req.TenantID)}
+	}
+	tenant, err := tenants.FindTenant(ctx, tenantID)
+	if err != nil {
 		return err
 	}
+	customer, err := customers.FindCustomer(ctx, tenantID, req.CustomerID)
+	if err != nil {
+		return errs.Wrap(err, fmt.Sprintf("unknown customer %s", req.CustomerID))
+	}
+	currency := req.Currency
+	if currency == "" {
+		currency = customer.Customer.DefaultCurrency
+	}
+	record.Currency = currency
+	if !currency.IsValid() {
+		return models.NewAPIError(errs.InvalidArgument, models.ReasonInvalidRequest, "invalid currency: %s (supported: USD, GEL)", currency)
+	}
+	if !tenant.Tenant.SupportsCurrency(currency) {
+		return models.NewAPIError(errs.FailedPrecondition, models.ReasonCurrencyNotEnabled, "currency %s is not enabled for tenant %s", currency, tenantID)
+	}
+	if err := service.ensureTenantWorker(tenant.Tenant); err != nil {
+		return err
+	}
+	wallet, err := wallets.FindWallet(ctx, tenantID, req.CustomerID)
+	if err != nil {
+		return errs.Wrap(err, fmt.Sprintf("failed to look up wallet for customer %s", req.CustomerID))
+	}
+	drawDownMode := models.DrawDownNone
+	if wallet.Wallet != nil {
+		drawDownMode = wallet.Wallet.DrawDownMode
+	}
 	startTime := time.Now()
 
-	workflowID := fmt.Sprintf("billing-period-workflow-%s-%s", startTime.Format("20060102"), req.CustomerID)
+	workflowID := models.BillingPeriodWorkflowID(tenantID, req.CustomerID, startTime)
+	record.WorkflowID = workflowID
+	ctx = tracing.WithIDs(ctx, tracing.IDs{TenantID: tenantID, CustomerID: req.CustomerID, WorkflowID: workflowID})
 
-	// Start Temporal workflow
+	// Start Temporal workflow on the tenant's task queue
 	workflowOptions := client.StartWorkflowOptions{
 		ID:        workflowID,
-		TaskQueue: service.GetTaskQueue(),
+		TaskQueue: tenant.Tenant.TaskQueue,
 	}
 
+	accrual := tenant.Tenant.Accrual
 	workflowInput := &models.BillWorkflowInput{
 		WorkflowID:        workflowID,
+		TenantID:          tenantID,
 		CustomerID:        req.CustomerID,
-		Currency:          models.Currency(req.Currency),
+		Currency:          currency,
 		BillingPeriodDays: req.BillingPeriodDays,
 		StartedAt:         startTime,
 		BillStates:        []*models.Bill{},
+		DrawDownMode:      drawDownMode,
+		Accrual:           &accrual,
+	}
+	if req.SpendingLimit != nil {
+		workflowInput.SpendingLimit = req.SpendingLimit.WithDefaults(currency)
 	}
 
-	workflowRun, err := service.GetTemporalClient().ExecuteWorkflow(
+	temporalClient, err := service.temporal()
+	if err != nil {
+		return err
+	}
+	workflowRun, err := temporalClient.ExecuteWorkflow(
 		ctx, workflowOptions, workflows.BillWorkflow, workflowInput,
 	)
 	if err != nil {
-		return fmt.Errorf("failed to start bill workflow: %w", err)
+		return temporalError(err, models.ReasonBillingPeriodNotFound, "failed to start bill workflow")
 	}
 	rlog.Info("started billing period workflow",
+		"tenant_id", tenantID,
 		"workflow_id", workflowRun.GetID(),
 		"run_id", workflowRun.GetRunID(),
 	)
-	service.workflows[req.CustomerID] = workflowRun.GetID()
+	service.setWorkflowID(tenantID, req.CustomerID, workflowRun.GetID())
 
 	return nil
 }
 
-//encore:api public method=POST path=/bills/createbill
-func CreateBill(ctx context.Context, req *models.CreateBillRequest) (*models.CreateBillResponse, error) {
+//encore:api auth method=POST path=/bills/createbill
+func CreateBill(ctx context.Context, req *models.CreateBillRequest) (_ *models.CreateBillResponse, err error) {
+	tenantID, err := authorizeCustomer(req.CustomerID, models.ScopeWrite)
+	if err != nil {
+		return nil, err
+	}
+	record := newAPIAuditRecord(tenantID, req.CustomerID, models.AuditCreateBill)
+	defer func() { appendAPIAuditRecord(ctx, record, err) }()
+	record.Currency = models.Currency(req.Currency)
 	// Validate request
 	if err := validateCreateBillRequest(req); err != nil {
-		return nil, err
+		return nil, models.InvalidRequest(err)
 	}
-	workflowID, customerBillingPeriodFound := service.GetWorkflowIDForCustomer(req.CustomerID)
+	workflowID, customerBillingPeriodFound := service.GetWorkflowIDForCustomer(tenantID, req.CustomerID)
 	if !customerBillingPeriodFound {
-		return nil, fmt.Errorf("billing period not started for customer %s", req.CustomerID)
+		return nil, billingPeriodNotFound(req.CustomerID)
+	}
+	tenant, err := tenants.FindTenant(ctx, tenantID)
+	if err != nil {
+		return nil, err
+	}
+	if !tenant.Tenant.SupportsCurrency(models.Currency(req.Currency)) {
+		return nil, models.NewAPIError(errs.FailedPrecondition, models.ReasonCurrencyNotEnabled, "currency %s is not enabled for tenant %s", req.Currency, tenantID)
 	}
 
 	// Generate unique IDs
 	billID := uuid.New().String()
+	record.BillID, record.WorkflowID = billID, workflowID
+	ctx = tracing.WithIDs(ctx, tracing.IDs{TenantID: tenantID, CustomerID: req.CustomerID, BillID: billID, WorkflowID: workflowID})
 
 	// Create bill
 	signalInput := &models.CreateBillSignal{
 		BillID:     billID,
 		Currency:   models.Currency(req.Currency),
 		WorkflowID: workflowID,
+		Audit:      currentAuditContext(),
+		Trace:      tracing.Inject(ctx),
 	}
 
 	// Store bill
-	err := service.temporalClient.SignalWorkflow(ctx, workflowID, "", constants.CreateBillSignalName, signalInput)
+	temporalClient, err := service.temporal()
 	if err != nil {
-		return nil, fmt.Errorf("failed to signal workflow: %w", err)
+		return nil, err
+	}
+	err = temporalClient.SignalWorkflow(ctx, workflowID, "", constants.CreateBillSignalName, signalInput)
+	if err != nil {
+		return nil, temporalError(err, models.ReasonBillingPeriodNotFound, "failed to signal workflow")
 	}
 
 	return &models.CreateBillResponse{
@@ -87,25 +169,37 @@ func CreateBill(ctx context.Context, req *models.CreateBillRequest) (*models.Cre
 	}, nil
 }
 
-//encore:api public method=POST path=/bills/addItem/:customerId/:billId
-func AddLineItem(ctx context.Context, customerId string, billId string, req *models.AddLineItemRequest) (*models.AddLineItemResponse, error) {
+//encore:api auth method=POST path=/bills/addItem/:customerId/:billId
+func AddLineItem(ctx context.Context, customerId string, billId string, req *models.AddLineItemRequest) (_ *models.AddLineItemResponse, err error) {
+	tenantID, err := authorizeCustomer(customerId, models.ScopeWrite)
+	if err != nil {
+		return nil, err
+	}
+	record := newAPIAuditRecord(tenantID, customerId, models.AuditAddLineItem)
+	defer func() { appendAPIAuditRecord(ctx, record, err) }()
+	record.BillID, record.Currency = billId, models.Currency(req.Currency)
 	// Validate request
 	if err := validateAddLineItemRequest(req); err != nil {
-		return nil, err
+		return nil, models.InvalidRequest(err)
 	}
-	workflowId, found := service.GetWorkflowIDForCustomer(customerId)
+	workflowId, found := service.GetWorkflowIDForCustomer(tenantID, customerId)
 	if !found {
-		return nil, fmt.Errorf("workflow not found")
+		return nil, billingPeriodNotFound(customerId)
 	}
+	record.WorkflowID = workflowId
+	ctx = tracing.WithIDs(ctx, tracing.IDs{TenantID: tenantID, CustomerID: customerId, BillID: billId, WorkflowID: workflowId})
 	// Get bill
-	bill, err := getBillByID(ctx, billId, workflowId)
+	bill, err := getBillByID(ctx, tenantID, billId, workflowId)
 	if err != nil {
-		return nil, fmt.Errorf("bill not found: %w", err)
+		return nil, err
 	}
 
 	// Check if bill can accept line items
 	if !bill.CanAddLineItems() {
-		return nil, fmt.Errorf("cannot add line items to closed bill")
+		return nil, models.NewAPIError(errs.FailedPrecondition, models.ReasonBillClosed, "cannot add line items to closed bill")
+	}
+	if err := checkSpendingLimit(ctx, tenantID, workflowId, bill, req); err != nil {
+		return nil, err
 	}
 
 	// Create line item
@@ -115,6 +209,8 @@ func AddLineItem(ctx context.Context, customerId string, billId string, req *mod
 		Amount:      req.Amount,
 		Quantity:    req.Quantity,
 		AddedAt:     time.Now(),
+		Product:     req.Product,
+		Recognition: req.Recognition,
 	}
 
 	// Send signal to workflow
@@ -122,13 +218,22 @@ func AddLineItem(ctx context.Context, customerId string, billId string, req *mod
 		LineItem: &lineItem,
 		BillID:   bill.ID,
 		Currency: models.Currency(req.Currency),
+		Audit:    currentAuditContext(),
+		Trace:    tracing.Inject(ctx),
 	}
 
-	err = service.GetTemporalClient().SignalWorkflow(
+	temporalClient, err := service.temporal()
+	if err != nil {
+		return nil, err
+	}
+	err = temporalClient.SignalWorkflow(
 		ctx, workflowId, "", constants.AddLineItemSignalName, signalInput,
 	)
 	if err != nil {
-		return nil, fmt.Errorf("failed to signal workflow: %w", err)
+		return nil, temporalError(err, models.ReasonBillingPeriodNotFound, "failed to signal workflow")
+	}
+	if err := checkLineItemApplied(ctx, tenantID, workflowId, bill.ID, lineItem.ID); err != nil {
+		return nil, err
 	}
 
 	rlog.Info("added line item to bill",
@@ -143,34 +248,49 @@ func AddLineItem(ctx context.Context, customerId string, billId string, req *mod
 	}, nil
 }
 
-//encore:api public method=POST path=/bills/close/:customerId/:billId
-func CloseBill(ctx context.Context, customerId string, billId string, req *models.CloseBillRequest) (*models.CloseBillResponse, error) {
+//encore:api auth method=POST path=/bills/close/:customerId/:billId
+func CloseBill(ctx context.Context, customerId string, billId string, req *models.CloseBillRequest) (_ *models.CloseBillResponse, err error) {
+	tenantID, err := authorizeCustomer(customerId, models.ScopeWrite)
+	if err != nil {
+		return nil, err
+	}
+	record := newAPIAuditRecord(tenantID, customerId, models.AuditCloseBill)
+	defer func() { appendAPIAuditRecord(ctx, record, err) }()
+	record.BillID, record.Reason = billId, req.Reason
 	// Get bill
-	workflowId, found := service.GetWorkflowIDForCustomer(customerId)
+	workflowId, found := service.GetWorkflowIDForCustomer(tenantID, customerId)
 	if !found {
-		return nil, fmt.Errorf("workflow not found")
+		return nil, billingPeriodNotFound(customerId)
 	}
-	bill, err := getBillByID(ctx, billId, workflowId)
+	record.WorkflowID = workflowId
+	ctx = tracing.WithIDs(ctx, tracing.IDs{TenantID: tenantID, CustomerID: customerId, BillID: billId, WorkflowID: workflowId})
+	bill, err := getBillByID(ctx, tenantID, billId, workflowId)
 	if err != nil {
-		return nil, fmt.Errorf("bill not found: %w", err)
+		return nil, err
 	}
 
 	// Check if bill is already closed
 	if bill.Status == models.StatusClosed {
-		return nil, fmt.Errorf("bill is already closed")
+		return nil, models.NewAPIError(errs.FailedPrecondition, models.ReasonBillClosed, "bill is already closed")
 	}
 
 	// Send signal to workflow
 	signalInput := models.CloseBillSignal{
 		Reason: req.Reason,
 		BillID: billId,
+		Audit:  currentAuditContext(),
+		Trace:  tracing.Inject(ctx),
 	}
 
-	err = service.GetTemporalClient().SignalWorkflow(
+	temporalClient, err := service.temporal()
+	if err != nil {
+		return nil, err
+	}
+	err = temporalClient.SignalWorkflow(
 		ctx, workflowId, "", constants.CloseBillSignalName, signalInput,
 	)
 	if err != nil {
-		return nil, fmt.Errorf("failed to signal workflow: %w", err)
+		return nil, temporalError(err, models.ReasonBillingPeriodNotFound, "failed to signal workflow")
 	}
 
 	rlog.Info("closed bill",
@@ -179,9 +299,9 @@ func CloseBill(ctx context.Context, customerId string, billId string, req *model
 		"total_amount", bill.TotalAmount,
 	)
 
-	bill, err = getBillByID(ctx, billId, workflowId)
+	bill, err = getBillByID(ctx, tenantID, billId, workflowId)
 	if err != nil {
-		return nil, fmt.Errorf("bill not found: %w", err)
+		return nil, err
 	}
 
 	return &models.CloseBillResponse{
@@ -192,15 +312,15 @@ func CloseBill(ctx context.Context, customerId string, billId string, req *model
 	}, nil
 }
 
-//encore:api public method=POST path=/bills/getBill/:customerId/:billId
+//encore:api auth method=POST path=/bills/getBill/:customerId/:billId
 func GetBill(ctx context.Context, customerId string, billId string) (*models.GetBillResponse, error) {
-	workflowId, found := service.GetWorkflowIDForCustomer(customerId)
-	if !found {
-		return nil, fmt.Errorf("workflow not found")
+	tenantID, err := authorizeCustomer(customerId, models.ScopeRead)
+	if err != nil {
+		return nil, err
 	}
-	bill, err := getBillByID(ctx, billId, workflowId)
+	bill, err := findBill(ctx, tenantID, customerId, billId)
 	if err != nil {
-		return nil, fmt.Errorf("bill not found: %w", err)
+		return nil, err
 	}
 
 	return &models.GetBillResponse{
@@ -208,35 +328,55 @@ func GetBill(ctx context.Context, customerId string, billId string) (*models.Get
 	}, nil
 }
 
-//encore:api public method=POST path=/bills/listBills/:customerId
+//encore:api auth method=POST path=/bills/listBills/:customerId
 func ListBills(ctx context.Context, customerId string, req *models.ListBillsRequest) (*models.ListBillsResponse, error) {
-	// Parse query parameters from context
-	// Note: In a real implementation, you'd extract these from query parameters
-
-	workflowId, found := service.GetWorkflowIDForCustomer(customerId)
-	if !found {
-		return nil, fmt.Errorf("workflow not found for customer %s", customerId)
+	tenantID, err := authorizeCustomer(customerId, models.ScopeRead)
+	if err != nil {
+		return nil, err
 	}
-
 	if err := validateListBillsRequest(req); err != nil {
-		return nil, fmt.Errorf("invalid request: %w", err)
+		return nil, models.InvalidRequest(err)
+	}
+	if req.PageSize == 0 {
+		req.PageSize = models.DefaultBillsPageSize
 	}
+	// Both sources return one bill past the page, it tells whether another page follows
+	query := *req
+	query.TenantID = tenantID
+	query.PageSize = req.PageSize + 1
 
-	queryResult, err := service.GetTemporalClient().QueryWorkflow(ctx, workflowId, "", constants.ListBillsQuery, *req)
+	// Bills of finished billing periods come from the archive, the active period is queried
+	archived, err := listArchivedBills(ctx, tenantID, customerId, models.BillStatus(req.Status))
+	if err != nil {
+		return nil, err
+	}
+	archived, err = models.SelectBills(query, archived)
 	if err != nil {
-		return nil, fmt.Errorf("failed to query workflow: %w", err)
+		return nil, models.InvalidRequest(err)
+	}
+	workflowId, found := service.GetWorkflowIDForCustomer(tenantID, customerId)
+	if !found {
+		if len(archived) == 0 && req.Cursor == "" {
+			return nil, billingPeriodNotFound(customerId)
+		}
+		return listBillsPage(req, archived, nil)
 	}
-	var result []*models.Bill
 
-	err = queryResult.Get(&result)
+	temporalClient, err := service.temporal()
 	if err != nil {
-		return nil, fmt.Errorf("failed to get query result: %w", err)
+		return nil, err
 	}
+	queryResult, err := temporalClient.QueryWorkflow(ctx, workflowId, "", constants.ListBillsQuery, query)
+	if err != nil {
+		return nil, temporalError(err, models.ReasonBillingPeriodNotFound, "failed to query workflow")
+	}
+	var active []*models.Bill
 
-	return &models.ListBillsResponse{
-		Bills: result,
-		Total: int64(len(result)),
-	}, nil
+	err = queryResult.Get(&active)
+	if err != nil {
+		return nil, models.NewAPIErrorWithCause(errs.Internal, models.ReasonInternal, err, "failed to get query result")
+	}
+	return listBillsPage(req, archived, active)
 }
 
 //encore:api public method=GET path=/bills/health
@@ -249,12 +389,16 @@ func HealthCheck(ctx context.Context) (*models.HealthResponse, error) {
 		}, nil
 	}
 
-	// Check Temporal client connection
-	_, err := service.GetTemporalClient().CheckHealth(ctx, &client.CheckHealthRequest{})
+	// Without Temporal the service keeps serving archived bills, so it is degraded rather than down
+	temporalClient, err := service.temporal()
+	if err == nil {
+		_, err = temporalClient.CheckHealth(ctx, &client.CheckHealthRequest{})
+	}
 	if err != nil {
 		return &models.HealthResponse{
-			Status: "unhealthy",
-			Error:  fmt.Sprintf("Temporal connection failed: %v", err),
+			Status:  "degraded",
+			Service: "bills",
+			Error:   fmt.Sprintf("Temporal connection failed: %v", err),
 		}, nil
 	}
 
@@ -265,65 +409,166 @@ func HealthCheck(ctx context.Context) (*models.HealthResponse, error) {
 	}, nil
 }
 
-//encore:api public method=POST path=/bills/closeBillingPeriod/:customerId
-func CloseBillingPeriod(ctx context.Context, customerId string) (*models.CloseBillingPeriodResponse, error) {
-	workflowId, found := service.GetWorkflowIDForCustomer(customerId)
+//encore:api auth method=POST path=/bills/closeBillingPeriod/:customerId
+func CloseBillingPeriod(ctx context.Context, customerId string) (_ *models.CloseBillingPeriodResponse, err error) {
+	tenantID, err := authorizeCustomer(customerId, models.ScopeWrite)
+	if err != nil {
+		return nil, err
+	}
+	record := newAPIAuditRecord(tenantID, customerId, models.AuditCloseBillingPeriod)
+	defer func() { appendAPIAuditRecord(ctx, record, err) }()
+	workflowId, found := service.GetWorkflowIDForCustomer(tenantID, customerId)
 	if !found {
-		return nil, fmt.Errorf("workflow not found")
+		return nil, billingPeriodNotFound(customerId)
+	}
+	record.WorkflowID = workflowId
+
+	// Child accounts are closed first so the parent period stays open if any of them fails. The parent's workflow
+	// consolidates their bills with its own when the period ends.
+	childAccounts, err := finalizeChildAccounts(ctx, tenantID, customerId, nil, time.Time{})
+	if err != nil {
+		return nil, err
+	}
+
+	workflowId, finalizedBills, err := finalizeBillingPeriod(ctx, tenantID, customerId, childAccounts)
+	if err != nil {
+		return nil, err
+	}
+
+	finalBillAmountUSD := 0.0
+
+	for _, bill := range finalizedBills {
+		finalBillAmountUSD += models.ConvertCurrencyAmount(bill.Currency, models.USD, bill.TotalAmount)
+	}
+
+	finalAmountGEL := models.ConvertCurrencyAmount(models.USD, models.GEL, finalBillAmountUSD)
+
+	response := &models.CloseBillingPeriodResponse{
+		WorkflowID:     workflowId,
+		Bills:          finalizedBills,
+		FinalAmountUSD: finalBillAmountUSD,
+		FinalAmountGEL: finalAmountGEL,
+	}
+	if len(childAccounts) > 0 {
+		response.ConsolidatedBill = awaitConsolidatedBill(ctx, tenantID, workflowId)
+	}
+	return response, nil
+}
+
+//encore:api auth method=GET path=/bills/consolidated/:customerId
+func ListConsolidatedBills(ctx context.Context, customerId string) (*models.ListConsolidatedBillsResponse, error) {
+	tenantID, err := authorizeCustomer(customerId, models.ScopeRead)
+	if err != nil {
+		return nil, err
+	}
+	// Consolidated bills are generated when the parent's billing period ends, so they are only in the archive
+	consolidatedBills, err := listConsolidatedBills(ctx, tenantID, customerId)
+	if err != nil {
+		return nil, err
+	}
+	return &models.ListConsolidatedBillsResponse{ConsolidatedBills: consolidatedBills}, nil
+}
+
+// finalizeBillingPeriod closes all bills of the customer's active billing period and returns them. The child
+// accounts closed before it are handed to the workflow, which consolidates them when the period ends.
+func finalizeBillingPeriod(ctx context.Context, tenantID string, customerId string, childAccounts []*models.ChildBills) (string, []*models.Bill, error) {
+	workflowId, found := service.GetWorkflowIDForCustomer(tenantID, customerId)
+	if !found {
+		return "", nil, billingPeriodNotFound(customerId)
+	}
+	ctx = tracing.WithIDs(ctx, tracing.IDs{TenantID: tenantID, CustomerID: customerId, WorkflowID: workflowId})
+	temporalClient, err := service.temporal()
+	if err != nil {
+		return "", nil, err
 	}
 	defer func() {
-		cancelErr := service.temporalClient.CancelWorkflow(ctx, workflowId, "")
+		cancelErr := temporalClient.CancelWorkflow(ctx, workflowId, "")
 		if cancelErr != nil {
 			rlog.Error("failed to cancel workflow", "error", cancelErr, "workflow_id", workflowId)
 		}
-		delete(service.workflows, customerId)
+		service.deleteWorkflowID(tenantID, customerId)
 	}()
 
-	err := service.GetTemporalClient().SignalWorkflow(ctx, workflowId, "", constants.CloseBillingPeriodSignalName, nil)
+	err = temporalClient.SignalWorkflow(ctx, workflowId, "", constants.CloseBillingPeriodSignalName, models.CloseBillingPeriodSignal{
+		Audit:         currentAuditContext(),
+		Trace:         tracing.Inject(ctx),
+		ChildAccounts: childAccounts,
+	})
+	if err != nil {
+		return "", nil, temporalError(err, models.ReasonBillingPeriodNotFound, "failed to signal workflow")
+	}
+
+	finalizedBills, err := queryClosedBills(ctx, tenantID, workflowId)
 	if err != nil {
-		return nil, fmt.Errorf("failed to signal workflow: %w", err)
+		return "", nil, err
 	}
+	return workflowId, finalizedBills, nil
+}
 
+// queryClosedBills returns the closed bills of a billing period, the workflow answers once the period has ended too
+func queryClosedBills(ctx context.Context, tenantID string, workflowId string) ([]*models.Bill, error) {
+	temporalClient, err := service.temporal()
+	if err != nil {
+		return nil, err
+	}
 	var finalizedBills []*models.Bill
-	queryResult, err := service.temporalClient.QueryWorkflow(ctx, workflowId, "", constants.ListBillsQuery, models.ListBillsRequest{
-		Status: string(models.StatusClosed),
+	queryResult, err := temporalClient.QueryWorkflow(ctx, workflowId, "", constants.ListBillsQuery, models.ListBillsRequest{
+		Status:   string(models.StatusClosed),
+		TenantID: tenantID,
 	})
 	if err != nil {
-		return nil, fmt.Errorf("failed to query finalized bills: %w", err)
+		return nil, temporalError(err, models.ReasonBillingPeriodNotFound, "failed to query finalized bills")
 	}
 	err = queryResult.Get(&finalizedBills)
 
 	if err != nil {
-		return nil, fmt.Errorf("failed to get finalized bills from query result: %w", err)
+		return nil, models.NewAPIErrorWithCause(errs.Internal, models.ReasonInternal, err, "failed to get finalized bills from query result")
 	}
 
-	finalBillAmountUSD := 0.0
+	return finalizedBills, nil
+}
 
-	for _, bill := range finalizedBills {
-		finalBillAmountUSD += models.ConvertCurrencyAmount(bill.Currency, models.USD, bill.TotalAmount)
+// findBill returns a bill of the customer's active billing period, or of an archived one once the period
+// has finished
+func findBill(ctx context.Context, tenantID string, customerId string, billId string) (*models.Bill, error) {
+	workflowId, found := service.GetWorkflowIDForCustomer(tenantID, customerId)
+	var queryErr error
+	if found {
+		bill, err := getBillByID(ctx, tenantID, billId, workflowId)
+		if err == nil && bill != nil {
+			return bill, nil
+		}
+		queryErr = err
 	}
-
-	finalAmountGEL := models.ConvertCurrencyAmount(models.USD, models.GEL, finalBillAmountUSD)
-
-	return &models.CloseBillingPeriodResponse{
-		WorkflowID:     workflowId,
-		Bills:          finalizedBills,
-		FinalAmountUSD: finalBillAmountUSD,
-		FinalAmountGEL: finalAmountGEL,
-	}, nil
+	bill, err := findArchivedBill(ctx, tenantID, customerId, billId)
+	if errors.Is(err, errArchivedBillNotFound) {
+		if queryErr != nil {
+			return nil, queryErr
+		}
+		return nil, models.NewAPIError(errs.NotFound, models.ReasonBillNotFound, "bill %s not found", billId)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return bill, nil
 }
 
-func getBillByID(ctx context.Context, id string, workflowId string) (*models.Bill, error) {
+func getBillByID(ctx context.Context, tenantID string, id string, workflowId string) (*models.Bill, error) {
 	req := models.GetBillRequest{
-		BillID: id,
+		TenantID: tenantID,
+		BillID:   id,
+	}
+	temporalClient, err := service.temporal()
+	if err != nil {
+		return nil, err
 	}
-	queryResult, err := service.temporalClient.QueryWorkflow(ctx, workflowId, "", constants.GetBillQuery, req)
+	queryResult, err := temporalClient.QueryWorkflow(ctx, workflowId, "", constants.GetBillQuery, req)
 	if err != nil {
-		return nil, fmt.Errorf("failed to query workflow: %w", err)
+		return nil, temporalError(err, models.ReasonBillNotFound, "bill %s not found", id)
 	}
 	var bill *models.Bill
 	if err := queryResult.Get(&bill); err != nil {
-		return nil, fmt.Errorf("failed to get bill from query result: %w", err)
+		return nil, models.NewAPIErrorWithCause(errs.Internal, models.ReasonInternal, err, "failed to get bill from query result")
 	}
 
 	return bill, nil
@@ -2,19 +2,113 @@ package bills
 
 import (
 	"context"
+	"net"
+	"net/http"
+	"net/http/httptest"
 	"testing"
-
+	"time"
+
+	"encore.dev/beta/auth"
+	"encore.dev/beta/errs"
+	"encore.dev/et"
+	"github.com/stretchr/testify/mock"
+	"go.temporal.io/sdk/client"
+	"go.temporal.io/sdk/mocks"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+
+	"encore.app/apikeys"
+	"encore.app/audit"
+	"encore.app/customers"
+	"encore.app/metrics"
 	"encore.app/models" // Encore's test support package``
+	billsv1 "encore.app/proto/billsv1"
+	"encore.app/tenants"
 	"github.com/google/uuid"
 	"github.com/stretchr/testify/assert"
 )
 
+// createTestCustomer registers a customer profile so billing periods can be started for it
+func createTestCustomer(t *testing.T, customerId string) {
+	t.Helper()
+	createTestChildCustomer(t, customerId, "")
+}
+
+// createTestChildCustomer registers a customer billed through the given parent account
+func createTestChildCustomer(t *testing.T, customerId string, parentCustomerId string) {
+	t.Helper()
+	createTestTenantCustomer(t, models.DefaultTenantID, customerId, parentCustomerId)
+}
+
+// createTestTenantCustomer registers a customer of the tenant, customer IDs are unique per tenant
+func createTestTenantCustomer(t *testing.T, tenantId string, customerId string, parentCustomerId string) {
+	t.Helper()
+	_, err := customers.CreateCustomer(tenantAdminContext(tenantId), &models.CreateCustomerRequest{
+		CustomerID: customerId,
+		CustomerDetails: models.CustomerDetails{
+			LegalName:        "Test Customer",
+			BillingAddress:   models.Address{CountryCode: "GE"},
+			DefaultCurrency:  models.USD,
+			Locale:           "en-US",
+			ContactEmails:    []string{"billing@example.com"},
+			ParentCustomerID: parentCustomerId,
+		},
+	})
+	assert.NoError(t, err)
+}
+
+// adminContext authenticates calls with an admin API key of the default tenant
+func adminContext() context.Context {
+	return tenantAdminContext(models.DefaultTenantID)
+}
+
+// tenantAdminContext authenticates calls with an admin API key of the tenant
+func tenantAdminContext(tenantId string) context.Context {
+	return auth.WithContext(context.Background(), auth.UID("test-admin-"+tenantId), &models.AuthData{
+		KeyID:    "test-admin-" + tenantId,
+		TenantID: tenantId,
+		Scopes:   []models.APIKeyScope{models.ScopeAdmin},
+	})
+}
+
+// customerContext authenticates calls with an API key bound to the customer of the default tenant
+func customerContext(customerId string, scopes ...models.APIKeyScope) context.Context {
+	return auth.WithContext(context.Background(), auth.UID("test-"+customerId), &models.AuthData{
+		KeyID:      "test-" + customerId,
+		TenantID:   models.DefaultTenantID,
+		CustomerID: customerId,
+		Scopes:     scopes,
+	})
+}
+
+// createTestTenant registers a tenant billing only in the given currencies
+func createTestTenant(t *testing.T, currencies ...models.Currency) string {
+	t.Helper()
+	tenantId := "test-" + uuid.New().String()[:8]
+	operatorCtx := auth.WithContext(context.Background(), "test-operator", &models.AuthData{
+		KeyID:  "test-operator",
+		Scopes: []models.APIKeyScope{models.ScopeAdmin},
+	})
+	_, err := tenants.CreateTenant(operatorCtx, &models.CreateTenantRequest{
+		TenantID:       tenantId,
+		TenantSettings: models.TenantSettings{Name: "Test Tenant", Currencies: currencies},
+	})
+	assert.NoError(t, err)
+	return tenantId
+}
+
 func TestCreateCloseWorkflow(t *testing.T) {
 	t.Parallel()
 	testCustomerId := uuid.New().String()
+	createTestCustomer(t, testCustomerId)
 	nonExistentCustomerId := uuid.New().String()
 	t.Run("Workflow Created And Closed", func(t *testing.T) {
-		ctx := context.Background()
+		ctx := adminContext()
 		err := StartBillingPeriod(ctx, &models.StartBillingPeriodRequest{
 			CustomerID:        testCustomerId,
 			Currency:          models.USD,
@@ -23,23 +117,36 @@ func TestCreateCloseWorkflow(t *testing.T) {
 		defer CloseBillingPeriod(ctx, testCustomerId)
 		assert.NoError(t, err)
 
-		_, workflowFound := service.workflows[testCustomerId]
+		_, workflowFound := service.GetWorkflowIDForCustomer(models.DefaultTenantID, testCustomerId)
 		assert.True(t, workflowFound)
-		assert.Len(t, service.workflows, 1, "Expected one workflow to be created")
+		assert.Equal(t, 1, service.workflowCount(), "Expected one workflow to be created")
 	})
 
 	t.Run("Workflow Not Found", func(t *testing.T) {
-		ctx := context.Background()
+		ctx := adminContext()
 		_, err := CloseBillingPeriod(ctx, nonExistentCustomerId)
 		assert.Error(t, err)
 	})
+
+	t.Run("Unknown Customer", func(t *testing.T) {
+		ctx := adminContext()
+		err := StartBillingPeriod(ctx, &models.StartBillingPeriodRequest{
+			CustomerID:        nonExistentCustomerId,
+			Currency:          models.USD,
+			BillingPeriodDays: 30,
+		})
+		assert.Error(t, err)
+		_, workflowFound := service.GetWorkflowIDForCustomer(models.DefaultTenantID, nonExistentCustomerId)
+		assert.False(t, workflowFound)
+	})
 }
 func TestAddLineItemToBill(t *testing.T) {
 	testCustomerId := uuid.New().String()
+	createTestCustomer(t, testCustomerId)
 	nonExistentBillId := uuid.New().String()
 
 	t.Run("Add Line Item", func(t *testing.T) {
-		ctx := context.Background()
+		ctx := adminContext()
 
 		err := StartBillingPeriod(ctx, &models.StartBillingPeriodRequest{
 			CustomerID:        testCustomerId,
@@ -49,9 +156,9 @@ func TestAddLineItemToBill(t *testing.T) {
 		defer CloseBillingPeriod(ctx, testCustomerId)
 		assert.NoError(t, err)
 
-		_, workflowFound := service.workflows[testCustomerId]
+		_, workflowFound := service.GetWorkflowIDForCustomer(models.DefaultTenantID, testCustomerId)
 		assert.True(t, workflowFound)
-		assert.Len(t, service.workflows, 1, "Expected one workflow to be created")
+		assert.Equal(t, 1, service.workflowCount(), "Expected one workflow to be created")
 
 		// Create a bill
 		createBillResp, err := CreateBill(ctx, &models.CreateBillRequest{
@@ -91,16 +198,16 @@ func TestAddLineItemToBill(t *testing.T) {
 
 	})
 	t.Run("Add Line Item To Non-Existent Bill", func(t *testing.T) {
-		ctx := context.Background()
+		ctx := adminContext()
 		err := StartBillingPeriod(ctx, &models.StartBillingPeriodRequest{
 			CustomerID:        testCustomerId,
 			Currency:          models.USD,
 			BillingPeriodDays: 30,
 		})
 		defer CloseBillingPeriod(ctx, testCustomerId)
-		_, workflowFound := service.workflows[testCustomerId]
+		_, workflowFound := service.GetWorkflowIDForCustomer(models.DefaultTenantID, testCustomerId)
 		assert.True(t, workflowFound)
-		assert.Len(t, service.workflows, 1, "Expected one workflow to be created")
+		assert.Equal(t, 1, service.workflowCount(), "Expected one workflow to be created")
 
 		// Try to add a line item to a non-existent bill
 		addLineItemResp, err := AddLineItem(ctx, testCustomerId, nonExistentBillId, &models.AddLineItemRequest{
@@ -114,7 +221,7 @@ func TestAddLineItemToBill(t *testing.T) {
 	})
 
 	t.Run("Add Line Item Without Workflow", func(t *testing.T) {
-		ctx := context.Background()
+		ctx := adminContext()
 
 		// Try to add a line item without starting a billing period
 		addLineItemResp, err := AddLineItem(ctx, testCustomerId, nonExistentBillId, &models.AddLineItemRequest{
@@ -129,10 +236,11 @@ func TestAddLineItemToBill(t *testing.T) {
 
 func TestCloseBillWorkflow(t *testing.T) {
 	testCustomerId := uuid.New().String()
+	createTestCustomer(t, testCustomerId)
 	nonExistentBillId := uuid.New().String()
 	nonExistentCustomerId := uuid.New().String()
 	t.Run("Close Bill Workflow", func(t *testing.T) {
-		ctx := context.Background()
+		ctx := adminContext()
 
 		err := StartBillingPeriod(ctx, &models.StartBillingPeriodRequest{
 			CustomerID:        testCustomerId,
@@ -142,9 +250,9 @@ func TestCloseBillWorkflow(t *testing.T) {
 		defer CloseBillingPeriod(ctx, testCustomerId)
 		assert.NoError(t, err)
 
-		_, workflowFound := service.workflows[testCustomerId]
+		_, workflowFound := service.GetWorkflowIDForCustomer(models.DefaultTenantID, testCustomerId)
 		assert.True(t, workflowFound)
-		assert.Len(t, service.workflows, 1, "Expected one workflow to be created")
+		assert.Equal(t, 1, service.workflowCount(), "Expected one workflow to be created")
 
 		// Create a bill
 		createBillResp, err := CreateBill(ctx, &models.CreateBillRequest{
@@ -202,7 +310,7 @@ func TestCloseBillWorkflow(t *testing.T) {
 	})
 
 	t.Run("Close Bill Workflow - Non-existent Customer", func(t *testing.T) {
-		ctx := context.Background()
+		ctx := adminContext()
 
 		closeBillResp, err := CloseBill(ctx, nonExistentCustomerId, nonExistentBillId, &models.CloseBillRequest{
 			Reason: "No longer needed",
@@ -214,8 +322,9 @@ func TestCloseBillWorkflow(t *testing.T) {
 
 func TestListBills(t *testing.T) {
 	testCustomerId := uuid.New().String()
+	createTestCustomer(t, testCustomerId)
 	t.Run("List Bills", func(t *testing.T) {
-		ctx := context.Background()
+		ctx := adminContext()
 
 		err := StartBillingPeriod(ctx, &models.StartBillingPeriodRequest{
 			CustomerID:        testCustomerId,
@@ -225,9 +334,9 @@ func TestListBills(t *testing.T) {
 		defer CloseBillingPeriod(ctx, testCustomerId)
 		assert.NoError(t, err)
 
-		_, workflowFound := service.workflows[testCustomerId]
+		_, workflowFound := service.GetWorkflowIDForCustomer(models.DefaultTenantID, testCustomerId)
 		assert.True(t, workflowFound)
-		assert.Len(t, service.workflows, 1, "Expected one workflow to be created")
+		assert.Equal(t, 1, service.workflowCount(), "Expected one workflow to be created")
 
 		// Create a bill
 		createBill1Resp, err := CreateBill(ctx, &models.CreateBillRequest{
@@ -266,10 +375,27 @@ func TestListBills(t *testing.T) {
 		assert.Len(t, listBillsResp.Bills, 1)
 		assert.Equal(t, listBillsResp.Bills[0].ID, CloseBillResp.Bill.ID)
 
+		// Without filters every bill is listed, one page at a time
+		listBillsResp, err = ListBills(ctx, testCustomerId, &models.ListBillsRequest{PageSize: 1})
+		assert.NoError(t, err)
+		assert.Len(t, listBillsResp.Bills, 1)
+		assert.Equal(t, createBill1Resp.BillID, listBillsResp.Bills[0].ID)
+		assert.NotEmpty(t, listBillsResp.NextCursor)
+
+		listBillsResp, err = ListBills(ctx, testCustomerId, &models.ListBillsRequest{PageSize: 1, Cursor: listBillsResp.NextCursor})
+		assert.NoError(t, err)
+		assert.Len(t, listBillsResp.Bills, 1)
+		assert.Equal(t, createBill2Resp.BillID, listBillsResp.Bills[0].ID)
+		assert.Empty(t, listBillsResp.NextCursor)
+
+		listBillsResp, err = ListBills(ctx, testCustomerId, &models.ListBillsRequest{Currency: models.GEL, Sort: models.BillSortCreatedDesc})
+		assert.NoError(t, err)
+		assert.Len(t, listBillsResp.Bills, 1)
+		assert.Equal(t, createBill2Resp.BillID, listBillsResp.Bills[0].ID)
 	})
 
 	t.Run("List Bills - Non-existent Customer", func(t *testing.T) {
-		ctx := context.Background()
+		ctx := adminContext()
 
 		listBillsResp, err := ListBills(ctx, testCustomerId, &models.ListBillsRequest{
 			Status: string(models.StatusOpen),
@@ -281,10 +407,11 @@ func TestListBills(t *testing.T) {
 
 func TestGetBill(t *testing.T) {
 	testCustomerId := uuid.New().String()
+	createTestCustomer(t, testCustomerId)
 	nonExistentBillId := uuid.New().String()
 	nonExistentCustomerId := uuid.New().String()
 	t.Run("Get Bill", func(t *testing.T) {
-		ctx := context.Background()
+		ctx := adminContext()
 
 		err := StartBillingPeriod(ctx, &models.StartBillingPeriodRequest{
 			CustomerID:        testCustomerId,
@@ -294,9 +421,9 @@ func TestGetBill(t *testing.T) {
 		defer CloseBillingPeriod(ctx, testCustomerId)
 		assert.NoError(t, err)
 
-		_, workflowFound := service.workflows[testCustomerId]
+		_, workflowFound := service.GetWorkflowIDForCustomer(models.DefaultTenantID, testCustomerId)
 		assert.True(t, workflowFound)
-		assert.Len(t, service.workflows, 1, "Expected one workflow to be created")
+		assert.Equal(t, 1, service.workflowCount(), "Expected one workflow to be created")
 
 		// Create a bill
 		createBillResp, err := CreateBill(ctx, &models.CreateBillRequest{
@@ -313,7 +440,7 @@ func TestGetBill(t *testing.T) {
 	})
 
 	t.Run("Get Bill - Non-existent Customer", func(t *testing.T) {
-		ctx := context.Background()
+		ctx := adminContext()
 
 		billResp, err := GetBill(ctx, nonExistentCustomerId, nonExistentBillId)
 		assert.Error(t, err)
@@ -321,7 +448,7 @@ func TestGetBill(t *testing.T) {
 	})
 
 	t.Run("Get Bill - Non-existent Bill", func(t *testing.T) {
-		ctx := context.Background()
+		ctx := adminContext()
 
 		err := StartBillingPeriod(ctx, &models.StartBillingPeriodRequest{
 			CustomerID:        testCustomerId,
@@ -337,7 +464,7 @@ func TestGetBill(t *testing.T) {
 	})
 
 	t.Run("Get Closed Bill", func(t *testing.T) {
-		ctx := context.Background()
+		ctx := adminContext()
 
 		err := StartBillingPeriod(ctx, &models.StartBillingPeriodRequest{
 			CustomerID:        testCustomerId,
@@ -371,8 +498,9 @@ func TestGetBill(t *testing.T) {
 
 func TestCloseBillingPeriod(t *testing.T) {
 	testCustomerId := uuid.New().String()
+	createTestCustomer(t, testCustomerId)
 	t.Run("Close Billing Period", func(t *testing.T) {
-		ctx := context.Background()
+		ctx := adminContext()
 
 		err := StartBillingPeriod(ctx, &models.StartBillingPeriodRequest{
 			CustomerID:        testCustomerId,
@@ -407,4 +535,663 @@ func TestCloseBillingPeriod(t *testing.T) {
 		assert.Equal(t, CloseBillPeriodResp.FinalAmountGEL, 250.0)
 
 	})
+
+	t.Run("Close Consolidated Billing Period", func(t *testing.T) {
+		ctx := adminContext()
+		parentCustomerId := uuid.New().String()
+		childCustomerId := uuid.New().String()
+		createTestCustomer(t, parentCustomerId)
+		createTestChildCustomer(t, childCustomerId, parentCustomerId)
+
+		for customerId, currency := range map[string]models.Currency{parentCustomerId: models.USD, childCustomerId: models.GEL} {
+			err := StartBillingPeriod(ctx, &models.StartBillingPeriodRequest{
+				CustomerID:        customerId,
+				Currency:          currency,
+				BillingPeriodDays: 30,
+			})
+			assert.NoError(t, err)
+		}
+
+		parentBillResp, err := CreateBill(ctx, &models.CreateBillRequest{CustomerID: parentCustomerId, Currency: string(models.USD)})
+		assert.NoError(t, err)
+		_, err = AddLineItem(ctx, parentCustomerId, parentBillResp.BillID, &models.AddLineItemRequest{
+			Description: "Parent Item",
+			Amount:      100.0,
+			Quantity:    1,
+			Currency:    string(models.USD),
+		})
+		assert.NoError(t, err)
+
+		childBillResp, err := CreateBill(ctx, &models.CreateBillRequest{CustomerID: childCustomerId, Currency: string(models.GEL)})
+		assert.NoError(t, err)
+		_, err = AddLineItem(ctx, childCustomerId, childBillResp.BillID, &models.AddLineItemRequest{
+			Description: "Child Item",
+			Amount:      50.0,
+			Quantity:    1,
+			Currency:    string(models.GEL),
+		})
+		assert.NoError(t, err)
+
+		closeResp, err := CloseBillingPeriod(ctx, parentCustomerId)
+		assert.NoError(t, err)
+		assert.NotNil(t, closeResp.ConsolidatedBill)
+		assert.Equal(t, models.USD, closeResp.ConsolidatedBill.Currency)
+		assert.Len(t, closeResp.ConsolidatedBill.ChildSubtotals, 2)
+		assert.Equal(t, parentCustomerId, closeResp.ConsolidatedBill.ChildSubtotals[0].CustomerID)
+		assert.Equal(t, 100.0, closeResp.ConsolidatedBill.ChildSubtotals[0].Subtotal)
+		assert.Equal(t, childCustomerId, closeResp.ConsolidatedBill.ChildSubtotals[1].CustomerID)
+		assert.Equal(t, 20.0, closeResp.ConsolidatedBill.ChildSubtotals[1].Subtotal)
+		assert.Equal(t, 120.0, closeResp.ConsolidatedBill.TotalAmount)
+		assert.Equal(t, closeResp.WorkflowID, closeResp.ConsolidatedBill.WorkflowID)
+
+		listResp, err := ListConsolidatedBills(ctx, parentCustomerId)
+		assert.NoError(t, err)
+		if assert.Len(t, listResp.ConsolidatedBills, 1) {
+			assert.Equal(t, closeResp.WorkflowID, listResp.ConsolidatedBills[0].WorkflowID)
+			assert.Equal(t, 120.0, listResp.ConsolidatedBills[0].TotalAmount)
+		}
+
+		_, childStillActive := service.GetWorkflowIDForCustomer(models.DefaultTenantID, childCustomerId)
+		assert.False(t, childStillActive)
+	})
+}
+
+func TestSpendingLimit(t *testing.T) {
+	testCustomerId := uuid.New().String()
+	createTestCustomer(t, testCustomerId)
+	t.Run("Enforce And Raise Hard Cap", func(t *testing.T) {
+		ctx := adminContext()
+
+		err := StartBillingPeriod(ctx, &models.StartBillingPeriodRequest{
+			CustomerID:        testCustomerId,
+			Currency:          models.USD,
+			BillingPeriodDays: 30,
+			SpendingLimit:     &models.SpendingLimit{HardCap: 150},
+		})
+		assert.NoError(t, err)
+		defer CloseBillingPeriod(ctx, testCustomerId)
+
+		createBillResp, err := CreateBill(ctx, &models.CreateBillRequest{
+			CustomerID: testCustomerId,
+			Currency:   string(models.USD),
+		})
+		assert.NoError(t, err)
+
+		_, err = AddLineItem(ctx, testCustomerId, createBillResp.BillID, &models.AddLineItemRequest{
+			Description: "Within Budget",
+			Amount:      100.0,
+			Quantity:    1,
+			Currency:    string(models.USD),
+		})
+		assert.NoError(t, err)
+
+		_, err = AddLineItem(ctx, testCustomerId, createBillResp.BillID, &models.AddLineItemRequest{
+			Description: "Over Budget",
+			Amount:      100.0,
+			Quantity:    1,
+			Currency:    string(models.USD),
+		})
+		assert.Error(t, err)
+		assert.Contains(t, err.Error(), "would exceed the spending limit")
+
+		statusResp, err := GetSpendingStatus(ctx, testCustomerId)
+		assert.NoError(t, err)
+		assert.Equal(t, 100.0, statusResp.Status.Total)
+		assert.Equal(t, 50.0, *statusResp.Status.Remaining)
+		assert.Len(t, statusResp.Status.Alerts, 1)
+		assert.Equal(t, 0.5, statusResp.Status.Alerts[0].Threshold)
+
+		// Raising the cap lets the period keep spending
+		setResp, err := SetSpendingLimit(ctx, testCustomerId, &models.SetSpendingLimitRequest{
+			Limit: models.SpendingLimit{HardCap: 1000, Currency: models.USD},
+		})
+		assert.NoError(t, err)
+		assert.Equal(t, 1000.0, setResp.Status.Limit.HardCap)
+		assert.Equal(t, 900.0, *setResp.Status.Remaining)
+	})
+}
+
+func TestAuthorization(t *testing.T) {
+	testCustomerId := uuid.New().String()
+	otherCustomerId := uuid.New().String()
+	createTestCustomer(t, testCustomerId)
+	createTestCustomer(t, otherCustomerId)
+	t.Run("Customer Key Acts On Own Bills Only", func(t *testing.T) {
+		ctx := customerContext(testCustomerId, models.ScopeWrite)
+
+		err := StartBillingPeriod(ctx, &models.StartBillingPeriodRequest{
+			CustomerID:        testCustomerId,
+			Currency:          models.USD,
+			BillingPeriodDays: 30,
+		})
+		assert.NoError(t, err)
+		defer CloseBillingPeriod(adminContext(), testCustomerId)
+
+		createBillResp, err := CreateBill(ctx, &models.CreateBillRequest{
+			CustomerID: testCustomerId,
+			Currency:   string(models.USD),
+		})
+		assert.NoError(t, err)
+
+		// A write key can read its own bills
+		_, err = GetBill(ctx, testCustomerId, createBillResp.BillID)
+		assert.NoError(t, err)
+
+		// Another customer's key cannot touch them
+		otherCtx := customerContext(otherCustomerId, models.ScopeWrite)
+		_, err = CloseBill(otherCtx, testCustomerId, createBillResp.BillID, &models.CloseBillRequest{Reason: "test"})
+		assert.Error(t, err)
+		assert.Contains(t, err.Error(), "may not act on customer")
+		_, err = CloseBillingPeriod(otherCtx, testCustomerId)
+		assert.Error(t, err)
+
+		// A read key cannot change them
+		readCtx := customerContext(testCustomerId, models.ScopeRead)
+		_, err = AddLineItem(readCtx, testCustomerId, createBillResp.BillID, &models.AddLineItemRequest{
+			Description: "Test Item",
+			Amount:      10.0,
+			Quantity:    1,
+			Currency:    string(models.USD),
+		})
+		assert.Error(t, err)
+		assert.Contains(t, err.Error(), "lacks the write scope")
+		_, err = ListBills(readCtx, testCustomerId, &models.ListBillsRequest{Status: string(models.StatusOpen)})
+		assert.NoError(t, err)
+	})
+
+	t.Run("Seller Profile Requires Admin", func(t *testing.T) {
+		err := SetLegalEntity(customerContext(testCustomerId, models.ScopeWrite), &models.LegalEntityProfile{})
+		assert.Error(t, err)
+		assert.Contains(t, err.Error(), "lacks the admin scope")
+	})
+
+	t.Run("Missing API Key", func(t *testing.T) {
+		_, err := GetSpendingStatus(context.Background(), testCustomerId)
+		assert.Error(t, err)
+	})
+}
+
+func TestTenantIsolation(t *testing.T) {
+	// The same customer ID is billed by two tenants
+	testCustomerId := uuid.New().String()
+	createTestCustomer(t, testCustomerId)
+	otherTenantId := createTestTenant(t, models.USD)
+	defaultCtx := adminContext()
+	otherCtx := tenantAdminContext(otherTenantId)
+
+	t.Run("Customers Of Another Tenant Are Unknown", func(t *testing.T) {
+		err := StartBillingPeriod(otherCtx, &models.StartBillingPeriodRequest{
+			CustomerID:        testCustomerId,
+			Currency:          models.USD,
+			BillingPeriodDays: 30,
+		})
+		assert.Error(t, err)
+		assert.Contains(t, err.Error(), "unknown customer")
+	})
+	createTestTenantCustomer(t, otherTenantId, testCustomerId, "")
+
+	t.Run("Tenants Never See Each Other's Bills", func(t *testing.T) {
+		for _, ctx := range []context.Context{defaultCtx, otherCtx} {
+			err := StartBillingPeriod(ctx, &models.StartBillingPeriodRequest{
+				CustomerID:        testCustomerId,
+				Currency:          models.USD,
+				BillingPeriodDays: 30,
+			})
+			assert.NoError(t, err)
+		}
+		defer CloseBillingPeriod(defaultCtx, testCustomerId)
+		defer CloseBillingPeriod(otherCtx, testCustomerId)
+
+		defaultWorkflowId, _ := service.GetWorkflowIDForCustomer(models.DefaultTenantID, testCustomerId)
+		otherWorkflowId, _ := service.GetWorkflowIDForCustomer(otherTenantId, testCustomerId)
+		assert.NotEqual(t, defaultWorkflowId, otherWorkflowId)
+		assert.Contains(t, otherWorkflowId, otherTenantId)
+
+		createBillResp, err := CreateBill(defaultCtx, &models.CreateBillRequest{
+			CustomerID: testCustomerId,
+			Currency:   string(models.USD),
+		})
+		assert.NoError(t, err)
+
+		_, err = GetBill(otherCtx, testCustomerId, createBillResp.BillID)
+		assert.Error(t, err)
+		_, err = CloseBill(otherCtx, testCustomerId, createBillResp.BillID, &models.CloseBillRequest{Reason: "test"})
+		assert.Error(t, err)
+
+		// A tenant named in the body cannot widen the key's tenant
+		listResp, err := ListBills(otherCtx, testCustomerId, &models.ListBillsRequest{
+			Status:   string(models.StatusOpen),
+			TenantID: models.DefaultTenantID,
+		})
+		assert.NoError(t, err)
+		assert.Empty(t, listResp.Bills)
+
+		listResp, err = ListBills(defaultCtx, testCustomerId, &models.ListBillsRequest{Status: string(models.StatusOpen)})
+		assert.NoError(t, err)
+		assert.Len(t, listResp.Bills, 1)
+	})
+
+	t.Run("Start Billing Period For Another Tenant", func(t *testing.T) {
+		err := StartBillingPeriod(otherCtx, &models.StartBillingPeriodRequest{
+			TenantID:          models.DefaultTenantID,
+			CustomerID:        testCustomerId,
+			BillingPeriodDays: 30,
+		})
+		assert.Error(t, err)
+		assert.Contains(t, err.Error(), "may not act on tenant")
+	})
+
+	t.Run("Tenant Currencies", func(t *testing.T) {
+		err := StartBillingPeriod(otherCtx, &models.StartBillingPeriodRequest{
+			CustomerID:        testCustomerId,
+			Currency:          models.GEL,
+			BillingPeriodDays: 30,
+		})
+		assert.Error(t, err)
+		assert.Contains(t, err.Error(), "not enabled for tenant")
+	})
+}
+
+func TestLegalEntityPerTenant(t *testing.T) {
+	tenantId := createTestTenant(t, models.USD)
+	otherTenantId := createTestTenant(t, models.USD)
+	seller := &models.LegalEntityProfile{
+		LegalName:      "Billing Co GmbH",
+		Address:        models.Address{CountryCode: "DE"},
+		VATID:          "DE123456789",
+		EndpointID:     "DE123456789",
+		EndpointScheme: "9930",
+		VATRate:        19,
+	}
+
+	t.Run("Seller Profile Is Stored Per Tenant", func(t *testing.T) {
+		assert.NoError(t, SetLegalEntity(tenantAdminContext(tenantId), seller))
+
+		resp, err := FindLegalEntity(context.Background(), tenantId)
+		assert.NoError(t, err)
+		assert.Equal(t, seller, resp.LegalEntity)
+
+		resp, err = FindLegalEntity(context.Background(), otherTenantId)
+		assert.NoError(t, err)
+		assert.Nil(t, resp.LegalEntity)
+	})
+
+	t.Run("Seller Profile Is Replaced", func(t *testing.T) {
+		replaced := *seller
+		replaced.VATRate = 7
+		assert.NoError(t, SetLegalEntity(tenantAdminContext(tenantId), &replaced))
+
+		resp, err := FindLegalEntity(context.Background(), tenantId)
+		assert.NoError(t, err)
+		assert.Equal(t, 7.0, resp.LegalEntity.VATRate)
+	})
+}
+
+func TestAuditLog(t *testing.T) {
+	testCustomerId := uuid.New().String()
+	createTestCustomer(t, testCustomerId)
+	ctx := customerContext(testCustomerId, models.ScopeWrite)
+
+	t.Run("Every Bill Mutation Is Recorded", func(t *testing.T) {
+		err := StartBillingPeriod(ctx, &models.StartBillingPeriodRequest{
+			CustomerID:        testCustomerId,
+			Currency:          models.USD,
+			BillingPeriodDays: 30,
+		})
+		assert.NoError(t, err)
+		defer CloseBillingPeriod(adminContext(), testCustomerId)
+
+		createBillResp, err := CreateBill(ctx, &models.CreateBillRequest{
+			CustomerID: testCustomerId,
+			Currency:   string(models.USD),
+		})
+		assert.NoError(t, err)
+		_, err = AddLineItem(ctx, testCustomerId, createBillResp.BillID, &models.AddLineItemRequest{
+			Description: "Test Item",
+			Amount:      10.0,
+			Quantity:    1,
+			Currency:    string(models.USD),
+		})
+		assert.NoError(t, err)
+		_, err = CloseBill(ctx, testCustomerId, createBillResp.BillID, &models.CloseBillRequest{Reason: "paid"})
+		assert.NoError(t, err)
+
+		// Rejected calls are recorded with their error
+		_, err = CloseBill(ctx, testCustomerId, createBillResp.BillID, &models.CloseBillRequest{Reason: "again"})
+		assert.Error(t, err)
+
+		readCtx := customerContext(testCustomerId, models.ScopeRead)
+		assert.Eventually(t, func() bool {
+			resp, err := audit.ListBillRecords(readCtx, testCustomerId, createBillResp.BillID)
+			if err != nil {
+				return false
+			}
+			// Create, add and two closes from the API, then create, add and close applied by the workflow
+			return resp.Total == 7
+		}, 10*time.Second, 100*time.Millisecond)
+
+		resp, err := audit.ListBillRecords(readCtx, testCustomerId, createBillResp.BillID)
+		assert.NoError(t, err)
+		for _, record := range resp.Records {
+			assert.Equal(t, "test-"+testCustomerId, record.Actor)
+			if record.Source == models.AuditSourceWorkflow && record.Action == models.AuditCloseBill {
+				assert.Equal(t, 10.0, *record.TotalBefore)
+				assert.Equal(t, "paid", record.Reason)
+			}
+			if record.Source == models.AuditSourceAPI && record.Reason == "again" {
+				assert.Contains(t, record.Error, "already closed")
+			}
+		}
+
+		resp, err = audit.ListCustomerRecords(readCtx, testCustomerId)
+		assert.NoError(t, err)
+		assert.Equal(t, models.AuditStartBillingPeriod, resp.Records[0].Action)
+	})
+
+	t.Run("Audit Log Is Scoped To The Customer", func(t *testing.T) {
+		_, err := audit.ListCustomerRecords(customerContext(uuid.New().String(), models.ScopeRead), testCustomerId)
+		assert.Error(t, err)
+		assert.Contains(t, err.Error(), "may not act on customer")
+	})
+}
+
+func TestArchivedBillingPeriod(t *testing.T) {
+	testCustomerId := uuid.New().String()
+	createTestCustomer(t, testCustomerId)
+	ctx := adminContext()
+
+	err := StartBillingPeriod(ctx, &models.StartBillingPeriodRequest{
+		CustomerID:        testCustomerId,
+		Currency:          models.USD,
+		BillingPeriodDays: 30,
+	})
+	assert.NoError(t, err)
+	createBillResp, err := CreateBill(ctx, &models.CreateBillRequest{CustomerID: testCustomerId, Currency: string(models.USD)})
+	assert.NoError(t, err)
+	_, err = AddLineItem(ctx, testCustomerId, createBillResp.BillID, &models.AddLineItemRequest{
+		Description: "Archived Item",
+		Amount:      75.0,
+		Quantity:    1,
+		Currency:    string(models.USD),
+	})
+	assert.NoError(t, err)
+	closeResp, err := CloseBillingPeriod(ctx, testCustomerId)
+	assert.NoError(t, err)
+
+	// The workflow archives the period as it returns, archiving it here as well keeps the test deterministic
+	assert.NoError(t, (&Activities{}).ArchiveBillingPeriod(context.Background(), models.ArchivedBillingPeriod{
+		WorkflowID: closeResp.WorkflowID,
+		TenantID:   models.DefaultTenantID,
+		CustomerID: testCustomerId,
+		Currency:   models.USD,
+		StartedAt:  time.Now().Add(-time.Minute),
+		ClosedAt:   time.Now(),
+		Bills:      closeResp.Bills,
+	}))
+
+	t.Run("Get Bill Falls Back to the Archive", func(t *testing.T) {
+		resp, err := GetBill(ctx, testCustomerId, createBillResp.BillID)
+		assert.NoError(t, err)
+		assert.Equal(t, models.StatusClosed, resp.Bill.Status)
+		assert.Equal(t, 75.0, resp.Bill.TotalAmount)
+		assert.Len(t, resp.Bill.LineItems, 1)
+	})
+
+	t.Run("List Bills Includes Archived Periods", func(t *testing.T) {
+		resp, err := ListBills(ctx, testCustomerId, &models.ListBillsRequest{Status: string(models.StatusClosed)})
+		assert.NoError(t, err)
+		assert.Len(t, resp.Bills, 1)
+		assert.Equal(t, createBillResp.BillID, resp.Bills[0].ID)
+
+		// A new period lists the archived bills before its own
+		err = StartBillingPeriod(ctx, &models.StartBillingPeriodRequest{
+			CustomerID:        testCustomerId,
+			Currency:          models.USD,
+			BillingPeriodDays: 30,
+		})
+		assert.NoError(t, err)
+		_, err = CreateBill(ctx, &models.CreateBillRequest{CustomerID: testCustomerId, Currency: string(models.USD)})
+		assert.NoError(t, err)
+		resp, err = ListBills(ctx, testCustomerId, &models.ListBillsRequest{Status: string(models.StatusOpen)})
+		assert.NoError(t, err)
+		assert.Len(t, resp.Bills, 1)
+		resp, err = ListBills(ctx, testCustomerId, &models.ListBillsRequest{Status: string(models.StatusClosed)})
+		assert.NoError(t, err)
+		assert.Len(t, resp.Bills, 1)
+	})
+
+	t.Run("Archived Bills Stay With Their Customer", func(t *testing.T) {
+		_, err := GetBill(ctx, uuid.New().String(), createBillResp.BillID)
+		assert.Error(t, err)
+	})
+}
+
+func TestSearchBills(t *testing.T) {
+	testCustomerId := uuid.New().String()
+	createTestCustomer(t, testCustomerId)
+	ctx := adminContext()
+
+	err := StartBillingPeriod(ctx, &models.StartBillingPeriodRequest{
+		CustomerID:        testCustomerId,
+		Currency:          models.USD,
+		BillingPeriodDays: 30,
+	})
+	assert.NoError(t, err)
+	defer CloseBillingPeriod(ctx, testCustomerId)
+	createBillResp, err := CreateBill(ctx, &models.CreateBillRequest{
+		CustomerID: testCustomerId,
+		Currency:   string(models.USD),
+	})
+	assert.NoError(t, err)
+
+	t.Run("Find a Bill Without Its Customer", func(t *testing.T) {
+		var resp *models.SearchBillsResponse
+		// Visibility is updated asynchronously after the workflow upserts its attributes
+		assert.Eventually(t, func() bool {
+			resp, err = SearchBills(ctx, &models.SearchBillsRequest{BillID: createBillResp.BillID})
+			return err == nil && len(resp.Periods) == 1
+		}, 10*time.Second, 200*time.Millisecond)
+		if assert.NotNil(t, resp) && assert.Len(t, resp.Periods, 1) {
+			period := resp.Periods[0]
+			assert.Equal(t, testCustomerId, period.CustomerID)
+			assert.Equal(t, models.StatusOpen, period.Status)
+			assert.Equal(t, 1, period.OpenBillCount)
+			if assert.NotNil(t, period.Bill) {
+				assert.Equal(t, createBillResp.BillID, period.Bill.ID)
+			}
+		}
+	})
+
+	t.Run("Invalid Filters", func(t *testing.T) {
+		_, err := SearchBills(ctx, &models.SearchBillsRequest{Currency: "EUR"})
+		assert.Error(t, err)
+	})
+
+	t.Run("Scoped to the Tenant", func(t *testing.T) {
+		resp, err := SearchBills(tenantAdminContext("wholesale"), &models.SearchBillsRequest{BillID: createBillResp.BillID})
+		assert.NoError(t, err)
+		assert.Empty(t, resp.Periods)
+	})
+}
+
+func TestGRPCAPI(t *testing.T) {
+	testCustomerId := uuid.New().String()
+	createTestCustomer(t, testCustomerId)
+	key, err := apikeys.CreateAPIKey(adminContext(), &models.CreateAPIKeyRequest{
+		CustomerID: testCustomerId,
+		Scopes:     []models.APIKeyScope{models.ScopeRead, models.ScopeWrite},
+	})
+	assert.NoError(t, err)
+
+	listener := bufconn.Listen(1 << 20)
+	server := newGRPCServer()
+	go server.Serve(listener)
+	defer server.Stop()
+	conn, err := grpc.NewClient("passthrough:///bufnet",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) { return listener.DialContext(ctx) }),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+	)
+	assert.NoError(t, err)
+	defer conn.Close()
+	client := billsv1.NewBillsServiceClient(conn)
+	ctx := metadata.AppendToOutgoingContext(context.Background(), "authorization", "Bearer "+key.Key)
+
+	t.Run("Requires an API Key", func(t *testing.T) {
+		_, err := client.ListBills(context.Background(), &billsv1.ListBillsRequest{CustomerId: testCustomerId})
+		assert.Equal(t, codes.Unauthenticated, status.Code(err))
+	})
+
+	t.Run("Bill Lifecycle", func(t *testing.T) {
+		_, err := client.StartBillingPeriod(ctx, &billsv1.StartBillingPeriodRequest{
+			CustomerId:        testCustomerId,
+			Currency:          string(models.USD),
+			BillingPeriodDays: 30,
+		})
+		assert.NoError(t, err)
+		defer client.CloseBillingPeriod(ctx, &billsv1.CloseBillingPeriodRequest{CustomerId: testCustomerId})
+
+		created, err := client.CreateBill(ctx, &billsv1.CreateBillRequest{CustomerId: testCustomerId, Currency: string(models.USD)})
+		assert.NoError(t, err)
+		time.Sleep(500 * time.Millisecond)
+		_, err = client.AddLineItem(ctx, &billsv1.AddLineItemRequest{
+			CustomerId:  testCustomerId,
+			BillId:      created.BillId,
+			Description: "API calls",
+			Amount:      4.5,
+			Quantity:    2,
+			Currency:    string(models.USD),
+		})
+		assert.NoError(t, err)
+		time.Sleep(500 * time.Millisecond)
+
+		resp, err := client.GetBill(ctx, &billsv1.GetBillRequest{CustomerId: testCustomerId, BillId: created.BillId})
+		assert.NoError(t, err)
+		assert.Equal(t, 9.0, resp.Bill.TotalAmount)
+		assert.Len(t, resp.Bill.LineItems, 1)
+
+		list, err := client.ListBills(ctx, &billsv1.ListBillsRequest{CustomerId: testCustomerId, Status: string(models.StatusOpen)})
+		assert.NoError(t, err)
+		assert.Equal(t, int64(1), list.Total)
+	})
+
+	t.Run("Errors Carry Their Reason", func(t *testing.T) {
+		_, err := client.GetBill(ctx, &billsv1.GetBillRequest{CustomerId: testCustomerId, BillId: "missing"})
+		st, _ := status.FromError(err)
+		assert.Equal(t, codes.NotFound, st.Code())
+		if assert.Len(t, st.Details(), 1) {
+			info, _ := st.Details()[0].(*errdetails.ErrorInfo)
+			assert.NotEmpty(t, info.GetReason())
+		}
+	})
+
+	t.Run("Scoped to the Key's Customer", func(t *testing.T) {
+		_, err := client.ListBills(ctx, &billsv1.ListBillsRequest{CustomerId: uuid.New().String()})
+		assert.Equal(t, codes.PermissionDenied, status.Code(err))
+	})
+}
+
+func TestHealthProbes(t *testing.T) {
+	t.Run("Live", func(t *testing.T) {
+		resp, err := Liveness(context.Background())
+		assert.NoError(t, err)
+		assert.Equal(t, "alive", resp.Status)
+		assert.NotEmpty(t, resp.Build.Version)
+	})
+
+	t.Run("Ready", func(t *testing.T) {
+		resp, err := Readiness(context.Background())
+		assert.NoError(t, err)
+		assert.Equal(t, models.StatusReady, resp.Status)
+		assert.Empty(t, resp.Problems)
+		if assert.NotEmpty(t, resp.TaskQueues) {
+			assert.Equal(t, billsTaskQueue, resp.TaskQueues[0].TaskQueue)
+			assert.True(t, resp.TaskQueues[0].LocalWorker)
+		}
+	})
+
+	t.Run("Not Ready Without Temporal", func(t *testing.T) {
+		connected := service.setTemporalClient(nil)
+		defer service.setTemporalClient(connected)
+
+		_, err := Readiness(context.Background())
+		assert.Equal(t, errs.Unavailable, errs.Code(err))
+		apiErr, ok := err.(*errs.Error)
+		if assert.True(t, ok) {
+			details, ok := apiErr.Details.(models.ReadinessResponse)
+			assert.True(t, ok)
+			assert.Equal(t, models.StatusNotReady, details.Status)
+			assert.Contains(t, details.Problems, "Temporal is unreachable")
+		}
+
+		// Liveness does not depend on Temporal
+		_, err = Liveness(context.Background())
+		assert.NoError(t, err)
+	})
+}
+
+func TestMetrics(t *testing.T) {
+	_, err := Liveness(context.Background())
+	assert.NoError(t, err)
+	connected := service.setTemporalClient(nil)
+	_, err = Readiness(context.Background())
+	service.setTemporalClient(connected)
+	assert.Error(t, err)
+
+	defer et.OverrideAuthInfo("", nil)
+	et.OverrideAuthInfo("test-admin-"+models.DefaultTenantID, &models.AuthData{
+		KeyID:    "test-admin-" + models.DefaultTenantID,
+		TenantID: models.DefaultTenantID,
+		Scopes:   []models.APIKeyScope{models.ScopeAdmin},
+	})
+	recorder := httptest.NewRecorder()
+	Metrics(recorder, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+	assert.Equal(t, http.StatusForbidden, recorder.Code, "Expected tenant keys to be denied")
+
+	et.OverrideAuthInfo("metrics-scraper", &models.AuthData{KeyID: "metrics-scraper", Scopes: []models.APIKeyScope{models.ScopeMetrics}})
+	recorder = httptest.NewRecorder()
+	Metrics(recorder, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+	assert.Equal(t, http.StatusOK, recorder.Code)
+	assert.Equal(t, metrics.ContentType, recorder.Header().Get("Content-Type"))
+	body := recorder.Body.String()
+	assert.Contains(t, body, `api_request_duration_seconds_count{endpoint="Liveness",service="bills"}`)
+	assert.Contains(t, body, `api_request_errors_total{code="unavailable",endpoint="Readiness",service="bills"} `)
+	// The worker of the default tenant reports through the Temporal metrics handler
+	assert.Contains(t, body, "# TYPE temporal_")
+}
+
+func TestTemporalDisconnected(t *testing.T) {
+	testCustomerId := uuid.New().String()
+	createTestCustomer(t, testCustomerId)
+	ctx := adminContext()
+	connected := service.setTemporalClient(nil)
+	defer service.setTemporalClient(connected)
+
+	t.Run("Health Is Degraded", func(t *testing.T) {
+		resp, err := HealthCheck(context.Background())
+		assert.NoError(t, err)
+		assert.Equal(t, "degraded", resp.Status)
+		assert.NotEmpty(t, resp.Error)
+	})
+
+	t.Run("API Calls Fail Fast", func(t *testing.T) {
+		start := time.Now()
+		err := StartBillingPeriod(ctx, &models.StartBillingPeriodRequest{
+			CustomerID:        testCustomerId,
+			Currency:          models.USD,
+			BillingPeriodDays: 30,
+		})
+		assert.Equal(t, errs.Unavailable, errs.Code(err))
+		assert.Less(t, time.Since(start), 5*time.Second)
+	})
+
+	t.Run("Injected Client", func(t *testing.T) {
+		mockClient := &mocks.Client{}
+		mockClient.On("CheckHealth", mock.Anything, mock.Anything).Return(&client.CheckHealthResponse{}, nil)
+		service.setTemporalClient(mockClient)
+
+		resp, err := HealthCheck(context.Background())
+		assert.NoError(t, err)
+		assert.Equal(t, "healthy", resp.Status)
+		mockClient.AssertExpectations(t)
+	})
 }
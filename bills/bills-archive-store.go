@@ -0,0 +1,200 @@
+package bills
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"encore.dev/storage/sqldb"
+
+	"encore.app/models"
+)
+
+// errArchivedBillNotFound is returned when no archived billing period of the customer holds the bill
+var errArchivedBillNotFound = errors.New("archived bill not found")
+
+// errConsolidatedBillNotFound is returned when the archived billing period has no consolidated bill
+var errConsolidatedBillNotFound = errors.New("consolidated bill not found")
+
+// insertArchivedPeriod writes a finished billing period and its bills. Archiving a period again, as a retried
+// activity does, leaves the stored period untouched.
+func insertArchivedPeriod(ctx context.Context, period *models.ArchivedBillingPeriod) error {
+	tx, err := db.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	var consolidated []byte
+	if period.ConsolidatedBill != nil {
+		consolidated, err = json.Marshal(period.ConsolidatedBill)
+		if err != nil {
+			return fmt.Errorf("failed to encode consolidated bill: %w", err)
+		}
+	}
+	_, err = tx.Exec(ctx, `
+		INSERT INTO archived_billing_periods (workflow_id, tenant_id, customer_id, currency, started_at, closed_at,
+			consolidated_bill)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)
+		ON CONFLICT (workflow_id) DO NOTHING
+	`, period.WorkflowID, period.TenantID, period.CustomerID, string(period.Currency), period.StartedAt, period.ClosedAt,
+		consolidated)
+	if err != nil {
+		return fmt.Errorf("failed to archive billing period: %w", err)
+	}
+	for _, bill := range period.Bills {
+		document, err := json.Marshal(bill)
+		if err != nil {
+			return fmt.Errorf("failed to encode bill %s: %w", bill.ID, err)
+		}
+		var closedAt *time.Time
+		if !bill.ClosedAt.IsZero() {
+			closedAt = &bill.ClosedAt
+		}
+		_, err = tx.Exec(ctx, `
+			INSERT INTO archived_bills (bill_id, workflow_id, tenant_id, customer_id, status, currency, total_amount,
+				close_reason, created_at, closed_at, bill)
+			VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11)
+			ON CONFLICT (bill_id) DO NOTHING
+		`, bill.ID, period.WorkflowID, period.TenantID, period.CustomerID, string(bill.Status), string(bill.Currency),
+			bill.TotalAmount, bill.CloseReason, bill.CreatedAt, closedAt, document)
+		if err != nil {
+			return fmt.Errorf("failed to archive bill %s: %w", bill.ID, err)
+		}
+	}
+	return tx.Commit()
+}
+
+// findArchivedBill returns a bill of one of the customer's archived billing periods
+func findArchivedBill(ctx context.Context, tenantID, customerID, billID string) (*models.Bill, error) {
+	var document []byte
+	err := db.QueryRow(ctx, `
+		SELECT bill FROM archived_bills
+		WHERE tenant_id = $1 AND customer_id = $2 AND bill_id = $3
+	`, tenantID, customerID, billID).Scan(&document)
+	if errors.Is(err, sqldb.ErrNoRows) {
+		return nil, errArchivedBillNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to load archived bill: %w", err)
+	}
+	var bill models.Bill
+	if err := json.Unmarshal(document, &bill); err != nil {
+		return nil, fmt.Errorf("failed to decode archived bill: %w", err)
+	}
+	return &bill, nil
+}
+
+// listArchivedBills returns the customer's archived bills with the status, of any status when empty, oldest first
+func listArchivedBills(ctx context.Context, tenantID, customerID string, status models.BillStatus) ([]*models.Bill, error) {
+	rows, err := db.Query(ctx, `
+		SELECT bill FROM archived_bills
+		WHERE tenant_id = $1 AND customer_id = $2 AND ($3 = '' OR status = $3)
+		ORDER BY created_at, bill_id
+	`, tenantID, customerID, string(status))
+	if err != nil {
+		return nil, fmt.Errorf("failed to list archived bills: %w", err)
+	}
+	defer rows.Close()
+
+	bills := make([]*models.Bill, 0)
+	for rows.Next() {
+		var document []byte
+		if err := rows.Scan(&document); err != nil {
+			return nil, fmt.Errorf("failed to scan archived bill: %w", err)
+		}
+		var bill models.Bill
+		if err := json.Unmarshal(document, &bill); err != nil {
+			return nil, fmt.Errorf("failed to decode archived bill: %w", err)
+		}
+		bills = append(bills, &bill)
+	}
+	return bills, rows.Err()
+}
+
+// findConsolidatedBill returns the consolidated bill of an archived billing period of the tenant
+func findConsolidatedBill(ctx context.Context, tenantID, workflowID string) (*models.ConsolidatedBill, error) {
+	var document []byte
+	err := db.QueryRow(ctx, `
+		SELECT consolidated_bill FROM archived_billing_periods
+		WHERE tenant_id = $1 AND workflow_id = $2
+	`, tenantID, workflowID).Scan(&document)
+	if errors.Is(err, sqldb.ErrNoRows) {
+		return nil, errConsolidatedBillNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to load consolidated bill: %w", err)
+	}
+	if document == nil {
+		return nil, errConsolidatedBillNotFound
+	}
+	var consolidated models.ConsolidatedBill
+	if err := json.Unmarshal(document, &consolidated); err != nil {
+		return nil, fmt.Errorf("failed to decode consolidated bill: %w", err)
+	}
+	return &consolidated, nil
+}
+
+// listConsolidatedBills returns the consolidated bills of the parent account's archived billing periods, newest first
+func listConsolidatedBills(ctx context.Context, tenantID, customerID string) ([]*models.ConsolidatedBill, error) {
+	rows, err := db.Query(ctx, `
+		SELECT consolidated_bill FROM archived_billing_periods
+		WHERE tenant_id = $1 AND customer_id = $2 AND consolidated_bill IS NOT NULL
+		ORDER BY closed_at DESC, workflow_id
+	`, tenantID, customerID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list consolidated bills: %w", err)
+	}
+	defer rows.Close()
+
+	consolidatedBills := make([]*models.ConsolidatedBill, 0)
+	for rows.Next() {
+		var document []byte
+		if err := rows.Scan(&document); err != nil {
+			return nil, fmt.Errorf("failed to scan consolidated bill: %w", err)
+		}
+		var consolidated models.ConsolidatedBill
+		if err := json.Unmarshal(document, &consolidated); err != nil {
+			return nil, fmt.Errorf("failed to decode consolidated bill: %w", err)
+		}
+		consolidatedBills = append(consolidatedBills, &consolidated)
+	}
+	return consolidatedBills, rows.Err()
+}
+
+// listArchivedPeriods returns the bills of the customer's billing periods archived since the given time, per period
+func listArchivedPeriods(ctx context.Context, tenantID, customerID string, since time.Time) ([]*models.ChildBills, error) {
+	rows, err := db.Query(ctx, `
+		SELECT p.workflow_id, b.bill FROM archived_billing_periods p
+		JOIN archived_bills b ON b.workflow_id = p.workflow_id
+		WHERE p.tenant_id = $1 AND p.customer_id = $2 AND p.closed_at >= $3
+		ORDER BY p.closed_at, p.workflow_id, b.created_at, b.bill_id
+	`, tenantID, customerID, since)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list archived billing periods: %w", err)
+	}
+	defer rows.Close()
+
+	periods := make([]*models.ChildBills, 0)
+	for rows.Next() {
+		var (
+			workflowID string
+			document   []byte
+		)
+		if err := rows.Scan(&workflowID, &document); err != nil {
+			return nil, fmt.Errorf("failed to scan archived bill: %w", err)
+		}
+		var bill models.Bill
+		if err := json.Unmarshal(document, &bill); err != nil {
+			return nil, fmt.Errorf("failed to decode archived bill: %w", err)
+		}
+		if len(periods) == 0 || periods[len(periods)-1].WorkflowID != workflowID {
+			periods = append(periods, &models.ChildBills{CustomerID: customerID, WorkflowID: workflowID})
+		}
+		period := periods[len(periods)-1]
+		period.Bills = append(period.Bills, &bill)
+	}
+	return periods, rows.Err()
+}
@@ -0,0 +1,65 @@
+package bills
+
+import (
+	"context"
+	"time"
+
+	"encore.dev"
+	"encore.dev/beta/auth"
+	"encore.dev/rlog"
+	"github.com/google/uuid"
+
+	"encore.app/audit"
+	"encore.app/models"
+)
+
+// requestIDHeader lets callers correlate their own request IDs with audit records
+const requestIDHeader = "X-Request-Id"
+
+// currentAuditContext identifies the caller of the current request, it travels with every signal so the
+// workflow records who asked for the mutation it applies
+func currentAuditContext() models.AuditContext {
+	uid, _ := auth.UserID()
+	auditCtx := models.AuditContext{Actor: string(uid)}
+	req := encore.CurrentRequest()
+	if req == nil {
+		return auditCtx
+	}
+	auditCtx.RequestID = req.Headers.Get(requestIDHeader)
+	if auditCtx.RequestID == "" && req.Trace != nil {
+		auditCtx.RequestID = req.Trace.TraceID
+	}
+	return auditCtx
+}
+
+// newAPIAuditRecord starts the audit record of a mutating API call, callers fill in what they learn as the
+// call proceeds and append it with appendAPIAuditRecord once it returns
+func newAPIAuditRecord(tenantID, customerID string, action models.AuditAction) *models.AuditRecord {
+	auditCtx := currentAuditContext()
+	return &models.AuditRecord{
+		RecordID:   uuid.New().String(),
+		TenantID:   tenantID,
+		CustomerID: customerID,
+		Action:     action,
+		Source:     models.AuditSourceAPI,
+		Actor:      auditCtx.Actor,
+		RequestID:  auditCtx.RequestID,
+		OccurredAt: time.Now(),
+	}
+}
+
+// appendAPIAuditRecord appends the record of an API call, rejected calls are recorded with their error.
+// Failing to record does not fail the call, the workflow records the mutations it applies on its own.
+func appendAPIAuditRecord(ctx context.Context, record *models.AuditRecord, callErr error) {
+	if callErr != nil {
+		record.Error = callErr.Error()
+	}
+	if err := audit.AppendRecord(ctx, record); err != nil {
+		rlog.Error("failed to append audit record",
+			"record_id", record.RecordID,
+			"action", record.Action,
+			"customer_id", record.CustomerID,
+			"error", err,
+		)
+	}
+}
@@ -0,0 +1,39 @@
+// Configuration of the bills service, see Config in bills-config.go.
+// Secrets of the Temporal connection are set with `encore secret set`.
+
+Temporal: {
+	HostPort:      string | *"127.0.0.1:7233"
+	Namespace:     string | *"default"
+	TLS:           bool | *false
+	TLSServerName: string | *""
+}
+
+Worker: {
+	// Deploy one environment with RunWorker enabled to run billing periods apart from the API
+	RunWorker: bool | *true
+
+	MaxConcurrentWorkflowTasks:   int | *0
+	MaxConcurrentActivities:      int | *0
+	MaxConcurrentWorkflowPollers: int | *0
+	MaxConcurrentActivityPollers: int | *0
+	StopTimeoutSeconds:           int | *30
+	TenantQueueRefreshSeconds:    int | *60
+}
+
+GRPC: {
+	// Serves bills.v1.BillsService next to the REST API, enable it in the environments gRPC callers use
+	Enabled: bool | *false
+	Addr:    string | *":9090"
+}
+
+if #Meta.Environment.Cloud == "local" {
+	GRPC: Enabled: true
+}
+
+Tracing: {
+	// Run a collector such as Jaeger locally with its OTLP gRPC receiver on 4317 to look at traces
+	Enabled:     bool | *false
+	Endpoint:    string | *"localhost:4317"
+	Insecure:    bool | *true
+	SampleRatio: float | *1.0
+}
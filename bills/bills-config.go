@@ -0,0 +1,78 @@
+package bills
+
+import (
+	"time"
+
+	"encore.dev/config"
+	"go.temporal.io/sdk/worker"
+)
+
+// Config is the per-environment configuration of the bills service, set in bills-config.cue
+type Config struct {
+	Temporal TemporalConfig
+	Worker   WorkerConfig
+	GRPC     GRPCConfig
+	Tracing  TracingConfig
+}
+
+// TemporalConfig locates the Temporal frontend, credentials are secrets. It converts to temporalconn.Config.
+type TemporalConfig struct {
+	HostPort  string
+	Namespace string
+
+	// TLS is implied by a client certificate or an API key, set it to use TLS with neither
+	TLS           bool
+	TLSServerName string // Overrides the server name verified, empty verifies the host of HostPort
+}
+
+// WorkerConfig tunes the worker running billing periods, zero values keep the SDK defaults
+type WorkerConfig struct {
+	// RunWorker runs billing periods in this process, disable it where only the API is served and
+	// enable it in the environment running the worker
+	RunWorker bool
+
+	MaxConcurrentWorkflowTasks   int
+	MaxConcurrentActivities      int
+	MaxConcurrentWorkflowPollers int
+	MaxConcurrentActivityPollers int
+	StopTimeoutSeconds           int
+	TenantQueueRefreshSeconds    int // How often workers are started for tenants created elsewhere
+}
+
+// GRPCConfig controls the gRPC API, served on a listener of its own beside the REST API
+type GRPCConfig struct {
+	Enabled bool   // Starts the listener in this process
+	Addr    string // host:port the listener binds
+}
+
+// TracingConfig exports OpenTelemetry traces of API calls, billing periods and activities to an OTLP collector
+type TracingConfig struct {
+	Enabled     bool
+	Endpoint    string  // host:port of the collector's OTLP gRPC receiver
+	Insecure    bool    // Plaintext, for a collector running next to the service
+	SampleRatio float64 // Share of new traces sampled, zero samples every trace
+}
+
+var cfg = config.Load[*Config]()
+
+var secrets struct {
+	TemporalAPIKey     string // Sent as a bearer token, as Temporal Cloud expects
+	TemporalClientCert string // PEM certificate presented for mTLS
+	TemporalClientKey  string // PEM key of TemporalClientCert
+	TemporalCACert     string // PEM bundle verifying the server, the system roots are used when unset
+}
+
+// workerOptions builds the options of the billing period workers from the configuration
+func workerOptions(w WorkerConfig) worker.Options {
+	options := worker.Options{
+		MaxConcurrentWorkflowTaskExecutionSize: w.MaxConcurrentWorkflowTasks,
+		MaxConcurrentActivityExecutionSize:     w.MaxConcurrentActivities,
+		MaxConcurrentWorkflowTaskPollers:       w.MaxConcurrentWorkflowPollers,
+		MaxConcurrentActivityTaskPollers:       w.MaxConcurrentActivityPollers,
+		WorkerStopTimeout:                      time.Duration(w.StopTimeoutSeconds) * time.Second,
+	}
+	if options.WorkerStopTimeout == 0 {
+		options.WorkerStopTimeout = 30 * time.Second
+	}
+	return options
+}
@@ -0,0 +1,29 @@
+package bills
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"go.temporal.io/sdk/client"
+)
+
+func TestWorkerOptions(t *testing.T) {
+	options := workerOptions(WorkerConfig{MaxConcurrentActivities: 50, MaxConcurrentWorkflowPollers: 4})
+	assert.Equal(t, 50, options.MaxConcurrentActivityExecutionSize)
+	assert.Equal(t, 4, options.MaxConcurrentWorkflowTaskPollers)
+	assert.Equal(t, 0, options.MaxConcurrentWorkflowTaskExecutionSize, "zero keeps the SDK default")
+	assert.Equal(t, 30*time.Second, options.WorkerStopTimeout)
+
+	options = workerOptions(WorkerConfig{StopTimeoutSeconds: 5})
+	assert.Equal(t, 5*time.Second, options.WorkerStopTimeout)
+}
+
+func TestSetupTracing(t *testing.T) {
+	options := client.Options{}
+	stop, err := setupTracing(TracingConfig{Endpoint: "localhost:4317"}, &options)
+	assert.NoError(t, err)
+	assert.Empty(t, options.Interceptors, "disabled tracing adds no interceptor")
+	assert.NoError(t, stop(context.Background()))
+}
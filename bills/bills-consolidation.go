@@ -0,0 +1,153 @@
+package bills
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"encore.dev/beta/errs"
+	"encore.dev/rlog"
+
+	"encore.app/customers"
+	"encore.app/models"
+)
+
+// consolidationTimeout bounds how long CloseBillingPeriod waits for the parent's workflow to consolidate the period
+const consolidationTimeout = 30 * time.Second
+
+// finalizeChildAccounts closes the active billing periods of every account below the parent in the customer
+// hierarchy, except those already closed. Child accounts accrue line items in their own BillWorkflow until
+// this point. Accounts are closed deepest first, each handed the accounts closed below it, so parent accounts
+// further down the hierarchy get their own consolidated bill. When since is set, accounts without an active
+// period contribute the periods they archived since then.
+func finalizeChildAccounts(ctx context.Context, tenantID string, parentCustomerId string, closed []*models.ChildBills, since time.Time) ([]*models.ChildBills, error) {
+	descendants, err := listDescendantCustomers(ctx, tenantID, parentCustomerId)
+	if err != nil {
+		return nil, err
+	}
+
+	alreadyClosed := make(map[string]bool, len(closed))
+	for _, account := range closed {
+		alreadyClosed[account.CustomerID] = true
+	}
+	finalized := make(map[string][]*models.ChildBills, len(descendants))
+	below := make(map[string][]*models.ChildBills, len(descendants))
+	// Descendants are listed breadth first, walking them backwards reaches every account after those below it
+	for i := len(descendants) - 1; i >= 0; i-- {
+		child := descendants[i]
+		if !alreadyClosed[child.CustomerID] {
+			finalized[child.CustomerID], err = finalizeChildAccount(ctx, tenantID, parentCustomerId, child.CustomerID, below[child.CustomerID], since)
+			if err != nil {
+				return nil, err
+			}
+		}
+		below[child.ParentCustomerID] = append(below[child.ParentCustomerID], finalized[child.CustomerID]...)
+		below[child.ParentCustomerID] = append(below[child.ParentCustomerID], below[child.CustomerID]...)
+	}
+
+	childAccounts := make([]*models.ChildBills, 0, len(descendants))
+	for _, child := range descendants {
+		childAccounts = append(childAccounts, finalized[child.CustomerID]...)
+	}
+	return childAccounts, nil
+}
+
+// finalizeChildAccount closes the active billing period of a child account. A period that has ended by its own
+// timer in the meantime is read back from its workflow, which still answers queries.
+func finalizeChildAccount(ctx context.Context, tenantID string, parentCustomerId string, customerId string, below []*models.ChildBills, since time.Time) ([]*models.ChildBills, error) {
+	workflowId, active := service.GetWorkflowIDForCustomer(tenantID, customerId)
+	if !active {
+		rlog.Info("child account has no active billing period",
+			"parent_customer_id", parentCustomerId,
+			"customer_id", customerId,
+		)
+		if since.IsZero() {
+			return nil, nil
+		}
+		return listArchivedPeriods(ctx, tenantID, customerId, since)
+	}
+
+	_, bills, err := finalizeBillingPeriod(ctx, tenantID, customerId, below)
+	if errs.Code(err) == errs.NotFound {
+		bills, err = queryClosedBills(ctx, tenantID, workflowId)
+	}
+	if err != nil {
+		return nil, errs.Wrap(err, fmt.Sprintf("failed to close billing period of child account %s", customerId))
+	}
+	return []*models.ChildBills{{
+		CustomerID: customerId,
+		WorkflowID: workflowId,
+		Bills:      bills,
+	}}, nil
+}
+
+// consolidateBillingPeriod rolls the parent's and its child accounts' bills up into one bill in the parent's currency
+func consolidateBillingPeriod(ctx context.Context, tenantID string, parentCustomerId string, parentWorkflowId string, parentBills []*models.Bill, childAccounts []*models.ChildBills, generatedAt time.Time) (*models.ConsolidatedBill, error) {
+	parent, err := customers.FindCustomer(ctx, tenantID, parentCustomerId)
+	if err != nil {
+		return nil, errs.Wrap(err, "failed to get parent customer")
+	}
+
+	accounts := append([]*models.ChildBills{{
+		CustomerID: parentCustomerId,
+		WorkflowID: parentWorkflowId,
+		Bills:      parentBills,
+	}}, childAccounts...)
+	consolidated := models.ConsolidateBills(parentCustomerId, parent.Customer.DefaultCurrency, accounts, generatedAt)
+	consolidated.WorkflowID = parentWorkflowId
+
+	rlog.Info("generated consolidated bill",
+		"parent_customer_id", parentCustomerId,
+		"child_accounts", len(childAccounts),
+		"total_amount", consolidated.TotalAmount,
+		"currency", consolidated.Currency,
+	)
+	return consolidated, nil
+}
+
+// awaitConsolidatedBill waits for the parent's workflow to consolidate and archive the billing period it was
+// asked to close, then returns the consolidated bill. It returns nil when the workflow does not finish in time,
+// the bill is listed by ListConsolidatedBills once it is archived.
+func awaitConsolidatedBill(ctx context.Context, tenantID string, workflowId string) *models.ConsolidatedBill {
+	temporalClient, err := service.temporal()
+	if err != nil {
+		rlog.Warn("billing period not consolidated yet", "workflow_id", workflowId, "error", err)
+		return nil
+	}
+	waitCtx, cancel := context.WithTimeout(ctx, consolidationTimeout)
+	defer cancel()
+	if err := temporalClient.GetWorkflow(waitCtx, workflowId, "").Get(waitCtx, nil); err != nil {
+		rlog.Warn("billing period not consolidated yet", "workflow_id", workflowId, "error", err)
+		return nil
+	}
+	consolidated, err := findConsolidatedBill(ctx, tenantID, workflowId)
+	if err != nil {
+		rlog.Warn("consolidated bill not found", "workflow_id", workflowId, "error", err)
+		return nil
+	}
+	return consolidated
+}
+
+// listDescendantCustomers walks the tenant's customer hierarchy breadth first below the given customer
+func listDescendantCustomers(ctx context.Context, tenantID string, customerId string) ([]*models.CustomerProfile, error) {
+	descendants := make([]*models.CustomerProfile, 0)
+	visited := map[string]bool{customerId: true}
+	queue := []string{customerId}
+	for len(queue) > 0 {
+		current := queue[0]
+		queue = queue[1:]
+		children, err := customers.FindChildCustomers(ctx, tenantID, current)
+		if err != nil {
+			return nil, errs.Wrap(err, fmt.Sprintf("failed to list child accounts of %s", current))
+		}
+		for _, child := range children.Customers {
+			if visited[child.CustomerID] {
+				continue
+			}
+			visited[child.CustomerID] = true
+			descendants = append(descendants, child)
+			queue = append(queue, child.CustomerID)
+		}
+	}
+	return descendants, nil
+}
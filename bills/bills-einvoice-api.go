@@ -0,0 +1,82 @@
+package bills
+
+import (
+	"context"
+	"errors"
+
+	"encore.dev/beta/errs"
+	"encore.dev/rlog"
+
+	"encore.app/customers"
+	"encore.app/einvoice"
+	"encore.app/models"
+)
+
+//encore:api auth method=POST path=/bills/legalEntity
+func SetLegalEntity(ctx context.Context, req *models.LegalEntityProfile) error {
+	// The seller profile is shared by every customer of the tenant
+	tenantID, err := authorizeCustomer("", models.ScopeAdmin)
+	if err != nil {
+		return err
+	}
+	if err := validateLegalEntityProfile(req); err != nil {
+		return models.InvalidRequest(err)
+	}
+	// Tenants invoice as their own legal entity, with their own VAT rate
+	if err := upsertLegalEntity(ctx, tenantID, req); err != nil {
+		return err
+	}
+	rlog.Info("updated seller legal entity", "tenant_id", tenantID, "legal_name", req.LegalName)
+	return nil
+}
+
+// FindLegalEntity returns the seller legal entity the tenant invoices as
+//
+//encore:api private method=GET path=/bills/legalEntity/:tenantId
+func FindLegalEntity(ctx context.Context, tenantId string) (*models.LegalEntityResponse, error) {
+	seller, err := findLegalEntity(ctx, tenantId)
+	if errors.Is(err, errLegalEntityNotFound) {
+		return &models.LegalEntityResponse{}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &models.LegalEntityResponse{LegalEntity: seller}, nil
+}
+
+//encore:api auth method=GET path=/bills/einvoice/:customerId/:billId
+func GetEInvoice(ctx context.Context, customerId string, billId string) (*models.GetEInvoiceResponse, error) {
+	tenantID, err := authorizeCustomer(customerId, models.ScopeRead)
+	if err != nil {
+		return nil, err
+	}
+	seller, err := findLegalEntity(ctx, tenantID)
+	if errors.Is(err, errLegalEntityNotFound) {
+		return nil, models.NewAPIError(errs.FailedPrecondition, models.ReasonSellerNotConfigured, "seller legal entity is not configured for tenant %s", tenantID)
+	}
+	if err != nil {
+		return nil, err
+	}
+	buyer, err := customers.FindCustomer(ctx, tenantID, customerId)
+	if err != nil {
+		return nil, errs.Wrap(err, "customer profile not found")
+	}
+	bill, err := findBill(ctx, tenantID, customerId, billId)
+	if err != nil {
+		return nil, err
+	}
+
+	document, documentType, err := einvoice.BuildDocument(bill, seller, buyer.Customer)
+	if err != nil {
+		return nil, models.NewAPIErrorWithCause(errs.FailedPrecondition, models.ReasonInvoiceIncomplete, err, "failed to build e-invoice")
+	}
+	if err := einvoice.Validate(document); err != nil {
+		return nil, models.NewAPIErrorWithCause(errs.Internal, models.ReasonInternal, err, "generated e-invoice is invalid")
+	}
+
+	return &models.GetEInvoiceResponse{
+		BillID:       bill.ID,
+		DocumentType: documentType,
+		XML:          string(document),
+	}, nil
+}
@@ -0,0 +1,39 @@
+package bills
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"encore.dev/storage/sqldb"
+)
+
+// The bills database keeps what bill workflows have published so activity retries stay idempotent
+var db = sqldb.NewDatabase("bills", sqldb.DatabaseConfig{
+	Migrations: "./migrations",
+})
+
+func isEventPublished(ctx context.Context, eventID string) (bool, error) {
+	var messageID string
+	err := db.QueryRow(ctx, `SELECT message_id FROM published_events WHERE event_id = $1`, eventID).Scan(&messageID)
+	if errors.Is(err, sqldb.ErrNoRows) {
+		return false, nil
+	}
+	if err != nil {
+		return false, fmt.Errorf("failed to check published event: %w", err)
+	}
+	return true, nil
+}
+
+func markEventPublished(ctx context.Context, eventID string, topic string, messageID string) error {
+	_, err := db.Exec(ctx, `
+		INSERT INTO published_events (event_id, topic, message_id, published_at)
+		VALUES ($1, $2, $3, $4)
+		ON CONFLICT (event_id) DO NOTHING
+	`, eventID, topic, messageID, time.Now())
+	if err != nil {
+		return fmt.Errorf("failed to record published event: %w", err)
+	}
+	return nil
+}
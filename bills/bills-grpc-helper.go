@@ -0,0 +1,112 @@
+package bills
+
+import (
+	"errors"
+	"time"
+
+	"encore.dev/beta/errs"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/timestamppb"
+
+	"encore.app/models"
+	billsv1 "encore.app/proto/billsv1"
+)
+
+// grpcErrorDomain scopes the reasons in the ErrorInfo details of gRPC errors
+const grpcErrorDomain = "bills.v1"
+
+// grpcError returns an API error as a gRPC status with the same code, Encore numbers its codes like gRPC.
+// The machine-readable reason travels as ErrorInfo details.
+func grpcError(err error) error {
+	var apiErr *errs.Error
+	if !errors.As(err, &apiErr) {
+		return status.Error(codes.Internal, err.Error())
+	}
+	st := status.New(codes.Code(apiErr.Code), apiErr.Message)
+	details, ok := apiErr.Details.(models.ErrorResponse)
+	if !ok || details.Code == "" {
+		return st.Err()
+	}
+	withDetails, detailsErr := st.WithDetails(&errdetails.ErrorInfo{Reason: details.Code, Domain: grpcErrorDomain})
+	if detailsErr != nil {
+		return st.Err()
+	}
+	return withDetails.Err()
+}
+
+// timeToProto leaves unset times, such as the closing time of open bills, unset
+func timeToProto(t time.Time) *timestamppb.Timestamp {
+	if t.IsZero() {
+		return nil
+	}
+	return timestamppb.New(t)
+}
+
+func timeFromProto(ts *timestamppb.Timestamp) *time.Time {
+	if ts == nil {
+		return nil
+	}
+	t := ts.AsTime()
+	return &t
+}
+
+func lineItemToProto(item *models.LineItem) *billsv1.LineItem {
+	if item == nil {
+		return nil
+	}
+	return &billsv1.LineItem{
+		Id:          item.ID,
+		Description: item.Description,
+		Amount:      item.Amount,
+		Quantity:    int32(item.Quantity),
+		AddedAt:     timeToProto(item.AddedAt),
+		Product:     item.Product,
+	}
+}
+
+func billToProto(bill *models.Bill) *billsv1.Bill {
+	if bill == nil {
+		return nil
+	}
+	lineItems := make([]*billsv1.LineItem, 0, len(bill.LineItems))
+	for _, item := range bill.LineItems {
+		lineItems = append(lineItems, lineItemToProto(item))
+	}
+	return &billsv1.Bill{
+		Id:          bill.ID,
+		Status:      string(bill.Status),
+		Currency:    string(bill.Currency),
+		TotalAmount: bill.TotalAmount,
+		LineItems:   lineItems,
+		CreatedAt:   timeToProto(bill.CreatedAt),
+		ClosedAt:    timeToProto(bill.ClosedAt),
+		CloseReason: bill.CloseReason,
+		WorkflowId:  bill.WorkflowID,
+	}
+}
+
+func billsToProto(bills []*models.Bill) []*billsv1.Bill {
+	result := make([]*billsv1.Bill, 0, len(bills))
+	for _, bill := range bills {
+		result = append(result, billToProto(bill))
+	}
+	return result
+}
+
+func listBillsRequestFromProto(req *billsv1.ListBillsRequest) *models.ListBillsRequest {
+	return &models.ListBillsRequest{
+		Status:      req.GetStatus(),
+		Currency:    models.Currency(req.GetCurrency()),
+		CreatedFrom: timeFromProto(req.GetCreatedFrom()),
+		CreatedTo:   timeFromProto(req.GetCreatedTo()),
+		ClosedFrom:  timeFromProto(req.GetClosedFrom()),
+		ClosedTo:    timeFromProto(req.GetClosedTo()),
+		MinTotal:    req.MinTotal,
+		MaxTotal:    req.MaxTotal,
+		Sort:        models.BillSort(req.GetSort()),
+		PageSize:    int(req.GetPageSize()),
+		Cursor:      req.GetCursor(),
+	}
+}
@@ -0,0 +1,112 @@
+package bills
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"encore.dev/beta/errs"
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/timestamppb"
+
+	"encore.app/models"
+	billsv1 "encore.app/proto/billsv1"
+)
+
+func TestGRPCError(t *testing.T) {
+	t.Run("keeps the code and reason", func(t *testing.T) {
+		st, ok := status.FromError(grpcError(billingPeriodNotFound("customer-1")))
+		assert.True(t, ok)
+		assert.Equal(t, codes.NotFound, st.Code())
+		assert.Contains(t, st.Message(), "customer-1")
+		if assert.Len(t, st.Details(), 1) {
+			info, ok := st.Details()[0].(*errdetails.ErrorInfo)
+			assert.True(t, ok)
+			assert.Equal(t, string(models.ReasonBillingPeriodNotFound), info.Reason)
+			assert.Equal(t, grpcErrorDomain, info.Domain)
+		}
+	})
+
+	t.Run("errors without a reason", func(t *testing.T) {
+		st, _ := status.FromError(grpcError(&errs.Error{Code: errs.PermissionDenied, Message: "denied"}))
+		assert.Equal(t, codes.PermissionDenied, st.Code())
+		assert.Empty(t, st.Details())
+	})
+
+	t.Run("other errors are internal", func(t *testing.T) {
+		st, _ := status.FromError(grpcError(errors.New("boom")))
+		assert.Equal(t, codes.Internal, st.Code())
+		assert.Equal(t, "boom", st.Message())
+	})
+}
+
+func TestBearerToken(t *testing.T) {
+	key, found := bearerToken([]string{"Bearer bk_123"})
+	assert.True(t, found)
+	assert.Equal(t, "bk_123", key)
+
+	key, found = bearerToken([]string{"Basic abc", "bearer bk_456"})
+	assert.True(t, found)
+	assert.Equal(t, "bk_456", key)
+
+	_, found = bearerToken([]string{"Bearer "})
+	assert.False(t, found)
+	_, found = bearerToken(nil)
+	assert.False(t, found)
+}
+
+func TestBillToProto(t *testing.T) {
+	createdAt := time.Date(2025, 3, 1, 10, 0, 0, 0, time.UTC)
+	bill := &models.Bill{
+		ID:          "bill-1",
+		Status:      models.StatusOpen,
+		Currency:    models.GEL,
+		TotalAmount: 12.5,
+		CreatedAt:   createdAt,
+		WorkflowID:  "workflow-1",
+		LineItems: []*models.LineItem{
+			{ID: "item-1", Description: "seats", Amount: 2.5, Quantity: 5, AddedAt: createdAt, Product: "seats"},
+		},
+	}
+	pb := billToProto(bill)
+	assert.Equal(t, "bill-1", pb.Id)
+	assert.Equal(t, "OPEN", pb.Status)
+	assert.Equal(t, "GEL", pb.Currency)
+	assert.Equal(t, 12.5, pb.TotalAmount)
+	assert.Equal(t, createdAt, pb.CreatedAt.AsTime())
+	assert.Nil(t, pb.ClosedAt, "open bills have no closing time")
+	if assert.Len(t, pb.LineItems, 1) {
+		assert.Equal(t, int32(5), pb.LineItems[0].Quantity)
+		assert.Equal(t, "seats", pb.LineItems[0].Product)
+	}
+	assert.Nil(t, billToProto(nil))
+}
+
+func TestListBillsRequestFromProto(t *testing.T) {
+	from := time.Date(2025, 3, 1, 0, 0, 0, 0, time.UTC)
+	minTotal := 10.0
+	req := listBillsRequestFromProto(&billsv1.ListBillsRequest{
+		CustomerId:  "customer-1",
+		Status:      "CLOSED",
+		Currency:    "USD",
+		CreatedFrom: timestamppb.New(from),
+		MinTotal:    &minTotal,
+		Sort:        "-total_amount",
+		PageSize:    25,
+		Cursor:      "next",
+	})
+	assert.Equal(t, "CLOSED", req.Status)
+	assert.Equal(t, models.USD, req.Currency)
+	if assert.NotNil(t, req.CreatedFrom) {
+		assert.Equal(t, from, *req.CreatedFrom)
+	}
+	assert.Nil(t, req.CreatedTo)
+	assert.Equal(t, &minTotal, req.MinTotal)
+	assert.Nil(t, req.MaxTotal)
+	assert.Equal(t, models.BillSortTotalDesc, req.Sort)
+	assert.Equal(t, 25, req.PageSize)
+	assert.Equal(t, "next", req.Cursor)
+}
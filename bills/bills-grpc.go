@@ -0,0 +1,157 @@
+package bills
+
+import (
+	"context"
+	"net"
+	"strings"
+
+	"encore.dev/beta/auth"
+	"encore.dev/beta/errs"
+	"encore.dev/rlog"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+
+	"encore.app/apikeys"
+	"encore.app/models"
+	billsv1 "encore.app/proto/billsv1"
+)
+
+// grpcServer serves BillsService by calling the REST endpoints, so both APIs share their validation,
+// authorization, audit records and Temporal client
+type grpcServer struct {
+	billsv1.UnimplementedBillsServiceServer
+}
+
+// newGRPCServer returns a gRPC server with BillsService registered behind API key authentication
+func newGRPCServer() *grpc.Server {
+	server := grpc.NewServer(grpc.UnaryInterceptor(grpcAuthInterceptor))
+	billsv1.RegisterBillsServiceServer(server, &grpcServer{})
+	return server
+}
+
+// startGRPCServer serves the gRPC API on the address until the service shuts down, the REST API keeps
+// working when the address is taken
+func startGRPCServer(addr string) *grpc.Server {
+	listener, err := net.Listen("tcp", addr)
+	if err != nil {
+		rlog.Warn("failed to listen for gRPC, the gRPC API is unavailable", "addr", addr, "error", err)
+		return nil
+	}
+	server := newGRPCServer()
+	go func() {
+		if err := server.Serve(listener); err != nil {
+			rlog.Error("gRPC server stopped", "error", err)
+		}
+	}()
+	rlog.Info("serving gRPC API", "addr", listener.Addr().String())
+	return server
+}
+
+// grpcAuthInterceptor resolves the API key in the authorization metadata and calls the endpoint as that key,
+// errors of every call are returned as gRPC statuses
+func grpcAuthInterceptor(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+	md, _ := metadata.FromIncomingContext(ctx)
+	key, found := bearerToken(md.Get("authorization"))
+	if !found {
+		return nil, grpcError(&errs.Error{Code: errs.Unauthenticated, Message: "missing API key"})
+	}
+	data, err := apikeys.VerifyAPIKey(ctx, &models.VerifyAPIKeyRequest{Key: key})
+	if err != nil {
+		return nil, grpcError(err)
+	}
+	resp, err := handler(auth.WithContext(ctx, auth.UID(data.KeyID), data), req)
+	if err != nil {
+		return nil, grpcError(err)
+	}
+	return resp, nil
+}
+
+// bearerToken returns the key of the first "Bearer <key>" authorization value
+func bearerToken(values []string) (string, bool) {
+	for _, value := range values {
+		scheme, token, found := strings.Cut(value, " ")
+		if found && strings.EqualFold(scheme, "Bearer") && strings.TrimSpace(token) != "" {
+			return strings.TrimSpace(token), true
+		}
+	}
+	return "", false
+}
+
+func (s *grpcServer) StartBillingPeriod(ctx context.Context, req *billsv1.StartBillingPeriodRequest) (*billsv1.StartBillingPeriodResponse, error) {
+	err := StartBillingPeriod(ctx, &models.StartBillingPeriodRequest{
+		CustomerID:        req.GetCustomerId(),
+		Currency:          models.Currency(req.GetCurrency()),
+		BillingPeriodDays: int(req.GetBillingPeriodDays()),
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &billsv1.StartBillingPeriodResponse{}, nil
+}
+
+func (s *grpcServer) CreateBill(ctx context.Context, req *billsv1.CreateBillRequest) (*billsv1.CreateBillResponse, error) {
+	resp, err := CreateBill(ctx, &models.CreateBillRequest{
+		CustomerID: req.GetCustomerId(),
+		Currency:   req.GetCurrency(),
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &billsv1.CreateBillResponse{BillId: resp.BillID, WorkflowId: resp.WorkflowID}, nil
+}
+
+func (s *grpcServer) AddLineItem(ctx context.Context, req *billsv1.AddLineItemRequest) (*billsv1.AddLineItemResponse, error) {
+	resp, err := AddLineItem(ctx, req.GetCustomerId(), req.GetBillId(), &models.AddLineItemRequest{
+		Description: req.GetDescription(),
+		Amount:      req.GetAmount(),
+		Quantity:    int(req.GetQuantity()),
+		Currency:    req.GetCurrency(),
+		Product:     req.GetProduct(),
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &billsv1.AddLineItemResponse{LineItem: lineItemToProto(resp.LineItem), Bill: billToProto(resp.Bill)}, nil
+}
+
+func (s *grpcServer) CloseBill(ctx context.Context, req *billsv1.CloseBillRequest) (*billsv1.CloseBillResponse, error) {
+	resp, err := CloseBill(ctx, req.GetCustomerId(), req.GetBillId(), &models.CloseBillRequest{Reason: req.GetReason()})
+	if err != nil {
+		return nil, err
+	}
+	return &billsv1.CloseBillResponse{
+		Bill:        billToProto(resp.Bill),
+		TotalAmount: resp.TotalAmount,
+		TotalItems:  int32(resp.TotalItems),
+		ClosedAt:    timeToProto(resp.ClosedAt),
+	}, nil
+}
+
+func (s *grpcServer) GetBill(ctx context.Context, req *billsv1.GetBillRequest) (*billsv1.GetBillResponse, error) {
+	resp, err := GetBill(ctx, req.GetCustomerId(), req.GetBillId())
+	if err != nil {
+		return nil, err
+	}
+	return &billsv1.GetBillResponse{Bill: billToProto(&resp.Bill)}, nil
+}
+
+func (s *grpcServer) ListBills(ctx context.Context, req *billsv1.ListBillsRequest) (*billsv1.ListBillsResponse, error) {
+	resp, err := ListBills(ctx, req.GetCustomerId(), listBillsRequestFromProto(req))
+	if err != nil {
+		return nil, err
+	}
+	return &billsv1.ListBillsResponse{Bills: billsToProto(resp.Bills), Total: resp.Total, NextCursor: resp.NextCursor}, nil
+}
+
+func (s *grpcServer) CloseBillingPeriod(ctx context.Context, req *billsv1.CloseBillingPeriodRequest) (*billsv1.CloseBillingPeriodResponse, error) {
+	resp, err := CloseBillingPeriod(ctx, req.GetCustomerId())
+	if err != nil {
+		return nil, err
+	}
+	return &billsv1.CloseBillingPeriodResponse{
+		WorkflowId:     resp.WorkflowID,
+		Bills:          billsToProto(resp.Bills),
+		FinalAmountUsd: resp.FinalAmountUSD,
+		FinalAmountGel: resp.FinalAmountGEL,
+	}, nil
+}
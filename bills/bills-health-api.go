@@ -0,0 +1,129 @@
+package bills
+
+import (
+	"context"
+	"fmt"
+	"runtime/debug"
+	"strings"
+	"time"
+
+	"encore.dev"
+	"encore.dev/beta/errs"
+	"encore.dev/rlog"
+	enumspb "go.temporal.io/api/enums/v1"
+	taskqueuepb "go.temporal.io/api/taskqueue/v1"
+	"go.temporal.io/api/workflowservice/v1"
+	"go.temporal.io/sdk/client"
+
+	"encore.app/models"
+)
+
+// readinessTimeout bounds the Temporal calls of a readiness probe, probes time out on their own soon after
+const readinessTimeout = 5 * time.Second
+
+// Liveness reports that the process is up without calling Temporal, so an outage never restarts the pods
+//
+//encore:api public method=GET path=/bills/health/live
+func Liveness(ctx context.Context) (*models.LivenessResponse, error) {
+	return &models.LivenessResponse{
+		Status:        "alive",
+		Service:       "bills",
+		StartedAt:     service.startedAt,
+		UptimeSeconds: int64(time.Since(service.startedAt).Seconds()),
+		Build:         currentBuildInfo(),
+	}, nil
+}
+
+// Readiness reports whether the instance can serve billing operations: Temporal must be reachable and, where
+// the worker runs, the default tenant's worker must be polling. A not ready instance answers Unavailable with
+// the diagnostics in the details. Poller counts, backlogs and the number of running billing periods are
+// reported for every task queue this instance works on.
+//
+//encore:api public method=GET path=/bills/health/ready
+func Readiness(ctx context.Context) (*models.ReadinessResponse, error) {
+	ctx, cancel := context.WithTimeout(ctx, readinessTimeout)
+	defer cancel()
+	resp := &models.ReadinessResponse{
+		Status:               models.StatusReady,
+		Service:              "bills",
+		Namespace:            service.namespace,
+		Temporal:             "connected",
+		TaskQueues:           []*models.TaskQueueHealth{},
+		ActiveBillingPeriods: -1,
+		Build:                currentBuildInfo(),
+	}
+	temporalClient, err := service.temporal()
+	if err == nil {
+		_, err = temporalClient.CheckHealth(ctx, &client.CheckHealthRequest{})
+	}
+	if err != nil {
+		resp.Temporal = err.Error()
+		resp.AddProblem("Temporal is unreachable")
+		return nil, notReady(resp)
+	}
+
+	local := service.workerTaskQueues()
+	if cfg.Worker.RunWorker && !local[billsTaskQueue] {
+		resp.AddProblem(fmt.Sprintf("the worker of task queue %s is not running", billsTaskQueue))
+	}
+	taskQueues := []string{billsTaskQueue}
+	for taskQueue := range local {
+		if taskQueue != billsTaskQueue {
+			taskQueues = append(taskQueues, taskQueue)
+		}
+	}
+	for _, taskQueue := range taskQueues {
+		resp.TaskQueues = append(resp.TaskQueues, describeTaskQueue(ctx, temporalClient, taskQueue, local[taskQueue]))
+	}
+
+	count, err := temporalClient.CountWorkflow(ctx, &workflowservice.CountWorkflowExecutionsRequest{Query: activeBillingPeriodsQuery})
+	if err != nil {
+		rlog.Warn("failed to count active billing periods", "error", err)
+	} else {
+		resp.ActiveBillingPeriods = count.GetCount()
+	}
+	if resp.Status != models.StatusReady {
+		return nil, notReady(resp)
+	}
+	return resp, nil
+}
+
+// describeTaskQueue reports the pollers and backlog of the task queue, a failure is recorded on the queue
+// without failing readiness
+func describeTaskQueue(ctx context.Context, temporalClient client.Client, taskQueue string, localWorker bool) *models.TaskQueueHealth {
+	resp, err := temporalClient.WorkflowService().DescribeTaskQueue(ctx, &workflowservice.DescribeTaskQueueRequest{
+		Namespace:      service.namespace,
+		TaskQueue:      &taskqueuepb.TaskQueue{Name: taskQueue, Kind: enumspb.TASK_QUEUE_KIND_NORMAL},
+		ApiMode:        enumspb.DESCRIBE_TASK_QUEUE_MODE_ENHANCED,
+		TaskQueueTypes: []enumspb.TaskQueueType{enumspb.TASK_QUEUE_TYPE_WORKFLOW, enumspb.TASK_QUEUE_TYPE_ACTIVITY},
+		ReportStats:    true,
+		ReportPollers:  true,
+	})
+	if err != nil {
+		return &models.TaskQueueHealth{TaskQueue: taskQueue, LocalWorker: localWorker, Error: err.Error()}
+	}
+	return newTaskQueueHealth(taskQueue, localWorker, resp)
+}
+
+// notReady returns the readiness diagnostics as an Unavailable error, so probes fail on the status code
+func notReady(resp *models.ReadinessResponse) error {
+	return &errs.Error{
+		Code:    errs.Unavailable,
+		Message: "bills is not ready: " + strings.Join(resp.Problems, "; "),
+		Details: *resp,
+	}
+}
+
+// currentBuildInfo identifies the running build, preferring the revision Encore recorded over the Go toolchain's
+func currentBuildInfo() models.BuildInfo {
+	info, _ := debug.ReadBuildInfo()
+	build := models.NewBuildInfo(info)
+	meta := encore.Meta()
+	if meta.Build.Revision != "" {
+		build.Version = meta.Build.Revision
+		build.Modified = meta.Build.UncommittedChanges
+	}
+	build.Environment = meta.Environment.Name
+	build.DeployID = meta.Deploy.ID
+	return build
+}
@@ -0,0 +1,50 @@
+package bills
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"encore.dev/storage/sqldb"
+
+	"encore.app/models"
+)
+
+// errLegalEntityNotFound is returned when the tenant has not configured its seller legal entity
+var errLegalEntityNotFound = errors.New("legal entity not found")
+
+// upsertLegalEntity stores the seller legal entity of the tenant, replacing the previous one
+func upsertLegalEntity(ctx context.Context, tenantID string, profile *models.LegalEntityProfile) error {
+	document, err := json.Marshal(profile)
+	if err != nil {
+		return fmt.Errorf("failed to encode legal entity: %w", err)
+	}
+	_, err = db.Exec(ctx, `
+		INSERT INTO legal_entities (tenant_id, profile, updated_at)
+		VALUES ($1, $2, $3)
+		ON CONFLICT (tenant_id) DO UPDATE SET profile = EXCLUDED.profile, updated_at = EXCLUDED.updated_at
+	`, tenantID, document, time.Now())
+	if err != nil {
+		return fmt.Errorf("failed to store legal entity: %w", err)
+	}
+	return nil
+}
+
+// findLegalEntity returns the seller legal entity of the tenant
+func findLegalEntity(ctx context.Context, tenantID string) (*models.LegalEntityProfile, error) {
+	var document []byte
+	err := db.QueryRow(ctx, `SELECT profile FROM legal_entities WHERE tenant_id = $1`, tenantID).Scan(&document)
+	if errors.Is(err, sqldb.ErrNoRows) {
+		return nil, errLegalEntityNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to load legal entity: %w", err)
+	}
+	var profile models.LegalEntityProfile
+	if err := json.Unmarshal(document, &profile); err != nil {
+		return nil, fmt.Errorf("failed to decode legal entity: %w", err)
+	}
+	return &profile, nil
+}
@@ -0,0 +1,54 @@
+package bills
+
+import (
+	"net/http"
+	"time"
+
+	"encore.dev/beta/auth"
+	"encore.dev/beta/errs"
+	"encore.dev/middleware"
+
+	"encore.app/metrics"
+	"encore.app/models"
+)
+
+// Metrics of the API calls of every service, recorded by RecordAPIMetrics
+const (
+	apiRequestDurationMetric = "api_request_duration_seconds"
+	apiRequestErrorsMetric   = "api_request_errors_total"
+)
+
+// Metrics serves the metrics of the process in the Prometheus text format: API latency and errors per
+// endpoint, the billing metrics recorded by the bill workflow and the Temporal SDK metrics of the clients
+// and workers. The series span every tenant, so only the metrics scrape token and the operator key may read them.
+//
+//encore:api auth raw method=GET path=/metrics
+func Metrics(w http.ResponseWriter, req *http.Request) {
+	data, _ := auth.Data().(*models.AuthData)
+	if data == nil {
+		errs.HTTPError(w, &errs.Error{Code: errs.Unauthenticated, Message: "missing API key"})
+		return
+	}
+	if !data.CanReadMetrics() {
+		errs.HTTPError(w, &errs.Error{Code: errs.PermissionDenied, Message: "only the metrics scrape token may read the metrics"})
+		return
+	}
+	metrics.Default.Handler().ServeHTTP(w, req)
+}
+
+// RecordAPIMetrics times every API call and counts the failed ones by error code. Raw endpoints, such as the
+// bill streams, are timed until they return.
+//
+//encore:middleware global target=all
+func RecordAPIMetrics(req middleware.Request, next middleware.Next) middleware.Response {
+	started := time.Now()
+	resp := next(req)
+	data := req.Data()
+	labels := map[string]string{"service": data.Service, "endpoint": data.Endpoint}
+	metrics.Default.Histogram(apiRequestDurationMetric, "Latency of API calls", nil, labels).Observe(time.Since(started).Seconds())
+	if resp.Err != nil {
+		labels["code"] = errs.Code(resp.Err).String()
+		metrics.Default.Counter(apiRequestErrorsMetric, "API calls that returned an error", labels).Inc()
+	}
+	return resp
+}
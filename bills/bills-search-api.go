@@ -0,0 +1,68 @@
+package bills
+
+import (
+	"context"
+	"encoding/base64"
+
+	"encore.dev/beta/errs"
+	"encore.dev/rlog"
+	"go.temporal.io/api/workflowservice/v1"
+
+	"encore.app/models"
+)
+
+// SearchBills finds the tenant's billing periods across all customers by the search attributes the bill
+// workflow keeps up to date. With bill_id the period holding the bill is returned along with the bill.
+//
+//encore:api auth method=GET path=/bills/search
+func SearchBills(ctx context.Context, req *models.SearchBillsRequest) (*models.SearchBillsResponse, error) {
+	tenantID, err := authorizeCustomer(req.CustomerID, models.ScopeRead)
+	if err != nil {
+		return nil, err
+	}
+	if err := validateSearchBillsRequest(req); err != nil {
+		return nil, models.InvalidRequest(err)
+	}
+	pageToken, err := base64.RawURLEncoding.DecodeString(req.PageToken)
+	if err != nil {
+		return nil, models.NewAPIError(errs.InvalidArgument, models.ReasonInvalidRequest, "invalid page_token")
+	}
+	pageSize := req.PageSize
+	if pageSize == 0 {
+		pageSize = models.DefaultSearchPageSize
+	}
+
+	temporalClient, err := service.temporal()
+	if err != nil {
+		return nil, err
+	}
+	resp, err := temporalClient.ListWorkflow(ctx, &workflowservice.ListWorkflowExecutionsRequest{
+		PageSize:      int32(pageSize),
+		NextPageToken: pageToken,
+		Query:         billSearchQuery(tenantID, req),
+	})
+	if err != nil {
+		return nil, temporalError(err, models.ReasonBillingPeriodNotFound, "failed to search billing periods")
+	}
+
+	periods := make([]*models.BillingPeriodSearchResult, 0, len(resp.GetExecutions()))
+	for _, info := range resp.GetExecutions() {
+		period, err := newBillingPeriodSearchResult(info)
+		if err != nil {
+			return nil, models.NewAPIErrorWithCause(errs.Internal, models.ReasonInternal, err, "failed to decode billing period %s", info.GetExecution().GetWorkflowId())
+		}
+		if req.BillID != "" {
+			bill, err := findBill(ctx, tenantID, period.CustomerID, req.BillID)
+			if err != nil {
+				// The period is still listed, the bill can be fetched once the workflow answers again
+				rlog.Warn("failed to get searched bill", "bill_id", req.BillID, "workflow_id", period.WorkflowID, "error", err)
+			}
+			period.Bill = bill
+		}
+		periods = append(periods, period)
+	}
+	return &models.SearchBillsResponse{
+		Periods:       periods,
+		NextPageToken: base64.RawURLEncoding.EncodeToString(resp.GetNextPageToken()),
+	}, nil
+}
@@ -2,27 +2,45 @@ package bills
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"sync"
 	"time"
 
+	"encore.app/constants"
+	"encore.app/models"
+	"encore.app/temporalconn"
+	"encore.app/tenants"
 	"encore.app/workflows"
+	"encore.dev/rlog"
+	enumspb "go.temporal.io/api/enums/v1"
+	"go.temporal.io/api/operatorservice/v1"
+	"go.temporal.io/api/serviceerror"
+	"go.temporal.io/sdk/activity"
 	"go.temporal.io/sdk/client"
 	"go.temporal.io/sdk/worker"
+	"google.golang.org/grpc"
 )
 
 // Service handles bill operations and Temporal workflow management
 //
 //encore:service
 type Service struct {
-	workflows      map[string]string // In-memory storage for demo
-	temporalClient client.Client
-	workers        []worker.Worker
+	workflows   map[string]string // In-memory storage for demo, keyed by models.TenantCustomerKey
+	workflowsMu sync.Mutex
+	connection  *temporalconn.Connection // Read through temporal()
+	namespace   string
+	workers     map[string]worker.Worker // Keyed by task queue, empty unless Worker.RunWorker is set
+	workersMu   sync.Mutex
+	streams     *billStreams  // Update streams open on this instance
+	stopStreams func()        // Stops the listener relaying bill events to the streams
+	grpcServer  *grpc.Server  // Nil unless GRPC.Enabled is set and its address could be bound
+	done        chan struct{} // Closed on shutdown to stop the tenant queue refresh
+	startedAt   time.Time
+	stopTracing func(context.Context) error // Flushes the spans left, a no-op while tracing is disabled
 }
 
-var (
-	billsTaskQueue = "local-bills"
-	localHost      = "127.0.0.1:7233"
-)
+var billsTaskQueue = models.TenantTaskQueue(models.DefaultTenantID)
 
 // Service initialization
 var service *Service
@@ -32,7 +50,8 @@ func init() {
 	var err error
 	service, err = initService()
 	if err != nil {
-		// In production, you might want to handle this differently
+		// Only invalid Temporal credentials or tracing settings fail here, an unreachable Temporal is retried
+		// in the background
 		panic(fmt.Sprintf("Failed to initialize bills service: %v", err))
 	}
 }
@@ -43,57 +62,237 @@ func GetService() *Service {
 	return service
 }
 
-// Initialize the service with Temporal client
+// Initialize the service and connect to Temporal in the background, the API answers Unavailable until the
+// connection is up. The worker of the default tenant only starts where Worker.RunWorker is set.
 func initService() (*Service, error) {
-	// Create Temporal client
-	temporalClient, err := client.Dial(client.Options{
-		HostPort: localHost, // Temporalite default port
+	options, err := temporalconn.ClientOptions(temporalconn.Config(cfg.Temporal), temporalconn.Credentials{
+		APIKey:     secrets.TemporalAPIKey,
+		ClientCert: secrets.TemporalClientCert,
+		ClientKey:  secrets.TemporalClientKey,
+		CACert:     secrets.TemporalCACert,
+	})
+	if err != nil {
+		return nil, err
+	}
+	stopTracing, err := setupTracing(cfg.Tracing, &options)
+	if err != nil {
+		return nil, err
+	}
+	s := &Service{
+		workflows:   make(map[string]string),
+		workers:     make(map[string]worker.Worker),
+		streams:     newBillStreams(),
+		connection:  temporalconn.New(),
+		namespace:   options.Namespace,
+		done:        make(chan struct{}),
+		startedAt:   time.Now(),
+		stopTracing: stopTracing,
+	}
+	streamsCtx, stopStreams := context.WithCancel(context.Background())
+	s.stopStreams = stopStreams
+	go s.streams.listen(streamsCtx)
+	go s.connection.Connect(options, func(temporalClient client.Client) {
+		s.onTemporalConnected(temporalClient, options.Namespace)
+	})
+	if cfg.GRPC.Enabled {
+		s.grpcServer = startGRPCServer(cfg.GRPC.Addr)
+	}
+	return s, nil
+}
+
+// onTemporalConnected prepares the namespace and starts the workers once the client is connected
+func (s *Service) onTemporalConnected(temporalClient client.Client, namespace string) {
+	registerSearchAttributes(temporalClient, namespace)
+	if !cfg.Worker.RunWorker {
+		return
+	}
+	if err := s.ensureWorker(billsTaskQueue); err != nil {
+		rlog.Error("failed to start the default tenant worker", "error", err)
+	}
+	if cfg.Worker.TenantQueueRefreshSeconds > 0 {
+		go s.refreshTenantWorkers(time.Duration(cfg.Worker.TenantQueueRefreshSeconds) * time.Second)
+	}
+}
+
+// registerSearchAttributes adds the search attributes of the bill workflow to the namespace. Attributes that
+// already exist are kept, a namespace managed elsewhere only logs a warning and leaves search unavailable.
+func registerSearchAttributes(temporalClient client.Client, namespace string) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	_, err := temporalClient.OperatorService().AddSearchAttributes(ctx, &operatorservice.AddSearchAttributesRequest{
+		Namespace: namespace,
+		SearchAttributes: map[string]enumspb.IndexedValueType{
+			constants.TenantIDSearchAttribute:      enumspb.INDEXED_VALUE_TYPE_KEYWORD,
+			constants.CustomerIDSearchAttribute:    enumspb.INDEXED_VALUE_TYPE_KEYWORD,
+			constants.CurrencySearchAttribute:      enumspb.INDEXED_VALUE_TYPE_KEYWORD,
+			constants.PeriodStatusSearchAttribute:  enumspb.INDEXED_VALUE_TYPE_KEYWORD,
+			constants.OpenBillCountSearchAttribute: enumspb.INDEXED_VALUE_TYPE_INT,
+			constants.RunningTotalSearchAttribute:  enumspb.INDEXED_VALUE_TYPE_DOUBLE,
+			constants.BillIDsSearchAttribute:       enumspb.INDEXED_VALUE_TYPE_KEYWORD_LIST,
+		},
+	})
+	var exists *serviceerror.AlreadyExists
+	if err != nil && !errors.As(err, &exists) {
+		rlog.Warn("failed to register bill search attributes", "error", err)
+	}
+}
+
+// newBillsWorker registers the workflow and activities of billing periods, then starts polling the task queue
+func newBillsWorker(temporalClient client.Client, taskQueue string) (worker.Worker, error) {
+	activities := &Activities{}
+	worker := worker.New(temporalClient, taskQueue, workerOptions(cfg.Worker))
+	worker.RegisterWorkflow(workflows.BillWorkflow)
+	worker.RegisterActivityWithOptions(activities.DrawDownCredits, activity.RegisterOptions{
+		Name: constants.DrawDownCreditsActivityName,
+	})
+	worker.RegisterActivityWithOptions(activities.EmitSpendingAlert, activity.RegisterOptions{
+		Name: constants.EmitSpendingAlertActivityName,
+	})
+	worker.RegisterActivityWithOptions(activities.PublishBillCreated, activity.RegisterOptions{
+		Name: constants.PublishBillCreatedActivityName,
+	})
+	worker.RegisterActivityWithOptions(activities.PublishLineItemAdded, activity.RegisterOptions{
+		Name: constants.PublishLineItemAddedActivityName,
+	})
+	worker.RegisterActivityWithOptions(activities.PublishBillClosed, activity.RegisterOptions{
+		Name: constants.PublishBillClosedActivityName,
+	})
+	worker.RegisterActivityWithOptions(activities.PublishBillingPeriodClosed, activity.RegisterOptions{
+		Name: constants.PublishBillingPeriodClosedActivityName,
+	})
+	worker.RegisterActivityWithOptions(activities.AppendAuditRecord, activity.RegisterOptions{
+		Name: constants.AppendAuditRecordActivityName,
 	})
+	worker.RegisterActivityWithOptions(activities.ArchiveBillingPeriod, activity.RegisterOptions{
+		Name: constants.ArchiveBillingPeriodActivityName,
+	})
+	worker.RegisterActivityWithOptions(activities.ConsolidateBillingPeriod, activity.RegisterOptions{
+		Name: constants.ConsolidateBillingPeriodActivityName,
+	})
+	if err := worker.Start(); err != nil {
+		return nil, fmt.Errorf("failed to start worker on %s: %w", taskQueue, err)
+	}
+	return worker, nil
+}
+
+// ensureTenantWorker starts a worker on the tenant's task queue unless one is running. Where the API runs
+// without the worker, the worker process picks the queue up on its next refresh.
+func (s *Service) ensureTenantWorker(tenant *models.Tenant) error {
+	if !cfg.Worker.RunWorker {
+		return nil
+	}
+	return s.ensureWorker(tenant.TaskQueue)
+}
+
+// ensureWorker starts the worker of the task queue unless one is running
+func (s *Service) ensureWorker(taskQueue string) error {
+	temporalClient, err := s.temporal()
+	if err != nil {
+		return err
+	}
+	s.workersMu.Lock()
+	defer s.workersMu.Unlock()
+	if _, running := s.workers[taskQueue]; running {
+		return nil
+	}
+	worker, err := newBillsWorker(temporalClient, taskQueue)
 	if err != nil {
-		return nil, fmt.Errorf("failed to create Temporal client: %w", err)
-	}
-	workers := []worker.Worker{}
-	for i := 0; i < 10; i++ {
-		worker := worker.New(temporalClient, billsTaskQueue, worker.Options{
-			WorkerStopTimeout: 30 * time.Second,
-		})
-		err = worker.Start()
+		return err
+	}
+	s.workers[taskQueue] = worker
+	return nil
+}
+
+// workerTaskQueues returns the task queues polled by workers of this instance
+func (s *Service) workerTaskQueues() map[string]bool {
+	s.workersMu.Lock()
+	defer s.workersMu.Unlock()
+	taskQueues := make(map[string]bool, len(s.workers))
+	for taskQueue := range s.workers {
+		taskQueues[taskQueue] = true
+	}
+	return taskQueues
+}
+
+// refreshTenantWorkers starts workers for the task queues of all tenants, including tenants created through
+// an API process that runs no worker, until the service shuts down
+func (s *Service) refreshTenantWorkers(interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		ctx, cancel := context.WithTimeout(context.Background(), interval)
+		resp, err := tenants.ListTaskQueues(ctx)
+		cancel()
 		if err != nil {
-			return nil, fmt.Errorf("failed to start worker: %w", err)
+			rlog.Warn("failed to list tenant task queues", "error", err)
+		} else {
+			for _, taskQueue := range resp.TaskQueues {
+				if err := s.ensureWorker(taskQueue); err != nil {
+					rlog.Error("failed to start tenant worker", "task_queue", taskQueue, "error", err)
+				}
+			}
+		}
+		select {
+		case <-s.done:
+			return
+		case <-ticker.C:
 		}
-		worker.RegisterWorkflow(workflows.BillWorkflow)
-		workers = append(workers, worker)
 	}
-
-	return &Service{
-		workflows:      make(map[string]string),
-		temporalClient: temporalClient,
-		workers:        workers,
-	}, nil
 }
 
 // Shutdown gracefully closes the service
 func (s *Service) Shutdown(force context.Context) {
+	if s.grpcServer != nil {
+		s.grpcServer.GracefulStop()
+	}
+	close(s.done)
+	s.stopStreams()
+	s.workersMu.Lock()
 	for _, w := range s.workers {
 		w.Stop()
 	}
-	if s.temporalClient != nil {
-		s.temporalClient.Close()
+	s.workersMu.Unlock()
+	s.connection.Close()
+	if err := s.stopTracing(force); err != nil {
+		rlog.Warn("failed to flush traces", "error", err)
 	}
 }
 
-// GetTemporalClient returns the Temporal client
+// GetTemporalClient returns the Temporal client, nil until it is connected
 func (s *Service) GetTemporalClient() client.Client {
-	return s.temporalClient
+	return s.connection.Current()
 }
 
-// GetTaskQueue returns the task queue name
+// GetTaskQueue returns the task queue name of the default tenant
 func (s *Service) GetTaskQueue() string {
 	return billsTaskQueue
 }
 
-// GetWorkflowIDForCustomer returns the workflow ID for a given customer
-func (s *Service) GetWorkflowIDForCustomer(customerID string) (string, bool) {
-	id, found := s.workflows[customerID]
+// GetWorkflowIDForCustomer returns the workflow ID for a given customer of the tenant
+func (s *Service) GetWorkflowIDForCustomer(tenantID string, customerID string) (string, bool) {
+	s.workflowsMu.Lock()
+	defer s.workflowsMu.Unlock()
+	id, found := s.workflows[models.TenantCustomerKey(tenantID, customerID)]
 	return id, found
 }
+
+// setWorkflowID records the workflow ID of the billing period of a customer of the tenant
+func (s *Service) setWorkflowID(tenantID string, customerID string, workflowID string) {
+	s.workflowsMu.Lock()
+	defer s.workflowsMu.Unlock()
+	s.workflows[models.TenantCustomerKey(tenantID, customerID)] = workflowID
+}
+
+// deleteWorkflowID forgets the workflow ID of a customer of the tenant once its billing period is closed
+func (s *Service) deleteWorkflowID(tenantID string, customerID string) {
+	s.workflowsMu.Lock()
+	defer s.workflowsMu.Unlock()
+	delete(s.workflows, models.TenantCustomerKey(tenantID, customerID))
+}
+
+// workflowCount returns the number of workflow IDs recorded on this instance
+func (s *Service) workflowCount() int {
+	s.workflowsMu.Lock()
+	defer s.workflowsMu.Unlock()
+	return len(s.workflows)
+}
@@ -0,0 +1,145 @@
+package bills
+
+import (
+	"context"
+
+	"encore.dev/beta/errs"
+	"encore.dev/rlog"
+
+	"encore.app/constants"
+	"encore.app/models"
+	"encore.app/tracing"
+)
+
+//encore:api auth method=POST path=/bills/spendingLimit/:customerId
+func SetSpendingLimit(ctx context.Context, customerId string, req *models.SetSpendingLimitRequest) (_ *models.SpendingStatusResponse, err error) {
+	tenantID, err := authorizeCustomer(customerId, models.ScopeWrite)
+	if err != nil {
+		return nil, err
+	}
+	record := newAPIAuditRecord(tenantID, customerId, models.AuditSetSpendingLimit)
+	defer func() { appendAPIAuditRecord(ctx, record, err) }()
+	record.Currency = req.Limit.Currency
+	if err := validateSpendingLimit(&req.Limit); err != nil {
+		return nil, models.InvalidRequest(err)
+	}
+	workflowId, found := service.GetWorkflowIDForCustomer(tenantID, customerId)
+	if !found {
+		return nil, billingPeriodNotFound(customerId)
+	}
+	record.WorkflowID = workflowId
+	ctx = tracing.WithIDs(ctx, tracing.IDs{TenantID: tenantID, CustomerID: customerId, WorkflowID: workflowId})
+
+	temporalClient, err := service.temporal()
+	if err != nil {
+		return nil, err
+	}
+	err = temporalClient.SignalWorkflow(
+		ctx, workflowId, "", constants.SetSpendingLimitSignalName, models.SetSpendingLimitSignal{Limit: req.Limit, Trace: tracing.Inject(ctx)},
+	)
+	if err != nil {
+		return nil, temporalError(err, models.ReasonBillingPeriodNotFound, "failed to signal workflow")
+	}
+	rlog.Info("set spending limit",
+		"customer_id", customerId,
+		"hard_cap", req.Limit.HardCap,
+		"currency", req.Limit.Currency,
+	)
+
+	status, err := getSpendingStatus(ctx, tenantID, workflowId)
+	if err != nil {
+		return nil, err
+	}
+	return &models.SpendingStatusResponse{WorkflowID: workflowId, Status: status}, nil
+}
+
+//encore:api auth method=GET path=/bills/spendingStatus/:customerId
+func GetSpendingStatus(ctx context.Context, customerId string) (*models.SpendingStatusResponse, error) {
+	tenantID, err := authorizeCustomer(customerId, models.ScopeRead)
+	if err != nil {
+		return nil, err
+	}
+	workflowId, found := service.GetWorkflowIDForCustomer(tenantID, customerId)
+	if !found {
+		return nil, billingPeriodNotFound(customerId)
+	}
+	status, err := getSpendingStatus(ctx, tenantID, workflowId)
+	if err != nil {
+		return nil, err
+	}
+	return &models.SpendingStatusResponse{WorkflowID: workflowId, Status: status}, nil
+}
+
+// checkSpendingLimit rejects line items that would take the billing period over its hard cap. The amount
+// checked is the one the workflow applies: converted to the bill currency, accrued and times the quantity.
+func checkSpendingLimit(ctx context.Context, tenantID string, workflowId string, bill *models.Bill, req *models.AddLineItemRequest) error {
+	status, err := getSpendingStatus(ctx, tenantID, workflowId)
+	if err != nil {
+		return err
+	}
+	if status.Limit == nil {
+		return nil
+	}
+	accrualFactor := status.AccrualFactor
+	if accrualFactor == 0 {
+		accrualFactor = 1
+	}
+	item := &models.LineItem{
+		Amount:   models.AccruedAmount(req.Amount, models.Currency(req.Currency), bill.Currency, accrualFactor),
+		Quantity: req.Quantity,
+	}
+	amount := status.Limit.LineItemSpend(item, bill.Currency)
+	if status.Limit.WouldExceed(status.Total, amount) {
+		return spendingLimitExceeded(amount, status)
+	}
+	return nil
+}
+
+// spendingLimitExceeded reports a line item of amount, in the currency of the limit, rejected at the hard cap
+func spendingLimitExceeded(amount float64, status *models.SpendingStatus) error {
+	return models.NewAPIError(errs.FailedPrecondition, models.ReasonSpendingLimitExceeded, "line item of %.2f %s would exceed the spending limit of %.2f %s (spent %.2f)",
+		amount, status.Limit.Currency, status.Limit.HardCap, status.Limit.Currency, status.Total)
+}
+
+// checkLineItemApplied queries the bill after the signal and reports a line item the workflow did not apply,
+// because the hard cap was reached by items racing it or the bill closed meanwhile. The query is answered
+// once the signal is handled.
+func checkLineItemApplied(ctx context.Context, tenantID string, workflowId string, billId string, lineItemID string) error {
+	bill, err := getBillByID(ctx, tenantID, billId, workflowId)
+	if err != nil {
+		return err
+	}
+	if bill.FindLineItem(lineItemID) != nil {
+		return nil
+	}
+	if !bill.CanAddLineItems() {
+		return models.NewAPIError(errs.FailedPrecondition, models.ReasonBillClosed, "bill was closed before the line item was added")
+	}
+	status, err := getSpendingStatus(ctx, tenantID, workflowId)
+	if err != nil {
+		return err
+	}
+	if status.Limit == nil {
+		return models.NewAPIError(errs.Internal, models.ReasonInternal, "line item %s was not added to bill %s", lineItemID, billId)
+	}
+	return models.NewAPIError(errs.FailedPrecondition, models.ReasonSpendingLimitExceeded, "line item was rejected, it would exceed the spending limit of %.2f %s (spent %.2f)",
+		status.Limit.HardCap, status.Limit.Currency, status.Total)
+}
+
+func getSpendingStatus(ctx context.Context, tenantID string, workflowId string) (*models.SpendingStatus, error) {
+	temporalClient, err := service.temporal()
+	if err != nil {
+		return nil, err
+	}
+	queryResult, err := temporalClient.QueryWorkflow(ctx, workflowId, "", constants.GetSpendingStatusQuery, models.SpendingStatusRequest{
+		TenantID: tenantID,
+	})
+	if err != nil {
+		return nil, temporalError(err, models.ReasonBillingPeriodNotFound, "failed to query spending status")
+	}
+	var status *models.SpendingStatus
+	if err := queryResult.Get(&status); err != nil {
+		return nil, models.NewAPIErrorWithCause(errs.Internal, models.ReasonInternal, err, "failed to get spending status from query result")
+	}
+	return status, nil
+}
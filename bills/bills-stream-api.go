@@ -0,0 +1,98 @@
+package bills
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"time"
+
+	"encore.dev"
+	"encore.dev/beta/errs"
+	"encore.dev/cron"
+	"encore.dev/rlog"
+
+	"encore.app/models"
+)
+
+// billStreamHeartbeat keeps idle streams open through proxies that drop silent connections
+const billStreamHeartbeat = 15 * time.Second
+
+// Relayed bill events are only read back by listeners catching up, older ones are deleted every few minutes
+var _ = cron.NewJob("prune-bill-stream-events", cron.JobConfig{
+	Title:    "Prune relayed bill stream events",
+	Every:    5 * cron.Minute,
+	Endpoint: PruneBillStreamEvents,
+})
+
+// StreamBillUpdates streams the customer's bill changes as Server-Sent Events while the workflow applies
+// them: bills created, line items added with the new total, bills closed and the end of the billing period.
+// With ?bill_id= only the changes of that bill are sent.
+//
+//encore:api auth raw method=GET path=/bills/stream/:customerId
+func StreamBillUpdates(w http.ResponseWriter, req *http.Request) {
+	customerId := encore.CurrentRequest().PathParams.Get("customerId")
+	tenantID, err := authorizeCustomer(customerId, models.ScopeRead)
+	if err != nil {
+		errs.HTTPError(w, err)
+		return
+	}
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		errs.HTTPError(w, models.NewAPIError(errs.Internal, models.ReasonInternal, "streaming is not supported"))
+		return
+	}
+	billID := req.URL.Query().Get("bill_id")
+
+	events, unsubscribe := service.streams.subscribe(tenantID, customerId)
+	defer unsubscribe()
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+	w.WriteHeader(http.StatusOK)
+	flusher.Flush()
+
+	heartbeat := time.NewTicker(billStreamHeartbeat)
+	defer heartbeat.Stop()
+	for {
+		select {
+		case <-req.Context().Done():
+			return
+		case event, open := <-events:
+			if !open {
+				return
+			}
+			if !event.Matches(billID) {
+				continue
+			}
+			message, err := event.SSE()
+			if err != nil {
+				rlog.Error("failed to encode bill stream event", "event_id", event.ID, "error", err)
+				continue
+			}
+			if _, err := w.Write(message); err != nil {
+				return
+			}
+			flusher.Flush()
+		case <-heartbeat.C:
+			if _, err := fmt.Fprint(w, ": keepalive\n\n"); err != nil {
+				return
+			}
+			flusher.Flush()
+		}
+	}
+}
+
+// PruneBillStreamEvents deletes the relayed bill events every instance has had time to read
+//
+//encore:api private
+func PruneBillStreamEvents(ctx context.Context) error {
+	pruned, err := pruneStreamEvents(ctx, time.Now().Add(-billStreamKeep))
+	if err != nil {
+		return err
+	}
+	if pruned > 0 {
+		rlog.Info("pruned relayed bill stream events", "events", pruned)
+	}
+	return nil
+}
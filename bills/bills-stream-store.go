@@ -0,0 +1,99 @@
+package bills
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"encore.app/models"
+)
+
+// billStreamChannel is the Postgres notification channel announcing new rows of bill_stream_events
+const billStreamChannel = "bill_stream"
+
+// billStreamBatch bounds the events read per query, an instance far behind catches up over several reads
+const billStreamBatch = 500
+
+// relayStreamEvent stores a bill event for the update streams of every instance and notifies their listeners
+// once committed. Pub/Sub hands each message to one instance only, the table is how the others see it.
+// Relaying an event again, as a redelivered message does, changes nothing.
+func relayStreamEvent(ctx context.Context, event *models.BillStreamEvent) error {
+	payload, err := json.Marshal(event)
+	if err != nil {
+		return fmt.Errorf("failed to encode stream event %s: %w", event.ID, err)
+	}
+	tx, err := db.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	result, err := tx.Exec(ctx, `
+		INSERT INTO bill_stream_events (event_id, tenant_id, customer_id, payload, created_at)
+		VALUES ($1, $2, $3, $4, $5)
+		ON CONFLICT (event_id) DO NOTHING
+	`, event.ID, event.TenantID, event.CustomerID, payload, time.Now())
+	if err != nil {
+		return fmt.Errorf("failed to store stream event: %w", err)
+	}
+	if result.RowsAffected() == 0 {
+		return nil
+	}
+	// Notifications are sent on commit, listeners never look for a row that is not visible yet
+	if _, err := tx.Exec(ctx, `SELECT pg_notify($1, '')`, billStreamChannel); err != nil {
+		return fmt.Errorf("failed to notify stream listeners: %w", err)
+	}
+	return tx.Commit()
+}
+
+// latestStreamEventSeq returns the sequence number of the newest stored event, 0 when there is none
+func latestStreamEventSeq(ctx context.Context) (int64, error) {
+	var seq int64
+	err := db.QueryRow(ctx, `SELECT COALESCE(MAX(seq), 0) FROM bill_stream_events`).Scan(&seq)
+	if err != nil {
+		return 0, fmt.Errorf("failed to read latest stream event: %w", err)
+	}
+	return seq, nil
+}
+
+// streamEventsAfter returns the stored events after the given sequence number, oldest first, and the
+// sequence number of the last one returned
+func streamEventsAfter(ctx context.Context, after int64) ([]*models.BillStreamEvent, int64, error) {
+	rows, err := db.Query(ctx, `
+		SELECT seq, tenant_id, payload FROM bill_stream_events
+		WHERE seq > $1
+		ORDER BY seq
+		LIMIT $2
+	`, after, billStreamBatch)
+	if err != nil {
+		return nil, after, fmt.Errorf("failed to read stream events: %w", err)
+	}
+	defer rows.Close()
+
+	events := make([]*models.BillStreamEvent, 0)
+	for rows.Next() {
+		var payload []byte
+		var tenantID string
+		if err := rows.Scan(&after, &tenantID, &payload); err != nil {
+			return nil, after, fmt.Errorf("failed to scan stream event: %w", err)
+		}
+		var event models.BillStreamEvent
+		if err := json.Unmarshal(payload, &event); err != nil {
+			return nil, after, fmt.Errorf("failed to decode stream event: %w", err)
+		}
+		// The tenant is not part of the event sent to clients
+		event.TenantID = tenantID
+		events = append(events, &event)
+	}
+	return events, after, rows.Err()
+}
+
+// pruneStreamEvents deletes the events stored before the cutoff, every listener has read them by then
+func pruneStreamEvents(ctx context.Context, before time.Time) (int64, error) {
+	result, err := db.Exec(ctx, `DELETE FROM bill_stream_events WHERE created_at < $1`, before)
+	if err != nil {
+		return 0, fmt.Errorf("failed to prune stream events: %w", err)
+	}
+	return result.RowsAffected(), nil
+}
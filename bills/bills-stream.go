@@ -0,0 +1,155 @@
+package bills
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"encore.dev/rlog"
+	"encore.dev/storage/sqldb"
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"encore.app/models"
+)
+
+const (
+	billStreamBuffer = 64               // Events a stream may fall behind before it is closed
+	billStreamPoll   = 5 * time.Second  // Longest wait for a notification before the table is read anyway
+	billStreamRetry  = 5 * time.Second  // Wait before the listener reconnects
+	billStreamKeep   = 10 * time.Minute // Relayed events are kept this long for listeners catching up
+)
+
+// billStreams fans the bill events relayed by any instance out to the update streams open on this one, keyed
+// by models.TenantCustomerKey
+type billStreams struct {
+	mu          sync.Mutex
+	subscribers map[string]map[chan *models.BillStreamEvent]struct{}
+}
+
+func newBillStreams() *billStreams {
+	return &billStreams{subscribers: make(map[string]map[chan *models.BillStreamEvent]struct{})}
+}
+
+// subscribe opens a stream of the customer's events, unsubscribe must be called once the client is gone
+func (s *billStreams) subscribe(tenantID, customerID string) (events <-chan *models.BillStreamEvent, unsubscribe func()) {
+	key := models.TenantCustomerKey(tenantID, customerID)
+	ch := make(chan *models.BillStreamEvent, billStreamBuffer)
+	s.mu.Lock()
+	if s.subscribers[key] == nil {
+		s.subscribers[key] = make(map[chan *models.BillStreamEvent]struct{})
+	}
+	s.subscribers[key][ch] = struct{}{}
+	s.mu.Unlock()
+	return ch, func() { s.remove(key, ch) }
+}
+
+// publish sends the event to every stream of its customer. A stream too far behind is closed rather than
+// silently skipping events, the client reconnects and reads the bill again.
+func (s *billStreams) publish(event *models.BillStreamEvent) {
+	key := models.TenantCustomerKey(event.TenantID, event.CustomerID)
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for ch := range s.subscribers[key] {
+		select {
+		case ch <- event:
+		default:
+			rlog.Warn("closing bill stream that fell behind", "customer_id", event.CustomerID, "event_id", event.ID)
+			s.removeLocked(key, ch)
+		}
+	}
+}
+
+func (s *billStreams) remove(key string, ch chan *models.BillStreamEvent) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.removeLocked(key, ch)
+}
+
+func (s *billStreams) removeLocked(key string, ch chan *models.BillStreamEvent) {
+	if _, open := s.subscribers[key][ch]; !open {
+		return
+	}
+	delete(s.subscribers[key], ch)
+	if len(s.subscribers[key]) == 0 {
+		delete(s.subscribers, key)
+	}
+	close(ch)
+}
+
+// listen delivers the events relayed through the bills database to the streams open on this instance until
+// ctx is done. It starts after the newest stored event and, after losing its connection, resumes after the
+// last event it delivered.
+func (s *billStreams) listen(ctx context.Context) {
+	after, err := latestStreamEventSeq(ctx)
+	for err != nil {
+		rlog.Warn("bill stream listener cannot read the relayed events, retrying", "error", err)
+		if !sleepContext(ctx, billStreamRetry) {
+			return
+		}
+		after, err = latestStreamEventSeq(ctx)
+	}
+	for {
+		after, err = s.follow(ctx, after)
+		if ctx.Err() != nil {
+			return
+		}
+		rlog.Warn("bill stream listener disconnected, reconnecting", "error", err)
+		if !sleepContext(ctx, billStreamRetry) {
+			return
+		}
+	}
+}
+
+// follow listens for relayed events on a connection of its own and publishes those after the given sequence
+// number. It returns the sequence number of the last event published once the connection fails.
+func (s *billStreams) follow(ctx context.Context, after int64) (int64, error) {
+	pooled, err := sqldb.Driver[*pgxpool.Pool](db).Acquire(ctx)
+	if err != nil {
+		return after, fmt.Errorf("failed to acquire listener connection: %w", err)
+	}
+	// Taken out of the pool for good, a connection returned to it would stay subscribed to the channel
+	conn := pooled.Hijack()
+	defer conn.Close(context.Background())
+
+	if _, err := conn.Exec(ctx, "LISTEN "+billStreamChannel); err != nil {
+		return after, fmt.Errorf("failed to listen for stream events: %w", err)
+	}
+	for {
+		// Read before waiting, so the events relayed while not listening are published as well
+		events, seq, err := streamEventsAfter(ctx, after)
+		if err != nil {
+			return after, err
+		}
+		for _, event := range events {
+			s.publish(event)
+		}
+		after = seq
+		if len(events) == billStreamBatch {
+			continue
+		}
+
+		waitCtx, cancel := context.WithTimeout(ctx, billStreamPoll)
+		err = conn.PgConn().WaitForNotification(waitCtx)
+		cancel()
+		if ctx.Err() != nil {
+			return after, ctx.Err()
+		}
+		if err != nil && !errors.Is(waitCtx.Err(), context.DeadlineExceeded) {
+			return after, fmt.Errorf("failed to wait for stream events: %w", err)
+		}
+	}
+}
+
+// sleepContext waits for the duration, it returns false when ctx is done first
+func sleepContext(ctx context.Context, d time.Duration) bool {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return false
+	case <-timer.C:
+		return true
+	}
+}
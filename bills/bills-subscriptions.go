@@ -0,0 +1,37 @@
+package bills
+
+import (
+	"context"
+
+	"encore.dev/pubsub"
+
+	"encore.app/events"
+	"encore.app/models"
+)
+
+// Pub/Sub hands each bill event to one instance, which relays it through the bills database to the update
+// streams open on every instance
+
+var _ = pubsub.NewSubscription(events.BillCreated, "bills-stream-bill-created", pubsub.SubscriptionConfig[*models.BillCreatedEvent]{
+	Handler: func(ctx context.Context, event *models.BillCreatedEvent) error {
+		return relayStreamEvent(ctx, models.NewBillCreatedStreamEvent(event))
+	},
+})
+
+var _ = pubsub.NewSubscription(events.LineItemAdded, "bills-stream-line-item-added", pubsub.SubscriptionConfig[*models.LineItemAddedEvent]{
+	Handler: func(ctx context.Context, event *models.LineItemAddedEvent) error {
+		return relayStreamEvent(ctx, models.NewLineItemAddedStreamEvent(event))
+	},
+})
+
+var _ = pubsub.NewSubscription(events.BillClosed, "bills-stream-bill-closed", pubsub.SubscriptionConfig[*models.BillClosedEvent]{
+	Handler: func(ctx context.Context, event *models.BillClosedEvent) error {
+		return relayStreamEvent(ctx, models.NewBillClosedStreamEvent(event))
+	},
+})
+
+var _ = pubsub.NewSubscription(events.BillingPeriodClosed, "bills-stream-billing-period-closed", pubsub.SubscriptionConfig[*models.BillingPeriodClosedEvent]{
+	Handler: func(ctx context.Context, event *models.BillingPeriodClosedEvent) error {
+		return relayStreamEvent(ctx, models.NewBillingPeriodClosedStreamEvent(event))
+	},
+})
@@ -0,0 +1,20 @@
+package bills
+
+import (
+	"go.temporal.io/sdk/client"
+)
+
+// temporal returns the connected client, or an Unavailable error while Temporal is unreachable so API calls
+// fail fast instead of waiting on the connection. Only calls made during the first attempt wait for it.
+func (s *Service) temporal() (client.Client, error) {
+	temporalClient, err := s.connection.Client()
+	if err != nil {
+		return nil, temporalNotConnected(err)
+	}
+	return temporalClient, nil
+}
+
+// setTemporalClient replaces the client and returns the previous one, tests inject clients with it
+func (s *Service) setTemporalClient(temporalClient client.Client) client.Client {
+	return s.connection.Set(temporalClient)
+}
@@ -0,0 +1,35 @@
+package bills
+
+import (
+	"testing"
+
+	"encore.dev/beta/errs"
+	"github.com/stretchr/testify/assert"
+	"go.temporal.io/sdk/mocks"
+
+	"encore.app/models"
+	"encore.app/temporalconn"
+)
+
+func TestTemporalClient(t *testing.T) {
+	s := &Service{connection: &temporalconn.Connection{}}
+
+	t.Run("unavailable until connected", func(t *testing.T) {
+		_, err := s.temporal()
+		apiErr, ok := err.(*errs.Error)
+		if assert.True(t, ok) {
+			assert.Equal(t, errs.Unavailable, apiErr.Code)
+			details, _ := apiErr.Details.(models.ErrorResponse)
+			assert.Equal(t, string(models.ReasonWorkflowUnavailable), details.Code)
+		}
+	})
+
+	t.Run("injected client", func(t *testing.T) {
+		injected := &mocks.Client{}
+		assert.Nil(t, s.setTemporalClient(injected))
+		temporalClient, err := s.temporal()
+		assert.NoError(t, err)
+		assert.Same(t, injected, temporalClient)
+		assert.Same(t, injected, s.setTemporalClient(nil))
+	})
+}
@@ -0,0 +1,65 @@
+package bills
+
+import (
+	"context"
+
+	"encore.dev/beta/errs"
+	"encore.dev/middleware"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+	"go.temporal.io/sdk/client"
+
+	"encore.app/tracing"
+)
+
+// setupTracing exports traces when tracing is enabled and adds the tracing interceptor to the Temporal
+// client options, so workflows and activities continue the traces of the API calls starting and signalling
+// them. The returned function flushes the spans left on shutdown.
+func setupTracing(tracingConfig TracingConfig, options *client.Options) (func(context.Context) error, error) {
+	if !tracingConfig.Enabled {
+		return func(context.Context) error { return nil }, nil
+	}
+	shutdown, err := tracing.Setup(context.Background(), tracing.Config{
+		ServiceName: "bills",
+		Endpoint:    tracingConfig.Endpoint,
+		Insecure:    tracingConfig.Insecure,
+		SampleRatio: tracingConfig.SampleRatio,
+	})
+	if err != nil {
+		return nil, err
+	}
+	tracingInterceptor, err := tracing.NewTemporalInterceptor()
+	if err != nil {
+		return nil, err
+	}
+	options.Interceptors = append(options.Interceptors, tracingInterceptor)
+	return shutdown, nil
+}
+
+// TraceAPICalls starts a span for every API call, continuing the trace of callers that send a traceparent
+// header. Endpoints add the IDs they work on with tracing.WithIDs. Spans are dropped while tracing is
+// disabled.
+//
+//encore:middleware global target=all
+func TraceAPICalls(req middleware.Request, next middleware.Next) middleware.Response {
+	data := req.Data()
+	ctx := tracing.Extract(req.Context(), data.Headers)
+	ctx, span := tracing.Tracer().Start(ctx, data.Service+"."+data.Endpoint,
+		trace.WithSpanKind(trace.SpanKindServer),
+		trace.WithAttributes(
+			attribute.String("rpc.service", data.Service),
+			attribute.String("rpc.method", data.Endpoint),
+			attribute.String("http.request.method", data.Method),
+			attribute.String("url.path", data.Path),
+		),
+	)
+	defer span.End()
+	resp := next(req.WithContext(ctx))
+	if resp.Err != nil {
+		span.RecordError(resp.Err)
+		span.SetStatus(codes.Error, resp.Err.Error())
+		span.SetAttributes(attribute.String("error.type", errs.Code(resp.Err).String()))
+	}
+	return resp
+}
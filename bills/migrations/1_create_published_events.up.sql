@@ -0,0 +1,7 @@
+-- Event IDs already published to Pub/Sub, retried activities skip them
+CREATE TABLE published_events (
+    event_id     TEXT PRIMARY KEY,
+    topic        TEXT NOT NULL,
+    message_id   TEXT NOT NULL,
+    published_at TIMESTAMPTZ NOT NULL
+);
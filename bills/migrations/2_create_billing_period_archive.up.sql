@@ -0,0 +1,28 @@
+-- Finished billing periods, written by the last activity of the bill workflow
+CREATE TABLE archived_billing_periods (
+    workflow_id TEXT PRIMARY KEY,
+    tenant_id   TEXT NOT NULL,
+    customer_id TEXT NOT NULL,
+    currency    TEXT NOT NULL,
+    started_at  TIMESTAMPTZ NOT NULL,
+    closed_at   TIMESTAMPTZ NOT NULL
+);
+
+CREATE INDEX archived_billing_periods_customer_idx ON archived_billing_periods (tenant_id, customer_id, closed_at);
+
+-- The final state of every bill of an archived period, line items included
+CREATE TABLE archived_bills (
+    bill_id      TEXT PRIMARY KEY,
+    workflow_id  TEXT NOT NULL REFERENCES archived_billing_periods (workflow_id),
+    tenant_id    TEXT NOT NULL,
+    customer_id  TEXT NOT NULL,
+    status       TEXT NOT NULL,
+    currency     TEXT NOT NULL,
+    total_amount DOUBLE PRECISION NOT NULL,
+    close_reason TEXT NOT NULL DEFAULT '',
+    created_at   TIMESTAMPTZ NOT NULL,
+    closed_at    TIMESTAMPTZ,
+    bill         JSONB NOT NULL
+);
+
+CREATE INDEX archived_bills_customer_idx ON archived_bills (tenant_id, customer_id, status, created_at);
@@ -0,0 +1,6 @@
+-- The seller legal entity each tenant invoices as, read by e-invoicing and by the ledger for the VAT rate
+CREATE TABLE legal_entities (
+    tenant_id  TEXT PRIMARY KEY,
+    profile    JSONB NOT NULL,
+    updated_at TIMESTAMPTZ NOT NULL
+);
@@ -0,0 +1,5 @@
+-- The consolidated bill of a parent account, generated by the bill workflow when the period ends
+ALTER TABLE archived_billing_periods ADD COLUMN consolidated_bill JSONB;
+
+CREATE INDEX archived_billing_periods_consolidated_idx ON archived_billing_periods (tenant_id, customer_id, closed_at)
+    WHERE consolidated_bill IS NOT NULL;
@@ -0,0 +1,12 @@
+-- Bill events relayed to the update streams of every instance, each instance LISTENs on bill_stream and
+-- reads the rows after the last one it saw
+CREATE TABLE bill_stream_events (
+    seq         BIGSERIAL PRIMARY KEY,
+    event_id    TEXT NOT NULL UNIQUE,
+    tenant_id   TEXT NOT NULL,
+    customer_id TEXT NOT NULL,
+    payload     JSONB NOT NULL,
+    created_at  TIMESTAMPTZ NOT NULL
+);
+
+CREATE INDEX bill_stream_events_created_at_idx ON bill_stream_events (created_at);
@@ -22,9 +22,128 @@ const (
 	// CloseBillingPeriodSignalName is used to close a billing period
 	CloseBillingPeriodSignalName = "close-billing-period"
 
+	// SetSpendingLimitSignalName is used to set or replace the spending limit of a billing period
+	SetSpendingLimitSignalName = "set-spending-limit"
+
 	// GetBillQuery is used to retrieve a bill by ID
 	GetBillQuery = "get-bill"
 
 	// ListBillsQuery is used to retrieve all bills
 	ListBillsQuery = "list-bills"
+
+	// GetSpendingStatusQuery is used to retrieve the spend of a billing period against its limit
+	GetSpendingStatusQuery = "get-spending-status"
+
+	// DrawDownCreditsActivityName is used to apply a customer's prepaid credits to a bill
+	DrawDownCreditsActivityName = "draw-down-credits"
+
+	// EmitSpendingAlertActivityName is used to publish an alert when a spending threshold is crossed
+	EmitSpendingAlertActivityName = "emit-spending-alert"
+
+	// PublishBillCreatedActivityName is used to publish a BillCreated event
+	PublishBillCreatedActivityName = "publish-bill-created"
+
+	// PublishLineItemAddedActivityName is used to publish a LineItemAdded event
+	PublishLineItemAddedActivityName = "publish-line-item-added"
+
+	// PublishBillClosedActivityName is used to publish a BillClosed event
+	PublishBillClosedActivityName = "publish-bill-closed"
+
+	// PublishBillingPeriodClosedActivityName is used to publish a BillingPeriodClosed event
+	PublishBillingPeriodClosedActivityName = "publish-billing-period-closed"
+
+	// AppendAuditRecordActivityName is used to append a bill mutation to the audit log
+	AppendAuditRecordActivityName = "append-audit-record"
+
+	// ArchiveBillingPeriodActivityName is used to write a finished billing period to the archive
+	ArchiveBillingPeriodActivityName = "archive-billing-period"
+
+	// ConsolidateBillingPeriodActivityName is used to roll a parent's billing period up with its child accounts
+	ConsolidateBillingPeriodActivityName = "consolidate-billing-period"
+
+	// DeliverWebhookActivityName is used to post a webhook delivery to its endpoint
+	DeliverWebhookActivityName = "deliver-webhook"
+
+	// DeadLetterWebhookActivityName is used to give up on a webhook delivery after its retries are exhausted
+	DeadLetterWebhookActivityName = "dead-letter-webhook"
+)
+
+// Search attributes upserted by the bill workflow, registered in the Temporal namespace when the bills service starts
+const (
+	// TenantIDSearchAttribute is the tenant of the billing period, every search is scoped to it
+	TenantIDSearchAttribute = "BillingTenantId"
+
+	// CustomerIDSearchAttribute is the customer of the billing period
+	CustomerIDSearchAttribute = "BillingCustomerId"
+
+	// CurrencySearchAttribute is the currency of the billing period
+	CurrencySearchAttribute = "BillingCurrency"
+
+	// PeriodStatusSearchAttribute is OPEN while the billing period runs and CLOSED once it has ended
+	PeriodStatusSearchAttribute = "BillingPeriodStatus"
+
+	// OpenBillCountSearchAttribute is the number of open bills of the billing period
+	OpenBillCountSearchAttribute = "BillingOpenBillCount"
+
+	// RunningTotalSearchAttribute is the total of all bills of the billing period in its currency
+	RunningTotalSearchAttribute = "BillingRunningTotal"
+
+	// BillIDsSearchAttribute lists the bills of the billing period
+	BillIDsSearchAttribute = "BillingBillIds"
+)
+
+// Metrics recorded by the bill workflow through the Temporal metrics handler, exported by the /metrics endpoint
+// next to the SDK metrics. Counters get the _total suffix when exported.
+const (
+	// SignalsHandledMetric counts the signals handled per signal type
+	SignalsHandledMetric = "billing_signals_handled"
+
+	// BillsCreatedMetric counts the bills created per currency
+	BillsCreatedMetric = "billing_bills_created"
+
+	// BillsClosedMetric counts the bills closed per currency and trigger, a close signal or the end of the period
+	BillsClosedMetric = "billing_bills_closed"
+
+	// LineItemsAddedMetric counts the line items added per currency
+	LineItemsAddedMetric = "billing_line_items_added"
+
+	// AmountBilledMetric sums the amounts of line items added per currency, in minor units (cents, tetri)
+	AmountBilledMetric = "billing_amount_billed_minor_units"
+
+	// FXConversionsMetric counts the line items converted from another currency per currency pair
+	FXConversionsMetric = "billing_fx_conversions"
+)
+
+// Change IDs of the workflow.GetVersion gates in the bill workflow, billing periods started before a change
+// replay the code they ran with
+const (
+	// LineTotalChangeID makes the running total add the amount times the quantity of line items
+	LineTotalChangeID = "line-total-quantity"
+
+	// SpendingCapLineTotalChangeID makes the hard cap check the line total of line items
+	SpendingCapLineTotalChangeID = "spending-cap-line-total"
+
+	// PrepaidCreditsChangeID draws prepaid credits down from the wallet of the customer
+	PrepaidCreditsChangeID = "prepaid-credits"
+
+	// SpendingAlertsChangeID emits an alert for every spending threshold crossed
+	SpendingAlertsChangeID = "spending-alerts"
+
+	// LifecycleEventsChangeID publishes bill created, line item added, bill closed and billing period closed
+	LifecycleEventsChangeID = "lifecycle-events"
+
+	// AuditLogChangeID appends the mutations of the workflow to the audit log
+	AuditLogChangeID = "audit-log"
+
+	// ArchiveChangeID archives the billing period when the workflow completes
+	ArchiveChangeID = "archive-billing-period"
+
+	// SearchAttributesChangeID indexes the billing period in search attributes
+	SearchAttributesChangeID = "search-attributes"
+
+	// ConsolidationChangeID generates the consolidated bill of parent accounts when the billing period ends
+	ConsolidationChangeID = "consolidated-bill"
+
+	// PrepaidCreditEventsChangeID publishes a line item added event for every prepaid credit line
+	PrepaidCreditEventsChangeID = "prepaid-credit-events"
 )
@@ -0,0 +1,129 @@
+package customers
+
+import (
+	"errors"
+	"fmt"
+	"net/mail"
+	"strings"
+
+	"encore.dev/beta/auth"
+	"encore.dev/beta/errs"
+	"golang.org/x/text/language"
+
+	"encore.app/models"
+)
+
+// authorizeCustomer checks that the calling API key may act on the customer with the given scope and
+// returns the key's tenant
+func authorizeCustomer(customerID string, scope models.APIKeyScope) (string, error) {
+	data, _ := auth.Data().(*models.AuthData)
+	if data == nil {
+		return "", &errs.Error{Code: errs.Unauthenticated, Message: "missing API key"}
+	}
+	if data.TenantID == "" {
+		return "", &errs.Error{Code: errs.PermissionDenied, Message: "the operator key cannot act on customers, use an admin key of the tenant"}
+	}
+	if err := data.Authorize(data.TenantID, customerID, scope); err != nil {
+		return "", &errs.Error{Code: errs.PermissionDenied, Message: err.Error()}
+	}
+	return data.TenantID, nil
+}
+
+// authorizeTenantAdmin checks that the calling API key is an admin key of a tenant and returns the tenant,
+// creating, listing and deleting customers is left to admin keys
+func authorizeTenantAdmin() (string, error) {
+	data, _ := auth.Data().(*models.AuthData)
+	if data == nil {
+		return "", &errs.Error{Code: errs.Unauthenticated, Message: "missing API key"}
+	}
+	if data.TenantID == "" {
+		return "", &errs.Error{Code: errs.PermissionDenied, Message: "the operator key cannot act on customers, use an admin key of the tenant"}
+	}
+	if err := data.AuthorizeTenant(data.TenantID, models.ScopeAdmin); err != nil {
+		return "", &errs.Error{Code: errs.PermissionDenied, Message: err.Error()}
+	}
+	return data.TenantID, nil
+}
+
+// authorizeSellerControlledChanges checks that only admin keys change the fields of a customer the seller
+// decides on, customer keys may only edit their contact details
+func authorizeSellerControlledChanges(changed []string) error {
+	if len(changed) == 0 {
+		return nil
+	}
+	data, _ := auth.Data().(*models.AuthData)
+	if data == nil || !data.IsAdmin() {
+		return &errs.Error{Code: errs.PermissionDenied, Message: fmt.Sprintf("only admin keys may change %s", strings.Join(changed, ", "))}
+	}
+	return nil
+}
+
+func validateCustomerDetails(req *models.CustomerDetails) error {
+	if req.LegalName == "" {
+		return fmt.Errorf("legal_name is required")
+	}
+	if !req.BillingAddress.IsComplete() {
+		return fmt.Errorf("billing_address country_code must be an ISO 3166-1 alpha-2 code")
+	}
+	if !req.DefaultCurrency.IsValid() {
+		return fmt.Errorf("invalid default_currency: %s (supported: USD, GEL)", req.DefaultCurrency)
+	}
+	if req.PaymentTermsDays < 0 {
+		return fmt.Errorf("payment_terms_days must be non-negative")
+	}
+	if _, err := language.Parse(req.Locale); err != nil {
+		return fmt.Errorf("invalid locale: %s", req.Locale)
+	}
+	if len(req.ContactEmails) == 0 {
+		return fmt.Errorf("at least one contact email is required")
+	}
+	for _, email := range req.ContactEmails {
+		if _, err := mail.ParseAddress(email); err != nil {
+			return fmt.Errorf("invalid contact email: %s", email)
+		}
+	}
+	for _, taxID := range req.TaxIDs {
+		if taxID.Type == "" || taxID.Value == "" {
+			return fmt.Errorf("tax_ids require both type and value")
+		}
+	}
+	if (req.EndpointID == "") != (req.EndpointScheme == "") {
+		return fmt.Errorf("endpoint_id and endpoint_scheme must be set together")
+	}
+	return nil
+}
+
+// validateParentCustomer checks that attaching customerID below parentID keeps the account hierarchy acyclic.
+// lookupParent returns the parent ID of an existing customer.
+func validateParentCustomer(customerID string, parentID string, lookupParent func(customerID string) (string, error)) error {
+	if parentID == "" {
+		return nil
+	}
+	if parentID == customerID {
+		return fmt.Errorf("customer cannot be its own parent")
+	}
+	visited := make(map[string]bool)
+	for current := parentID; current != ""; {
+		if current == customerID {
+			return fmt.Errorf("parent_customer_id %s would create a cycle in the account hierarchy", parentID)
+		}
+		if visited[current] {
+			return fmt.Errorf("account hierarchy above %s contains a cycle", parentID)
+		}
+		visited[current] = true
+		next, err := lookupParent(current)
+		if err != nil {
+			return fmt.Errorf("parent customer %s not found: %w", current, err)
+		}
+		current = next
+	}
+	return nil
+}
+
+// customerError reports a missing customer as not found, other store failures keep their cause
+func customerError(err error, format string, args ...interface{}) error {
+	if errors.Is(err, errCustomerNotFound) {
+		return models.NewAPIErrorWithCause(errs.NotFound, models.ReasonCustomerNotFound, err, format, args...)
+	}
+	return errs.Wrap(err, fmt.Sprintf(format, args...))
+}
@@ -0,0 +1,93 @@
+package customers
+
+import (
+	"testing"
+
+	"encore.app/models"
+	"github.com/stretchr/testify/assert"
+)
+
+func validCustomerDetails() *models.CustomerDetails {
+	return &models.CustomerDetails{
+		LegalName:        "Buyer BV",
+		BillingAddress:   models.Address{CityName: "Amsterdam", CountryCode: "NL"},
+		TaxIDs:           []models.TaxID{{Type: models.TaxIDTypeVAT, Value: "NL123456789B01"}},
+		DefaultCurrency:  models.USD,
+		PaymentTermsDays: 30,
+		Locale:           "nl-NL",
+		ContactEmails:    []string{"billing@buyer.example"},
+	}
+}
+
+func TestValidateCustomerDetails(t *testing.T) {
+	t.Parallel()
+	cases := []struct {
+		name    string
+		mutate  func(*models.CustomerDetails)
+		wantErr string
+	}{
+		{"valid request", func(*models.CustomerDetails) {}, ""},
+		{"missing legal name", func(d *models.CustomerDetails) { d.LegalName = "" }, "legal_name is required"},
+		{"missing country", func(d *models.CustomerDetails) { d.BillingAddress.CountryCode = "" }, "country_code"},
+		{"invalid currency", func(d *models.CustomerDetails) { d.DefaultCurrency = "EUR" }, "invalid default_currency"},
+		{"negative payment terms", func(d *models.CustomerDetails) { d.PaymentTermsDays = -1 }, "payment_terms_days must be non-negative"},
+		{"invalid locale", func(d *models.CustomerDetails) { d.Locale = "not a locale" }, "invalid locale"},
+		{"no contact emails", func(d *models.CustomerDetails) { d.ContactEmails = nil }, "at least one contact email is required"},
+		{"invalid contact email", func(d *models.CustomerDetails) { d.ContactEmails = []string{"billing"} }, "invalid contact email"},
+		{"incomplete tax id", func(d *models.CustomerDetails) { d.TaxIDs = []models.TaxID{{Type: "VAT"}} }, "tax_ids require both type and value"},
+		{"endpoint without scheme", func(d *models.CustomerDetails) { d.EndpointID = "NL123456789B01" }, "endpoint_id and endpoint_scheme must be set together"},
+	}
+	for _, tc := range cases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+			details := validCustomerDetails()
+			tc.mutate(details)
+			err := validateCustomerDetails(details)
+			if tc.wantErr == "" {
+				assert.NoError(t, err)
+				return
+			}
+			assert.Error(t, err)
+			assert.Contains(t, err.Error(), tc.wantErr)
+		})
+	}
+}
+
+func TestValidateParentCustomer(t *testing.T) {
+	t.Parallel()
+	// child-1 -> parent-1 -> root
+	parents := map[string]string{
+		"root":     "",
+		"parent-1": "root",
+		"child-1":  "parent-1",
+	}
+	lookup := func(customerID string) (string, error) {
+		parent, found := parents[customerID]
+		if !found {
+			return "", errCustomerNotFound
+		}
+		return parent, nil
+	}
+	t.Run("no parent", func(t *testing.T) {
+		assert.NoError(t, validateParentCustomer("new", "", lookup))
+	})
+	t.Run("valid parent", func(t *testing.T) {
+		assert.NoError(t, validateParentCustomer("new", "child-1", lookup))
+	})
+	t.Run("own parent", func(t *testing.T) {
+		err := validateParentCustomer("root", "root", lookup)
+		assert.Error(t, err)
+		assert.Contains(t, err.Error(), "cannot be its own parent")
+	})
+	t.Run("cycle", func(t *testing.T) {
+		err := validateParentCustomer("root", "child-1", lookup)
+		assert.Error(t, err)
+		assert.Contains(t, err.Error(), "would create a cycle")
+	})
+	t.Run("unknown parent", func(t *testing.T) {
+		err := validateParentCustomer("new", "missing", lookup)
+		assert.Error(t, err)
+		assert.Contains(t, err.Error(), "parent customer missing not found")
+	})
+}
@@ -0,0 +1,178 @@
+package customers
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"encore.dev/beta/errs"
+	"encore.dev/rlog"
+	"github.com/google/uuid"
+
+	"encore.app/models"
+)
+
+// CreateCustomer adds a customer of the tenant, only admin keys may create customers
+//
+//encore:api auth method=POST path=/customers
+func CreateCustomer(ctx context.Context, req *models.CreateCustomerRequest) (*models.CustomerResponse, error) {
+	tenantID, err := authorizeTenantAdmin()
+	if err != nil {
+		return nil, err
+	}
+	if err := validateCustomerDetails(&req.CustomerDetails); err != nil {
+		return nil, models.InvalidRequest(err)
+	}
+	customerID := req.CustomerID
+	if customerID == "" {
+		customerID = uuid.New().String()
+	}
+	if _, err := findCustomer(ctx, tenantID, customerID); err == nil {
+		return nil, models.NewAPIError(errs.AlreadyExists, models.ReasonCustomerExists, "customer %s already exists", customerID)
+	} else if !errors.Is(err, errCustomerNotFound) {
+		return nil, err
+	}
+	if err := validateParentCustomer(customerID, req.ParentCustomerID, parentLookup(ctx, tenantID)); err != nil {
+		return nil, models.InvalidRequest(err)
+	}
+
+	now := time.Now()
+	customer := models.NewCustomerProfile(tenantID, customerID, req.CustomerDetails)
+	customer.CreatedAt = now
+	customer.UpdatedAt = now
+	if err := insertCustomer(ctx, customer); err != nil {
+		return nil, err
+	}
+
+	rlog.Info("created customer", "tenant_id", tenantID, "customer_id", customerID)
+	return &models.CustomerResponse{Customer: customer}, nil
+}
+
+//encore:api auth method=GET path=/customers/:customerId
+func GetCustomer(ctx context.Context, customerId string) (*models.CustomerResponse, error) {
+	tenantID, err := authorizeCustomer(customerId, models.ScopeRead)
+	if err != nil {
+		return nil, err
+	}
+	return FindCustomer(ctx, tenantID, customerId)
+}
+
+// FindCustomer returns the profile of the tenant's customer to services that authorized the caller themselves
+//
+//encore:api private method=GET path=/customers/find/:tenantId/:customerId
+func FindCustomer(ctx context.Context, tenantId string, customerId string) (*models.CustomerResponse, error) {
+	customer, err := findCustomer(ctx, tenantId, customerId)
+	if err != nil {
+		return nil, customerError(err, "failed to get customer %s", customerId)
+	}
+	return &models.CustomerResponse{Customer: customer}, nil
+}
+
+// UpdateCustomer replaces the customer's profile. Moving the customer below another parent needs a key
+// authorized for the new parent as well, since the parent's billing period closes the child's.
+//
+//encore:api auth method=PUT path=/customers/:customerId
+func UpdateCustomer(ctx context.Context, customerId string, req *models.UpdateCustomerRequest) (*models.CustomerResponse, error) {
+	tenantID, err := authorizeCustomer(customerId, models.ScopeWrite)
+	if err != nil {
+		return nil, err
+	}
+	if err := validateCustomerDetails(&req.CustomerDetails); err != nil {
+		return nil, models.InvalidRequest(err)
+	}
+	existing, err := findCustomer(ctx, tenantID, customerId)
+	if err != nil {
+		return nil, customerError(err, "failed to update customer %s", customerId)
+	}
+	// Customer keys may edit their contact details, re-parenting and commercial terms are left to admin keys
+	if err := authorizeSellerControlledChanges(existing.SellerControlledChanges(req.CustomerDetails)); err != nil {
+		return nil, err
+	}
+	if err := validateParentCustomer(customerId, req.ParentCustomerID, parentLookup(ctx, tenantID)); err != nil {
+		return nil, models.InvalidRequest(err)
+	}
+
+	customer := models.NewCustomerProfile(tenantID, customerId, req.CustomerDetails)
+	customer.CreatedAt = existing.CreatedAt
+	customer.UpdatedAt = time.Now()
+	if err := updateCustomer(ctx, customer); err != nil {
+		return nil, customerError(err, "failed to update customer %s", customerId)
+	}
+
+	rlog.Info("updated customer", "tenant_id", tenantID, "customer_id", customerId)
+	return &models.CustomerResponse{Customer: customer}, nil
+}
+
+//encore:api auth method=DELETE path=/customers/:customerId
+func DeleteCustomer(ctx context.Context, customerId string) error {
+	tenantID, err := authorizeTenantAdmin()
+	if err != nil {
+		return err
+	}
+	children, err := listChildCustomers(ctx, tenantID, customerId)
+	if err != nil {
+		return err
+	}
+	if len(children) > 0 {
+		return models.NewAPIError(errs.FailedPrecondition, models.ReasonCustomerHasChildren, "customer %s still has %d child accounts", customerId, len(children))
+	}
+	if err := softDeleteCustomer(ctx, tenantID, customerId); err != nil {
+		return customerError(err, "failed to delete customer %s", customerId)
+	}
+	rlog.Info("deleted customer", "tenant_id", tenantID, "customer_id", customerId)
+	return nil
+}
+
+//encore:api auth method=GET path=/customers
+func ListCustomers(ctx context.Context) (*models.ListCustomersResponse, error) {
+	tenantID, err := authorizeTenantAdmin()
+	if err != nil {
+		return nil, err
+	}
+	customers, err := listCustomers(ctx, tenantID)
+	if err != nil {
+		return nil, err
+	}
+	return &models.ListCustomersResponse{
+		Customers: customers,
+		Total:     int64(len(customers)),
+	}, nil
+}
+
+//encore:api auth method=GET path=/customers/:customerId/children
+func ListChildCustomers(ctx context.Context, customerId string) (*models.ListCustomersResponse, error) {
+	tenantID, err := authorizeCustomer(customerId, models.ScopeRead)
+	if err != nil {
+		return nil, err
+	}
+	return FindChildCustomers(ctx, tenantID, customerId)
+}
+
+// FindChildCustomers lists the direct child accounts of the tenant's customer to services that authorized the
+// caller for the parent
+//
+//encore:api private method=GET path=/customers/find/:tenantId/:customerId/children
+func FindChildCustomers(ctx context.Context, tenantId string, customerId string) (*models.ListCustomersResponse, error) {
+	if _, err := findCustomer(ctx, tenantId, customerId); err != nil {
+		return nil, customerError(err, "failed to get customer %s", customerId)
+	}
+	children, err := listChildCustomers(ctx, tenantId, customerId)
+	if err != nil {
+		return nil, err
+	}
+	return &models.ListCustomersResponse{
+		Customers: children,
+		Total:     int64(len(children)),
+	}, nil
+}
+
+// parentLookup resolves the parent of the tenant's stored customers for hierarchy validation
+func parentLookup(ctx context.Context, tenantID string) func(customerID string) (string, error) {
+	return func(customerID string) (string, error) {
+		customer, err := findCustomer(ctx, tenantID, customerID)
+		if err != nil {
+			return "", err
+		}
+		return customer.ParentCustomerID, nil
+	}
+}
@@ -0,0 +1,208 @@
+package customers
+
+import (
+	"context"
+	"testing"
+
+	"encore.dev/beta/auth"
+	"encore.dev/beta/errs"
+
+	"encore.app/models"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+)
+
+// adminContext authenticates calls with an admin API key of the default tenant
+func adminContext() context.Context {
+	return auth.WithContext(context.Background(), auth.UID("test-admin"), &models.AuthData{
+		KeyID:    "test-admin",
+		TenantID: models.DefaultTenantID,
+		Scopes:   []models.APIKeyScope{models.ScopeAdmin},
+	})
+}
+
+// tenantAdminContext authenticates calls with an admin API key of the given tenant
+func tenantAdminContext(tenantId string) context.Context {
+	return auth.WithContext(context.Background(), auth.UID("test-admin-"+tenantId), &models.AuthData{
+		KeyID:    "test-admin-" + tenantId,
+		TenantID: tenantId,
+		Scopes:   []models.APIKeyScope{models.ScopeAdmin},
+	})
+}
+
+// customerContext authenticates calls with an API key bound to the customer of the default tenant
+func customerContext(customerId string, scopes ...models.APIKeyScope) context.Context {
+	return auth.WithContext(context.Background(), auth.UID("test-"+customerId), &models.AuthData{
+		KeyID:      "test-" + customerId,
+		TenantID:   models.DefaultTenantID,
+		CustomerID: customerId,
+		Scopes:     scopes,
+	})
+}
+
+func TestCustomerCRUD(t *testing.T) {
+	ctx := adminContext()
+	testCustomerId := uuid.New().String()
+
+	t.Run("Create Customer", func(t *testing.T) {
+		resp, err := CreateCustomer(ctx, &models.CreateCustomerRequest{
+			CustomerID:      testCustomerId,
+			CustomerDetails: *validCustomerDetails(),
+		})
+		assert.NoError(t, err)
+		assert.Equal(t, testCustomerId, resp.Customer.CustomerID)
+		assert.NotZero(t, resp.Customer.CreatedAt)
+	})
+
+	t.Run("Create Duplicate Customer", func(t *testing.T) {
+		_, err := CreateCustomer(ctx, &models.CreateCustomerRequest{
+			CustomerID:      testCustomerId,
+			CustomerDetails: *validCustomerDetails(),
+		})
+		assert.Error(t, err)
+	})
+
+	t.Run("Create Customer With Generated ID", func(t *testing.T) {
+		resp, err := CreateCustomer(ctx, &models.CreateCustomerRequest{
+			CustomerDetails: *validCustomerDetails(),
+		})
+		assert.NoError(t, err)
+		assert.NotEmpty(t, resp.Customer.CustomerID)
+	})
+
+	t.Run("Get Customer", func(t *testing.T) {
+		resp, err := GetCustomer(ctx, testCustomerId)
+		assert.NoError(t, err)
+		assert.Equal(t, "Buyer BV", resp.Customer.LegalName)
+		assert.Equal(t, "NL123456789B01", resp.Customer.VATID())
+		assert.Equal(t, []string{"billing@buyer.example"}, resp.Customer.ContactEmails)
+	})
+
+	t.Run("Update Customer", func(t *testing.T) {
+		details := validCustomerDetails()
+		details.DefaultCurrency = models.GEL
+		details.PaymentTermsDays = 14
+		resp, err := UpdateCustomer(ctx, testCustomerId, &models.UpdateCustomerRequest{CustomerDetails: *details})
+		assert.NoError(t, err)
+		assert.Equal(t, models.GEL, resp.Customer.DefaultCurrency)
+
+		getResp, err := GetCustomer(ctx, testCustomerId)
+		assert.NoError(t, err)
+		assert.Equal(t, 14, getResp.Customer.PaymentTermsDays)
+	})
+
+	t.Run("Customer Key Reads Only Its Own Customer", func(t *testing.T) {
+		customerCtx := customerContext(testCustomerId, models.ScopeWrite)
+		_, err := GetCustomer(customerCtx, testCustomerId)
+		assert.NoError(t, err)
+		_, err = ListCustomers(customerCtx)
+		assert.Error(t, err)
+		_, err = GetCustomer(context.Background(), testCustomerId)
+		assert.Error(t, err)
+	})
+
+	t.Run("List Customers", func(t *testing.T) {
+		resp, err := ListCustomers(ctx)
+		assert.NoError(t, err)
+		assert.GreaterOrEqual(t, resp.Total, int64(2))
+	})
+
+	t.Run("Delete Customer", func(t *testing.T) {
+		err := DeleteCustomer(ctx, testCustomerId)
+		assert.NoError(t, err)
+
+		_, err = GetCustomer(ctx, testCustomerId)
+		assert.Error(t, err)
+
+		err = DeleteCustomer(ctx, testCustomerId)
+		assert.Error(t, err)
+	})
+}
+
+func TestReparentCustomer(t *testing.T) {
+	ctx := adminContext()
+	parentId := uuid.New().String()
+	childId := uuid.New().String()
+	for _, customerId := range []string{parentId, childId} {
+		_, err := CreateCustomer(ctx, &models.CreateCustomerRequest{CustomerID: customerId, CustomerDetails: *validCustomerDetails()})
+		assert.NoError(t, err)
+	}
+	details := validCustomerDetails()
+	details.ParentCustomerID = parentId
+
+	t.Run("Child Key Cannot Attach To Another Parent", func(t *testing.T) {
+		_, err := UpdateCustomer(customerContext(childId, models.ScopeWrite), childId, &models.UpdateCustomerRequest{CustomerDetails: *details})
+		assert.Error(t, err)
+	})
+
+	t.Run("Parent Key Cannot Attach Another Customer", func(t *testing.T) {
+		_, err := UpdateCustomer(customerContext(parentId, models.ScopeWrite), childId, &models.UpdateCustomerRequest{CustomerDetails: *details})
+		assert.Error(t, err)
+	})
+
+	t.Run("Admin Key Attaches Child", func(t *testing.T) {
+		resp, err := UpdateCustomer(ctx, childId, &models.UpdateCustomerRequest{CustomerDetails: *details})
+		assert.NoError(t, err)
+		assert.Equal(t, parentId, resp.Customer.ParentCustomerID)
+
+		children, err := ListChildCustomers(customerContext(parentId, models.ScopeRead), parentId)
+		assert.NoError(t, err)
+		assert.Len(t, children.Customers, 1)
+	})
+
+	t.Run("Child Key Cannot Detach From Parent", func(t *testing.T) {
+		_, err := UpdateCustomer(customerContext(childId, models.ScopeWrite), childId, &models.UpdateCustomerRequest{CustomerDetails: *validCustomerDetails()})
+		assert.Equal(t, errs.PermissionDenied, errs.Code(err))
+	})
+
+	t.Run("Child Key Cannot Change Payment Terms", func(t *testing.T) {
+		update := *details
+		update.PaymentTermsDays = 90
+		_, err := UpdateCustomer(customerContext(childId, models.ScopeWrite), childId, &models.UpdateCustomerRequest{CustomerDetails: update})
+		assert.Equal(t, errs.PermissionDenied, errs.Code(err))
+	})
+
+	t.Run("Child Key Updates Contact Details", func(t *testing.T) {
+		update := *details
+		update.ContactEmails = []string{"ap@buyer.example"}
+		resp, err := UpdateCustomer(customerContext(childId, models.ScopeWrite), childId, &models.UpdateCustomerRequest{CustomerDetails: update})
+		assert.NoError(t, err)
+		assert.Equal(t, parentId, resp.Customer.ParentCustomerID)
+		assert.Equal(t, 30, resp.Customer.PaymentTermsDays)
+	})
+}
+
+func TestCustomersScopedToTenant(t *testing.T) {
+	retail := tenantAdminContext("retail-" + uuid.New().String())
+	wholesale := tenantAdminContext("wholesale-" + uuid.New().String())
+	customerId := uuid.New().String()
+
+	t.Run("Same Customer ID In Two Tenants", func(t *testing.T) {
+		details := validCustomerDetails()
+		_, err := CreateCustomer(retail, &models.CreateCustomerRequest{CustomerID: customerId, CustomerDetails: *details})
+		assert.NoError(t, err)
+
+		details.LegalName = "Wholesale Buyer"
+		resp, err := CreateCustomer(wholesale, &models.CreateCustomerRequest{CustomerID: customerId, CustomerDetails: *details})
+		assert.NoError(t, err)
+		assert.Equal(t, "Wholesale Buyer", resp.Customer.LegalName)
+	})
+
+	t.Run("Tenant Reads Only Its Own Customers", func(t *testing.T) {
+		resp, err := GetCustomer(retail, customerId)
+		assert.NoError(t, err)
+		assert.NotEqual(t, "Wholesale Buyer", resp.Customer.LegalName)
+
+		list, err := ListCustomers(wholesale)
+		assert.NoError(t, err)
+		assert.Len(t, list.Customers, 1)
+		assert.Equal(t, "Wholesale Buyer", list.Customers[0].LegalName)
+	})
+
+	t.Run("Deleting Leaves The Other Tenant's Customer", func(t *testing.T) {
+		assert.NoError(t, DeleteCustomer(wholesale, customerId))
+
+		_, err := GetCustomer(retail, customerId)
+		assert.NoError(t, err)
+	})
+}
@@ -0,0 +1,178 @@
+package customers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"encore.dev/storage/sqldb"
+
+	"encore.app/models"
+)
+
+// Customer profiles are stored in their own database so billing workflows only hold IDs
+var db = sqldb.NewDatabase("customers", sqldb.DatabaseConfig{
+	Migrations: "./migrations",
+})
+
+// errCustomerNotFound is returned when a customer does not exist or has been deleted
+var errCustomerNotFound = errors.New("customer not found")
+
+const customerColumns = `tenant_id, customer_id, legal_name, billing_address, tax_ids, company_id, default_currency,
+	payment_terms_days, locale, contact_emails, endpoint_id, endpoint_scheme, parent_customer_id, created_at, updated_at`
+
+type rowScanner interface {
+	Scan(dest ...interface{}) error
+}
+
+func insertCustomer(ctx context.Context, customer *models.CustomerProfile) error {
+	address, taxIDs, emails, err := marshalCustomerJSON(customer)
+	if err != nil {
+		return err
+	}
+	_, err = db.Exec(ctx, `
+		INSERT INTO customers (`+customerColumns+`)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15)
+	`, customer.TenantID, customer.CustomerID, customer.LegalName, address, taxIDs, customer.CompanyID, string(customer.DefaultCurrency),
+		customer.PaymentTermsDays, customer.Locale, emails, customer.EndpointID, customer.EndpointScheme,
+		customer.ParentCustomerID, customer.CreatedAt, customer.UpdatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to insert customer: %w", err)
+	}
+	return nil
+}
+
+func updateCustomer(ctx context.Context, customer *models.CustomerProfile) error {
+	address, taxIDs, emails, err := marshalCustomerJSON(customer)
+	if err != nil {
+		return err
+	}
+	result, err := db.Exec(ctx, `
+		UPDATE customers
+		SET legal_name = $3, billing_address = $4, tax_ids = $5, company_id = $6, default_currency = $7,
+			payment_terms_days = $8, locale = $9, contact_emails = $10, endpoint_id = $11, endpoint_scheme = $12,
+			parent_customer_id = $13, updated_at = $14
+		WHERE tenant_id = $1 AND customer_id = $2 AND deleted_at IS NULL
+	`, customer.TenantID, customer.CustomerID, customer.LegalName, address, taxIDs, customer.CompanyID, string(customer.DefaultCurrency),
+		customer.PaymentTermsDays, customer.Locale, emails, customer.EndpointID, customer.EndpointScheme,
+		customer.ParentCustomerID, customer.UpdatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to update customer: %w", err)
+	}
+	if result.RowsAffected() == 0 {
+		return errCustomerNotFound
+	}
+	return nil
+}
+
+func findCustomer(ctx context.Context, tenantID string, customerID string) (*models.CustomerProfile, error) {
+	row := db.QueryRow(ctx, `
+		SELECT `+customerColumns+`
+		FROM customers
+		WHERE tenant_id = $1 AND customer_id = $2 AND deleted_at IS NULL
+	`, tenantID, customerID)
+	customer, err := scanCustomer(row)
+	if errors.Is(err, sqldb.ErrNoRows) {
+		return nil, errCustomerNotFound
+	}
+	return customer, err
+}
+
+func listCustomers(ctx context.Context, tenantID string) ([]*models.CustomerProfile, error) {
+	rows, err := db.Query(ctx, `
+		SELECT `+customerColumns+`
+		FROM customers
+		WHERE tenant_id = $1 AND deleted_at IS NULL
+		ORDER BY created_at, customer_id
+	`, tenantID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list customers: %w", err)
+	}
+	return scanCustomers(rows)
+}
+
+func listChildCustomers(ctx context.Context, tenantID string, parentCustomerID string) ([]*models.CustomerProfile, error) {
+	rows, err := db.Query(ctx, `
+		SELECT `+customerColumns+`
+		FROM customers
+		WHERE tenant_id = $1 AND parent_customer_id = $2 AND deleted_at IS NULL
+		ORDER BY created_at, customer_id
+	`, tenantID, parentCustomerID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list child customers: %w", err)
+	}
+	return scanCustomers(rows)
+}
+
+func scanCustomers(rows *sqldb.Rows) ([]*models.CustomerProfile, error) {
+	defer rows.Close()
+
+	customers := make([]*models.CustomerProfile, 0)
+	for rows.Next() {
+		customer, err := scanCustomer(rows)
+		if err != nil {
+			return nil, err
+		}
+		customers = append(customers, customer)
+	}
+	return customers, rows.Err()
+}
+
+// softDeleteCustomer keeps the row so historical bills and invoices still resolve the customer
+func softDeleteCustomer(ctx context.Context, tenantID string, customerID string) error {
+	result, err := db.Exec(ctx, `
+		UPDATE customers SET deleted_at = NOW()
+		WHERE tenant_id = $1 AND customer_id = $2 AND deleted_at IS NULL
+	`, tenantID, customerID)
+	if err != nil {
+		return fmt.Errorf("failed to delete customer: %w", err)
+	}
+	if result.RowsAffected() == 0 {
+		return errCustomerNotFound
+	}
+	return nil
+}
+
+func scanCustomer(row rowScanner) (*models.CustomerProfile, error) {
+	var customer models.CustomerProfile
+	var currency string
+	var address, taxIDs, emails []byte
+	err := row.Scan(&customer.TenantID, &customer.CustomerID, &customer.LegalName, &address, &taxIDs, &customer.CompanyID, &currency,
+		&customer.PaymentTermsDays, &customer.Locale, &emails, &customer.EndpointID, &customer.EndpointScheme,
+		&customer.ParentCustomerID, &customer.CreatedAt, &customer.UpdatedAt)
+	if err != nil {
+		return nil, err
+	}
+	customer.DefaultCurrency = models.Currency(currency)
+	if err := json.Unmarshal(address, &customer.BillingAddress); err != nil {
+		return nil, fmt.Errorf("failed to decode billing address: %w", err)
+	}
+	if err := json.Unmarshal(taxIDs, &customer.TaxIDs); err != nil {
+		return nil, fmt.Errorf("failed to decode tax ids: %w", err)
+	}
+	if err := json.Unmarshal(emails, &customer.ContactEmails); err != nil {
+		return nil, fmt.Errorf("failed to decode contact emails: %w", err)
+	}
+	return &customer, nil
+}
+
+func marshalCustomerJSON(customer *models.CustomerProfile) (address, taxIDs, emails []byte, err error) {
+	if address, err = json.Marshal(customer.BillingAddress); err != nil {
+		return nil, nil, nil, fmt.Errorf("failed to encode billing address: %w", err)
+	}
+	if taxIDs, err = json.Marshal(nonNil(customer.TaxIDs)); err != nil {
+		return nil, nil, nil, fmt.Errorf("failed to encode tax ids: %w", err)
+	}
+	if emails, err = json.Marshal(nonNil(customer.ContactEmails)); err != nil {
+		return nil, nil, nil, fmt.Errorf("failed to encode contact emails: %w", err)
+	}
+	return address, taxIDs, emails, nil
+}
+
+func nonNil[T any](values []T) []T {
+	if values == nil {
+		return []T{}
+	}
+	return values
+}
@@ -0,0 +1,16 @@
+CREATE TABLE customers (
+    customer_id        TEXT PRIMARY KEY,
+    legal_name         TEXT NOT NULL,
+    billing_address    JSONB NOT NULL,
+    tax_ids            JSONB NOT NULL DEFAULT '[]',
+    company_id         TEXT NOT NULL DEFAULT '',
+    default_currency   TEXT NOT NULL,
+    payment_terms_days INTEGER NOT NULL,
+    locale             TEXT NOT NULL,
+    contact_emails     JSONB NOT NULL DEFAULT '[]',
+    endpoint_id        TEXT NOT NULL DEFAULT '',
+    endpoint_scheme    TEXT NOT NULL DEFAULT '',
+    created_at         TIMESTAMPTZ NOT NULL,
+    updated_at         TIMESTAMPTZ NOT NULL,
+    deleted_at         TIMESTAMPTZ
+);
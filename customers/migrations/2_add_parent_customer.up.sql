@@ -0,0 +1,3 @@
+ALTER TABLE customers ADD COLUMN parent_customer_id TEXT NOT NULL DEFAULT '';
+
+CREATE INDEX customers_parent_customer_id_idx ON customers (parent_customer_id) WHERE deleted_at IS NULL;
@@ -0,0 +1,8 @@
+-- Customers created before tenants existed belong to the default tenant, customer IDs are unique per tenant
+ALTER TABLE customers ADD COLUMN tenant_id TEXT NOT NULL DEFAULT 'default';
+
+ALTER TABLE customers DROP CONSTRAINT customers_pkey;
+ALTER TABLE customers ADD PRIMARY KEY (tenant_id, customer_id);
+
+DROP INDEX customers_parent_customer_id_idx;
+CREATE INDEX customers_tenant_parent_idx ON customers (tenant_id, parent_customer_id) WHERE deleted_at IS NULL;
@@ -0,0 +1,25 @@
+#!/bin/sh
+# Downloads the OASIS UBL 2.1 standard and unpacks its xsd folder into testdata/ubl-2.1/xsd.
+# Run it from the einvoice directory, or through go generate ./einvoice.
+set -eu
+
+url="https://docs.oasis-open.org/ubl/os-UBL-2.1/UBL-2.1.zip"
+dest="testdata/ubl-2.1"
+
+if [ -f "$dest/xsd/maindoc/UBL-Invoice-2.1.xsd" ]; then
+	exit 0
+fi
+
+tmp=$(mktemp -d)
+trap 'rm -rf "$tmp"' EXIT
+
+curl -fsSL -o "$tmp/UBL-2.1.zip" "$url"
+unzip -q "$tmp/UBL-2.1.zip" -d "$tmp"
+maindoc=$(find "$tmp" -type d -path '*/xsd/maindoc' | head -n 1)
+if [ -z "$maindoc" ]; then
+	echo "no xsd/maindoc folder in $url" >&2
+	exit 1
+fi
+mkdir -p "$dest"
+rm -rf "$dest/xsd"
+mv "$(dirname "$maindoc")" "$dest/xsd"
@@ -0,0 +1,360 @@
+package einvoice
+
+import (
+	"encoding/xml"
+	"fmt"
+	"math"
+	"time"
+
+	"encore.app/models"
+)
+
+const (
+	invoiceNamespace    = "urn:oasis:names:specification:ubl:schema:xsd:Invoice-2"
+	creditNoteNamespace = "urn:oasis:names:specification:ubl:schema:xsd:CreditNote-2"
+	cacNamespace        = "urn:oasis:names:specification:ubl:schema:xsd:CommonAggregateComponents-2"
+	cbcNamespace        = "urn:oasis:names:specification:ubl:schema:xsd:CommonBasicComponents-2"
+
+	// Peppol BIS Billing 3.0 specification and process identifiers
+	peppolCustomizationID = "urn:cen.eu:en16931:2017#compliant#urn:fdc:peppol.eu:2017:poacc:billing:3.0"
+	peppolProfileID       = "urn:fdc:peppol.eu:2017:poacc:billing:01:1.0"
+
+	invoiceTypeCode    = "380"
+	creditNoteTypeCode = "381"
+	unitCodeOne        = "C62"
+	vatSchemeID        = "VAT"
+	standardRateID     = "S"
+	zeroRateID         = "Z"
+	creditTransferCode = "58"
+)
+
+type amount struct {
+	CurrencyID string `xml:"currencyID,attr"`
+	Value      string `xml:",chardata"`
+}
+
+type quantity struct {
+	UnitCode string `xml:"unitCode,attr"`
+	Value    string `xml:",chardata"`
+}
+
+type endpointID struct {
+	SchemeID string `xml:"schemeID,attr"`
+	Value    string `xml:",chardata"`
+}
+
+type country struct {
+	IdentificationCode string `xml:"cbc:IdentificationCode"`
+}
+
+type postalAddress struct {
+	StreetName           string  `xml:"cbc:StreetName,omitempty"`
+	AdditionalStreetName string  `xml:"cbc:AdditionalStreetName,omitempty"`
+	CityName             string  `xml:"cbc:CityName,omitempty"`
+	PostalZone           string  `xml:"cbc:PostalZone,omitempty"`
+	Country              country `xml:"cac:Country"`
+}
+
+type taxScheme struct {
+	ID string `xml:"cbc:ID"`
+}
+
+type partyTaxScheme struct {
+	CompanyID string    `xml:"cbc:CompanyID"`
+	TaxScheme taxScheme `xml:"cac:TaxScheme"`
+}
+
+type partyLegalEntity struct {
+	RegistrationName string `xml:"cbc:RegistrationName"`
+	CompanyID        string `xml:"cbc:CompanyID,omitempty"`
+}
+
+type party struct {
+	EndpointID       endpointID       `xml:"cbc:EndpointID"`
+	PostalAddress    postalAddress    `xml:"cac:PostalAddress"`
+	PartyTaxScheme   *partyTaxScheme  `xml:"cac:PartyTaxScheme,omitempty"`
+	PartyLegalEntity partyLegalEntity `xml:"cac:PartyLegalEntity"`
+}
+
+type partyWrapper struct {
+	Party party `xml:"cac:Party"`
+}
+
+type payeeFinancialAccount struct {
+	ID string `xml:"cbc:ID"`
+}
+
+type paymentMeans struct {
+	PaymentMeansCode      string                `xml:"cbc:PaymentMeansCode"`
+	PayeeFinancialAccount payeeFinancialAccount `xml:"cac:PayeeFinancialAccount"`
+}
+
+type taxCategory struct {
+	ID        string    `xml:"cbc:ID"`
+	Percent   string    `xml:"cbc:Percent"`
+	TaxScheme taxScheme `xml:"cac:TaxScheme"`
+}
+
+type taxSubtotal struct {
+	TaxableAmount amount      `xml:"cbc:TaxableAmount"`
+	TaxAmount     amount      `xml:"cbc:TaxAmount"`
+	TaxCategory   taxCategory `xml:"cac:TaxCategory"`
+}
+
+type taxTotal struct {
+	TaxAmount   amount      `xml:"cbc:TaxAmount"`
+	TaxSubtotal taxSubtotal `xml:"cac:TaxSubtotal"`
+}
+
+type monetaryTotal struct {
+	LineExtensionAmount amount `xml:"cbc:LineExtensionAmount"`
+	TaxExclusiveAmount  amount `xml:"cbc:TaxExclusiveAmount"`
+	TaxInclusiveAmount  amount `xml:"cbc:TaxInclusiveAmount"`
+	PayableAmount       amount `xml:"cbc:PayableAmount"`
+}
+
+type item struct {
+	Name                  string      `xml:"cbc:Name"`
+	ClassifiedTaxCategory taxCategory `xml:"cac:ClassifiedTaxCategory"`
+}
+
+type price struct {
+	PriceAmount amount `xml:"cbc:PriceAmount"`
+}
+
+type documentLine struct {
+	ID                  string    `xml:"cbc:ID"`
+	InvoicedQuantity    *quantity `xml:"cbc:InvoicedQuantity,omitempty"`
+	CreditedQuantity    *quantity `xml:"cbc:CreditedQuantity,omitempty"`
+	LineExtensionAmount amount    `xml:"cbc:LineExtensionAmount"`
+	Item                item      `xml:"cac:Item"`
+	Price               price     `xml:"cac:Price"`
+}
+
+// document is the shared shape of UBL Invoice and CreditNote, element order follows the UBL 2.1 schema
+type document struct {
+	XMLName                 xml.Name       `xml:""`
+	Xmlns                   string         `xml:"xmlns,attr"`
+	XmlnsCac                string         `xml:"xmlns:cac,attr"`
+	XmlnsCbc                string         `xml:"xmlns:cbc,attr"`
+	CustomizationID         string         `xml:"cbc:CustomizationID"`
+	ProfileID               string         `xml:"cbc:ProfileID"`
+	ID                      string         `xml:"cbc:ID"`
+	IssueDate               string         `xml:"cbc:IssueDate"`
+	InvoiceTypeCode         string         `xml:"cbc:InvoiceTypeCode,omitempty"`
+	CreditNoteTypeCode      string         `xml:"cbc:CreditNoteTypeCode,omitempty"`
+	DocumentCurrencyCode    string         `xml:"cbc:DocumentCurrencyCode"`
+	BuyerReference          string         `xml:"cbc:BuyerReference"`
+	AccountingSupplierParty partyWrapper   `xml:"cac:AccountingSupplierParty"`
+	AccountingCustomerParty partyWrapper   `xml:"cac:AccountingCustomerParty"`
+	PaymentMeans            *paymentMeans  `xml:"cac:PaymentMeans,omitempty"`
+	TaxTotal                taxTotal       `xml:"cac:TaxTotal"`
+	LegalMonetaryTotal      monetaryTotal  `xml:"cac:LegalMonetaryTotal"`
+	InvoiceLines            []documentLine `xml:"cac:InvoiceLine,omitempty"`
+	CreditNoteLines         []documentLine `xml:"cac:CreditNoteLine,omitempty"`
+}
+
+// BuildDocument serialises a closed bill to a Peppol BIS Billing 3.0 UBL document.
+// Bills with a negative total are issued as a CreditNote with the signs reversed.
+func BuildDocument(bill *models.Bill, seller *models.LegalEntityProfile, buyer *models.CustomerProfile) ([]byte, models.EInvoiceDocumentType, error) {
+	if err := checkInputs(bill, seller, buyer); err != nil {
+		return nil, "", err
+	}
+
+	docType := models.DocumentInvoice
+	sign := 1.0
+	if bill.CalculateTotal() < 0 {
+		docType = models.DocumentCreditNote
+		sign = -1.0
+	}
+
+	currency := string(bill.Currency)
+	category := lineTaxCategory(seller.VATRate)
+
+	lines := make([]documentLine, 0, len(bill.LineItems))
+	lineTotal := 0.0
+	for index, lineItem := range bill.LineItems {
+		// Lines that run against the document direction keep a positive price and a negative quantity. The
+		// line amount is the price as issued times the quantity, so PEPPOL-EN16931-R120 holds on the document.
+		unitPrice := roundAmount(math.Abs(lineItem.Amount))
+		lineQuantity := lineItemQuantity(sign, lineItem)
+		lineAmount := roundAmount(unitPrice * float64(lineQuantity))
+		lineTotal += lineAmount
+		line := documentLine{
+			ID:                  fmt.Sprintf("%d", index+1),
+			LineExtensionAmount: newAmount(currency, lineAmount),
+			Item: item{
+				Name:                  lineItem.Description,
+				ClassifiedTaxCategory: category,
+			},
+			Price: price{PriceAmount: newAmount(currency, unitPrice)},
+		}
+		qty := &quantity{
+			UnitCode: unitCodeOne,
+			Value:    fmt.Sprintf("%d", lineQuantity),
+		}
+		if docType == models.DocumentCreditNote {
+			line.CreditedQuantity = qty
+		} else {
+			line.InvoicedQuantity = qty
+		}
+		lines = append(lines, line)
+	}
+	lineTotal = roundAmount(lineTotal)
+	taxAmount := roundAmount(lineTotal * seller.VATRate / 100)
+
+	doc := document{
+		Xmlns:                   invoiceNamespace,
+		XmlnsCac:                cacNamespace,
+		XmlnsCbc:                cbcNamespace,
+		CustomizationID:         peppolCustomizationID,
+		ProfileID:               peppolProfileID,
+		ID:                      bill.ID,
+		IssueDate:               bill.ClosedAt.Format(time.DateOnly),
+		DocumentCurrencyCode:    currency,
+		BuyerReference:          buyer.CustomerID,
+		AccountingSupplierParty: partyWrapper{Party: sellerParty(seller)},
+		AccountingCustomerParty: partyWrapper{Party: buyerParty(buyer)},
+		TaxTotal: taxTotal{
+			TaxAmount: newAmount(currency, taxAmount),
+			TaxSubtotal: taxSubtotal{
+				TaxableAmount: newAmount(currency, lineTotal),
+				TaxAmount:     newAmount(currency, taxAmount),
+				TaxCategory:   category,
+			},
+		},
+		LegalMonetaryTotal: monetaryTotal{
+			LineExtensionAmount: newAmount(currency, lineTotal),
+			TaxExclusiveAmount:  newAmount(currency, lineTotal),
+			TaxInclusiveAmount:  newAmount(currency, lineTotal+taxAmount),
+			PayableAmount:       newAmount(currency, lineTotal+taxAmount),
+		},
+	}
+	if seller.IBAN != "" {
+		doc.PaymentMeans = &paymentMeans{
+			PaymentMeansCode:      creditTransferCode,
+			PayeeFinancialAccount: payeeFinancialAccount{ID: seller.IBAN},
+		}
+	}
+
+	if docType == models.DocumentCreditNote {
+		doc.XMLName = xml.Name{Local: string(models.DocumentCreditNote)}
+		doc.Xmlns = creditNoteNamespace
+		doc.CreditNoteTypeCode = creditNoteTypeCode
+		doc.CreditNoteLines = lines
+	} else {
+		doc.XMLName = xml.Name{Local: string(models.DocumentInvoice)}
+		doc.InvoiceTypeCode = invoiceTypeCode
+		doc.InvoiceLines = lines
+	}
+
+	out, err := xml.MarshalIndent(doc, "", "  ")
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to marshal %s: %w", docType, err)
+	}
+	return append([]byte(xml.Header), out...), docType, nil
+}
+
+func checkInputs(bill *models.Bill, seller *models.LegalEntityProfile, buyer *models.CustomerProfile) error {
+	if bill == nil {
+		return fmt.Errorf("bill is required")
+	}
+	if bill.Status != models.StatusClosed {
+		return fmt.Errorf("bill %s must be closed before it can be invoiced", bill.ID)
+	}
+	if len(bill.LineItems) == 0 {
+		return fmt.Errorf("bill %s has no line items", bill.ID)
+	}
+	if seller == nil || seller.LegalName == "" || seller.VATID == "" || seller.EndpointID == "" {
+		return fmt.Errorf("seller legal name, vat_id and endpoint_id are required")
+	}
+	if !seller.Address.IsComplete() {
+		return fmt.Errorf("seller country_code is required")
+	}
+	if buyer == nil || buyer.LegalName == "" || buyer.EndpointID == "" {
+		return fmt.Errorf("buyer legal name and endpoint_id are required")
+	}
+	if !buyer.BillingAddress.IsComplete() {
+		return fmt.Errorf("buyer country_code is required")
+	}
+	return nil
+}
+
+func sellerParty(seller *models.LegalEntityProfile) party {
+	return party{
+		EndpointID:    endpointID{SchemeID: seller.EndpointScheme, Value: seller.EndpointID},
+		PostalAddress: newPostalAddress(seller.Address),
+		PartyTaxScheme: &partyTaxScheme{
+			CompanyID: seller.VATID,
+			TaxScheme: taxScheme{ID: vatSchemeID},
+		},
+		PartyLegalEntity: partyLegalEntity{
+			RegistrationName: seller.LegalName,
+			CompanyID:        seller.CompanyID,
+		},
+	}
+}
+
+func buyerParty(buyer *models.CustomerProfile) party {
+	p := party{
+		EndpointID:    endpointID{SchemeID: buyer.EndpointScheme, Value: buyer.EndpointID},
+		PostalAddress: newPostalAddress(buyer.BillingAddress),
+		PartyLegalEntity: partyLegalEntity{
+			RegistrationName: buyer.LegalName,
+			CompanyID:        buyer.CompanyID,
+		},
+	}
+	if vatID := buyer.VATID(); vatID != "" {
+		p.PartyTaxScheme = &partyTaxScheme{
+			CompanyID: vatID,
+			TaxScheme: taxScheme{ID: vatSchemeID},
+		}
+	}
+	return p
+}
+
+func newPostalAddress(address models.Address) postalAddress {
+	return postalAddress{
+		StreetName:           address.StreetName,
+		AdditionalStreetName: address.AdditionalStreet,
+		CityName:             address.CityName,
+		PostalZone:           address.PostalZone,
+		Country:              country{IdentificationCode: address.CountryCode},
+	}
+}
+
+func lineTaxCategory(vatRate float64) taxCategory {
+	id := standardRateID
+	if vatRate == 0 {
+		id = zeroRateID
+	}
+	return taxCategory{
+		ID:        id,
+		Percent:   formatAmount(vatRate),
+		TaxScheme: taxScheme{ID: vatSchemeID},
+	}
+}
+
+func lineItemQuantity(sign float64, lineItem *models.LineItem) int {
+	if sign*lineItem.Amount < 0 {
+		return -lineItem.Quantity
+	}
+	return lineItem.Quantity
+}
+
+func newAmount(currency string, value float64) amount {
+	return amount{CurrencyID: currency, Value: formatAmount(value)}
+}
+
+func formatAmount(value float64) string {
+	return fmt.Sprintf("%.2f", roundAmount(value))
+}
+
+func roundAmount(value float64) float64 {
+	rounded := math.Round(value*100) / 100
+	if rounded == 0 {
+		// Avoid serialising negative zero as "-0.00"
+		return 0
+	}
+	return rounded
+}
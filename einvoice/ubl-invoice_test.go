@@ -0,0 +1,261 @@
+package einvoice
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"encore.app/models"
+	"github.com/stretchr/testify/assert"
+)
+
+func testSeller() *models.LegalEntityProfile {
+	return &models.LegalEntityProfile{
+		LegalName: "Billing Co GmbH",
+		Address: models.Address{
+			StreetName:  "Hauptstrasse 1",
+			CityName:    "Berlin",
+			PostalZone:  "10115",
+			CountryCode: "DE",
+		},
+		VATID:          "DE123456789",
+		CompanyID:      "HRB 12345",
+		EndpointID:     "DE123456789",
+		EndpointScheme: "9930",
+		VATRate:        19,
+		IBAN:           "DE89370400440532013000",
+	}
+}
+
+func testBuyer() *models.CustomerProfile {
+	return &models.CustomerProfile{
+		CustomerID: "cust-1",
+		LegalName:  "Buyer BV",
+		BillingAddress: models.Address{
+			StreetName:  "Damrak 1",
+			CityName:    "Amsterdam",
+			PostalZone:  "1012",
+			CountryCode: "NL",
+		},
+		TaxIDs:         []models.TaxID{{Type: models.TaxIDTypeVAT, Value: "NL123456789B01"}},
+		EndpointID:     "NL123456789B01",
+		EndpointScheme: "9944",
+	}
+}
+
+func testBill(items ...*models.LineItem) *models.Bill {
+	return &models.Bill{
+		ID:        "bill-1",
+		Status:    models.StatusClosed,
+		Currency:  models.USD,
+		LineItems: items,
+		ClosedAt:  time.Date(2025, 8, 21, 7, 0, 0, 0, time.UTC),
+	}
+}
+
+func TestBuildDocument(t *testing.T) {
+	t.Parallel()
+	t.Run("invoice", func(t *testing.T) {
+		bill := testBill(
+			&models.LineItem{Description: "Seats", Amount: 10, Quantity: 3},
+			&models.LineItem{Description: "Discount", Amount: -5, Quantity: 1},
+		)
+		doc, docType, err := BuildDocument(bill, testSeller(), testBuyer())
+		assert.NoError(t, err)
+		assert.Equal(t, models.DocumentInvoice, docType)
+		assert.NoError(t, Validate(doc))
+
+		xmlDoc := string(doc)
+		assert.Contains(t, xmlDoc, "<Invoice xmlns=\""+invoiceNamespace+"\"")
+		assert.Contains(t, xmlDoc, "<cbc:InvoiceTypeCode>380</cbc:InvoiceTypeCode>")
+		assert.Contains(t, xmlDoc, "<cbc:IssueDate>2025-08-21</cbc:IssueDate>")
+		assert.Contains(t, xmlDoc, `<cbc:LineExtensionAmount currencyID="USD">25.00</cbc:LineExtensionAmount>`)
+		assert.Contains(t, xmlDoc, `<cbc:TaxAmount currencyID="USD">4.75</cbc:TaxAmount>`)
+		assert.Contains(t, xmlDoc, `<cbc:PayableAmount currencyID="USD">29.75</cbc:PayableAmount>`)
+		assert.Contains(t, xmlDoc, `<cbc:InvoicedQuantity unitCode="C62">-1</cbc:InvoicedQuantity>`)
+	})
+	t.Run("credit note for negative total", func(t *testing.T) {
+		bill := testBill(&models.LineItem{Description: "Refund", Amount: -40, Quantity: 1})
+		doc, docType, err := BuildDocument(bill, testSeller(), testBuyer())
+		assert.NoError(t, err)
+		assert.Equal(t, models.DocumentCreditNote, docType)
+		assert.NoError(t, Validate(doc))
+
+		xmlDoc := string(doc)
+		assert.Contains(t, xmlDoc, "<CreditNote xmlns=\""+creditNoteNamespace+"\"")
+		assert.Contains(t, xmlDoc, "<cbc:CreditNoteTypeCode>381</cbc:CreditNoteTypeCode>")
+		assert.Contains(t, xmlDoc, `<cbc:CreditedQuantity unitCode="C62">1</cbc:CreditedQuantity>`)
+		assert.Contains(t, xmlDoc, `<cbc:PayableAmount currencyID="USD">47.60</cbc:PayableAmount>`)
+		assert.NotContains(t, xmlDoc, "InvoiceLine")
+	})
+	t.Run("line amount from the rounded price", func(t *testing.T) {
+		// Accrued amounts have more decimals than the price can carry
+		doc, _, err := BuildDocument(testBill(&models.LineItem{Description: "Seats", Amount: 10.0 / 3, Quantity: 30}), testSeller(), testBuyer())
+		assert.NoError(t, err)
+		assert.NoError(t, Validate(doc))
+
+		xmlDoc := string(doc)
+		assert.Contains(t, xmlDoc, `<cbc:PriceAmount currencyID="USD">3.33</cbc:PriceAmount>`)
+		assert.Contains(t, xmlDoc, `<cbc:LineExtensionAmount currencyID="USD">99.90</cbc:LineExtensionAmount>`)
+	})
+	t.Run("zero rated seller", func(t *testing.T) {
+		seller := testSeller()
+		seller.VATRate = 0
+		doc, _, err := BuildDocument(testBill(&models.LineItem{Description: "Seats", Amount: 10, Quantity: 1}), seller, testBuyer())
+		assert.NoError(t, err)
+		assert.NoError(t, Validate(doc))
+		assert.Contains(t, string(doc), "<cbc:ID>Z</cbc:ID>")
+	})
+	t.Run("open bill", func(t *testing.T) {
+		bill := testBill(&models.LineItem{Description: "Seats", Amount: 10, Quantity: 1})
+		bill.Status = models.StatusOpen
+		_, _, err := BuildDocument(bill, testSeller(), testBuyer())
+		assert.Error(t, err)
+		assert.Contains(t, err.Error(), "must be closed")
+	})
+	t.Run("missing buyer endpoint", func(t *testing.T) {
+		buyer := testBuyer()
+		buyer.EndpointID = ""
+		_, _, err := BuildDocument(testBill(&models.LineItem{Description: "Seats", Amount: 10, Quantity: 1}), testSeller(), buyer)
+		assert.Error(t, err)
+		assert.Contains(t, err.Error(), "buyer legal name and endpoint_id are required")
+	})
+	t.Run("missing seller country", func(t *testing.T) {
+		seller := testSeller()
+		seller.Address.CountryCode = ""
+		_, _, err := BuildDocument(testBill(&models.LineItem{Description: "Seats", Amount: 10, Quantity: 1}), seller, testBuyer())
+		assert.Error(t, err)
+		assert.Contains(t, err.Error(), "seller country_code is required")
+	})
+}
+
+func TestValidate(t *testing.T) {
+	t.Parallel()
+	doc, _, err := BuildDocument(testBill(&models.LineItem{Description: "Seats", Amount: 10, Quantity: 2}), testSeller(), testBuyer())
+	assert.NoError(t, err)
+
+	cases := []struct {
+		name    string
+		mutate  func(string) string
+		wantErr string
+	}{
+		{
+			name: "wrong root namespace",
+			mutate: func(s string) string {
+				return strings.Replace(s, invoiceNamespace, "urn:example", 1)
+			},
+			wantErr: "unexpected root element",
+		},
+		{
+			name: "missing buyer reference",
+			mutate: func(s string) string {
+				return strings.Replace(s, "<cbc:BuyerReference>cust-1</cbc:BuyerReference>", "", 1)
+			},
+			wantErr: "missing required element cbc:BuyerReference",
+		},
+		{
+			name: "payable mismatch",
+			mutate: func(s string) string {
+				return strings.Replace(s, `<cbc:PayableAmount currencyID="USD">23.80</cbc:PayableAmount>`, `<cbc:PayableAmount currencyID="USD">1.00</cbc:PayableAmount>`, 1)
+			},
+			wantErr: "PayableAmount 1.00",
+		},
+		{
+			name: "tax inclusive mismatch",
+			mutate: func(s string) string {
+				return strings.Replace(s, `<cbc:TaxInclusiveAmount currencyID="USD">23.80</cbc:TaxInclusiveAmount>`, `<cbc:TaxInclusiveAmount currencyID="USD">20.00</cbc:TaxInclusiveAmount>`, 1)
+			},
+			wantErr: "TaxInclusiveAmount 20.00",
+		},
+		{
+			name: "currency mismatch",
+			mutate: func(s string) string {
+				return strings.Replace(s, `<cbc:TaxAmount currencyID="USD">3.80</cbc:TaxAmount>`, `<cbc:TaxAmount currencyID="GEL">3.80</cbc:TaxAmount>`, 1)
+			},
+			wantErr: "does not match document currency",
+		},
+		{
+			name: "wrong type code",
+			mutate: func(s string) string {
+				return strings.Replace(s, "<cbc:InvoiceTypeCode>380</cbc:InvoiceTypeCode>", "<cbc:InvoiceTypeCode>381</cbc:InvoiceTypeCode>", 1)
+			},
+			wantErr: "cbc:InvoiceTypeCode must be 380",
+		},
+		{
+			name: "line amount not quantity times price",
+			mutate: func(s string) string {
+				return strings.Replace(s, `<cbc:InvoicedQuantity unitCode="C62">2</cbc:InvoicedQuantity>`, `<cbc:InvoicedQuantity unitCode="C62">3</cbc:InvoicedQuantity>`, 1)
+			},
+			wantErr: "PEPPOL-EN16931-R120",
+		},
+		{
+			name: "zero base quantity",
+			mutate: func(s string) string {
+				return strings.Replace(s, `<cbc:PriceAmount currencyID="USD">10.00</cbc:PriceAmount>`, `<cbc:PriceAmount currencyID="USD">10.00</cbc:PriceAmount><cbc:BaseQuantity unitCode="C62">0</cbc:BaseQuantity>`, 1)
+			},
+			wantErr: "BaseQuantity must be a positive number",
+		},
+		{
+			name: "malformed",
+			mutate: func(s string) string {
+				return s[:len(s)/2]
+			},
+			wantErr: "malformed XML",
+		},
+	}
+	for _, tc := range cases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			err := Validate([]byte(tc.mutate(string(doc))))
+			assert.Error(t, err)
+			assert.Contains(t, err.Error(), tc.wantErr)
+		})
+	}
+}
+
+//go:generate sh testdata/fetch-ubl-schemas.sh
+
+// ublSchemaDir holds the xsd folder of the official OASIS UBL 2.1 distribution, fetched by go generate
+const ublSchemaDir = "testdata/ubl-2.1/xsd"
+
+// TestBuildDocumentSchema validates generated documents against the official UBL 2.1 schemas with xmllint.
+// It fails in CI when xmllint or the schemas are missing, and is skipped elsewhere.
+func TestBuildDocumentSchema(t *testing.T) {
+	t.Parallel()
+	xmllint, err := exec.LookPath("xmllint")
+	if err != nil {
+		missingSchemaTool(t, "xmllint is not installed")
+	}
+	if _, err := os.Stat(filepath.Join(ublSchemaDir, "maindoc")); err != nil {
+		missingSchemaTool(t, "UBL 2.1 schemas not found in %s, run go generate ./einvoice", ublSchemaDir)
+	}
+
+	bills := map[string]*models.Bill{
+		"UBL-Invoice-2.1.xsd": testBill(
+			&models.LineItem{Description: "Seats", Amount: 10.0 / 3, Quantity: 30},
+			&models.LineItem{Description: "Discount", Amount: -5, Quantity: 1},
+		),
+		"UBL-CreditNote-2.1.xsd": testBill(&models.LineItem{Description: "Refund", Amount: -40, Quantity: 1}),
+	}
+	for schema, bill := range bills {
+		doc, _, err := BuildDocument(bill, testSeller(), testBuyer())
+		assert.NoError(t, err)
+		path := filepath.Join(t.TempDir(), "document.xml")
+		assert.NoError(t, os.WriteFile(path, doc, 0o600))
+
+		out, err := exec.Command(xmllint, "--noout", "--schema", filepath.Join(ublSchemaDir, "maindoc", schema), path).CombinedOutput()
+		assert.NoError(t, err, "%s: %s", schema, out)
+	}
+}
+
+// missingSchemaTool fails the test when running in CI, where the schema check must not be skipped.
+func missingSchemaTool(t *testing.T, format string, args ...any) {
+	t.Helper()
+	if os.Getenv("CI") != "" {
+		t.Fatalf(format, args...)
+	}
+	t.Skipf(format, args...)
+}
@@ -0,0 +1,303 @@
+package einvoice
+
+import (
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// node is a namespace aware XML element tree used for validation
+type node struct {
+	name     xml.Name
+	attrs    []xml.Attr
+	value    string
+	children []*node
+}
+
+// requiredPaths lists the Peppol BIS Billing 3.0 mandatory elements shared by Invoice and CreditNote.
+// Paths are relative to the document root and use the UBL component prefixes.
+var requiredPaths = []string{
+	"cbc:CustomizationID",
+	"cbc:ProfileID",
+	"cbc:ID",
+	"cbc:IssueDate",
+	"cbc:DocumentCurrencyCode",
+	"cbc:BuyerReference",
+	"cac:AccountingSupplierParty/cac:Party/cbc:EndpointID",
+	"cac:AccountingSupplierParty/cac:Party/cac:PostalAddress/cac:Country/cbc:IdentificationCode",
+	"cac:AccountingSupplierParty/cac:Party/cac:PartyLegalEntity/cbc:RegistrationName",
+	"cac:AccountingCustomerParty/cac:Party/cbc:EndpointID",
+	"cac:AccountingCustomerParty/cac:Party/cac:PostalAddress/cac:Country/cbc:IdentificationCode",
+	"cac:AccountingCustomerParty/cac:Party/cac:PartyLegalEntity/cbc:RegistrationName",
+	"cac:TaxTotal/cbc:TaxAmount",
+	"cac:LegalMonetaryTotal/cbc:LineExtensionAmount",
+	"cac:LegalMonetaryTotal/cbc:TaxExclusiveAmount",
+	"cac:LegalMonetaryTotal/cbc:TaxInclusiveAmount",
+	"cac:LegalMonetaryTotal/cbc:PayableAmount",
+}
+
+var prefixNamespaces = map[string]string{
+	"cac": cacNamespace,
+	"cbc": cbcNamespace,
+}
+
+// Validate checks a UBL Invoice or CreditNote against the UBL 2.1 namespaces and the
+// Peppol BIS Billing 3.0 cardinality and calculation rules this service relies on
+func Validate(doc []byte) error {
+	root, err := parseTree(doc)
+	if err != nil {
+		return err
+	}
+
+	var lineName, quantityName, typeCodeName, expectedTypeCode string
+	switch root.name {
+	case xml.Name{Space: invoiceNamespace, Local: "Invoice"}:
+		lineName, quantityName, typeCodeName, expectedTypeCode = "cac:InvoiceLine", "cbc:InvoicedQuantity", "cbc:InvoiceTypeCode", invoiceTypeCode
+	case xml.Name{Space: creditNoteNamespace, Local: "CreditNote"}:
+		lineName, quantityName, typeCodeName, expectedTypeCode = "cac:CreditNoteLine", "cbc:CreditedQuantity", "cbc:CreditNoteTypeCode", creditNoteTypeCode
+	default:
+		return fmt.Errorf("unexpected root element {%s}%s", root.name.Space, root.name.Local)
+	}
+
+	for _, path := range requiredPaths {
+		if root.find(path) == nil {
+			return fmt.Errorf("missing required element %s", path)
+		}
+	}
+	if got := root.text(typeCodeName); got != expectedTypeCode {
+		return fmt.Errorf("%s must be %s, got %q", typeCodeName, expectedTypeCode, got)
+	}
+	if got := root.text("cbc:CustomizationID"); got != peppolCustomizationID {
+		return fmt.Errorf("unexpected CustomizationID %q", got)
+	}
+	if got := root.text("cbc:ProfileID"); got != peppolProfileID {
+		return fmt.Errorf("unexpected ProfileID %q", got)
+	}
+	if err := checkDate(root.text("cbc:IssueDate")); err != nil {
+		return err
+	}
+
+	currency := root.text("cbc:DocumentCurrencyCode")
+	for _, endpoint := range []string{
+		"cac:AccountingSupplierParty/cac:Party/cbc:EndpointID",
+		"cac:AccountingCustomerParty/cac:Party/cbc:EndpointID",
+	} {
+		if root.find(endpoint).attr("schemeID") == "" {
+			return fmt.Errorf("%s requires a schemeID", endpoint)
+		}
+	}
+
+	lines := root.findAll(lineName)
+	if len(lines) == 0 {
+		return fmt.Errorf("document must contain at least one %s", lineName)
+	}
+	lineSum := 0.0
+	for _, line := range lines {
+		for _, path := range []string{"cbc:ID", quantityName, "cbc:LineExtensionAmount", "cac:Item/cbc:Name", "cac:Item/cac:ClassifiedTaxCategory/cbc:ID", "cac:Price/cbc:PriceAmount"} {
+			if line.find(path) == nil {
+				return fmt.Errorf("%s is missing %s", lineName, path)
+			}
+		}
+		priceAmount, err := line.amount("cac:Price/cbc:PriceAmount", currency)
+		if err != nil {
+			return err
+		}
+		if priceAmount < 0 {
+			return fmt.Errorf("line %s has a negative price", line.text("cbc:ID"))
+		}
+		lineAmount, err := line.amount("cbc:LineExtensionAmount", currency)
+		if err != nil {
+			return err
+		}
+		if err := checkLineAmount(line, quantityName, currency, priceAmount, lineAmount); err != nil {
+			return err
+		}
+		lineSum += lineAmount
+	}
+
+	lineExtension, err := root.amount("cac:LegalMonetaryTotal/cbc:LineExtensionAmount", currency)
+	if err != nil {
+		return err
+	}
+	taxExclusive, err := root.amount("cac:LegalMonetaryTotal/cbc:TaxExclusiveAmount", currency)
+	if err != nil {
+		return err
+	}
+	taxInclusive, err := root.amount("cac:LegalMonetaryTotal/cbc:TaxInclusiveAmount", currency)
+	if err != nil {
+		return err
+	}
+	taxAmount, err := root.amount("cac:TaxTotal/cbc:TaxAmount", currency)
+	if err != nil {
+		return err
+	}
+	payable, err := root.amount("cac:LegalMonetaryTotal/cbc:PayableAmount", currency)
+	if err != nil {
+		return err
+	}
+
+	// BR-CO-10, BR-CO-15, BR-CO-16: totals must add up, no prepaid or rounding amounts are issued
+	if !amountsEqual(lineSum, lineExtension) {
+		return fmt.Errorf("sum of line amounts %.2f does not match LineExtensionAmount %.2f", lineSum, lineExtension)
+	}
+	if !amountsEqual(taxExclusive+taxAmount, taxInclusive) {
+		return fmt.Errorf("TaxInclusiveAmount %.2f does not equal TaxExclusiveAmount plus TaxAmount", taxInclusive)
+	}
+	if !amountsEqual(payable, taxInclusive) {
+		return fmt.Errorf("PayableAmount %.2f does not equal TaxInclusiveAmount %.2f", payable, taxInclusive)
+	}
+	return nil
+}
+
+// checkLineAmount applies PEPPOL-EN16931-R120: the line amount is the quantity times the price per base
+// quantity, plus the line charges and minus the line allowances, within 0.02 for rounding
+func checkLineAmount(line *node, quantityName string, currency string, priceAmount float64, lineAmount float64) error {
+	lineID := line.text("cbc:ID")
+	lineQuantity, err := strconv.ParseFloat(line.text(quantityName), 64)
+	if err != nil {
+		return fmt.Errorf("line %s %s is not a valid quantity: %w", lineID, quantityName, err)
+	}
+	baseQuantity := 1.0
+	if line.find("cac:Price/cbc:BaseQuantity") != nil {
+		// PEPPOL-EN16931-R121: the base quantity must be positive
+		baseQuantity, err = strconv.ParseFloat(line.text("cac:Price/cbc:BaseQuantity"), 64)
+		if err != nil || baseQuantity <= 0 {
+			return fmt.Errorf("line %s BaseQuantity must be a positive number", lineID)
+		}
+	}
+	expected := lineQuantity * priceAmount / baseQuantity
+	for _, allowanceCharge := range line.findAll("cac:AllowanceCharge") {
+		value, err := allowanceCharge.amount("cbc:Amount", currency)
+		if err != nil {
+			return err
+		}
+		if allowanceCharge.text("cbc:ChargeIndicator") == "true" {
+			expected += value
+		} else {
+			expected -= value
+		}
+	}
+	if math.Abs(lineAmount-expected) > 0.02 {
+		return fmt.Errorf("line %s LineExtensionAmount %.2f does not equal quantity times price %.2f (PEPPOL-EN16931-R120)", lineID, lineAmount, expected)
+	}
+	return nil
+}
+
+func parseTree(doc []byte) (*node, error) {
+	decoder := xml.NewDecoder(bytes.NewReader(doc))
+	var stack []*node
+	var root *node
+	for {
+		token, err := decoder.Token()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("malformed XML: %w", err)
+		}
+		switch t := token.(type) {
+		case xml.StartElement:
+			n := &node{name: t.Name, attrs: t.Attr}
+			if len(stack) > 0 {
+				parent := stack[len(stack)-1]
+				parent.children = append(parent.children, n)
+			} else {
+				root = n
+			}
+			stack = append(stack, n)
+		case xml.EndElement:
+			stack = stack[:len(stack)-1]
+		case xml.CharData:
+			if len(stack) > 0 {
+				stack[len(stack)-1].value += strings.TrimSpace(string(t))
+			}
+		}
+	}
+	if root == nil {
+		return nil, fmt.Errorf("document is empty")
+	}
+	return root, nil
+}
+
+func (n *node) find(path string) *node {
+	current := n
+	for _, step := range strings.Split(path, "/") {
+		var next *node
+		for _, child := range current.children {
+			if child.matches(step) {
+				next = child
+				break
+			}
+		}
+		if next == nil {
+			return nil
+		}
+		current = next
+	}
+	return current
+}
+
+func (n *node) findAll(step string) []*node {
+	var found []*node
+	for _, child := range n.children {
+		if child.matches(step) {
+			found = append(found, child)
+		}
+	}
+	return found
+}
+
+func (n *node) matches(step string) bool {
+	prefix, local, _ := strings.Cut(step, ":")
+	return n.name.Local == local && n.name.Space == prefixNamespaces[prefix]
+}
+
+func (n *node) text(path string) string {
+	if found := n.find(path); found != nil {
+		return found.value
+	}
+	return ""
+}
+
+func (n *node) attr(name string) string {
+	if n == nil {
+		return ""
+	}
+	for _, a := range n.attrs {
+		if a.Name.Local == name {
+			return a.Value
+		}
+	}
+	return ""
+}
+
+func (n *node) amount(path string, currency string) (float64, error) {
+	found := n.find(path)
+	if found == nil {
+		return 0, fmt.Errorf("missing required element %s", path)
+	}
+	if found.attr("currencyID") != currency {
+		return 0, fmt.Errorf("%s currencyID %q does not match document currency %q", path, found.attr("currencyID"), currency)
+	}
+	value, err := strconv.ParseFloat(found.value, 64)
+	if err != nil {
+		return 0, fmt.Errorf("%s is not a valid amount: %w", path, err)
+	}
+	return value, nil
+}
+
+func checkDate(value string) error {
+	if _, err := time.Parse(time.DateOnly, value); err != nil {
+		return fmt.Errorf("IssueDate %q is not a valid date", value)
+	}
+	return nil
+}
+
+func amountsEqual(a, b float64) bool {
+	return math.Abs(a-b) < 0.005
+}
@@ -0,0 +1,35 @@
+package events
+
+import (
+	"encore.dev/pubsub"
+
+	"encore.app/models"
+)
+
+// Bill lifecycle topics are published from bill workflow activities. Every event carries a deterministic
+// event_id, publishing is deduplicated on it and subscribers can use it to drop redelivered messages.
+
+// BillCreated receives an event for every bill opened in a billing period
+var BillCreated = pubsub.NewTopic[*models.BillCreatedEvent]("bill-created", pubsub.TopicConfig{
+	DeliveryGuarantee: pubsub.ExactlyOnce,
+})
+
+// LineItemAdded receives an event for every line item added to a bill
+var LineItemAdded = pubsub.NewTopic[*models.LineItemAddedEvent]("line-item-added", pubsub.TopicConfig{
+	DeliveryGuarantee: pubsub.ExactlyOnce,
+})
+
+// BillClosed receives an event with the final state of every closed bill
+var BillClosed = pubsub.NewTopic[*models.BillClosedEvent]("bill-closed", pubsub.TopicConfig{
+	DeliveryGuarantee: pubsub.ExactlyOnce,
+})
+
+// BillingPeriodClosed receives an event when a billing period has finalized all of its bills
+var BillingPeriodClosed = pubsub.NewTopic[*models.BillingPeriodClosedEvent]("billing-period-closed", pubsub.TopicConfig{
+	DeliveryGuarantee: pubsub.ExactlyOnce,
+})
+
+// SpendingAlerts receives an event each time a billing period crosses one of its spending thresholds
+var SpendingAlerts = pubsub.NewTopic[*models.SpendingAlertEvent]("spending-alerts", pubsub.TopicConfig{
+	DeliveryGuarantee: pubsub.AtLeastOnce,
+})
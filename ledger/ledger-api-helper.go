@@ -0,0 +1,89 @@
+package ledger
+
+import (
+	"fmt"
+	"time"
+
+	"encore.dev/beta/auth"
+	"encore.dev/beta/errs"
+
+	"encore.app/models"
+)
+
+func validateRecordPaymentRequest(req *models.RecordPaymentRequest) error {
+	if req.BillID == "" {
+		return fmt.Errorf("bill_id is required")
+	}
+	if req.Amount <= 0 {
+		return fmt.Errorf("amount must be positive")
+	}
+	if !req.Currency.IsValid() {
+		return fmt.Errorf("invalid currency: %s (supported: USD, GEL)", req.Currency)
+	}
+	if req.ExchangeRate < 0 {
+		return fmt.Errorf("exchange_rate must be positive")
+	}
+	return nil
+}
+
+func validateIssueCreditRequest(req *models.IssueCreditRequest) error {
+	if req.BillID == "" {
+		return fmt.Errorf("bill_id is required")
+	}
+	if req.Amount <= 0 {
+		return fmt.Errorf("amount must be positive")
+	}
+	if req.Reason == "" {
+		return fmt.Errorf("reason is required")
+	}
+	return nil
+}
+
+// validateCustomerStatementRequest parses the statement dates, an open range starts at the first entry and
+// ends today
+func validateCustomerStatementRequest(req *models.CustomerStatementRequest, now time.Time) (from, to time.Time, err error) {
+	if !req.Currency.IsValid() {
+		return from, to, fmt.Errorf("invalid currency: %s (supported: USD, GEL)", req.Currency)
+	}
+	if req.From != "" {
+		if from, err = time.Parse(time.DateOnly, req.From); err != nil {
+			return from, to, fmt.Errorf("invalid from %q, expected YYYY-MM-DD", req.From)
+		}
+	}
+	to = now.UTC().Truncate(24 * time.Hour)
+	if req.To != "" {
+		if to, err = time.Parse(time.DateOnly, req.To); err != nil {
+			return from, to, fmt.Errorf("invalid to %q, expected YYYY-MM-DD", req.To)
+		}
+	}
+	if to.Before(from) {
+		return from, to, fmt.Errorf("to must not be before from")
+	}
+	return from, to, nil
+}
+
+func validateAgingReportRequest(req *models.AgingReportRequest) error {
+	if req.Currency != "" && !req.Currency.IsValid() {
+		return fmt.Errorf("invalid currency: %s (supported: USD, GEL)", req.Currency)
+	}
+	if req.Bucket != "" && !req.Bucket.IsValid() {
+		return fmt.Errorf("invalid bucket: %s (supported: current, 1-30, 31-60, 61-90, 90+)", req.Bucket)
+	}
+	return nil
+}
+
+// authorizeCustomer checks that the calling API key may act on the customer with the given scope and
+// returns the key's tenant, the ledger of every tenant is kept apart
+func authorizeCustomer(customerID string, scope models.APIKeyScope) (string, error) {
+	data, _ := auth.Data().(*models.AuthData)
+	if data == nil {
+		return "", &errs.Error{Code: errs.Unauthenticated, Message: "missing API key"}
+	}
+	if data.TenantID == "" {
+		return "", &errs.Error{Code: errs.PermissionDenied, Message: "the operator key cannot act on the ledger, use an admin key of the tenant"}
+	}
+	if err := data.Authorize(data.TenantID, customerID, scope); err != nil {
+		return "", &errs.Error{Code: errs.PermissionDenied, Message: err.Error()}
+	}
+	return data.TenantID, nil
+}
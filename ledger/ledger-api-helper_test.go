@@ -0,0 +1,71 @@
+package ledger
+
+import (
+	"testing"
+	"time"
+
+	"encore.app/models"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestValidateRecordPaymentRequest(t *testing.T) {
+	t.Parallel()
+	cases := []struct {
+		name    string
+		req     models.RecordPaymentRequest
+		wantErr string
+	}{
+		{"valid request", models.RecordPaymentRequest{BillID: "bill-1", Amount: 10, Currency: models.USD}, ""},
+		{"missing bill", models.RecordPaymentRequest{Amount: 10, Currency: models.USD}, "bill_id is required"},
+		{"zero amount", models.RecordPaymentRequest{BillID: "bill-1", Currency: models.USD}, "amount must be positive"},
+		{"unknown currency", models.RecordPaymentRequest{BillID: "bill-1", Amount: 10, Currency: "EUR"}, "invalid currency"},
+		{"negative rate", models.RecordPaymentRequest{BillID: "bill-1", Amount: 10, Currency: models.GEL, ExchangeRate: -1}, "exchange_rate must be positive"},
+	}
+	for _, tc := range cases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+			err := validateRecordPaymentRequest(&tc.req)
+			if tc.wantErr == "" {
+				assert.NoError(t, err)
+				return
+			}
+			assert.Error(t, err)
+			assert.Contains(t, err.Error(), tc.wantErr)
+		})
+	}
+}
+
+func TestValidateIssueCreditRequest(t *testing.T) {
+	assert.NoError(t, validateIssueCreditRequest(&models.IssueCreditRequest{BillID: "bill-1", Amount: 5, Reason: "goodwill"}))
+	assert.EqualError(t, validateIssueCreditRequest(&models.IssueCreditRequest{BillID: "bill-1", Amount: 5}), "reason is required")
+	assert.EqualError(t, validateIssueCreditRequest(&models.IssueCreditRequest{BillID: "bill-1", Amount: -5, Reason: "x"}), "amount must be positive")
+}
+
+func TestValidateAgingReportRequest(t *testing.T) {
+	assert.NoError(t, validateAgingReportRequest(&models.AgingReportRequest{}))
+	assert.NoError(t, validateAgingReportRequest(&models.AgingReportRequest{Currency: models.GEL, Bucket: models.Aging31To60}))
+	assert.ErrorContains(t, validateAgingReportRequest(&models.AgingReportRequest{Currency: "EUR"}), "invalid currency")
+	assert.ErrorContains(t, validateAgingReportRequest(&models.AgingReportRequest{Bucket: "120+"}), "invalid bucket")
+}
+
+func TestValidateCustomerStatementRequest(t *testing.T) {
+	now := time.Date(2025, 3, 15, 18, 30, 0, 0, time.UTC)
+
+	from, to, err := validateCustomerStatementRequest(&models.CustomerStatementRequest{Currency: models.USD}, now)
+	assert.NoError(t, err)
+	assert.True(t, from.IsZero())
+	assert.Equal(t, time.Date(2025, 3, 15, 0, 0, 0, 0, time.UTC), to)
+
+	from, to, err = validateCustomerStatementRequest(&models.CustomerStatementRequest{From: "2025-01-01", To: "2025-01-31", Currency: models.GEL}, now)
+	assert.NoError(t, err)
+	assert.Equal(t, time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC), from)
+	assert.Equal(t, time.Date(2025, 1, 31, 0, 0, 0, 0, time.UTC), to)
+
+	_, _, err = validateCustomerStatementRequest(&models.CustomerStatementRequest{From: "2025-02-01", To: "2025-01-31", Currency: models.USD}, now)
+	assert.EqualError(t, err, "to must not be before from")
+	_, _, err = validateCustomerStatementRequest(&models.CustomerStatementRequest{From: "01/02/2025", Currency: models.USD}, now)
+	assert.ErrorContains(t, err, "expected YYYY-MM-DD")
+	_, _, err = validateCustomerStatementRequest(&models.CustomerStatementRequest{}, now)
+	assert.ErrorContains(t, err, "invalid currency")
+}
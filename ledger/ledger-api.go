@@ -0,0 +1,230 @@
+package ledger
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"encore.dev/beta/errs"
+	"encore.dev/rlog"
+	"github.com/google/uuid"
+
+	"encore.app/models"
+)
+
+// RecordPayment books a payment received against a closed bill, clearing its receivable
+//
+//encore:api auth method=POST path=/ledger/payments/:customerId
+func RecordPayment(ctx context.Context, customerId string, req *models.RecordPaymentRequest) (*models.JournalEntryResponse, error) {
+	tenantID, err := authorizeCustomer(customerId, models.ScopeAdmin)
+	if err != nil {
+		return nil, err
+	}
+	if err := validateRecordPaymentRequest(req); err != nil {
+		return nil, models.InvalidRequest(err)
+	}
+	billEntry, err := findBillEntry(ctx, tenantID, customerId, req.BillID)
+	if err != nil {
+		return nil, err
+	}
+
+	paymentID := req.PaymentID
+	if paymentID == "" {
+		paymentID = uuid.New().String()
+	}
+	postedAt := req.ReceivedAt
+	if postedAt.IsZero() {
+		postedAt = time.Now()
+	}
+	entry := models.NewPaymentEntry(tenantID, customerId, paymentID, req, billEntry.Currency, postedAt)
+	return postAgainstBill(ctx, billEntry, entry)
+}
+
+// IssueCredit books a credit note reducing what the customer owes on a closed bill
+//
+//encore:api auth method=POST path=/ledger/credits/:customerId
+func IssueCredit(ctx context.Context, customerId string, req *models.IssueCreditRequest) (*models.JournalEntryResponse, error) {
+	tenantID, err := authorizeCustomer(customerId, models.ScopeAdmin)
+	if err != nil {
+		return nil, err
+	}
+	if err := validateIssueCreditRequest(req); err != nil {
+		return nil, models.InvalidRequest(err)
+	}
+	billEntry, err := findBillEntry(ctx, tenantID, customerId, req.BillID)
+	if err != nil {
+		return nil, err
+	}
+
+	creditID := req.CreditID
+	if creditID == "" {
+		creditID = uuid.New().String()
+	}
+	entry := models.NewCreditEntry(creditID, req, billEntry, time.Now())
+	return postAgainstBill(ctx, billEntry, entry)
+}
+
+// GetTrialBalance returns the debit and credit totals of every account of the tenant, per currency
+//
+//encore:api auth method=GET path=/ledger/trialBalance
+func GetTrialBalance(ctx context.Context, req *models.TrialBalanceRequest) (*models.TrialBalanceResponse, error) {
+	tenantID, err := authorizeCustomer("", models.ScopeAdmin)
+	if err != nil {
+		return nil, err
+	}
+	if req.Currency != "" && !req.Currency.IsValid() {
+		return nil, models.NewAPIError(errs.InvalidArgument, models.ReasonInvalidRequest, "invalid currency: %s (supported: USD, GEL)", req.Currency)
+	}
+	balances, err := trialBalances(ctx, tenantID, req.Currency)
+	if err != nil {
+		return nil, err
+	}
+	return &models.TrialBalanceResponse{TenantID: tenantID, Balances: balances}, nil
+}
+
+// GetAccountStatement returns the customer's movements on an account with the running balance
+//
+//encore:api auth method=GET path=/ledger/statements/:customerId/:account
+func GetAccountStatement(ctx context.Context, customerId string, account string, req *models.AccountStatementRequest) (*models.AccountStatementResponse, error) {
+	tenantID, err := authorizeCustomer(customerId, models.ScopeRead)
+	if err != nil {
+		return nil, err
+	}
+	ledgerAccount := models.LedgerAccount(account)
+	if !ledgerAccount.IsValid() {
+		return nil, models.NewAPIError(errs.InvalidArgument, models.ReasonInvalidRequest, "unknown ledger account: %s", account)
+	}
+	if !req.Currency.IsValid() {
+		return nil, models.NewAPIError(errs.InvalidArgument, models.ReasonInvalidRequest, "invalid currency: %s (supported: USD, GEL)", req.Currency)
+	}
+	lines, err := statementLines(ctx, tenantID, customerId, ledgerAccount, req.Currency)
+	if err != nil {
+		return nil, err
+	}
+	return models.NewAccountStatement(customerId, ledgerAccount, req.Currency, lines), nil
+}
+
+// GetCustomerStatement returns the customer's statement of account over a date range: every booked bill,
+// payment and credit between the opening and closing balance, across all billing periods
+//
+//encore:api auth method=GET path=/ledger/customers/:customerId/statement
+func GetCustomerStatement(ctx context.Context, customerId string, req *models.CustomerStatementRequest) (*models.CustomerStatement, error) {
+	tenantID, err := authorizeCustomer(customerId, models.ScopeRead)
+	if err != nil {
+		return nil, err
+	}
+	from, to, err := validateCustomerStatementRequest(req, time.Now())
+	if err != nil {
+		return nil, models.InvalidRequest(err)
+	}
+	// The range is inclusive of the last day
+	end := to.AddDate(0, 0, 1)
+	opening, err := receivableBalance(ctx, tenantID, customerId, req.Currency, from)
+	if err != nil {
+		return nil, err
+	}
+	lines, err := receivableLines(ctx, tenantID, customerId, req.Currency, from, end)
+	if err != nil {
+		return nil, err
+	}
+	return models.NewCustomerStatement(customerId, req.Currency, from, to, opening, lines), nil
+}
+
+// GetAgingReport buckets the tenant's unpaid closed bills by days past due, in the reporting currency.
+// The customer_id and bucket filters drill down to the bills behind a figure.
+//
+//encore:api auth method=GET path=/ledger/aging
+func GetAgingReport(ctx context.Context, req *models.AgingReportRequest) (*models.AgingReport, error) {
+	tenantID, err := authorizeCustomer(req.CustomerID, models.ScopeAdmin)
+	if err != nil {
+		return nil, err
+	}
+	if err := validateAgingReportRequest(req); err != nil {
+		return nil, models.InvalidRequest(err)
+	}
+	return agingReport(ctx, tenantID, req)
+}
+
+// ExportAgingReport returns the aging report as CSV, one row per unpaid bill
+//
+//encore:api auth method=GET path=/ledger/aging/export
+func ExportAgingReport(ctx context.Context, req *models.AgingReportRequest) (*models.AgingExportResponse, error) {
+	tenantID, err := authorizeCustomer(req.CustomerID, models.ScopeAdmin)
+	if err != nil {
+		return nil, err
+	}
+	if err := validateAgingReportRequest(req); err != nil {
+		return nil, models.InvalidRequest(err)
+	}
+	report, err := agingReport(ctx, tenantID, req)
+	if err != nil {
+		return nil, err
+	}
+	document, err := report.CSV()
+	if err != nil {
+		return nil, err
+	}
+	return &models.AgingExportResponse{
+		FileName: fmt.Sprintf("aging-%s-%s.csv", tenantID, report.AsOf.UTC().Format(time.DateOnly)),
+		CSV:      document,
+	}, nil
+}
+
+// PostEntry posts a balanced journal entry produced by another service, posting an entry ID twice keeps the first
+//
+//encore:api private method=POST path=/ledger/entries
+func PostEntry(ctx context.Context, entry *models.JournalEntry) error {
+	if err := entry.Validate(); err != nil {
+		return models.InvalidRequest(err)
+	}
+	inserted, err := insertEntry(ctx, entry)
+	if err != nil {
+		return err
+	}
+	if inserted {
+		rlog.Info("posted journal entry", "entry_id", entry.EntryID, "kind", entry.Kind, "bill_id", entry.BillID)
+	}
+	return nil
+}
+
+// findBillEntry returns the entry booking the customer's closed bill, payments and credits are posted against it
+func findBillEntry(ctx context.Context, tenantID, customerID, billID string) (*models.JournalEntry, error) {
+	entry, err := findEntry(ctx, models.NewBillClosedEntryID(billID))
+	if errors.Is(err, errEntryNotFound) || (err == nil && (entry.TenantID != tenantID || entry.CustomerID != customerID)) {
+		return nil, models.NewAPIError(errs.FailedPrecondition, models.ReasonBillNotBooked, "bill %s has not been booked, only closed bills can be paid or credited", billID)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return entry, nil
+}
+
+func postAgainstBill(ctx context.Context, billEntry *models.JournalEntry, entry *models.JournalEntry) (*models.JournalEntryResponse, error) {
+	if err := entry.Validate(); err != nil {
+		return nil, models.InvalidRequest(err)
+	}
+	entry, outstanding, err := postAgainstReceivable(ctx, billEntry.EntryID, entry)
+	if err != nil {
+		return nil, err
+	}
+	rlog.Info("posted journal entry",
+		"entry_id", entry.EntryID,
+		"kind", entry.Kind,
+		"bill_id", entry.BillID,
+		"outstanding", outstanding,
+	)
+	return &models.JournalEntryResponse{Entry: entry, Outstanding: outstanding}, nil
+}
+
+func agingReport(ctx context.Context, tenantID string, req *models.AgingReportRequest) (*models.AgingReport, error) {
+	bills, err := receivableBills(ctx, tenantID, req.CustomerID)
+	if err != nil {
+		return nil, err
+	}
+	currency := req.Currency
+	if currency == "" {
+		currency = models.USD
+	}
+	return models.NewAgingReport(tenantID, currency, req.Bucket, bills, time.Now()), nil
+}
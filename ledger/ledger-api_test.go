@@ -0,0 +1,169 @@
+package ledger
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"encore.app/models"
+	"encore.dev/beta/auth"
+	"encore.dev/beta/errs"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+)
+
+// adminContext authenticates calls with an admin API key of the default tenant
+func adminContext() context.Context {
+	return auth.WithContext(context.Background(), "test-admin", &models.AuthData{
+		KeyID:    "test-admin",
+		TenantID: models.DefaultTenantID,
+		Scopes:   []models.APIKeyScope{models.ScopeAdmin},
+	})
+}
+
+// customerContext authenticates calls with a read API key bound to the customer of the default tenant
+func customerContext(customerId string) context.Context {
+	return auth.WithContext(context.Background(), auth.UID("test-"+customerId), &models.AuthData{
+		KeyID:      "test-" + customerId,
+		TenantID:   models.DefaultTenantID,
+		CustomerID: customerId,
+		Scopes:     []models.APIKeyScope{models.ScopeRead},
+	})
+}
+
+func TestLedger(t *testing.T) {
+	customerId := uuid.New().String()
+	billId := uuid.New().String()
+	closedBill := &models.BillClosedEvent{
+		EventID:    models.BillClosedEventID(billId),
+		TenantID:   models.DefaultTenantID,
+		CustomerID: customerId,
+		Bill: &models.Bill{
+			ID:          billId,
+			Status:      models.StatusClosed,
+			Currency:    models.USD,
+			TotalAmount: 100,
+			ClosedAt:    time.Now(),
+		},
+	}
+
+	t.Run("Closed Bills Are Booked Once", func(t *testing.T) {
+		assert.NoError(t, bookClosedBill(context.Background(), closedBill))
+		assert.NoError(t, bookClosedBill(context.Background(), closedBill))
+
+		statement, err := GetAccountStatement(customerContext(customerId), customerId, string(models.AccountsReceivable), &models.AccountStatementRequest{Currency: models.USD})
+		assert.NoError(t, err)
+		assert.Len(t, statement.Lines, 1)
+		assert.Equal(t, 100.0, statement.Balance)
+	})
+
+	t.Run("Payments And Credits Clear The Receivable", func(t *testing.T) {
+		resp, err := RecordPayment(adminContext(), customerId, &models.RecordPaymentRequest{
+			PaymentID: "pay-" + billId, BillID: billId, Amount: 60, Currency: models.USD,
+		})
+		assert.NoError(t, err)
+		assert.Equal(t, 40.0, resp.Outstanding)
+
+		// Recording the same payment again does not clear it twice
+		resp, err = RecordPayment(adminContext(), customerId, &models.RecordPaymentRequest{
+			PaymentID: "pay-" + billId, BillID: billId, Amount: 60, Currency: models.USD,
+		})
+		assert.NoError(t, err)
+		assert.Equal(t, 40.0, resp.Outstanding)
+
+		// Reusing the payment ID for another amount is rejected
+		_, err = RecordPayment(adminContext(), customerId, &models.RecordPaymentRequest{
+			PaymentID: "pay-" + billId, BillID: billId, Amount: 30, Currency: models.USD,
+		})
+		assert.Equal(t, errs.AlreadyExists, errs.Code(err))
+
+		_, err = IssueCredit(adminContext(), customerId, &models.IssueCreditRequest{BillID: billId, Amount: 50, Reason: "goodwill"})
+		assert.Error(t, err)
+		assert.Contains(t, err.Error(), "exceeds the 40.00 outstanding")
+
+		resp, err = IssueCredit(adminContext(), customerId, &models.IssueCreditRequest{BillID: billId, Amount: 40, Reason: "goodwill"})
+		assert.NoError(t, err)
+		assert.Equal(t, 0.0, resp.Outstanding)
+
+		statement, err := GetAccountStatement(customerContext(customerId), customerId, string(models.AccountsReceivable), &models.AccountStatementRequest{Currency: models.USD})
+		assert.NoError(t, err)
+		assert.Len(t, statement.Lines, 3)
+		assert.Equal(t, 0.0, statement.Balance)
+	})
+
+	t.Run("Statement Carries The Balance Across The Range", func(t *testing.T) {
+		today := time.Now().UTC().Format(time.DateOnly)
+		statement, err := GetCustomerStatement(customerContext(customerId), customerId, &models.CustomerStatementRequest{From: today, Currency: models.USD})
+		assert.NoError(t, err)
+		assert.Equal(t, 0.0, statement.OpeningBalance)
+		assert.Len(t, statement.Lines, 3)
+		assert.Equal(t, 100.0, statement.Charged)
+		assert.Equal(t, 60.0, statement.Paid)
+		assert.Equal(t, 40.0, statement.Credited)
+		assert.Equal(t, 0.0, statement.ClosingBalance)
+
+		yesterday := time.Now().UTC().AddDate(0, 0, -1).Format(time.DateOnly)
+		statement, err = GetCustomerStatement(customerContext(customerId), customerId, &models.CustomerStatementRequest{To: yesterday, Currency: models.USD})
+		assert.NoError(t, err)
+		assert.Empty(t, statement.Lines)
+	})
+
+	t.Run("Trial Balance Is Balanced", func(t *testing.T) {
+		resp, err := GetTrialBalance(adminContext(), &models.TrialBalanceRequest{Currency: models.USD})
+		assert.NoError(t, err)
+		assert.Len(t, resp.Balances, 1)
+		assert.True(t, resp.Balances[0].Balanced)
+	})
+
+	t.Run("Only Booked Bills Of The Customer Can Be Paid", func(t *testing.T) {
+		_, err := RecordPayment(adminContext(), uuid.New().String(), &models.RecordPaymentRequest{BillID: billId, Amount: 1, Currency: models.USD})
+		assert.Error(t, err)
+		assert.Contains(t, err.Error(), "has not been booked")
+
+		_, err = RecordPayment(customerContext(customerId), customerId, &models.RecordPaymentRequest{BillID: billId, Amount: 1, Currency: models.USD})
+		assert.Error(t, err)
+		assert.Contains(t, err.Error(), "lacks the admin scope")
+	})
+}
+
+func TestAgingReport(t *testing.T) {
+	customerId := uuid.New().String()
+	for _, bill := range []*models.Bill{
+		{ID: uuid.New().String(), Status: models.StatusClosed, Currency: models.USD, TotalAmount: 80, ClosedAt: time.Now()},
+		{ID: uuid.New().String(), Status: models.StatusClosed, Currency: models.GEL, TotalAmount: 100, ClosedAt: time.Now().AddDate(0, 0, -45)},
+	} {
+		assert.NoError(t, bookClosedBill(context.Background(), &models.BillClosedEvent{
+			EventID:    models.BillClosedEventID(bill.ID),
+			TenantID:   models.DefaultTenantID,
+			CustomerID: customerId,
+			Bill:       bill,
+		}))
+	}
+
+	t.Run("Buckets Unpaid Bills In The Reporting Currency", func(t *testing.T) {
+		report, err := GetAgingReport(adminContext(), &models.AgingReportRequest{CustomerID: customerId})
+		assert.NoError(t, err)
+		assert.Equal(t, models.USD, report.Currency)
+		assert.Len(t, report.Bills, 2)
+		assert.Equal(t, 80.0, report.Buckets[0].Amount)
+		assert.Equal(t, 40.0, report.Buckets[2].Amount)
+		assert.Equal(t, 120.0, report.Total)
+	})
+
+	t.Run("Drills Down And Exports", func(t *testing.T) {
+		req := &models.AgingReportRequest{CustomerID: customerId, Bucket: models.Aging31To60, Currency: models.GEL}
+		report, err := GetAgingReport(adminContext(), req)
+		assert.NoError(t, err)
+		assert.Len(t, report.Bills, 1)
+		assert.Equal(t, 45, report.Bills[0].DaysOverdue)
+
+		export, err := ExportAgingReport(adminContext(), req)
+		assert.NoError(t, err)
+		assert.Contains(t, export.CSV, ",31-60,GEL,100.00,100.00")
+	})
+
+	t.Run("Customers Cannot Read The Aging Report", func(t *testing.T) {
+		_, err := GetAgingReport(customerContext(customerId), &models.AgingReportRequest{CustomerID: customerId})
+		assert.Error(t, err)
+	})
+}
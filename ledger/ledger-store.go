@@ -0,0 +1,305 @@
+package ledger
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"encore.dev/beta/errs"
+	"encore.dev/storage/sqldb"
+
+	"encore.app/models"
+)
+
+// Journal entries live in their own database, entries and lines can only be inserted
+var db = sqldb.NewDatabase("ledger", sqldb.DatabaseConfig{
+	Migrations: "./migrations",
+})
+
+// errEntryNotFound is returned when a journal entry does not exist
+var errEntryNotFound = errors.New("journal entry not found")
+
+type queryer interface {
+	Query(ctx context.Context, query string, args ...interface{}) (*sqldb.Rows, error)
+	QueryRow(ctx context.Context, query string, args ...interface{}) *sqldb.Row
+}
+
+const entryColumns = `entry_id, tenant_id, customer_id, bill_id, kind, currency, memo, posted_at, due_at`
+
+// insertEntry posts a balanced entry. An entry whose ID is already posted is kept as it was and
+// reported with inserted set to false.
+func insertEntry(ctx context.Context, entry *models.JournalEntry) (inserted bool, err error) {
+	tx, err := db.Begin(ctx)
+	if err != nil {
+		return false, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	if inserted, err = insertEntryTx(ctx, tx, entry); err != nil || !inserted {
+		return inserted, err
+	}
+	if err := tx.Commit(); err != nil {
+		return false, fmt.Errorf("failed to commit journal entry: %w", err)
+	}
+	return true, nil
+}
+
+// postAgainstReceivable posts a payment or credit reducing the receivable of a bill. The bill's entry is
+// locked so concurrent postings cannot clear more than is outstanding. It returns the entry posted and what
+// is left to collect. An entry already posted under the same ID is returned as it was stored, unless it
+// posts something else.
+func postAgainstReceivable(ctx context.Context, billEntryID string, entry *models.JournalEntry) (*models.JournalEntry, float64, error) {
+	tx, err := db.Begin(ctx)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	_, err = tx.Exec(ctx, `SELECT entry_id FROM journal_entries WHERE entry_id = $1 FOR UPDATE`, billEntryID)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to lock bill entry: %w", err)
+	}
+	outstanding, err := billReceivable(ctx, tx, entry.TenantID, entry.BillID)
+	if err != nil {
+		return nil, 0, err
+	}
+	inserted, err := insertEntryTx(ctx, tx, entry)
+	if err != nil {
+		return nil, 0, err
+	}
+	if !inserted {
+		stored, err := findEntry(ctx, entry.EntryID)
+		if err != nil {
+			return nil, 0, err
+		}
+		if !stored.SamePosting(entry) {
+			return nil, 0, models.NewAPIError(errs.AlreadyExists, models.ReasonEntryExists,
+				"entry %s was already posted with a different amount or currency", entry.EntryID)
+		}
+		// Posted by an earlier request against this bill, whose lock orders it before the sum above
+		return stored, outstanding, nil
+	}
+	cleared := -entry.AccountTotal(models.AccountsReceivable)
+	if cleared > outstanding+0.005 {
+		return nil, 0, models.NewAPIError(errs.FailedPrecondition, models.ReasonExceedsOutstanding,
+			"%.2f %s exceeds the %.2f outstanding on bill %s", cleared, entry.Currency, outstanding, entry.BillID)
+	}
+	if err := tx.Commit(); err != nil {
+		return nil, 0, fmt.Errorf("failed to commit journal entry: %w", err)
+	}
+	return entry, models.RoundAmount(outstanding - cleared), nil
+}
+
+func insertEntryTx(ctx context.Context, tx *sqldb.Tx, entry *models.JournalEntry) (bool, error) {
+	result, err := tx.Exec(ctx, `
+		INSERT INTO journal_entries (`+entryColumns+`)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+		ON CONFLICT (entry_id) DO NOTHING
+	`, entry.EntryID, entry.TenantID, entry.CustomerID, entry.BillID, string(entry.Kind), string(entry.Currency), entry.Memo,
+		entry.PostedAt, entry.DueAt)
+	if err != nil {
+		return false, fmt.Errorf("failed to insert journal entry: %w", err)
+	}
+	if result.RowsAffected() == 0 {
+		return false, nil
+	}
+	for index, line := range entry.Lines {
+		_, err := tx.Exec(ctx, `
+			INSERT INTO journal_lines (entry_id, line_no, account, debit, credit)
+			VALUES ($1, $2, $3, $4, $5)
+		`, entry.EntryID, index+1, string(line.Account), line.Debit, line.Credit)
+		if err != nil {
+			return false, fmt.Errorf("failed to insert journal line: %w", err)
+		}
+	}
+	return true, nil
+}
+
+// billReceivable returns what is still to be collected on the bill
+func billReceivable(ctx context.Context, q queryer, tenantID, billID string) (float64, error) {
+	var receivable float64
+	err := q.QueryRow(ctx, `
+		SELECT COALESCE(SUM(l.debit - l.credit), 0)
+		FROM journal_lines l
+		JOIN journal_entries e ON e.entry_id = l.entry_id
+		WHERE e.tenant_id = $1 AND e.bill_id = $2 AND l.account = $3
+	`, tenantID, billID, string(models.AccountsReceivable)).Scan(&receivable)
+	if err != nil {
+		return 0, fmt.Errorf("failed to sum bill receivable: %w", err)
+	}
+	return models.RoundAmount(receivable), nil
+}
+
+func findEntry(ctx context.Context, entryID string) (*models.JournalEntry, error) {
+	var entry models.JournalEntry
+	var kind, currency string
+	err := db.QueryRow(ctx, `
+		SELECT `+entryColumns+`
+		FROM journal_entries
+		WHERE entry_id = $1
+	`, entryID).Scan(&entry.EntryID, &entry.TenantID, &entry.CustomerID, &entry.BillID, &kind, &currency, &entry.Memo,
+		&entry.PostedAt, &entry.DueAt)
+	if errors.Is(err, sqldb.ErrNoRows) {
+		return nil, errEntryNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to load journal entry: %w", err)
+	}
+	entry.Kind, entry.Currency = models.JournalEntryKind(kind), models.Currency(currency)
+
+	rows, err := db.Query(ctx, `
+		SELECT account, debit, credit FROM journal_lines WHERE entry_id = $1 ORDER BY line_no
+	`, entryID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load journal lines: %w", err)
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var line models.JournalLine
+		var account string
+		if err := rows.Scan(&account, &line.Debit, &line.Credit); err != nil {
+			return nil, fmt.Errorf("failed to scan journal line: %w", err)
+		}
+		line.Account = models.LedgerAccount(account)
+		entry.Lines = append(entry.Lines, line)
+	}
+	return &entry, rows.Err()
+}
+
+// trialBalances totals every account of the tenant per currency, or of one currency when set
+func trialBalances(ctx context.Context, tenantID string, currency models.Currency) ([]*models.TrialBalance, error) {
+	rows, err := db.Query(ctx, `
+		SELECT e.currency, l.account, SUM(l.debit), SUM(l.credit)
+		FROM journal_lines l
+		JOIN journal_entries e ON e.entry_id = l.entry_id
+		WHERE e.tenant_id = $1 AND ($2 = '' OR e.currency = $2)
+		GROUP BY e.currency, l.account
+		ORDER BY e.currency, l.account
+	`, tenantID, string(currency))
+	if err != nil {
+		return nil, fmt.Errorf("failed to total accounts: %w", err)
+	}
+	defer rows.Close()
+
+	balances := make([]*models.TrialBalance, 0)
+	var lines []*models.TrialBalanceLine
+	var current string
+	for rows.Next() {
+		var rowCurrency, account string
+		var line models.TrialBalanceLine
+		if err := rows.Scan(&rowCurrency, &account, &line.Debit, &line.Credit); err != nil {
+			return nil, fmt.Errorf("failed to scan account total: %w", err)
+		}
+		if rowCurrency != current && lines != nil {
+			balances = append(balances, models.NewTrialBalance(models.Currency(current), lines))
+			lines = nil
+		}
+		current = rowCurrency
+		line.Account = models.LedgerAccount(account)
+		line.Debit, line.Credit = models.RoundAmount(line.Debit), models.RoundAmount(line.Credit)
+		lines = append(lines, &line)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	if lines != nil {
+		balances = append(balances, models.NewTrialBalance(models.Currency(current), lines))
+	}
+	return balances, nil
+}
+
+// statementLines returns the customer's lines on the account in one currency, oldest first
+func statementLines(ctx context.Context, tenantID, customerID string, account models.LedgerAccount, currency models.Currency) ([]*models.AccountStatementLine, error) {
+	rows, err := db.Query(ctx, `
+		SELECT e.entry_id, e.bill_id, e.kind, e.memo, l.debit, l.credit, e.posted_at
+		FROM journal_lines l
+		JOIN journal_entries e ON e.entry_id = l.entry_id
+		WHERE e.tenant_id = $1 AND e.customer_id = $2 AND l.account = $3 AND e.currency = $4
+		ORDER BY e.posted_at, e.entry_id, l.line_no
+	`, tenantID, customerID, string(account), string(currency))
+	if err != nil {
+		return nil, fmt.Errorf("failed to list account lines: %w", err)
+	}
+	return scanStatementLines(rows)
+}
+
+func scanStatementLines(rows *sqldb.Rows) ([]*models.AccountStatementLine, error) {
+	defer rows.Close()
+
+	lines := make([]*models.AccountStatementLine, 0)
+	for rows.Next() {
+		var line models.AccountStatementLine
+		var kind string
+		if err := rows.Scan(&line.EntryID, &line.BillID, &kind, &line.Memo, &line.Debit, &line.Credit, &line.PostedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan account line: %w", err)
+		}
+		line.Kind = models.JournalEntryKind(kind)
+		lines = append(lines, &line)
+	}
+	return lines, rows.Err()
+}
+
+// receivableBalance returns what the customer owed in the currency before the given time
+func receivableBalance(ctx context.Context, tenantID, customerID string, currency models.Currency, before time.Time) (float64, error) {
+	var balance float64
+	err := db.QueryRow(ctx, `
+		SELECT COALESCE(SUM(l.debit - l.credit), 0)
+		FROM journal_lines l
+		JOIN journal_entries e ON e.entry_id = l.entry_id
+		WHERE e.tenant_id = $1 AND e.customer_id = $2 AND l.account = $3 AND e.currency = $4 AND e.posted_at < $5
+	`, tenantID, customerID, string(models.AccountsReceivable), string(currency), before).Scan(&balance)
+	if err != nil {
+		return 0, fmt.Errorf("failed to sum receivable balance: %w", err)
+	}
+	return balance, nil
+}
+
+// receivableLines returns the customer's receivable lines in the currency posted in [from, to), oldest first
+func receivableLines(ctx context.Context, tenantID, customerID string, currency models.Currency, from, to time.Time) ([]*models.AccountStatementLine, error) {
+	rows, err := db.Query(ctx, `
+		SELECT e.entry_id, e.bill_id, e.kind, e.memo, l.debit, l.credit, e.posted_at
+		FROM journal_lines l
+		JOIN journal_entries e ON e.entry_id = l.entry_id
+		WHERE e.tenant_id = $1 AND e.customer_id = $2 AND l.account = $3 AND e.currency = $4
+			AND e.posted_at >= $5 AND e.posted_at < $6
+		ORDER BY e.posted_at, e.entry_id, l.line_no
+	`, tenantID, customerID, string(models.AccountsReceivable), string(currency), from, to)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list receivable lines: %w", err)
+	}
+	return scanStatementLines(rows)
+}
+
+// receivableBills returns the tenant's booked bills with an amount left to collect, of one customer when set.
+// Bills booked before due dates were recorded are due when they closed.
+func receivableBills(ctx context.Context, tenantID, customerID string) ([]*models.ReceivableBill, error) {
+	rows, err := db.Query(ctx, `
+		SELECT b.customer_id, b.bill_id, b.currency, b.posted_at, COALESCE(b.due_at, b.posted_at),
+			SUM(l.debit - l.credit)
+		FROM journal_entries b
+		JOIN journal_entries e ON e.tenant_id = b.tenant_id AND e.bill_id = b.bill_id
+		JOIN journal_lines l ON l.entry_id = e.entry_id AND l.account = $2
+		WHERE b.tenant_id = $1 AND b.kind = $3 AND ($4 = '' OR b.customer_id = $4)
+		GROUP BY b.customer_id, b.bill_id, b.currency, b.posted_at, b.due_at
+		HAVING SUM(l.debit - l.credit) > 0.005
+		ORDER BY COALESCE(b.due_at, b.posted_at), b.bill_id
+	`, tenantID, string(models.AccountsReceivable), string(models.JournalBillClosed), customerID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list receivable bills: %w", err)
+	}
+	defer rows.Close()
+
+	bills := make([]*models.ReceivableBill, 0)
+	for rows.Next() {
+		var bill models.ReceivableBill
+		var currency string
+		if err := rows.Scan(&bill.CustomerID, &bill.BillID, &currency, &bill.ClosedAt, &bill.DueAt, &bill.Outstanding); err != nil {
+			return nil, fmt.Errorf("failed to scan receivable bill: %w", err)
+		}
+		bill.Currency = models.Currency(currency)
+		bill.Outstanding = models.RoundAmount(bill.Outstanding)
+		bills = append(bills, &bill)
+	}
+	return bills, rows.Err()
+}
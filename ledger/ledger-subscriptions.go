@@ -0,0 +1,64 @@
+package ledger
+
+import (
+	"context"
+
+	"encore.dev/pubsub"
+	"encore.dev/rlog"
+
+	"encore.app/bills"
+	"encore.app/customers"
+	"encore.app/events"
+	"encore.app/models"
+)
+
+// Closed bills are booked as receivables as soon as the bill workflow announces them
+var _ = pubsub.NewSubscription(events.BillClosed, "ledger-bill-closed", pubsub.SubscriptionConfig[*models.BillClosedEvent]{
+	Handler: bookClosedBill,
+})
+
+// bookClosedBill posts the receivable, revenue and VAT of a closed bill. Redelivered events find the entry
+// already posted and leave it untouched.
+func bookClosedBill(ctx context.Context, event *models.BillClosedEvent) error {
+	tenantID := event.TenantID
+	if tenantID == "" {
+		tenantID = models.DefaultTenantID
+	}
+	vatRate := 0.0
+	seller, err := bills.FindLegalEntity(ctx, tenantID)
+	if err != nil {
+		return err
+	}
+	if seller.LegalEntity != nil {
+		vatRate = seller.LegalEntity.VATRate
+	}
+
+	entry := models.NewBillClosedEntry(tenantID, event.CustomerID, event.Bill, vatRate, event.Bill.ClosedAt)
+	if entry == nil {
+		rlog.Info("skipped booking bill with nothing to collect", "bill_id", event.Bill.ID)
+		return nil
+	}
+	if err := entry.Validate(); err != nil {
+		return err
+	}
+	dueAt := event.Bill.ClosedAt.AddDate(0, 0, paymentTermsDays(ctx, tenantID, event.CustomerID))
+	entry.DueAt = &dueAt
+	inserted, err := insertEntry(ctx, entry)
+	if err != nil {
+		return err
+	}
+	if inserted {
+		rlog.Info("booked closed bill", "entry_id", entry.EntryID, "tenant_id", tenantID, "customer_id", event.CustomerID)
+	}
+	return nil
+}
+
+// paymentTermsDays returns the customer's payment terms, bills of customers without a profile are due on close
+func paymentTermsDays(ctx context.Context, tenantID string, customerID string) int {
+	customer, err := customers.FindCustomer(ctx, tenantID, customerID)
+	if err != nil {
+		rlog.Warn("no customer profile, the bill is due on close", "customer_id", customerID, "error", err)
+		return 0
+	}
+	return customer.Customer.PaymentTermsDays
+}
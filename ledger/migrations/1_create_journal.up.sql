@@ -0,0 +1,39 @@
+CREATE TABLE journal_entries (
+    entry_id    TEXT PRIMARY KEY,
+    tenant_id   TEXT NOT NULL,
+    customer_id TEXT NOT NULL,
+    bill_id     TEXT NOT NULL,
+    kind        TEXT NOT NULL,
+    currency    TEXT NOT NULL,
+    memo        TEXT NOT NULL DEFAULT '',
+    posted_at   TIMESTAMPTZ NOT NULL
+);
+
+CREATE INDEX journal_entries_customer_idx ON journal_entries (tenant_id, customer_id, posted_at);
+CREATE INDEX journal_entries_bill_idx ON journal_entries (tenant_id, bill_id);
+
+CREATE TABLE journal_lines (
+    entry_id TEXT NOT NULL REFERENCES journal_entries (entry_id),
+    line_no  INT NOT NULL,
+    account  TEXT NOT NULL,
+    debit    DOUBLE PRECISION NOT NULL DEFAULT 0 CHECK (debit >= 0),
+    credit   DOUBLE PRECISION NOT NULL DEFAULT 0 CHECK (credit >= 0),
+    PRIMARY KEY (entry_id, line_no)
+);
+
+CREATE INDEX journal_lines_account_idx ON journal_lines (account);
+
+-- Posted entries are corrected with new entries, never edited
+CREATE FUNCTION reject_journal_change() RETURNS TRIGGER AS $$
+BEGIN
+    RAISE EXCEPTION 'journal entries are immutable';
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER journal_entries_immutable
+    BEFORE UPDATE OR DELETE ON journal_entries
+    FOR EACH ROW EXECUTE FUNCTION reject_journal_change();
+
+CREATE TRIGGER journal_lines_immutable
+    BEFORE UPDATE OR DELETE ON journal_lines
+    FOR EACH ROW EXECUTE FUNCTION reject_journal_change();
@@ -0,0 +1,4 @@
+-- When a closed bill is to be paid, from the customer's payment terms at the time it was booked
+ALTER TABLE journal_entries ADD COLUMN due_at TIMESTAMPTZ;
+
+CREATE INDEX journal_entries_receivable_idx ON journal_entries (tenant_id, kind, due_at);
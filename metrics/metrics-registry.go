@@ -0,0 +1,307 @@
+// Package metrics keeps the counters, gauges and histograms of the process and exposes them in the
+// Prometheus text format. Temporal SDK metrics reach the same registry through NewTemporalHandler.
+package metrics
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"math"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// ContentType is the Prometheus text exposition format written by WriteText
+const ContentType = "text/plain; version=0.0.4; charset=utf-8"
+
+// DefaultBuckets are the upper bounds in seconds of latency histograms, from API calls of a few
+// milliseconds up to activities and schedule-to-start delays of a minute
+var DefaultBuckets = []float64{0.005, 0.01, 0.025, 0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10, 30, 60}
+
+// Default is the registry of the process, served by the /metrics endpoint
+var Default = NewRegistry()
+
+type kind string
+
+const (
+	kindCounter   kind = "counter"
+	kindGauge     kind = "gauge"
+	kindHistogram kind = "histogram"
+)
+
+// Registry holds metric families by name, each family holds one series per label set
+type Registry struct {
+	mu       sync.Mutex
+	families map[string]*family
+}
+
+type family struct {
+	name    string
+	help    string
+	kind    kind
+	buckets []float64
+	series  map[string]*series
+}
+
+type series struct {
+	labels string // Rendered label pairs, sorted by label name
+	value  float64
+	counts []uint64 // Observations per bucket of histograms, not cumulative
+	sum    float64
+	count  uint64
+}
+
+// NewRegistry returns an empty registry
+func NewRegistry() *Registry {
+	return &Registry{families: make(map[string]*family)}
+}
+
+// Counter is a value that only goes up
+type Counter struct {
+	registry *Registry
+	series   *series
+}
+
+// Gauge is a value that goes up and down
+type Gauge struct {
+	registry *Registry
+	series   *series
+}
+
+// Histogram counts observations into buckets
+type Histogram struct {
+	registry *Registry
+	buckets  []float64
+	series   *series
+}
+
+// Counter returns the counter of the name and labels, creating it on first use
+func (r *Registry) Counter(name string, help string, labels map[string]string) *Counter {
+	return &Counter{registry: r, series: r.series(name, help, kindCounter, nil, labels)}
+}
+
+// Gauge returns the gauge of the name and labels, creating it on first use
+func (r *Registry) Gauge(name string, help string, labels map[string]string) *Gauge {
+	return &Gauge{registry: r, series: r.series(name, help, kindGauge, nil, labels)}
+}
+
+// Histogram returns the histogram of the name and labels, creating it on first use. The buckets of the
+// first use are kept, nil buckets are DefaultBuckets.
+func (r *Registry) Histogram(name string, help string, buckets []float64, labels map[string]string) *Histogram {
+	if buckets == nil {
+		buckets = DefaultBuckets
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	f := r.familyLocked(name, help, kindHistogram, buckets)
+	return &Histogram{registry: r, buckets: f.buckets, series: f.seriesLocked(labels)}
+}
+
+// Inc adds one to the counter
+func (c *Counter) Inc() {
+	c.Add(1)
+}
+
+// Add adds the delta to the counter, negative deltas are ignored since counters only go up
+func (c *Counter) Add(delta float64) {
+	if delta < 0 || math.IsNaN(delta) {
+		return
+	}
+	c.registry.mu.Lock()
+	c.series.value += delta
+	c.registry.mu.Unlock()
+}
+
+// Set sets the gauge to the value
+func (g *Gauge) Set(value float64) {
+	g.registry.mu.Lock()
+	g.series.value = value
+	g.registry.mu.Unlock()
+}
+
+// Add adds the delta to the gauge
+func (g *Gauge) Add(delta float64) {
+	g.registry.mu.Lock()
+	g.series.value += delta
+	g.registry.mu.Unlock()
+}
+
+// Observe records the value in the first bucket it fits, values above every bound only count in +Inf
+func (h *Histogram) Observe(value float64) {
+	index := sort.SearchFloat64s(h.buckets, value)
+	h.registry.mu.Lock()
+	defer h.registry.mu.Unlock()
+	if index < len(h.buckets) {
+		h.series.counts[index]++
+	}
+	h.series.sum += value
+	h.series.count++
+}
+
+func (r *Registry) series(name string, help string, k kind, buckets []float64, labels map[string]string) *series {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.familyLocked(name, help, k, buckets).seriesLocked(labels)
+}
+
+// familyLocked returns the family of the name, a family registered with another kind is not replaced and the
+// metric asking for it is recorded in a detached family that is never written
+func (r *Registry) familyLocked(name string, help string, k kind, buckets []float64) *family {
+	name = sanitizeName(name, true)
+	f, found := r.families[name]
+	if !found {
+		f = &family{name: name, help: help, kind: k, buckets: buckets, series: make(map[string]*series)}
+		r.families[name] = f
+	}
+	if f.kind != k {
+		return &family{name: name, kind: k, buckets: buckets, series: make(map[string]*series)}
+	}
+	if f.help == "" {
+		f.help = help
+	}
+	return f
+}
+
+func (f *family) seriesLocked(labels map[string]string) *series {
+	key := renderLabels(labels)
+	s, found := f.series[key]
+	if !found {
+		s = &series{labels: key}
+		if f.kind == kindHistogram {
+			s.counts = make([]uint64, len(f.buckets))
+		}
+		f.series[key] = s
+	}
+	return s
+}
+
+// WriteText writes every family in the Prometheus text format, families and series sorted so scrapes diff
+func (r *Registry) WriteText(w io.Writer) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	names := make([]string, 0, len(r.families))
+	for name := range r.families {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	out := bufio.NewWriter(w)
+	for _, name := range names {
+		f := r.families[name]
+		if f.help != "" {
+			fmt.Fprintf(out, "# HELP %s %s\n", f.name, escapeHelp(f.help))
+		}
+		fmt.Fprintf(out, "# TYPE %s %s\n", f.name, f.kind)
+		keys := make([]string, 0, len(f.series))
+		for key := range f.series {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		for _, key := range keys {
+			f.write(out, f.series[key])
+		}
+	}
+	return out.Flush()
+}
+
+func (f *family) write(out *bufio.Writer, s *series) {
+	if f.kind != kindHistogram {
+		fmt.Fprintf(out, "%s%s %s\n", f.name, braces(s.labels), formatValue(s.value))
+		return
+	}
+	var cumulative uint64
+	for i, bound := range f.buckets {
+		cumulative += s.counts[i]
+		fmt.Fprintf(out, "%s_bucket%s %d\n", f.name, braces(joinLabels(s.labels, `le="`+formatValue(bound)+`"`)), cumulative)
+	}
+	fmt.Fprintf(out, "%s_bucket%s %d\n", f.name, braces(joinLabels(s.labels, `le="+Inf"`)), s.count)
+	fmt.Fprintf(out, "%s_sum%s %s\n", f.name, braces(s.labels), formatValue(s.sum))
+	fmt.Fprintf(out, "%s_count%s %d\n", f.name, braces(s.labels), s.count)
+}
+
+// Handler serves the registry to Prometheus scrapes
+func (r *Registry) Handler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		w.Header().Set("Content-Type", ContentType)
+		if err := r.WriteText(w); err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+		}
+	})
+}
+
+// renderLabels renders the labels as name="value" pairs sorted by name
+func renderLabels(labels map[string]string) string {
+	if len(labels) == 0 {
+		return ""
+	}
+	names := make([]string, 0, len(labels))
+	for name := range labels {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	pairs := make([]string, 0, len(names))
+	for _, name := range names {
+		pairs = append(pairs, sanitizeName(name, false)+`="`+escapeLabelValue(labels[name])+`"`)
+	}
+	return strings.Join(pairs, ",")
+}
+
+func joinLabels(labels string, pair string) string {
+	if labels == "" {
+		return pair
+	}
+	return labels + "," + pair
+}
+
+func braces(labels string) string {
+	if labels == "" {
+		return ""
+	}
+	return "{" + labels + "}"
+}
+
+// sanitizeName replaces the characters Prometheus does not allow in names, colons are only allowed in
+// metric names
+func sanitizeName(name string, metric bool) string {
+	var b strings.Builder
+	for i, r := range name {
+		valid := r == '_' || (r >= 'a' && r <= 'z') || (r >= 'A' && r <= 'Z') || (i > 0 && r >= '0' && r <= '9') || (metric && r == ':')
+		if valid {
+			b.WriteRune(r)
+		} else {
+			b.WriteRune('_')
+		}
+	}
+	if b.Len() == 0 {
+		return "_"
+	}
+	return b.String()
+}
+
+var (
+	labelValueEscaper = strings.NewReplacer(`\`, `\\`, "\n", `\n`, `"`, `\"`)
+	helpEscaper       = strings.NewReplacer(`\`, `\\`, "\n", `\n`)
+)
+
+func escapeLabelValue(value string) string {
+	return labelValueEscaper.Replace(value)
+}
+
+func escapeHelp(help string) string {
+	return helpEscaper.Replace(help)
+}
+
+func formatValue(value float64) string {
+	switch {
+	case math.IsInf(value, 1):
+		return "+Inf"
+	case math.IsInf(value, -1):
+		return "-Inf"
+	case math.IsNaN(value):
+		return "NaN"
+	}
+	return strconv.FormatFloat(value, 'g', -1, 64)
+}
@@ -0,0 +1,90 @@
+package metrics
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func scrape(t *testing.T, registry *Registry) string {
+	var out strings.Builder
+	assert.NoError(t, registry.WriteText(&out))
+	return out.String()
+}
+
+func TestRegistryWriteText(t *testing.T) {
+	registry := NewRegistry()
+	registry.Counter("bills_created_total", "Bills created", map[string]string{"currency": "USD"}).Inc()
+	registry.Counter("bills_created_total", "Bills created", map[string]string{"currency": "USD"}).Add(2)
+	registry.Counter("bills_created_total", "Bills created", map[string]string{"currency": "GEL"}).Inc()
+	registry.Gauge("open_bills", "", nil).Set(4)
+	registry.Gauge("open_bills", "", nil).Add(-1)
+
+	assert.Equal(t, `# HELP bills_created_total Bills created
+# TYPE bills_created_total counter
+bills_created_total{currency="GEL"} 1
+bills_created_total{currency="USD"} 3
+# TYPE open_bills gauge
+open_bills 3
+`, scrape(t, registry))
+}
+
+func TestRegistryHistogram(t *testing.T) {
+	registry := NewRegistry()
+	labels := map[string]string{"endpoint": "AddLineItem"}
+	histogram := registry.Histogram("api_request_duration_seconds", "", []float64{0.1, 1}, labels)
+	histogram.Observe(0.05)
+	histogram.Observe(0.1)
+	histogram.Observe(0.5)
+	histogram.Observe(3)
+
+	assert.Equal(t, `# TYPE api_request_duration_seconds histogram
+api_request_duration_seconds_bucket{endpoint="AddLineItem",le="0.1"} 2
+api_request_duration_seconds_bucket{endpoint="AddLineItem",le="1"} 3
+api_request_duration_seconds_bucket{endpoint="AddLineItem",le="+Inf"} 4
+api_request_duration_seconds_sum{endpoint="AddLineItem"} 3.65
+api_request_duration_seconds_count{endpoint="AddLineItem"} 4
+`, scrape(t, registry))
+}
+
+func TestRegistryCountersOnlyGoUp(t *testing.T) {
+	registry := NewRegistry()
+	counter := registry.Counter("line_items_added_total", "", nil)
+	counter.Add(5)
+	counter.Add(-2)
+	assert.Contains(t, scrape(t, registry), "line_items_added_total 5\n")
+}
+
+func TestRegistryKeepsTheFirstKind(t *testing.T) {
+	registry := NewRegistry()
+	registry.Counter("requests", "", nil).Inc()
+	registry.Gauge("requests", "", nil).Set(10)
+	assert.Equal(t, "# TYPE requests counter\nrequests 1\n", scrape(t, registry))
+}
+
+func TestRegistrySanitizesNamesAndEscapesValues(t *testing.T) {
+	registry := NewRegistry()
+	registry.Counter("temporal.request-failure", "line one\nline two", map[string]string{
+		"operation-name": `Signal"Workflow\`,
+		"9tag":           "a\nb",
+	}).Inc()
+
+	assert.Equal(t, `# HELP temporal_request_failure line one\nline two
+# TYPE temporal_request_failure counter
+temporal_request_failure{_tag="a\nb",operation_name="Signal\"Workflow\\"} 1
+`, scrape(t, registry))
+}
+
+func TestRegistryHandler(t *testing.T) {
+	registry := NewRegistry()
+	registry.Counter("scrapes_total", "", nil).Inc()
+
+	recorder := httptest.NewRecorder()
+	registry.Handler().ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+	assert.Equal(t, http.StatusOK, recorder.Code)
+	assert.Equal(t, ContentType, recorder.Header().Get("Content-Type"))
+	assert.Equal(t, "# TYPE scrapes_total counter\nscrapes_total 1\n", recorder.Body.String())
+}
@@ -0,0 +1,64 @@
+package metrics
+
+import (
+	"strings"
+	"time"
+
+	"go.temporal.io/sdk/client"
+)
+
+// temporalHandler records the metrics of the Temporal SDK, and of workflows through
+// workflow.GetMetricsHandler, in a registry. Counters get the _total suffix and timers become histograms
+// in seconds, as Prometheus names them.
+type temporalHandler struct {
+	registry *Registry
+	tags     map[string]string
+}
+
+// NewTemporalHandler returns a handler for client.Options.MetricsHandler that records in the registry.
+// Workers created from the client inherit it.
+func NewTemporalHandler(registry *Registry) client.MetricsHandler {
+	return &temporalHandler{registry: registry, tags: map[string]string{}}
+}
+
+func (h *temporalHandler) WithTags(tags map[string]string) client.MetricsHandler {
+	merged := make(map[string]string, len(h.tags)+len(tags))
+	for name, value := range h.tags {
+		merged[name] = value
+	}
+	for name, value := range tags {
+		merged[name] = value
+	}
+	return &temporalHandler{registry: h.registry, tags: merged}
+}
+
+func (h *temporalHandler) Counter(name string) client.MetricsCounter {
+	return temporalCounter{h.registry.Counter(withSuffix(name, "_total"), "", h.tags)}
+}
+
+func (h *temporalHandler) Gauge(name string) client.MetricsGauge {
+	return temporalGauge{h.registry.Gauge(name, "", h.tags)}
+}
+
+func (h *temporalHandler) Timer(name string) client.MetricsTimer {
+	return temporalTimer{h.registry.Histogram(withSuffix(name, "_seconds"), "", nil, h.tags)}
+}
+
+type temporalCounter struct{ counter *Counter }
+
+func (c temporalCounter) Inc(delta int64) { c.counter.Add(float64(delta)) }
+
+type temporalGauge struct{ gauge *Gauge }
+
+func (g temporalGauge) Update(value float64) { g.gauge.Set(value) }
+
+type temporalTimer struct{ histogram *Histogram }
+
+func (t temporalTimer) Record(d time.Duration) { t.histogram.Observe(d.Seconds()) }
+
+func withSuffix(name string, suffix string) string {
+	if strings.HasSuffix(name, suffix) {
+		return name
+	}
+	return name + suffix
+}
@@ -0,0 +1,36 @@
+package metrics
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTemporalHandler(t *testing.T) {
+	registry := NewRegistry()
+	handler := NewTemporalHandler(registry).WithTags(map[string]string{"namespace": "default"})
+	tagged := handler.WithTags(map[string]string{"task_queue": "bills"})
+
+	tagged.Counter("temporal_request").Inc(2)
+	tagged.Counter("billing_signals_handled_total").Inc(1)
+	handler.Gauge("temporal_num_pollers").Update(3)
+	tagged.Timer("temporal_request_latency").Record(20 * time.Millisecond)
+
+	text := scrape(t, registry)
+	assert.Contains(t, text, "# TYPE temporal_request_total counter\n")
+	assert.Contains(t, text, `temporal_request_total{namespace="default",task_queue="bills"} 2`+"\n")
+	assert.Contains(t, text, `billing_signals_handled_total{namespace="default",task_queue="bills"} 1`+"\n")
+	assert.Contains(t, text, `temporal_num_pollers{namespace="default"} 3`+"\n")
+	assert.Contains(t, text, "# TYPE temporal_request_latency_seconds histogram\n")
+	assert.Contains(t, text, `temporal_request_latency_seconds_bucket{namespace="default",task_queue="bills",le="0.025"} 1`+"\n")
+	assert.Contains(t, text, `temporal_request_latency_seconds_bucket{namespace="default",task_queue="bills",le="0.01"} 0`+"\n")
+}
+
+func TestTemporalHandlerWithTagsDoesNotLeak(t *testing.T) {
+	registry := NewRegistry()
+	handler := NewTemporalHandler(registry)
+	handler.WithTags(map[string]string{"task_queue": "bills"})
+	handler.Counter("temporal_request").Inc(1)
+	assert.Contains(t, scrape(t, registry), "temporal_request_total 1\n")
+}
@@ -0,0 +1,176 @@
+package models
+
+import (
+	"bytes"
+	"encoding/csv"
+	"fmt"
+	"strconv"
+	"time"
+)
+
+// AgingBucket groups unpaid bills by how many days past their due date they are
+type AgingBucket string
+
+const (
+	AgingCurrent AgingBucket = "current" // Not due yet, or due today
+	Aging1To30   AgingBucket = "1-30"
+	Aging31To60  AgingBucket = "31-60"
+	Aging61To90  AgingBucket = "61-90"
+	AgingOver90  AgingBucket = "90+"
+)
+
+// AgingBuckets lists the buckets from the least to the most overdue
+var AgingBuckets = []AgingBucket{AgingCurrent, Aging1To30, Aging31To60, Aging61To90, AgingOver90}
+
+// AgingReportRequest represents the reporting currency and the optional drill-down filters
+type AgingReportRequest struct {
+	Currency   Currency    `query:"currency"`    // Reporting currency, USD when empty
+	CustomerID string      `query:"customer_id"` // Only the bills of this customer
+	Bucket     AgingBucket `query:"bucket"`      // Only the bills in this bucket
+}
+
+// ReceivableBill represents a closed bill with an amount left to collect
+type ReceivableBill struct {
+	CustomerID  string      `json:"customer_id"`
+	BillID      string      `json:"bill_id"`
+	Currency    Currency    `json:"currency"`
+	Outstanding float64     `json:"outstanding"` // In the bill currency
+	Amount      float64     `json:"amount"`      // Outstanding in the reporting currency
+	ClosedAt    time.Time   `json:"closed_at"`
+	DueAt       time.Time   `json:"due_at"`
+	DaysOverdue int         `json:"days_overdue"`
+	Bucket      AgingBucket `json:"bucket"`
+}
+
+// AgingBucketTotal represents the unpaid bills of a bucket in the reporting currency
+type AgingBucketTotal struct {
+	Bucket AgingBucket `json:"bucket"`
+	Bills  int         `json:"bills"`
+	Amount float64     `json:"amount"`
+}
+
+// CustomerAging represents what a customer owes per bucket in the reporting currency
+type CustomerAging struct {
+	CustomerID string                  `json:"customer_id"`
+	Buckets    map[AgingBucket]float64 `json:"buckets"`
+	Total      float64                 `json:"total"`
+}
+
+// AgingReport represents the unpaid closed bills of a tenant bucketed by days past due
+type AgingReport struct {
+	TenantID  string              `json:"tenant_id"`
+	Currency  Currency            `json:"currency"`
+	AsOf      time.Time           `json:"as_of"`
+	Buckets   []*AgingBucketTotal `json:"buckets"`
+	Customers []*CustomerAging    `json:"customers"`
+	Bills     []*ReceivableBill   `json:"bills"`
+	Total     float64             `json:"total"`
+}
+
+// AgingExportResponse represents the aging report as a CSV file with one row per bill
+type AgingExportResponse struct {
+	FileName string `json:"file_name"`
+	CSV      string `json:"csv"`
+}
+
+// IsValid checks if the bucket is one of the aging buckets
+func (b AgingBucket) IsValid() bool {
+	for _, bucket := range AgingBuckets {
+		if b == bucket {
+			return true
+		}
+	}
+	return false
+}
+
+// AgingBucketFor returns the bucket of a bill the given number of days past its due date
+func AgingBucketFor(daysOverdue int) AgingBucket {
+	switch {
+	case daysOverdue <= 0:
+		return AgingCurrent
+	case daysOverdue <= 30:
+		return Aging1To30
+	case daysOverdue <= 60:
+		return Aging31To60
+	case daysOverdue <= 90:
+		return Aging61To90
+	}
+	return AgingOver90
+}
+
+// DaysOverdue returns the number of whole days from the due date to asOf, zero before the due date
+func DaysOverdue(dueAt, asOf time.Time) int {
+	if !asOf.After(dueAt) {
+		return 0
+	}
+	return int(asOf.Sub(dueAt).Hours() / 24)
+}
+
+// NewAgingReport buckets the receivable bills as of the given time, converting what is outstanding to the
+// reporting currency. Bills outside the bucket are left out when one is given.
+func NewAgingReport(tenantID string, currency Currency, bucket AgingBucket, bills []*ReceivableBill, asOf time.Time) *AgingReport {
+	report := &AgingReport{
+		TenantID:  tenantID,
+		Currency:  currency,
+		AsOf:      asOf,
+		Buckets:   make([]*AgingBucketTotal, 0, len(AgingBuckets)),
+		Customers: make([]*CustomerAging, 0),
+		Bills:     make([]*ReceivableBill, 0, len(bills)),
+	}
+	totals := make(map[AgingBucket]*AgingBucketTotal, len(AgingBuckets))
+	for _, b := range AgingBuckets {
+		totals[b] = &AgingBucketTotal{Bucket: b}
+		report.Buckets = append(report.Buckets, totals[b])
+	}
+	customers := make(map[string]*CustomerAging)
+
+	for _, bill := range bills {
+		bill.DaysOverdue = DaysOverdue(bill.DueAt, asOf)
+		bill.Bucket = AgingBucketFor(bill.DaysOverdue)
+		if bucket != "" && bill.Bucket != bucket {
+			continue
+		}
+		bill.Amount = RoundAmount(ConvertCurrencyAmount(bill.Currency, currency, bill.Outstanding))
+		report.Bills = append(report.Bills, bill)
+
+		totals[bill.Bucket].Bills++
+		totals[bill.Bucket].Amount = RoundAmount(totals[bill.Bucket].Amount + bill.Amount)
+		customer := customers[bill.CustomerID]
+		if customer == nil {
+			customer = &CustomerAging{CustomerID: bill.CustomerID, Buckets: make(map[AgingBucket]float64)}
+			customers[bill.CustomerID] = customer
+			report.Customers = append(report.Customers, customer)
+		}
+		customer.Buckets[bill.Bucket] = RoundAmount(customer.Buckets[bill.Bucket] + bill.Amount)
+		customer.Total = RoundAmount(customer.Total + bill.Amount)
+		report.Total = RoundAmount(report.Total + bill.Amount)
+	}
+	return report
+}
+
+// CSV renders the bills of the report one per row, with what is outstanding in both currencies
+func (r *AgingReport) CSV() (string, error) {
+	var buf bytes.Buffer
+	w := csv.NewWriter(&buf)
+	rows := [][]string{{
+		"customer_id", "bill_id", "closed_at", "due_at", "days_overdue", "bucket",
+		"currency", "outstanding", "amount_" + string(r.Currency),
+	}}
+	for _, bill := range r.Bills {
+		rows = append(rows, []string{
+			bill.CustomerID,
+			bill.BillID,
+			bill.ClosedAt.UTC().Format(time.DateOnly),
+			bill.DueAt.UTC().Format(time.DateOnly),
+			strconv.Itoa(bill.DaysOverdue),
+			string(bill.Bucket),
+			string(bill.Currency),
+			strconv.FormatFloat(bill.Outstanding, 'f', 2, 64),
+			strconv.FormatFloat(bill.Amount, 'f', 2, 64),
+		})
+	}
+	if err := w.WriteAll(rows); err != nil {
+		return "", fmt.Errorf("failed to write aging report: %w", err)
+	}
+	return buf.String(), nil
+}
@@ -0,0 +1,56 @@
+package models
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAgingBucketFor(t *testing.T) {
+	cases := map[int]AgingBucket{
+		-5: AgingCurrent, 0: AgingCurrent, 1: Aging1To30, 30: Aging1To30, 31: Aging31To60,
+		60: Aging31To60, 61: Aging61To90, 90: Aging61To90, 91: AgingOver90, 400: AgingOver90,
+	}
+	for days, bucket := range cases {
+		assert.Equal(t, bucket, AgingBucketFor(days), "%d days overdue", days)
+	}
+}
+
+func TestNewAgingReport(t *testing.T) {
+	asOf := time.Date(2025, 6, 30, 12, 0, 0, 0, time.UTC)
+	receivables := func() []*ReceivableBill {
+		return []*ReceivableBill{
+			{CustomerID: "cust-1", BillID: "bill-1", Currency: USD, Outstanding: 100, DueAt: asOf.AddDate(0, 0, 10)},
+			{CustomerID: "cust-1", BillID: "bill-2", Currency: GEL, Outstanding: 50, DueAt: asOf.AddDate(0, 0, -45)},
+			{CustomerID: "cust-2", BillID: "bill-3", Currency: USD, Outstanding: 20, DueAt: asOf.AddDate(0, 0, -120)},
+		}
+	}
+
+	report := NewAgingReport("retail", USD, "", receivables(), asOf)
+	assert.Len(t, report.Buckets, len(AgingBuckets))
+	assert.Equal(t, 100.0, report.Buckets[0].Amount)
+	assert.Equal(t, 20.0, report.Buckets[2].Amount, "50 GEL converted to USD")
+	assert.Equal(t, 1, report.Buckets[4].Bills)
+	assert.Equal(t, 140.0, report.Total)
+	assert.Len(t, report.Customers, 2)
+	assert.Equal(t, 120.0, report.Customers[0].Total)
+	assert.Equal(t, 45, report.Bills[1].DaysOverdue)
+
+	t.Run("drills down to a bucket", func(t *testing.T) {
+		report := NewAgingReport("retail", GEL, AgingOver90, receivables(), asOf)
+		assert.Len(t, report.Bills, 1)
+		assert.Equal(t, "bill-3", report.Bills[0].BillID)
+		assert.Equal(t, 50.0, report.Total)
+	})
+
+	t.Run("exports one row per bill", func(t *testing.T) {
+		document, err := report.CSV()
+		assert.NoError(t, err)
+		rows := strings.Split(strings.TrimSpace(document), "\n")
+		assert.Len(t, rows, 4)
+		assert.Equal(t, "customer_id,bill_id,closed_at,due_at,days_overdue,bucket,currency,outstanding,amount_USD", rows[0])
+		assert.Equal(t, "cust-1,bill-2,0001-01-01,2025-05-16,45,31-60,GEL,50.00,20.00", rows[2])
+	})
+}
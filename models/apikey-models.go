@@ -0,0 +1,140 @@
+package models
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"time"
+)
+
+// APIKeyScope is a permission granted to an API key
+type APIKeyScope string
+
+const (
+	ScopeRead  APIKeyScope = "read"
+	ScopeWrite APIKeyScope = "write"
+	ScopeAdmin APIKeyScope = "admin" // Acts on every customer of the tenant, only granted to keys not bound to one
+
+	// ScopeMetrics only reads the metrics of every tenant. It is held by the MetricsScrapeToken secret and never
+	// granted to issued keys.
+	ScopeMetrics APIKeyScope = "metrics"
+)
+
+// APIKeyPrefix starts every generated key so leaked keys are easy to recognise
+const APIKeyPrefix = "bk_"
+
+// AuthData is attached to every authenticated request and describes the calling key
+type AuthData struct {
+	KeyID      string        `json:"key_id"`
+	TenantID   string        `json:"tenant_id,omitempty"`   // Empty for the operator key managing all tenants
+	CustomerID string        `json:"customer_id,omitempty"` // Empty for admin keys
+	Scopes     []APIKeyScope `json:"scopes"`
+}
+
+// APIKey represents a stored API key, the key itself is only known to its holder
+type APIKey struct {
+	KeyID       string        `json:"key_id"`
+	TenantID    string        `json:"tenant_id"`
+	CustomerID  string        `json:"customer_id,omitempty"`
+	Description string        `json:"description,omitempty"`
+	KeyPrefix   string        `json:"key_prefix"` // First characters of the key, to tell keys apart
+	Scopes      []APIKeyScope `json:"scopes"`
+	CreatedAt   time.Time     `json:"created_at"`
+	RevokedAt   *time.Time    `json:"revoked_at,omitempty"`
+}
+
+// CreateAPIKeyRequest represents the request to issue an API key
+type CreateAPIKeyRequest struct {
+	TenantID    string        `json:"tenant_id,omitempty"`   // Defaults to the tenant of the calling key
+	CustomerID  string        `json:"customer_id,omitempty"` // Required unless the admin scope is requested
+	Description string        `json:"description,omitempty"`
+	Scopes      []APIKeyScope `json:"scopes"`
+}
+
+// CreateAPIKeyResponse represents an issued API key, Key is never returned again
+type CreateAPIKeyResponse struct {
+	APIKey *APIKey `json:"api_key"`
+	Key    string  `json:"key"`
+}
+
+// ListAPIKeysRequest represents the tenant whose customer keys are listed
+type ListAPIKeysRequest struct {
+	TenantID string `query:"tenant_id"` // Defaults to the tenant of the calling key, required for the operator key
+}
+
+// ListAPIKeysResponse represents the API keys issued to a customer
+type ListAPIKeysResponse struct {
+	APIKeys []*APIKey `json:"api_keys"`
+}
+
+// VerifyAPIKeyRequest represents an API key presented outside of the REST API, such as in gRPC metadata
+type VerifyAPIKeyRequest struct {
+	Key string `json:"key"`
+}
+
+// IsValid checks if the scope is supported
+func (s APIKeyScope) IsValid() bool {
+	return s == ScopeRead || s == ScopeWrite || s == ScopeAdmin
+}
+
+// HashAPIKey returns the hex SHA-256 digest under which a key is stored
+func HashAPIKey(key string) string {
+	sum := sha256.Sum256([]byte(key))
+	return hex.EncodeToString(sum[:])
+}
+
+// IsAdmin returns true if the key may act on every customer of its tenant
+func (a *AuthData) IsAdmin() bool {
+	for _, scope := range a.Scopes {
+		if scope == ScopeAdmin {
+			return true
+		}
+	}
+	return false
+}
+
+// HasScope reports whether the key grants the scope, admin implies write and write implies read
+func (a *AuthData) HasScope(scope APIKeyScope) bool {
+	for _, granted := range a.Scopes {
+		switch {
+		case granted == scope, granted == ScopeAdmin:
+			return true
+		case granted == ScopeWrite && scope == ScopeRead:
+			return true
+		}
+	}
+	return false
+}
+
+// IsOperator returns true for the admin key not bound to a tenant, it manages tenants and their keys
+func (a *AuthData) IsOperator() bool {
+	return a.TenantID == "" && a.IsAdmin()
+}
+
+// CanReadMetrics returns true for the keys not bound to a tenant that may read the metrics of all tenants:
+// the metrics scrape token and the operator key
+func (a *AuthData) CanReadMetrics() bool {
+	return a.TenantID == "" && a.HasScope(ScopeMetrics)
+}
+
+// AuthorizeTenant checks that the key may act on the tenant with the given scope
+func (a *AuthData) AuthorizeTenant(tenantID string, scope APIKeyScope) error {
+	if !a.HasScope(scope) {
+		return fmt.Errorf("API key lacks the %s scope", scope)
+	}
+	if !a.IsOperator() && a.TenantID != tenantID {
+		return fmt.Errorf("API key may not act on tenant %s", tenantID)
+	}
+	return nil
+}
+
+// Authorize checks that the key may act on the customer of the tenant with the given scope
+func (a *AuthData) Authorize(tenantID string, customerID string, scope APIKeyScope) error {
+	if err := a.AuthorizeTenant(tenantID, scope); err != nil {
+		return err
+	}
+	if !a.IsAdmin() && a.CustomerID != customerID {
+		return fmt.Errorf("API key may not act on customer %s", customerID)
+	}
+	return nil
+}
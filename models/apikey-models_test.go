@@ -0,0 +1,70 @@
+package models
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAuthDataHasScope(t *testing.T) {
+	reader := &AuthData{CustomerID: "cust-1", Scopes: []APIKeyScope{ScopeRead}}
+	writer := &AuthData{CustomerID: "cust-1", Scopes: []APIKeyScope{ScopeWrite}}
+	admin := &AuthData{Scopes: []APIKeyScope{ScopeAdmin}}
+
+	assert.True(t, reader.HasScope(ScopeRead))
+	assert.False(t, reader.HasScope(ScopeWrite))
+	assert.True(t, writer.HasScope(ScopeRead))
+	assert.True(t, writer.HasScope(ScopeWrite))
+	assert.False(t, writer.HasScope(ScopeAdmin))
+	assert.True(t, admin.HasScope(ScopeRead))
+	assert.True(t, admin.HasScope(ScopeWrite))
+	assert.True(t, admin.HasScope(ScopeAdmin))
+}
+
+func TestAuthDataAuthorize(t *testing.T) {
+	t.Run("own customer", func(t *testing.T) {
+		data := &AuthData{TenantID: "retail", CustomerID: "cust-1", Scopes: []APIKeyScope{ScopeWrite}}
+		assert.NoError(t, data.Authorize("retail", "cust-1", ScopeWrite))
+	})
+	t.Run("other customer", func(t *testing.T) {
+		data := &AuthData{TenantID: "retail", CustomerID: "cust-1", Scopes: []APIKeyScope{ScopeWrite}}
+		assert.EqualError(t, data.Authorize("retail", "cust-2", ScopeRead), "API key may not act on customer cust-2")
+	})
+	t.Run("same customer of another tenant", func(t *testing.T) {
+		data := &AuthData{TenantID: "retail", CustomerID: "cust-1", Scopes: []APIKeyScope{ScopeWrite}}
+		assert.EqualError(t, data.Authorize("wholesale", "cust-1", ScopeRead), "API key may not act on tenant wholesale")
+	})
+	t.Run("missing scope", func(t *testing.T) {
+		data := &AuthData{TenantID: "retail", CustomerID: "cust-1", Scopes: []APIKeyScope{ScopeRead}}
+		assert.EqualError(t, data.Authorize("retail", "cust-1", ScopeWrite), "API key lacks the write scope")
+	})
+	t.Run("admin acts on every customer of its tenant", func(t *testing.T) {
+		data := &AuthData{TenantID: "retail", Scopes: []APIKeyScope{ScopeAdmin}}
+		assert.NoError(t, data.Authorize("retail", "cust-2", ScopeWrite))
+		assert.EqualError(t, data.Authorize("wholesale", "cust-2", ScopeWrite), "API key may not act on tenant wholesale")
+		assert.False(t, data.IsOperator())
+	})
+	t.Run("operator acts on every tenant", func(t *testing.T) {
+		data := &AuthData{Scopes: []APIKeyScope{ScopeAdmin}}
+		assert.True(t, data.IsOperator())
+		assert.NoError(t, data.AuthorizeTenant("wholesale", ScopeAdmin))
+		assert.True(t, data.CanReadMetrics())
+	})
+	t.Run("metrics scraper only reads metrics", func(t *testing.T) {
+		data := &AuthData{Scopes: []APIKeyScope{ScopeMetrics}}
+		assert.True(t, data.CanReadMetrics())
+		assert.False(t, data.IsOperator())
+		assert.Error(t, data.AuthorizeTenant("retail", ScopeRead))
+		assert.False(t, ScopeMetrics.IsValid(), "Issued keys cannot hold the metrics scope")
+	})
+	t.Run("tenant admin cannot read metrics", func(t *testing.T) {
+		data := &AuthData{TenantID: "retail", Scopes: []APIKeyScope{ScopeAdmin}}
+		assert.False(t, data.CanReadMetrics())
+	})
+}
+
+func TestHashAPIKey(t *testing.T) {
+	assert.Regexp(t, `^[0-9a-f]{64}$`, HashAPIKey("bk_test"))
+	assert.Equal(t, HashAPIKey("bk_test"), HashAPIKey("bk_test"))
+	assert.NotEqual(t, HashAPIKey("bk_test"), HashAPIKey("bk_other"))
+}
@@ -0,0 +1,80 @@
+package models
+
+import (
+	"fmt"
+	"time"
+)
+
+// AuditAction identifies the bill mutation an audit record describes
+type AuditAction string
+
+const (
+	AuditStartBillingPeriod AuditAction = "billing_period.start"
+	AuditCreateBill         AuditAction = "bill.create"
+	AuditAddLineItem        AuditAction = "line_item.add"
+	AuditRejectLineItem     AuditAction = "line_item.reject"
+	AuditCloseBill          AuditAction = "bill.close"
+	AuditCloseBillingPeriod AuditAction = "billing_period.close"
+	AuditSetSpendingLimit   AuditAction = "spending_limit.set"
+)
+
+// AuditSource tells whether a record was written for the API call or for the workflow applying it
+type AuditSource string
+
+const (
+	AuditSourceAPI      AuditSource = "api"
+	AuditSourceWorkflow AuditSource = "workflow"
+)
+
+// SystemActor is the actor of mutations the workflow makes on its own, such as closing bills at the end of the period
+const SystemActor = "system"
+
+// AuditContext identifies who asked for a mutation, it travels with the signal into the workflow
+type AuditContext struct {
+	Actor     string `json:"actor"`
+	RequestID string `json:"request_id,omitempty"`
+}
+
+// AuditRecord is one immutable entry of the audit log
+type AuditRecord struct {
+	RecordID    string      `json:"record_id"`
+	TenantID    string      `json:"tenant_id"`
+	CustomerID  string      `json:"customer_id"`
+	BillID      string      `json:"bill_id,omitempty"`
+	WorkflowID  string      `json:"workflow_id,omitempty"`
+	Action      AuditAction `json:"action"`
+	Source      AuditSource `json:"source"`
+	Actor       string      `json:"actor"`
+	RequestID   string      `json:"request_id,omitempty"`
+	TotalBefore *float64    `json:"total_before,omitempty"` // Bill total in the bill currency, set by the workflow
+	TotalAfter  *float64    `json:"total_after,omitempty"`
+	Currency    Currency    `json:"currency,omitempty"`
+	Reason      string      `json:"reason,omitempty"`
+	Error       string      `json:"error,omitempty"` // Set when the API call was rejected
+	OccurredAt  time.Time   `json:"occurred_at"`
+}
+
+// ListAuditRecordsResponse represents audit records, oldest first
+type ListAuditRecordsResponse struct {
+	Records []*AuditRecord `json:"records"`
+	Total   int64          `json:"total"`
+}
+
+// IsValid checks if the audit action is known
+func (a AuditAction) IsValid() bool {
+	switch a {
+	case AuditStartBillingPeriod, AuditCreateBill, AuditAddLineItem, AuditRejectLineItem, AuditCloseBill,
+		AuditCloseBillingPeriod, AuditSetSpendingLimit:
+		return true
+	}
+	return false
+}
+
+// WorkflowAuditRecordID derives the ID of a record written by the workflow from what it describes, so
+// retried activities append it once
+func WorkflowAuditRecordID(workflowID string, action AuditAction, subject string) string {
+	if subject == "" {
+		return fmt.Sprintf("%s/%s", workflowID, action)
+	}
+	return fmt.Sprintf("%s/%s/%s", workflowID, action, subject)
+}
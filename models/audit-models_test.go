@@ -0,0 +1,14 @@
+package models
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWorkflowAuditRecordID(t *testing.T) {
+	assert.Equal(t, "wf-1/bill.create/bill-1", WorkflowAuditRecordID("wf-1", AuditCreateBill, "bill-1"))
+	assert.Equal(t, "wf-1/billing_period.close", WorkflowAuditRecordID("wf-1", AuditCloseBillingPeriod, ""))
+	assert.True(t, AuditRejectLineItem.IsValid())
+	assert.False(t, AuditAction("bill.delete").IsValid())
+}
@@ -0,0 +1,180 @@
+package models
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"time"
+)
+
+const (
+	// DefaultBillsPageSize is the page size of ListBills when the request does not set one
+	DefaultBillsPageSize = 50
+	// MaxBillsPageSize is the largest page ListBills returns
+	MaxBillsPageSize = 200
+)
+
+// BillSort is the order bills are listed in, a leading minus sorts descending
+type BillSort string
+
+const (
+	BillSortCreatedAsc  BillSort = "created_at"
+	BillSortCreatedDesc BillSort = "-created_at"
+	BillSortClosedAsc   BillSort = "closed_at"
+	BillSortClosedDesc  BillSort = "-closed_at"
+	BillSortTotalAsc    BillSort = "total_amount"
+	BillSortTotalDesc   BillSort = "-total_amount"
+)
+
+// IsValid checks if the sort order is supported, empty sorts by creation
+func (s BillSort) IsValid() bool {
+	switch s {
+	case "", BillSortCreatedAsc, BillSortCreatedDesc, BillSortClosedAsc, BillSortClosedDesc, BillSortTotalAsc, BillSortTotalDesc:
+		return true
+	}
+	return false
+}
+
+// OrDefault returns the sort order, bills are listed oldest first by default
+func (s BillSort) OrDefault() BillSort {
+	if s == "" {
+		return BillSortCreatedAsc
+	}
+	return s
+}
+
+// BillCursor is the position after which the next page starts: the sort key and ID of the last bill listed
+type BillCursor struct {
+	Sort        BillSort  `json:"s"`
+	CreatedAt   time.Time `json:"c,omitempty"`
+	ClosedAt    time.Time `json:"d,omitempty"`
+	TotalAmount float64   `json:"t,omitempty"`
+	BillID      string    `json:"i"`
+}
+
+// NewBillCursor returns the opaque cursor of the page following the bill
+func NewBillCursor(sort BillSort, bill *Bill) string {
+	cursor := BillCursor{Sort: sort.OrDefault(), BillID: bill.ID}
+	switch cursor.Sort {
+	case BillSortCreatedAsc, BillSortCreatedDesc:
+		cursor.CreatedAt = bill.CreatedAt
+	case BillSortClosedAsc, BillSortClosedDesc:
+		cursor.ClosedAt = bill.ClosedAt
+	case BillSortTotalAsc, BillSortTotalDesc:
+		cursor.TotalAmount = bill.TotalAmount
+	}
+	document, _ := json.Marshal(cursor)
+	return base64.RawURLEncoding.EncodeToString(document)
+}
+
+// DecodeBillCursor parses a cursor returned by NewBillCursor
+func DecodeBillCursor(value string) (*BillCursor, error) {
+	document, err := base64.RawURLEncoding.DecodeString(value)
+	if err != nil {
+		return nil, fmt.Errorf("invalid cursor")
+	}
+	var cursor BillCursor
+	if err := json.Unmarshal(document, &cursor); err != nil || cursor.BillID == "" || !cursor.Sort.IsValid() {
+		return nil, fmt.Errorf("invalid cursor")
+	}
+	return &cursor, nil
+}
+
+// Matches checks the bill against every filter of the request
+func (r *ListBillsRequest) Matches(bill *Bill) bool {
+	if r.Status != "" && bill.Status != BillStatus(r.Status) {
+		return false
+	}
+	if r.Currency != "" && bill.Currency != r.Currency {
+		return false
+	}
+	if !inRange(bill.CreatedAt, r.CreatedFrom, r.CreatedTo) {
+		return false
+	}
+	if (r.ClosedFrom != nil || r.ClosedTo != nil) && (bill.ClosedAt.IsZero() || !inRange(bill.ClosedAt, r.ClosedFrom, r.ClosedTo)) {
+		return false
+	}
+	if r.MinTotal != nil && bill.TotalAmount < *r.MinTotal {
+		return false
+	}
+	if r.MaxTotal != nil && bill.TotalAmount > *r.MaxTotal {
+		return false
+	}
+	return true
+}
+
+// SelectBills returns the page of bills the request asks for: the matching bills in its sort order, after
+// its cursor and at most PageSize of them, all of them when PageSize is 0
+func SelectBills(req ListBillsRequest, bills []*Bill) ([]*Bill, error) {
+	order := req.Sort.OrDefault()
+	selected := make([]*Bill, 0, len(bills))
+	for _, bill := range bills {
+		if req.Matches(bill) {
+			selected = append(selected, bill)
+		}
+	}
+	sort.SliceStable(selected, func(i, j int) bool {
+		return compareBills(order, selected[i], selected[j]) < 0
+	})
+
+	if req.Cursor != "" {
+		cursor, err := DecodeBillCursor(req.Cursor)
+		if err != nil {
+			return nil, err
+		}
+		if cursor.Sort != order {
+			return nil, fmt.Errorf("cursor was issued for sort %s, not %s", cursor.Sort, order)
+		}
+		last := &Bill{ID: cursor.BillID, CreatedAt: cursor.CreatedAt, ClosedAt: cursor.ClosedAt, TotalAmount: cursor.TotalAmount}
+		start := sort.Search(len(selected), func(i int) bool {
+			return compareBills(order, selected[i], last) > 0
+		})
+		selected = selected[start:]
+	}
+	if req.PageSize > 0 && len(selected) > req.PageSize {
+		selected = selected[:req.PageSize]
+	}
+	return selected, nil
+}
+
+// compareBills orders two bills by the sort key, ties are broken by bill ID so pages never overlap
+func compareBills(order BillSort, a, b *Bill) int {
+	var result int
+	switch order {
+	case BillSortCreatedAsc, BillSortCreatedDesc:
+		result = a.CreatedAt.Compare(b.CreatedAt)
+	case BillSortClosedAsc, BillSortClosedDesc:
+		result = a.ClosedAt.Compare(b.ClosedAt)
+	case BillSortTotalAsc, BillSortTotalDesc:
+		switch {
+		case a.TotalAmount < b.TotalAmount:
+			result = -1
+		case a.TotalAmount > b.TotalAmount:
+			result = 1
+		}
+	}
+	if result == 0 {
+		switch {
+		case a.ID < b.ID:
+			result = -1
+		case a.ID > b.ID:
+			result = 1
+		}
+	}
+	if order[0] == '-' {
+		return -result
+	}
+	return result
+}
+
+// inRange checks that t is at or after from and before to, a nil bound is open
+func inRange(t time.Time, from, to *time.Time) bool {
+	if from != nil && t.Before(*from) {
+		return false
+	}
+	if to != nil && !t.Before(*to) {
+		return false
+	}
+	return true
+}
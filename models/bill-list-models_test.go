@@ -0,0 +1,102 @@
+package models
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func listTestBills() []*Bill {
+	day := time.Date(2025, 8, 1, 0, 0, 0, 0, time.UTC)
+	return []*Bill{
+		{ID: "bill-1", Status: StatusClosed, Currency: USD, TotalAmount: 30, CreatedAt: day, ClosedAt: day.AddDate(0, 0, 2)},
+		{ID: "bill-2", Status: StatusOpen, Currency: USD, TotalAmount: 10, CreatedAt: day.AddDate(0, 0, 1)},
+		{ID: "bill-3", Status: StatusClosed, Currency: GEL, TotalAmount: 20, CreatedAt: day.AddDate(0, 0, 2), ClosedAt: day.AddDate(0, 0, 3)},
+		{ID: "bill-4", Status: StatusOpen, Currency: USD, TotalAmount: 20, CreatedAt: day.AddDate(0, 0, 3)},
+	}
+}
+
+func billIDs(bills []*Bill) []string {
+	ids := make([]string, 0, len(bills))
+	for _, bill := range bills {
+		ids = append(ids, bill.ID)
+	}
+	return ids
+}
+
+func TestListBillsRequestMatches(t *testing.T) {
+	bills := listTestBills()
+	from := time.Date(2025, 8, 2, 0, 0, 0, 0, time.UTC)
+	to := time.Date(2025, 8, 4, 0, 0, 0, 0, time.UTC)
+	minTotal, maxTotal := 15.0, 25.0
+
+	tests := []struct {
+		name string
+		req  ListBillsRequest
+		want []string
+	}{
+		{"no filters", ListBillsRequest{}, []string{"bill-1", "bill-2", "bill-3", "bill-4"}},
+		{"status", ListBillsRequest{Status: string(StatusOpen)}, []string{"bill-2", "bill-4"}},
+		{"currency", ListBillsRequest{Currency: GEL}, []string{"bill-3"}},
+		{"created range excludes its end", ListBillsRequest{CreatedFrom: &from, CreatedTo: &to}, []string{"bill-2", "bill-3"}},
+		{"closed range skips open bills", ListBillsRequest{ClosedFrom: &from}, []string{"bill-1", "bill-3"}},
+		{"total range", ListBillsRequest{MinTotal: &minTotal, MaxTotal: &maxTotal}, []string{"bill-3", "bill-4"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			matched := make([]*Bill, 0)
+			for _, bill := range bills {
+				if tt.req.Matches(bill) {
+					matched = append(matched, bill)
+				}
+			}
+			assert.Equal(t, tt.want, billIDs(matched))
+		})
+	}
+}
+
+func TestSelectBills(t *testing.T) {
+	t.Run("sorts", func(t *testing.T) {
+		bills, err := SelectBills(ListBillsRequest{Sort: BillSortCreatedDesc}, listTestBills())
+		assert.NoError(t, err)
+		assert.Equal(t, []string{"bill-4", "bill-3", "bill-2", "bill-1"}, billIDs(bills))
+
+		bills, err = SelectBills(ListBillsRequest{Sort: BillSortTotalAsc}, listTestBills())
+		assert.NoError(t, err)
+		assert.Equal(t, []string{"bill-2", "bill-3", "bill-4", "bill-1"}, billIDs(bills), "Equal totals are ordered by ID")
+	})
+	t.Run("pages with cursors", func(t *testing.T) {
+		req := ListBillsRequest{Sort: BillSortTotalDesc, PageSize: 2}
+		first, err := SelectBills(req, listTestBills())
+		assert.NoError(t, err)
+		assert.Equal(t, []string{"bill-1", "bill-4"}, billIDs(first))
+
+		req.Cursor = NewBillCursor(req.Sort, first[len(first)-1])
+		second, err := SelectBills(req, listTestBills())
+		assert.NoError(t, err)
+		assert.Equal(t, []string{"bill-3", "bill-2"}, billIDs(second))
+
+		req.Cursor = NewBillCursor(req.Sort, second[len(second)-1])
+		last, err := SelectBills(req, listTestBills())
+		assert.NoError(t, err)
+		assert.Empty(t, last)
+	})
+	t.Run("rejects a cursor of another sort", func(t *testing.T) {
+		cursor := NewBillCursor(BillSortCreatedAsc, listTestBills()[0])
+		_, err := SelectBills(ListBillsRequest{Sort: BillSortTotalAsc, Cursor: cursor}, listTestBills())
+		assert.Error(t, err)
+	})
+}
+
+func TestDecodeBillCursor(t *testing.T) {
+	bill := listTestBills()[0]
+	cursor, err := DecodeBillCursor(NewBillCursor("", bill))
+	assert.NoError(t, err)
+	assert.Equal(t, BillSortCreatedAsc, cursor.Sort)
+	assert.Equal(t, bill.ID, cursor.BillID)
+	assert.True(t, bill.CreatedAt.Equal(cursor.CreatedAt))
+
+	_, err = DecodeBillCursor("not a cursor")
+	assert.Error(t, err)
+}
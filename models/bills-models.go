@@ -39,6 +39,9 @@ type Bill struct {
 	ClosedAt    time.Time   `json:"closed_at,omitempty"`
 	CloseReason string      `json:"close_reason,omitempty"`
 	WorkflowID  string      `json:"workflow_id,omitempty"`
+
+	// RecognitionSchedule is built when the bill closes
+	RecognitionSchedule []*RecognitionEntry `json:"recognition_schedule,omitempty"`
 }
 
 // LineItem represents a charge or fee within a bill
@@ -48,12 +51,17 @@ type LineItem struct {
 	Amount      float64   `json:"amount"`
 	Quantity    int       `json:"quantity"`
 	AddedAt     time.Time `json:"added_at"`
+
+	Product     string           `json:"product,omitempty"`
+	Recognition *RecognitionRule `json:"recognition,omitempty"` // Recognised when the bill closes when nil
 }
 
 type StartBillingPeriodRequest struct {
-	CustomerID        string   `json:"customer_id"`
-	Currency          Currency `json:"currency"`
-	BillingPeriodDays int      `json:"billing_period_days"`
+	TenantID          string         `json:"tenant_id,omitempty"` // Defaults to the tenant of the calling API key
+	CustomerID        string         `json:"customer_id"`
+	Currency          Currency       `json:"currency"`
+	BillingPeriodDays int            `json:"billing_period_days"`
+	SpendingLimit     *SpendingLimit `json:"spending_limit,omitempty"`
 }
 
 // CreateBillRequest represents the request to create a new bill
@@ -70,10 +78,12 @@ type CreateBillResponse struct {
 
 // AddLineItemRequest represents the request to add a line item
 type AddLineItemRequest struct {
-	Description string  `json:"description"`
-	Amount      float64 `json:"amount"`
-	Quantity    int     `json:"quantity"`
-	Currency    string  `json:"currency"`
+	Description string           `json:"description"`
+	Amount      float64          `json:"amount"`
+	Quantity    int              `json:"quantity"`
+	Currency    string           `json:"currency"`
+	Product     string           `json:"product,omitempty"`
+	Recognition *RecognitionRule `json:"recognition,omitempty"`
 }
 
 // AddLineItemResponse represents the response when adding a line item
@@ -95,18 +105,35 @@ type CloseBillResponse struct {
 	ClosedAt    time.Time `json:"closed_at"`
 }
 
-// ListBillsRequest represents query parameters for listing bills
+// ListBillsRequest represents query parameters for listing bills, every filter is optional
 type ListBillsRequest struct {
-	Status string `json:"status,omitempty"`
+	Status   string   `json:"status,omitempty"`
+	Currency Currency `json:"currency,omitempty"`
+
+	// Date ranges include their start and exclude their end, open bills never match a closed range
+	CreatedFrom *time.Time `json:"created_from,omitempty"`
+	CreatedTo   *time.Time `json:"created_to,omitempty"`
+	ClosedFrom  *time.Time `json:"closed_from,omitempty"`
+	ClosedTo    *time.Time `json:"closed_to,omitempty"`
+	MinTotal    *float64   `json:"min_total,omitempty"`
+	MaxTotal    *float64   `json:"max_total,omitempty"`
+
+	Sort     BillSort `json:"sort,omitempty"`
+	PageSize int      `json:"page_size,omitempty"` // The API defaults it, the list-bills query returns every bill when 0
+	Cursor   string   `json:"cursor,omitempty"`    // The next_cursor of the previous page
+
+	TenantID string `json:"tenant_id,omitempty"` // Set by the API from the calling API key, workflows of other tenants return nothing
 }
 
 // ListBillsResponse represents the response when listing bills
 type ListBillsResponse struct {
-	Bills []*Bill `json:"bills"`
-	Total int64   `json:"total"`
+	Bills      []*Bill `json:"bills"`
+	Total      int64   `json:"total"`
+	NextCursor string  `json:"next_cursor,omitempty"` // Empty on the last page
 }
 
 type GetBillRequest struct {
+	TenantID   string `json:"tenant_id"`
 	CustomerID string `json:"customer_id"`
 	BillID     string `json:"bill_id"`
 }
@@ -117,10 +144,11 @@ type GetBillResponse struct {
 }
 
 type CloseBillingPeriodResponse struct {
-	WorkflowID     string  `json:"workflow_id"`
-	Bills          []*Bill `json:"bills"`
-	FinalAmountUSD float64 `json:"final_amount_usd"`
-	FinalAmountGEL float64 `json:"final_amount_gel"`
+	WorkflowID       string            `json:"workflow_id"`
+	Bills            []*Bill           `json:"bills"`
+	FinalAmountUSD   float64           `json:"final_amount_usd"`
+	FinalAmountGEL   float64           `json:"final_amount_gel"`
+	ConsolidatedBill *ConsolidatedBill `json:"consolidated_bill,omitempty"` // Set when the customer has child accounts
 }
 
 // ErrorResponse represents an API error response
@@ -159,8 +187,7 @@ func (b *Bill) CanAddLineItems() bool {
 func (b *Bill) CalculateTotal() float64 {
 	total := 0.0
 	for _, item := range b.LineItems {
-		itemTotal := item.Amount * float64(item.Quantity)
-		total += itemTotal
+		total += item.Total()
 	}
 	return total
 }
@@ -168,16 +195,38 @@ func (b *Bill) CalculateTotal() float64 {
 // AddLineItem adds a new line item to the bill and updates the total
 func (b *Bill) AddLineItem(lineItem *LineItem) {
 	b.LineItems = append(b.LineItems, lineItem)
-	b.TotalAmount += lineItem.Amount * float64(lineItem.Quantity)
+	b.TotalAmount += lineItem.Total()
+}
+
+// Total returns what the line item charges, its amount per unit times the quantity
+func (l *LineItem) Total() float64 {
+	return l.Amount * float64(l.Quantity)
+}
+
+// AccruedAmount returns the unit amount a line item is billed at: the amount converted from the currency it
+// was priced in to the bill currency and scaled by the accrual factor of the billing period
+func AccruedAmount(amount float64, from Currency, billCurrency Currency, accrualFactor float64) float64 {
+	return ConvertCurrencyAmount(from, billCurrency, amount) * accrualFactor
+}
+
+// FindLineItem returns the line item of the bill with the given ID, or nil
+func (b *Bill) FindLineItem(id string) *LineItem {
+	for _, item := range b.LineItems {
+		if item.ID == id {
+			return item
+		}
+	}
+	return nil
 }
 
-// Close closes the bill with the given reason
-func (b *Bill) Close(reason string) {
-	now := time.Now()
+// Close closes the bill with the given reason at closedAt, workflows pass workflow.Now so the close time and
+// the recognition schedule built from it are the same when the workflow is replayed
+func (b *Bill) Close(reason string, closedAt time.Time) {
 	b.Status = StatusClosed
-	b.ClosedAt = now
+	b.ClosedAt = closedAt
 	b.CloseReason = reason
 	b.TotalAmount = b.CalculateTotal()
+	b.RecognitionSchedule = NewRecognitionSchedule(b)
 }
 
 func ConvertCurrencyAmount(currency1 Currency, currency2 Currency, amount float64) float64 {
@@ -2,6 +2,7 @@ package models
 
 import (
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 )
@@ -79,9 +80,10 @@ func TestBill_Close(t *testing.T) {
 		Status:    StatusOpen,
 		LineItems: []*LineItem{{Amount: 3, Quantity: 2}},
 	}
-	b.Close("done")
+	closedAt := time.Date(2025, 3, 31, 23, 59, 0, 0, time.UTC)
+	b.Close("done", closedAt)
 	assert.Equal(t, StatusClosed, b.Status)
-	assert.NotZero(t, b.ClosedAt)
+	assert.Equal(t, closedAt, b.ClosedAt)
 	assert.Equal(t, "done", b.CloseReason)
 	assert.Equal(t, 6.0, b.TotalAmount)
 }
@@ -0,0 +1,74 @@
+package models
+
+import (
+	"time"
+)
+
+// ChildBills holds the finalized bills of one account in a customer hierarchy
+type ChildBills struct {
+	CustomerID string  `json:"customer_id"`
+	WorkflowID string  `json:"workflow_id"`
+	Bills      []*Bill `json:"bills"`
+}
+
+// ChildSubtotal represents one account's share of a consolidated bill
+type ChildSubtotal struct {
+	CustomerID string   `json:"customer_id"`
+	WorkflowID string   `json:"workflow_id"`
+	BillIDs    []string `json:"bill_ids"`
+	Subtotal   float64  `json:"subtotal"` // In the parent's currency
+}
+
+// ConsolidatedBill represents the single bill issued to a parent account for all of its subsidiaries
+type ConsolidatedBill struct {
+	ParentCustomerID string           `json:"parent_customer_id"`
+	WorkflowID       string           `json:"workflow_id"` // The parent's billing period
+	Currency         Currency         `json:"currency"`
+	ChildSubtotals   []*ChildSubtotal `json:"child_subtotals"`
+	TotalAmount      float64          `json:"total_amount"`
+	GeneratedAt      time.Time        `json:"generated_at"`
+}
+
+// ConsolidateBillingPeriodInput represents the parent's billing period consolidated when it ends. ChildAccounts
+// holds the child accounts closed before the parent, the others are closed by the consolidation.
+type ConsolidateBillingPeriodInput struct {
+	TenantID      string        `json:"tenant_id"`
+	CustomerID    string        `json:"customer_id"`
+	WorkflowID    string        `json:"workflow_id"`
+	Bills         []*Bill       `json:"bills"`
+	ChildAccounts []*ChildBills `json:"child_accounts,omitempty"`
+	StartedAt     time.Time     `json:"started_at"`
+	ClosedAt      time.Time     `json:"closed_at"`
+}
+
+// ListConsolidatedBillsResponse represents the consolidated bills of a parent account, newest first
+type ListConsolidatedBillsResponse struct {
+	ConsolidatedBills []*ConsolidatedBill `json:"consolidated_bills"`
+}
+
+// ConsolidateBills converts every account's closed bills to the parent's currency and totals them per account
+func ConsolidateBills(parentCustomerID string, currency Currency, accounts []*ChildBills, generatedAt time.Time) *ConsolidatedBill {
+	consolidated := &ConsolidatedBill{
+		ParentCustomerID: parentCustomerID,
+		Currency:         currency,
+		ChildSubtotals:   make([]*ChildSubtotal, 0, len(accounts)),
+		GeneratedAt:      generatedAt,
+	}
+	for _, account := range accounts {
+		subtotal := &ChildSubtotal{
+			CustomerID: account.CustomerID,
+			WorkflowID: account.WorkflowID,
+			BillIDs:    make([]string, 0, len(account.Bills)),
+		}
+		for _, bill := range account.Bills {
+			if bill.Status != StatusClosed {
+				continue
+			}
+			subtotal.BillIDs = append(subtotal.BillIDs, bill.ID)
+			subtotal.Subtotal += ConvertCurrencyAmount(bill.Currency, currency, bill.TotalAmount)
+		}
+		consolidated.TotalAmount += subtotal.Subtotal
+		consolidated.ChildSubtotals = append(consolidated.ChildSubtotals, subtotal)
+	}
+	return consolidated
+}
@@ -0,0 +1,44 @@
+package models
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestConsolidateBills(t *testing.T) {
+	generatedAt := time.Date(2025, 9, 1, 0, 0, 0, 0, time.UTC)
+	accounts := []*ChildBills{
+		{
+			CustomerID: "parent",
+			WorkflowID: "wf-parent",
+			Bills: []*Bill{
+				{ID: "p-1", Status: StatusClosed, Currency: GEL, TotalAmount: 25},
+			},
+		},
+		{
+			CustomerID: "child-usd",
+			WorkflowID: "wf-child-usd",
+			Bills: []*Bill{
+				{ID: "c-1", Status: StatusClosed, Currency: USD, TotalAmount: 10},
+				{ID: "c-2", Status: StatusClosed, Currency: GEL, TotalAmount: 5},
+				{ID: "c-3", Status: StatusOpen, Currency: USD, TotalAmount: 100},
+			},
+		},
+		{CustomerID: "child-empty", WorkflowID: "wf-child-empty"},
+	}
+
+	consolidated := ConsolidateBills("parent", GEL, accounts, generatedAt)
+
+	assert.Equal(t, "parent", consolidated.ParentCustomerID)
+	assert.Equal(t, GEL, consolidated.Currency)
+	assert.Equal(t, generatedAt, consolidated.GeneratedAt)
+	assert.Len(t, consolidated.ChildSubtotals, 3)
+	assert.Equal(t, 25.0, consolidated.ChildSubtotals[0].Subtotal)
+	assert.Equal(t, []string{"c-1", "c-2"}, consolidated.ChildSubtotals[1].BillIDs)
+	assert.Equal(t, 30.0, consolidated.ChildSubtotals[1].Subtotal)
+	assert.Equal(t, 0.0, consolidated.ChildSubtotals[2].Subtotal)
+	assert.Empty(t, consolidated.ChildSubtotals[2].BillIDs)
+	assert.Equal(t, 55.0, consolidated.TotalAmount)
+}
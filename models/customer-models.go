@@ -0,0 +1,127 @@
+package models
+
+import (
+	"slices"
+	"time"
+)
+
+// TaxIDTypeVAT identifies a VAT registration number, used as the buyer VAT identifier on e-invoices
+const TaxIDTypeVAT = "VAT"
+
+// TaxID represents a tax registration of a customer
+type TaxID struct {
+	Type  string `json:"type"` // e.g. VAT, EIN, GST
+	Value string `json:"value"`
+}
+
+// CustomerProfile represents a customer account referenced by billing periods, invoices, tax and dunning
+type CustomerProfile struct {
+	TenantID         string    `json:"tenant_id"`
+	CustomerID       string    `json:"customer_id"`
+	LegalName        string    `json:"legal_name"`
+	BillingAddress   Address   `json:"billing_address"`
+	TaxIDs           []TaxID   `json:"tax_ids"`
+	CompanyID        string    `json:"company_id,omitempty"`
+	DefaultCurrency  Currency  `json:"default_currency"`
+	PaymentTermsDays int       `json:"payment_terms_days"`
+	Locale           string    `json:"locale"`
+	ContactEmails    []string  `json:"contact_emails"`
+	EndpointID       string    `json:"endpoint_id,omitempty"`
+	EndpointScheme   string    `json:"endpoint_scheme,omitempty"`
+	ParentCustomerID string    `json:"parent_customer_id,omitempty"`
+	CreatedAt        time.Time `json:"created_at"`
+	UpdatedAt        time.Time `json:"updated_at"`
+}
+
+// CustomerDetails holds the editable fields of a customer profile
+type CustomerDetails struct {
+	LegalName        string   `json:"legal_name"`
+	BillingAddress   Address  `json:"billing_address"`
+	TaxIDs           []TaxID  `json:"tax_ids"`
+	CompanyID        string   `json:"company_id,omitempty"`
+	DefaultCurrency  Currency `json:"default_currency"`
+	PaymentTermsDays int      `json:"payment_terms_days"`
+	Locale           string   `json:"locale"`
+	ContactEmails    []string `json:"contact_emails"`
+	EndpointID       string   `json:"endpoint_id,omitempty"`
+	EndpointScheme   string   `json:"endpoint_scheme,omitempty"`
+	ParentCustomerID string   `json:"parent_customer_id,omitempty"` // Set on child accounts billed through their parent
+}
+
+// CreateCustomerRequest represents the request to create a customer, an ID is generated when omitted
+type CreateCustomerRequest struct {
+	CustomerID string `json:"customer_id,omitempty"`
+	CustomerDetails
+}
+
+// UpdateCustomerRequest represents the request to replace a customer's profile
+type UpdateCustomerRequest struct {
+	CustomerDetails
+}
+
+// CustomerResponse represents the response for a single customer
+type CustomerResponse struct {
+	Customer *CustomerProfile `json:"customer"`
+}
+
+// ListCustomersResponse represents the response when listing customers
+type ListCustomersResponse struct {
+	Customers []*CustomerProfile `json:"customers"`
+	Total     int64              `json:"total"`
+}
+
+// SellerControlledChanges returns the JSON names of the fields the update changes that the seller decides on
+// rather than the customer: payment terms, legal and tax registrations, the billing currency and the parent
+func (c *CustomerProfile) SellerControlledChanges(details CustomerDetails) []string {
+	changed := make([]string, 0)
+	if details.PaymentTermsDays != c.PaymentTermsDays {
+		changed = append(changed, "payment_terms_days")
+	}
+	if !slices.Equal(details.TaxIDs, c.TaxIDs) {
+		changed = append(changed, "tax_ids")
+	}
+	if details.CompanyID != c.CompanyID {
+		changed = append(changed, "company_id")
+	}
+	if details.DefaultCurrency != c.DefaultCurrency {
+		changed = append(changed, "default_currency")
+	}
+	if details.ParentCustomerID != c.ParentCustomerID {
+		changed = append(changed, "parent_customer_id")
+	}
+	return changed
+}
+
+// IsChildAccount returns true if the customer is billed through a parent account
+func (c *CustomerProfile) IsChildAccount() bool {
+	return c.ParentCustomerID != ""
+}
+
+// VATID returns the customer's VAT registration number, if any
+func (c *CustomerProfile) VATID() string {
+	for _, taxID := range c.TaxIDs {
+		if taxID.Type == TaxIDTypeVAT {
+			return taxID.Value
+		}
+	}
+	return ""
+}
+
+// NewCustomerProfile builds a profile of the tenant for the given ID from its editable details
+func NewCustomerProfile(tenantID string, customerID string, details CustomerDetails) *CustomerProfile {
+	return &CustomerProfile{
+		TenantID:         tenantID,
+		CustomerID:       customerID,
+		LegalName:        details.LegalName,
+		BillingAddress:   details.BillingAddress,
+		TaxIDs:           details.TaxIDs,
+		CompanyID:        details.CompanyID,
+		DefaultCurrency:  details.DefaultCurrency,
+		PaymentTermsDays: details.PaymentTermsDays,
+		Locale:           details.Locale,
+		ContactEmails:    details.ContactEmails,
+		EndpointID:       details.EndpointID,
+		EndpointScheme:   details.EndpointScheme,
+		ParentCustomerID: details.ParentCustomerID,
+	}
+}
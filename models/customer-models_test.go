@@ -0,0 +1,68 @@
+package models
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCustomerProfile_VATID(t *testing.T) {
+	t.Parallel()
+	t.Run("vat registered", func(t *testing.T) {
+		c := &CustomerProfile{TaxIDs: []TaxID{{Type: "EIN", Value: "12-3456789"}, {Type: TaxIDTypeVAT, Value: "NL123456789B01"}}}
+		assert.Equal(t, "NL123456789B01", c.VATID())
+	})
+	t.Run("no vat registration", func(t *testing.T) {
+		c := &CustomerProfile{TaxIDs: []TaxID{{Type: "EIN", Value: "12-3456789"}}}
+		assert.Empty(t, c.VATID())
+	})
+}
+
+func TestNewCustomerProfile(t *testing.T) {
+	details := CustomerDetails{
+		LegalName:        "Buyer BV",
+		DefaultCurrency:  USD,
+		PaymentTermsDays: 30,
+		Locale:           "nl-NL",
+		ContactEmails:    []string{"billing@buyer.example"},
+	}
+	c := NewCustomerProfile("retail", "cust-1", details)
+	assert.Equal(t, "retail", c.TenantID)
+	assert.Equal(t, "cust-1", c.CustomerID)
+	assert.Equal(t, "Buyer BV", c.LegalName)
+	assert.Equal(t, USD, c.DefaultCurrency)
+	assert.Equal(t, 30, c.PaymentTermsDays)
+	assert.Equal(t, []string{"billing@buyer.example"}, c.ContactEmails)
+}
+
+func TestCustomerProfile_SellerControlledChanges(t *testing.T) {
+	t.Parallel()
+	details := CustomerDetails{
+		LegalName:        "Buyer BV",
+		TaxIDs:           []TaxID{{Type: TaxIDTypeVAT, Value: "NL123456789B01"}},
+		CompanyID:        "KVK 12345678",
+		DefaultCurrency:  USD,
+		PaymentTermsDays: 30,
+		Locale:           "nl-NL",
+		ParentCustomerID: "parent-1",
+	}
+	c := NewCustomerProfile("retail", "cust-1", details)
+
+	t.Run("customer details", func(t *testing.T) {
+		update := details
+		update.LegalName = "Buyer B.V."
+		update.Locale = "en-GB"
+		update.ContactEmails = []string{"ap@buyer.example"}
+		assert.Empty(t, c.SellerControlledChanges(update))
+	})
+	t.Run("seller details", func(t *testing.T) {
+		update := details
+		update.PaymentTermsDays = 90
+		update.TaxIDs = nil
+		update.CompanyID = ""
+		update.DefaultCurrency = GEL
+		update.ParentCustomerID = ""
+		assert.Equal(t, []string{"payment_terms_days", "tax_ids", "company_id", "default_currency", "parent_customer_id"},
+			c.SellerControlledChanges(update))
+	})
+}
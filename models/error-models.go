@@ -0,0 +1,59 @@
+package models
+
+import (
+	"fmt"
+
+	"encore.dev/beta/errs"
+)
+
+// ErrorReason is the machine-readable reason of an API error, clients branch on it rather than on messages
+type ErrorReason string
+
+const (
+	ReasonInvalidRequest          ErrorReason = "invalid_request"
+	ReasonCurrencyNotEnabled      ErrorReason = "currency_not_enabled"
+	ReasonBillingPeriodNotFound   ErrorReason = "billing_period_not_found"
+	ReasonBillingPeriodExists     ErrorReason = "billing_period_exists"
+	ReasonBillNotFound            ErrorReason = "bill_not_found"
+	ReasonBillClosed              ErrorReason = "bill_closed"
+	ReasonSpendingLimitExceeded   ErrorReason = "spending_limit_exceeded"
+	ReasonCustomerNotFound        ErrorReason = "customer_not_found"
+	ReasonCustomerExists          ErrorReason = "customer_exists"
+	ReasonCustomerHasChildren     ErrorReason = "customer_has_children"
+	ReasonWalletNotFound          ErrorReason = "wallet_not_found"
+	ReasonWalletExists            ErrorReason = "wallet_exists"
+	ReasonTenantNotFound          ErrorReason = "tenant_not_found"
+	ReasonTenantExists            ErrorReason = "tenant_exists"
+	ReasonAPIKeyNotFound          ErrorReason = "api_key_not_found"
+	ReasonWebhookNotFound         ErrorReason = "webhook_not_found"
+	ReasonDeliveryNotDeadLettered ErrorReason = "delivery_not_dead_lettered"
+	ReasonSellerNotConfigured     ErrorReason = "seller_not_configured"
+	ReasonInvoiceIncomplete       ErrorReason = "invoice_incomplete"
+	ReasonBillNotBooked           ErrorReason = "bill_not_booked"
+	ReasonExceedsOutstanding      ErrorReason = "exceeds_outstanding"
+	ReasonEntryExists             ErrorReason = "entry_exists"
+	ReasonWorkflowUnavailable     ErrorReason = "workflow_unavailable"
+	ReasonWorkflowRequestRejected ErrorReason = "workflow_request_rejected"
+	ReasonInternal                ErrorReason = "internal"
+)
+
+// ErrDetails marks ErrorResponse as the details of Encore API errors, every error carries its reason in code
+func (ErrorResponse) ErrDetails() {}
+
+// NewAPIError returns an API error with the status of code and the machine-readable reason in its details
+func NewAPIError(code errs.ErrCode, reason ErrorReason, format string, args ...interface{}) *errs.Error {
+	message := fmt.Sprintf(format, args...)
+	return &errs.Error{Code: code, Message: message, Details: ErrorResponse{Error: message, Code: string(reason)}}
+}
+
+// NewAPIErrorWithCause is NewAPIError with the error behind it in the details
+func NewAPIErrorWithCause(code errs.ErrCode, reason ErrorReason, cause error, format string, args ...interface{}) *errs.Error {
+	apiErr := NewAPIError(code, reason, format, args...)
+	apiErr.Details = ErrorResponse{Error: apiErr.Message, Code: string(reason), Details: cause.Error()}
+	return apiErr
+}
+
+// InvalidRequest returns a failed request validation as an invalid argument error
+func InvalidRequest(err error) *errs.Error {
+	return NewAPIError(errs.InvalidArgument, ReasonInvalidRequest, "%s", err.Error())
+}
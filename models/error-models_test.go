@@ -0,0 +1,33 @@
+package models
+
+import (
+	"errors"
+	"testing"
+
+	"encore.dev/beta/errs"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewAPIError(t *testing.T) {
+	apiErr := NewAPIError(errs.NotFound, ReasonCustomerNotFound, "customer %s not found", "cust-1")
+	assert.Equal(t, errs.NotFound, apiErr.Code)
+	assert.Equal(t, "customer cust-1 not found", apiErr.Message)
+	assert.Equal(t, ErrorResponse{Error: "customer cust-1 not found", Code: "customer_not_found"}, apiErr.Details)
+}
+
+func TestNewAPIErrorWithCause(t *testing.T) {
+	apiErr := NewAPIErrorWithCause(errs.Unavailable, ReasonWorkflowUnavailable, errors.New("connection refused"), "failed to get bill %s", "bill-1")
+	assert.Equal(t, errs.Unavailable, apiErr.Code)
+	assert.Equal(t, "failed to get bill bill-1", apiErr.Message)
+	details, ok := apiErr.Details.(ErrorResponse)
+	assert.True(t, ok)
+	assert.Equal(t, "workflow_unavailable", details.Code)
+	assert.Equal(t, "connection refused", details.Details)
+}
+
+func TestInvalidRequest(t *testing.T) {
+	apiErr := InvalidRequest(errors.New("customer_id is required"))
+	assert.Equal(t, errs.InvalidArgument, apiErr.Code)
+	assert.Equal(t, "customer_id is required", apiErr.Message)
+	assert.Equal(t, ErrorResponse{Error: "customer_id is required", Code: "invalid_request"}, apiErr.Details)
+}
@@ -0,0 +1,76 @@
+package models
+
+import (
+	"fmt"
+	"time"
+)
+
+// BillCreatedEvent is published when a bill is opened in a billing period
+type BillCreatedEvent struct {
+	EventID    string    `json:"event_id"`
+	TenantID   string    `json:"tenant_id,omitempty"`
+	CustomerID string    `json:"customer_id"`
+	WorkflowID string    `json:"workflow_id"`
+	BillID     string    `json:"bill_id"`
+	Currency   Currency  `json:"currency"`
+	CreatedAt  time.Time `json:"created_at"`
+}
+
+// LineItemAddedEvent is published when a line item is added to a bill
+type LineItemAddedEvent struct {
+	EventID     string    `json:"event_id"`
+	TenantID    string    `json:"tenant_id,omitempty"`
+	CustomerID  string    `json:"customer_id"`
+	WorkflowID  string    `json:"workflow_id"`
+	BillID      string    `json:"bill_id"`
+	LineItem    *LineItem `json:"line_item"`
+	Currency    Currency  `json:"currency"`
+	TotalAmount float64   `json:"total_amount"`
+}
+
+// BillClosedEvent is published when a bill is closed, by signal or at the end of the billing period
+type BillClosedEvent struct {
+	EventID    string `json:"event_id"`
+	TenantID   string `json:"tenant_id,omitempty"`
+	CustomerID string `json:"customer_id"`
+	WorkflowID string `json:"workflow_id"`
+	Bill       *Bill  `json:"bill"`
+}
+
+// BillingPeriodClosedEvent is published once a billing period has finalized all of its bills
+type BillingPeriodClosedEvent struct {
+	EventID          string            `json:"event_id"`
+	TenantID         string            `json:"tenant_id,omitempty"`
+	CustomerID       string            `json:"customer_id"`
+	WorkflowID       string            `json:"workflow_id"`
+	Bills            []*Bill           `json:"bills"`
+	ClosedAt         time.Time         `json:"closed_at"`
+	ConsolidatedBill *ConsolidatedBill `json:"consolidated_bill,omitempty"` // Set when the customer is a parent account
+}
+
+// Event IDs are derived from workflow state so retried activities and replays publish the same ID
+
+// BillCreatedEventID returns the event ID of the creation of a bill
+func BillCreatedEventID(billID string) string {
+	return fmt.Sprintf("bill-created/%s", billID)
+}
+
+// LineItemAddedEventID returns the event ID of a line item added to a bill
+func LineItemAddedEventID(billID string, lineItemID string) string {
+	return fmt.Sprintf("line-item-added/%s/%s", billID, lineItemID)
+}
+
+// BillClosedEventID returns the event ID of the closing of a bill
+func BillClosedEventID(billID string) string {
+	return fmt.Sprintf("bill-closed/%s", billID)
+}
+
+// BillingPeriodClosedEventID returns the event ID of the end of a billing period
+func BillingPeriodClosedEventID(workflowID string) string {
+	return fmt.Sprintf("billing-period-closed/%s", workflowID)
+}
+
+// SpendingAlertEventID returns the event ID of a threshold crossed under a spending limit
+func SpendingAlertEventID(workflowID string, alert SpendingAlert) string {
+	return fmt.Sprintf("spending-alert/%s/%v%s/%v", workflowID, alert.HardCap, alert.Currency, alert.Threshold)
+}
@@ -0,0 +1,19 @@
+package models
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEventIDs(t *testing.T) {
+	assert.Equal(t, "bill-created/bill-1", BillCreatedEventID("bill-1"))
+	assert.Equal(t, "line-item-added/bill-1/item-1", LineItemAddedEventID("bill-1", "item-1"))
+	assert.Equal(t, "bill-closed/bill-1", BillClosedEventID("bill-1"))
+	assert.Equal(t, "billing-period-closed/wf-1", BillingPeriodClosedEventID("wf-1"))
+	assert.Equal(t, "spending-alert/wf-1/100USD/0.8",
+		SpendingAlertEventID("wf-1", SpendingAlert{Threshold: 0.8, HardCap: 100, Currency: USD}))
+	assert.NotEqual(t,
+		SpendingAlertEventID("wf-1", SpendingAlert{Threshold: 0.8, HardCap: 100, Currency: USD}),
+		SpendingAlertEventID("wf-1", SpendingAlert{Threshold: 0.8, HardCap: 150, Currency: USD}))
+}
@@ -0,0 +1,83 @@
+package models
+
+import (
+	"runtime/debug"
+	"time"
+)
+
+// Readiness statuses, a probe treats anything but ready as a failure
+const (
+	StatusReady    = "ready"
+	StatusNotReady = "not_ready"
+)
+
+// BuildInfo identifies the running build
+type BuildInfo struct {
+	Version     string `json:"version"`            // VCS revision, "dev" for builds without VCS information
+	Modified    bool   `json:"modified,omitempty"` // Built from a working tree with uncommitted changes
+	CommittedAt string `json:"committed_at,omitempty"`
+	GoVersion   string `json:"go_version"`
+	Environment string `json:"environment,omitempty"`
+	DeployID    string `json:"deploy_id,omitempty"`
+}
+
+// LivenessResponse reports that the process is up, it does not depend on Temporal
+type LivenessResponse struct {
+	Status        string    `json:"status"`
+	Service       string    `json:"service"`
+	StartedAt     time.Time `json:"started_at"`
+	UptimeSeconds int64     `json:"uptime_seconds"`
+	Build         BuildInfo `json:"build"`
+}
+
+// TaskQueueHealth describes the pollers and backlog of a task queue as Temporal sees them
+type TaskQueueHealth struct {
+	TaskQueue       string `json:"task_queue"`
+	LocalWorker     bool   `json:"local_worker"` // A worker of this instance polls the queue
+	WorkflowPollers int    `json:"workflow_pollers"`
+	ActivityPollers int    `json:"activity_pollers"`
+	WorkflowBacklog int64  `json:"workflow_backlog"`
+	ActivityBacklog int64  `json:"activity_backlog"`
+	Error           string `json:"error,omitempty"` // Set when the queue could not be described
+}
+
+// ReadinessResponse reports whether the instance can serve billing operations, with the diagnostics behind it
+type ReadinessResponse struct {
+	Status               string             `json:"status"`
+	Service              string             `json:"service"`
+	Namespace            string             `json:"namespace"`
+	Temporal             string             `json:"temporal"` // "connected" or why the connection failed
+	TaskQueues           []*TaskQueueHealth `json:"task_queues"`
+	ActiveBillingPeriods int64              `json:"active_billing_periods"` // -1 when visibility could not be queried
+	Build                BuildInfo          `json:"build"`
+	Problems             []string           `json:"problems,omitempty"` // Why the instance is not ready
+}
+
+// ErrDetails lets a not ready response travel as the details of an Unavailable error
+func (ReadinessResponse) ErrDetails() {}
+
+// NewBuildInfo reads the build of the running binary
+func NewBuildInfo(info *debug.BuildInfo) BuildInfo {
+	build := BuildInfo{Version: "dev"}
+	if info == nil {
+		return build
+	}
+	build.GoVersion = info.GoVersion
+	for _, setting := range info.Settings {
+		switch setting.Key {
+		case "vcs.revision":
+			build.Version = setting.Value
+		case "vcs.time":
+			build.CommittedAt = setting.Value
+		case "vcs.modified":
+			build.Modified = setting.Value == "true"
+		}
+	}
+	return build
+}
+
+// AddProblem records why the instance is not ready
+func (r *ReadinessResponse) AddProblem(problem string) {
+	r.Problems = append(r.Problems, problem)
+	r.Status = StatusNotReady
+}
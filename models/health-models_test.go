@@ -0,0 +1,36 @@
+package models
+
+import (
+	"runtime/debug"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewBuildInfo(t *testing.T) {
+	build := NewBuildInfo(&debug.BuildInfo{
+		GoVersion: "go1.24.6",
+		Settings: []debug.BuildSetting{
+			{Key: "vcs.revision", Value: "8266804"},
+			{Key: "vcs.time", Value: "2025-03-01T10:00:00Z"},
+			{Key: "vcs.modified", Value: "true"},
+		},
+	})
+	assert.Equal(t, "8266804", build.Version)
+	assert.Equal(t, "2025-03-01T10:00:00Z", build.CommittedAt)
+	assert.True(t, build.Modified)
+	assert.Equal(t, "go1.24.6", build.GoVersion)
+
+	build = NewBuildInfo(&debug.BuildInfo{GoVersion: "go1.24.6"})
+	assert.Equal(t, "dev", build.Version)
+	assert.False(t, build.Modified)
+
+	assert.Equal(t, "dev", NewBuildInfo(nil).Version)
+}
+
+func TestReadinessResponseAddProblem(t *testing.T) {
+	resp := &ReadinessResponse{Status: StatusReady}
+	resp.AddProblem("Temporal is unreachable")
+	assert.Equal(t, StatusNotReady, resp.Status)
+	assert.Equal(t, []string{"Temporal is unreachable"}, resp.Problems)
+}
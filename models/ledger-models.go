@@ -0,0 +1,397 @@
+package models
+
+import (
+	"fmt"
+	"math"
+	"time"
+)
+
+// LedgerAccount is an account of the double-entry ledger
+type LedgerAccount string
+
+const (
+	AccountsReceivable LedgerAccount = "accounts_receivable"
+	AccountRevenue     LedgerAccount = "revenue"
+	AccountDeferred    LedgerAccount = "deferred_revenue" // Billed revenue not earned yet
+	AccountTaxPayable  LedgerAccount = "tax_payable"
+	AccountCash        LedgerAccount = "cash"
+	AccountFXGainLoss  LedgerAccount = "fx_gain_loss" // Credit balance is a net gain
+)
+
+// JournalEntryKind tells what produced a journal entry
+type JournalEntryKind string
+
+const (
+	JournalBillClosed JournalEntryKind = "bill_closed"
+	JournalPayment    JournalEntryKind = "payment"
+	JournalCredit     JournalEntryKind = "credit"
+	JournalRecognized JournalEntryKind = "recognition"
+)
+
+// balanceTolerance absorbs float rounding when comparing amounts in cents
+const balanceTolerance = 0.005
+
+// JournalLine debits or credits one account, exactly one of Debit and Credit is positive
+type JournalLine struct {
+	Account LedgerAccount `json:"account"`
+	Debit   float64       `json:"debit"`
+	Credit  float64       `json:"credit"`
+}
+
+// JournalEntry is a balanced, immutable set of journal lines in a single currency
+type JournalEntry struct {
+	EntryID    string           `json:"entry_id"`
+	TenantID   string           `json:"tenant_id"`
+	CustomerID string           `json:"customer_id"`
+	BillID     string           `json:"bill_id"`
+	Kind       JournalEntryKind `json:"kind"`
+	Currency   Currency         `json:"currency"`
+	Memo       string           `json:"memo,omitempty"`
+	Lines      []JournalLine    `json:"lines"`
+	PostedAt   time.Time        `json:"posted_at"`
+	DueAt      *time.Time       `json:"due_at,omitempty"` // Bill closed entries only, when the bill is to be paid
+}
+
+// RecordPaymentRequest represents a payment received against a closed bill
+type RecordPaymentRequest struct {
+	PaymentID    string    `json:"payment_id,omitempty"` // Recording the same payment ID twice posts it once
+	BillID       string    `json:"bill_id"`
+	Amount       float64   `json:"amount"`
+	Currency     Currency  `json:"currency"`
+	ExchangeRate float64   `json:"exchange_rate,omitempty"` // Bill currency per unit of the payment currency, defaults to the reference rate
+	ReceivedAt   time.Time `json:"received_at,omitempty"`
+}
+
+// IssueCreditRequest represents a credit note reducing what the customer owes on a closed bill
+type IssueCreditRequest struct {
+	CreditID string  `json:"credit_id,omitempty"` // Issuing the same credit ID twice posts it once
+	BillID   string  `json:"bill_id"`
+	Amount   float64 `json:"amount"` // In the bill currency, tax included
+	Reason   string  `json:"reason"`
+}
+
+// JournalEntryResponse represents a posted journal entry
+type JournalEntryResponse struct {
+	Entry       *JournalEntry `json:"entry"`
+	Outstanding float64       `json:"outstanding"` // Receivable left on the bill after the entry
+}
+
+// TrialBalanceRequest represents the currency to report the trial balance in, all currencies when empty
+type TrialBalanceRequest struct {
+	Currency Currency `query:"currency"`
+}
+
+// TrialBalanceLine represents the totals of an account
+type TrialBalanceLine struct {
+	Account LedgerAccount `json:"account"`
+	Debit   float64       `json:"debit"`
+	Credit  float64       `json:"credit"`
+	Balance float64       `json:"balance"` // On the account's normal side
+}
+
+// TrialBalance represents the account totals of one currency
+type TrialBalance struct {
+	Currency    Currency            `json:"currency"`
+	Lines       []*TrialBalanceLine `json:"lines"`
+	TotalDebit  float64             `json:"total_debit"`
+	TotalCredit float64             `json:"total_credit"`
+	Balanced    bool                `json:"balanced"`
+}
+
+// TrialBalanceResponse represents the trial balance of a tenant, one per currency
+type TrialBalanceResponse struct {
+	TenantID string          `json:"tenant_id"`
+	Balances []*TrialBalance `json:"balances"`
+}
+
+// AccountStatementRequest represents the currency of an account statement, entries are never converted
+type AccountStatementRequest struct {
+	Currency Currency `query:"currency"`
+}
+
+// AccountStatementLine represents a journal line of the account with the running balance after it
+type AccountStatementLine struct {
+	EntryID  string           `json:"entry_id"`
+	BillID   string           `json:"bill_id"`
+	Kind     JournalEntryKind `json:"kind"`
+	Memo     string           `json:"memo,omitempty"`
+	Debit    float64          `json:"debit"`
+	Credit   float64          `json:"credit"`
+	Balance  float64          `json:"balance"`
+	PostedAt time.Time        `json:"posted_at"`
+}
+
+// AccountStatementResponse represents the movements of a customer's account
+type AccountStatementResponse struct {
+	CustomerID string                  `json:"customer_id"`
+	Account    LedgerAccount           `json:"account"`
+	Currency   Currency                `json:"currency"`
+	Lines      []*AccountStatementLine `json:"lines"`
+	Balance    float64                 `json:"balance"`
+}
+
+// CustomerStatementRequest represents the dates of a statement of account, both inclusive in YYYY-MM-DD form
+type CustomerStatementRequest struct {
+	From     string   `query:"from"` // From the first entry when empty
+	To       string   `query:"to"`   // Up to today when empty
+	Currency Currency `query:"currency"`
+}
+
+// CustomerStatement represents what a customer was charged, paid and credited over a date range, across
+// every billing period
+type CustomerStatement struct {
+	CustomerID     string                  `json:"customer_id"`
+	Currency       Currency                `json:"currency"`
+	From           time.Time               `json:"from"`
+	To             time.Time               `json:"to"`
+	OpeningBalance float64                 `json:"opening_balance"`
+	Lines          []*AccountStatementLine `json:"lines"`
+	Charged        float64                 `json:"charged"`  // Closed bills
+	Paid           float64                 `json:"paid"`     // Payments
+	Credited       float64                 `json:"credited"` // Credit notes
+	ClosingBalance float64                 `json:"closing_balance"`
+}
+
+// IsValid checks if the account is part of the chart of accounts
+func (a LedgerAccount) IsValid() bool {
+	switch a {
+	case AccountsReceivable, AccountRevenue, AccountDeferred, AccountTaxPayable, AccountCash, AccountFXGainLoss:
+		return true
+	}
+	return false
+}
+
+// IsDebitNormal returns true for asset accounts, whose balance grows with debits
+func (a LedgerAccount) IsDebitNormal() bool {
+	return a == AccountsReceivable || a == AccountCash
+}
+
+// NormalBalance returns the balance of the debit and credit totals on the account's normal side
+func (a LedgerAccount) NormalBalance(debit, credit float64) float64 {
+	if a.IsDebitNormal() {
+		return RoundAmount(debit - credit)
+	}
+	return RoundAmount(credit - debit)
+}
+
+// RoundAmount rounds an amount to cents, every amount posted to the ledger is rounded
+func RoundAmount(amount float64) float64 {
+	return math.Round(amount*100) / 100
+}
+
+// Validate checks that the entry has at least two lines on known accounts and that debits equal credits
+func (e *JournalEntry) Validate() error {
+	if len(e.Lines) < 2 {
+		return fmt.Errorf("journal entry %s needs at least two lines", e.EntryID)
+	}
+	debit, credit := 0.0, 0.0
+	for _, line := range e.Lines {
+		if !line.Account.IsValid() {
+			return fmt.Errorf("unknown ledger account: %s", line.Account)
+		}
+		if line.Debit < 0 || line.Credit < 0 || (line.Debit > 0) == (line.Credit > 0) {
+			return fmt.Errorf("journal line on %s must either debit or credit a positive amount", line.Account)
+		}
+		debit += line.Debit
+		credit += line.Credit
+	}
+	if math.Abs(debit-credit) > balanceTolerance {
+		return fmt.Errorf("journal entry %s is unbalanced: debits %.2f, credits %.2f", e.EntryID, debit, credit)
+	}
+	return nil
+}
+
+// AccountTotal returns the debits less the credits the entry posts to the account
+func (e *JournalEntry) AccountTotal(account LedgerAccount) float64 {
+	total := 0.0
+	for _, line := range e.Lines {
+		if line.Account == account {
+			total += line.Debit - line.Credit
+		}
+	}
+	return RoundAmount(total)
+}
+
+// SamePosting reports whether two entries post the same amount in the same currency against the same bill
+// of the same tenant, a payment or credit recorded again under its ID must match the one stored
+func (e *JournalEntry) SamePosting(other *JournalEntry) bool {
+	return e.TenantID == other.TenantID && e.BillID == other.BillID && e.Kind == other.Kind &&
+		e.Currency == other.Currency && e.AccountTotal(AccountsReceivable) == other.AccountTotal(AccountsReceivable)
+}
+
+// addLine debits the account with a positive amount or credits it with a negative one, zero amounts are dropped
+func (e *JournalEntry) addLine(account LedgerAccount, amount float64) {
+	amount = RoundAmount(amount)
+	switch {
+	case amount > 0:
+		e.Lines = append(e.Lines, JournalLine{Account: account, Debit: amount})
+	case amount < 0:
+		e.Lines = append(e.Lines, JournalLine{Account: account, Credit: -amount})
+	}
+}
+
+// NewBillClosedEntry books the closed bill as a receivable: the bill total is revenue, less what its
+// recognition schedule defers to later months, and VAT at vatRate percent is added on top, as on the
+// e-invoice. It returns nil for bills closed with nothing to collect.
+func NewBillClosedEntry(tenantID, customerID string, bill *Bill, vatRate float64, postedAt time.Time) *JournalEntry {
+	net := RoundAmount(bill.TotalAmount)
+	if net == 0 {
+		return nil
+	}
+	tax := RoundAmount(net * vatRate / 100)
+	entry := &JournalEntry{
+		EntryID:    NewBillClosedEntryID(bill.ID),
+		TenantID:   tenantID,
+		CustomerID: customerID,
+		BillID:     bill.ID,
+		Kind:       JournalBillClosed,
+		Currency:   bill.Currency,
+		Memo:       bill.CloseReason,
+		PostedAt:   postedAt,
+	}
+	deferred := bill.DeferredRevenue()
+	entry.addLine(AccountsReceivable, net+tax)
+	entry.addLine(AccountRevenue, -(net - deferred))
+	entry.addLine(AccountDeferred, -deferred)
+	entry.addLine(AccountTaxPayable, -tax)
+	return entry
+}
+
+// NewRecognitionJournalEntry moves revenue of a bill earned in the month from deferred to recognised revenue
+func NewRecognitionJournalEntry(tenantID, customerID, billID string, month string, amount float64, currency Currency, postedAt time.Time) *JournalEntry {
+	entry := &JournalEntry{
+		EntryID:    fmt.Sprintf("recognition/%s/%s", billID, month),
+		TenantID:   tenantID,
+		CustomerID: customerID,
+		BillID:     billID,
+		Kind:       JournalRecognized,
+		Currency:   currency,
+		Memo:       fmt.Sprintf("Revenue recognised for %s", month),
+		PostedAt:   postedAt,
+	}
+	entry.addLine(AccountDeferred, amount)
+	entry.addLine(AccountRevenue, -amount)
+	return entry
+}
+
+// NewBillClosedEntryID returns the ID of the entry booking a closed bill, there is one per bill
+func NewBillClosedEntryID(billID string) string {
+	return fmt.Sprintf("bill-closed/%s", billID)
+}
+
+// NewPaymentEntryID returns the ID of the entry booking a payment, payment IDs are chosen by the caller and
+// only unique within the bill they are recorded against
+func NewPaymentEntryID(tenantID, billID, paymentID string) string {
+	return fmt.Sprintf("payment/%s/%s/%s", tenantID, billID, paymentID)
+}
+
+// NewCreditEntryID returns the ID of the entry booking a credit note, scoped like payments
+func NewCreditEntryID(tenantID, billID, creditID string) string {
+	return fmt.Sprintf("credit/%s/%s/%s", tenantID, billID, creditID)
+}
+
+// NewPaymentEntry books a payment against the receivable of a bill. The receivable is cleared at the
+// reference rate and cash is booked at the rate the payment settled at, the difference is an FX gain or loss.
+func NewPaymentEntry(tenantID, customerID string, paymentID string, req *RecordPaymentRequest, billCurrency Currency, postedAt time.Time) *JournalEntry {
+	booked := RoundAmount(ConvertCurrencyAmount(req.Currency, billCurrency, req.Amount))
+	settled := booked
+	if req.Currency != billCurrency && req.ExchangeRate > 0 {
+		settled = RoundAmount(req.Amount * req.ExchangeRate)
+	}
+	entry := &JournalEntry{
+		EntryID:    NewPaymentEntryID(tenantID, req.BillID, paymentID),
+		TenantID:   tenantID,
+		CustomerID: customerID,
+		BillID:     req.BillID,
+		Kind:       JournalPayment,
+		Currency:   billCurrency,
+		Memo:       fmt.Sprintf("Payment of %.2f %s", req.Amount, req.Currency),
+		PostedAt:   postedAt,
+	}
+	entry.addLine(AccountCash, settled)
+	entry.addLine(AccountsReceivable, -booked)
+	entry.addLine(AccountFXGainLoss, booked-settled)
+	return entry
+}
+
+// NewCreditEntry books a credit note against a closed bill, splitting the credited amount between revenue
+// and tax in the proportion the bill was booked with
+func NewCreditEntry(creditID string, req *IssueCreditRequest, billEntry *JournalEntry, postedAt time.Time) *JournalEntry {
+	amount := RoundAmount(req.Amount)
+	tax := 0.0
+	if receivable := billEntry.AccountTotal(AccountsReceivable); receivable != 0 {
+		tax = RoundAmount(amount * -billEntry.AccountTotal(AccountTaxPayable) / receivable)
+	}
+	entry := &JournalEntry{
+		EntryID:    NewCreditEntryID(billEntry.TenantID, billEntry.BillID, creditID),
+		TenantID:   billEntry.TenantID,
+		CustomerID: billEntry.CustomerID,
+		BillID:     billEntry.BillID,
+		Kind:       JournalCredit,
+		Currency:   billEntry.Currency,
+		Memo:       req.Reason,
+		PostedAt:   postedAt,
+	}
+	entry.addLine(AccountRevenue, amount-tax)
+	entry.addLine(AccountTaxPayable, tax)
+	entry.addLine(AccountsReceivable, -amount)
+	return entry
+}
+
+// NewTrialBalance totals the account lines of one currency and checks that debits equal credits
+func NewTrialBalance(currency Currency, lines []*TrialBalanceLine) *TrialBalance {
+	balance := &TrialBalance{Currency: currency, Lines: lines}
+	for _, line := range lines {
+		line.Balance = line.Account.NormalBalance(line.Debit, line.Credit)
+		balance.TotalDebit += line.Debit
+		balance.TotalCredit += line.Credit
+	}
+	balance.TotalDebit = RoundAmount(balance.TotalDebit)
+	balance.TotalCredit = RoundAmount(balance.TotalCredit)
+	balance.Balanced = math.Abs(balance.TotalDebit-balance.TotalCredit) <= balanceTolerance
+	return balance
+}
+
+// NewAccountStatement fills in the running balance of the account's lines, given oldest first
+func NewAccountStatement(customerID string, account LedgerAccount, currency Currency, lines []*AccountStatementLine) *AccountStatementResponse {
+	statement := &AccountStatementResponse{CustomerID: customerID, Account: account, Currency: currency, Lines: lines}
+	debit, credit := 0.0, 0.0
+	for _, line := range lines {
+		debit += line.Debit
+		credit += line.Credit
+		line.Balance = account.NormalBalance(debit, credit)
+	}
+	statement.Balance = account.NormalBalance(debit, credit)
+	return statement
+}
+
+// NewCustomerStatement carries the opening balance through the customer's receivable lines of the range,
+// given oldest first, and totals them by what produced them
+func NewCustomerStatement(customerID string, currency Currency, from, to time.Time, opening float64, lines []*AccountStatementLine) *CustomerStatement {
+	statement := &CustomerStatement{
+		CustomerID:     customerID,
+		Currency:       currency,
+		From:           from,
+		To:             to,
+		OpeningBalance: RoundAmount(opening),
+		Lines:          lines,
+	}
+	balance := opening
+	for _, line := range lines {
+		balance += line.Debit - line.Credit
+		line.Balance = RoundAmount(balance)
+		switch line.Kind {
+		case JournalBillClosed:
+			statement.Charged += line.Debit - line.Credit
+		case JournalPayment:
+			statement.Paid += line.Credit - line.Debit
+		case JournalCredit:
+			statement.Credited += line.Credit - line.Debit
+		}
+	}
+	statement.Charged = RoundAmount(statement.Charged)
+	statement.Paid = RoundAmount(statement.Paid)
+	statement.Credited = RoundAmount(statement.Credited)
+	statement.ClosingBalance = RoundAmount(balance)
+	return statement
+}
@@ -0,0 +1,135 @@
+package models
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewBillClosedEntry(t *testing.T) {
+	bill := &Bill{ID: "bill-1", Currency: USD, TotalAmount: 100, CloseReason: "done"}
+	entry := NewBillClosedEntry("retail", "cust-1", bill, 18, time.Now())
+
+	assert.NoError(t, entry.Validate())
+	assert.Equal(t, "bill-closed/bill-1", entry.EntryID)
+	assert.Equal(t, 118.0, entry.AccountTotal(AccountsReceivable))
+	assert.Equal(t, -100.0, entry.AccountTotal(AccountRevenue))
+	assert.Equal(t, -18.0, entry.AccountTotal(AccountTaxPayable))
+
+	t.Run("zero rated bills have no tax line", func(t *testing.T) {
+		entry := NewBillClosedEntry("retail", "cust-1", bill, 0, time.Now())
+		assert.Len(t, entry.Lines, 2)
+		assert.NoError(t, entry.Validate())
+	})
+	t.Run("empty bills are not booked", func(t *testing.T) {
+		assert.Nil(t, NewBillClosedEntry("retail", "cust-1", &Bill{ID: "bill-2", Currency: USD}, 18, time.Now()))
+	})
+}
+
+func TestNewPaymentEntry(t *testing.T) {
+	t.Run("same currency", func(t *testing.T) {
+		entry := NewPaymentEntry("retail", "cust-1", "pay-1", &RecordPaymentRequest{BillID: "bill-1", Amount: 50, Currency: USD}, USD, time.Now())
+		assert.NoError(t, entry.Validate())
+		assert.Equal(t, 50.0, entry.AccountTotal(AccountCash))
+		assert.Equal(t, -50.0, entry.AccountTotal(AccountsReceivable))
+		assert.Len(t, entry.Lines, 2)
+		assert.Equal(t, "payment/retail/bill-1/pay-1", entry.EntryID)
+	})
+	t.Run("recorded again", func(t *testing.T) {
+		entry := NewPaymentEntry("retail", "cust-1", "pay-1", &RecordPaymentRequest{BillID: "bill-1", Amount: 50, Currency: USD}, USD, time.Now())
+		again := NewPaymentEntry("retail", "cust-1", "pay-1", &RecordPaymentRequest{BillID: "bill-1", Amount: 50, Currency: USD}, USD, time.Now().Add(time.Minute))
+		assert.True(t, entry.SamePosting(again))
+		other := NewPaymentEntry("retail", "cust-1", "pay-1", &RecordPaymentRequest{BillID: "bill-1", Amount: 40, Currency: USD}, USD, time.Now())
+		assert.False(t, entry.SamePosting(other))
+		wholesale := NewPaymentEntry("wholesale", "cust-1", "pay-1", &RecordPaymentRequest{BillID: "bill-2", Amount: 50, Currency: USD}, USD, time.Now())
+		assert.NotEqual(t, entry.EntryID, wholesale.EntryID)
+	})
+	t.Run("settled above the reference rate is an FX gain", func(t *testing.T) {
+		booked := RoundAmount(ConvertCurrencyAmount(GEL, USD, 100))
+		entry := NewPaymentEntry("retail", "cust-1", "pay-2", &RecordPaymentRequest{
+			BillID: "bill-1", Amount: 100, Currency: GEL, ExchangeRate: booked/100 + 0.01,
+		}, USD, time.Now())
+		assert.NoError(t, entry.Validate())
+		assert.Equal(t, -booked, entry.AccountTotal(AccountsReceivable))
+		assert.Equal(t, -1.0, entry.AccountTotal(AccountFXGainLoss))
+	})
+	t.Run("settled below the reference rate is an FX loss", func(t *testing.T) {
+		booked := RoundAmount(ConvertCurrencyAmount(GEL, USD, 100))
+		entry := NewPaymentEntry("retail", "cust-1", "pay-3", &RecordPaymentRequest{
+			BillID: "bill-1", Amount: 100, Currency: GEL, ExchangeRate: booked/100 - 0.01,
+		}, USD, time.Now())
+		assert.NoError(t, entry.Validate())
+		assert.Equal(t, 1.0, entry.AccountTotal(AccountFXGainLoss))
+	})
+}
+
+func TestNewCreditEntry(t *testing.T) {
+	billEntry := NewBillClosedEntry("retail", "cust-1", &Bill{ID: "bill-1", Currency: USD, TotalAmount: 100}, 18, time.Now())
+	entry := NewCreditEntry("credit-1", &IssueCreditRequest{BillID: "bill-1", Amount: 59, Reason: "goodwill"}, billEntry, time.Now())
+
+	assert.NoError(t, entry.Validate())
+	assert.Equal(t, "cust-1", entry.CustomerID)
+	assert.Equal(t, "credit/retail/bill-1/credit-1", entry.EntryID)
+	assert.Equal(t, -59.0, entry.AccountTotal(AccountsReceivable))
+	assert.Equal(t, 50.0, entry.AccountTotal(AccountRevenue))
+	assert.Equal(t, 9.0, entry.AccountTotal(AccountTaxPayable))
+}
+
+func TestJournalEntryValidate(t *testing.T) {
+	unbalanced := &JournalEntry{EntryID: "e-1", Lines: []JournalLine{
+		{Account: AccountCash, Debit: 10},
+		{Account: AccountsReceivable, Credit: 9},
+	}}
+	assert.EqualError(t, unbalanced.Validate(), "journal entry e-1 is unbalanced: debits 10.00, credits 9.00")
+
+	twoSided := &JournalEntry{EntryID: "e-2", Lines: []JournalLine{
+		{Account: AccountCash, Debit: 10, Credit: 10},
+		{Account: AccountsReceivable, Credit: 0},
+	}}
+	assert.Error(t, twoSided.Validate())
+
+	unknown := &JournalEntry{EntryID: "e-3", Lines: []JournalLine{
+		{Account: "inventory", Debit: 10},
+		{Account: AccountsReceivable, Credit: 10},
+	}}
+	assert.EqualError(t, unknown.Validate(), "unknown ledger account: inventory")
+}
+
+func TestTrialBalanceAndStatement(t *testing.T) {
+	balance := NewTrialBalance(USD, []*TrialBalanceLine{
+		{Account: AccountsReceivable, Debit: 118, Credit: 50},
+		{Account: AccountCash, Debit: 50},
+		{Account: AccountRevenue, Credit: 100},
+		{Account: AccountTaxPayable, Credit: 18},
+	})
+	assert.True(t, balance.Balanced)
+	assert.Equal(t, 168.0, balance.TotalDebit)
+	assert.Equal(t, 68.0, balance.Lines[0].Balance)
+	assert.Equal(t, 100.0, balance.Lines[2].Balance)
+
+	statement := NewAccountStatement("cust-1", AccountsReceivable, USD, []*AccountStatementLine{
+		{EntryID: "bill-closed/bill-1", Debit: 118},
+		{EntryID: "payment/pay-1", Credit: 50},
+	})
+	assert.Equal(t, 118.0, statement.Lines[0].Balance)
+	assert.Equal(t, 68.0, statement.Lines[1].Balance)
+	assert.Equal(t, 68.0, statement.Balance)
+}
+
+func TestNewCustomerStatement(t *testing.T) {
+	from := time.Date(2025, 2, 1, 0, 0, 0, 0, time.UTC)
+	lines := []*AccountStatementLine{
+		{BillID: "bill-2", Kind: JournalBillClosed, Debit: 118},
+		{BillID: "bill-1", Kind: JournalPayment, Credit: 50},
+		{BillID: "bill-2", Kind: JournalCredit, Credit: 18},
+	}
+	statement := NewCustomerStatement("cust-1", USD, from, from.AddDate(0, 1, -1), 50, lines)
+
+	assert.Equal(t, 50.0, statement.OpeningBalance)
+	assert.Equal(t, []float64{168, 118, 100}, []float64{lines[0].Balance, lines[1].Balance, lines[2].Balance})
+	assert.Equal(t, 118.0, statement.Charged)
+	assert.Equal(t, 50.0, statement.Paid)
+	assert.Equal(t, 18.0, statement.Credited)
+	assert.Equal(t, 100.0, statement.ClosingBalance)
+}
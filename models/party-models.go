@@ -0,0 +1,47 @@
+package models
+
+// Address represents a postal address printed on invoices
+type Address struct {
+	StreetName       string `json:"street_name"`
+	AdditionalStreet string `json:"additional_street,omitempty"`
+	CityName         string `json:"city_name"`
+	PostalZone       string `json:"postal_zone"`
+	CountryCode      string `json:"country_code"` // ISO 3166-1 alpha-2
+}
+
+// LegalEntityProfile holds the seller details of the legal entity issuing bills
+type LegalEntityProfile struct {
+	LegalName      string  `json:"legal_name"`
+	Address        Address `json:"address"`
+	VATID          string  `json:"vat_id"`
+	CompanyID      string  `json:"company_id,omitempty"`
+	EndpointID     string  `json:"endpoint_id"`
+	EndpointScheme string  `json:"endpoint_scheme"`
+	VATRate        float64 `json:"vat_rate"` // Percentage applied to all lines, 0 for zero rated
+	IBAN           string  `json:"iban,omitempty"`
+}
+
+// LegalEntityResponse represents the seller legal entity of a tenant, nil when it is not configured
+type LegalEntityResponse struct {
+	LegalEntity *LegalEntityProfile `json:"legal_entity"`
+}
+
+// EInvoiceDocumentType is the UBL document a bill is serialised to
+type EInvoiceDocumentType string
+
+const (
+	DocumentInvoice    EInvoiceDocumentType = "Invoice"
+	DocumentCreditNote EInvoiceDocumentType = "CreditNote"
+)
+
+// GetEInvoiceResponse represents the UBL document generated for a closed bill
+type GetEInvoiceResponse struct {
+	BillID       string               `json:"bill_id"`
+	DocumentType EInvoiceDocumentType `json:"document_type"`
+	XML          string               `json:"xml"`
+}
+
+// IsComplete checks that the address has the fields Peppol BIS requires
+func (a Address) IsComplete() bool {
+	return len(a.CountryCode) == 2
+}
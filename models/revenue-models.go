@@ -0,0 +1,212 @@
+package models
+
+import (
+	"fmt"
+	"math"
+	"time"
+)
+
+// RecognitionMethod tells when the revenue of a line item is earned
+type RecognitionMethod string
+
+const (
+	RecognizeImmediate RecognitionMethod = "immediate" // Earned when the bill closes, the default
+	RecognizeRatable   RecognitionMethod = "ratable"   // Earned day by day over the service period
+	RecognizeMilestone RecognitionMethod = "milestone" // Earned in parts on milestone dates
+)
+
+// recognitionMonthLayout formats the months revenue is recognised in
+const recognitionMonthLayout = "2006-01"
+
+// RecognitionRule describes how the revenue of a line item is recognised
+type RecognitionRule struct {
+	Method       RecognitionMethod      `json:"method"`
+	ServiceStart time.Time              `json:"service_start,omitempty"` // Ratable only, inclusive
+	ServiceEnd   time.Time              `json:"service_end,omitempty"`   // Ratable only, exclusive
+	Milestones   []RecognitionMilestone `json:"milestones,omitempty"`
+}
+
+// RecognitionMilestone is the share of a line item earned on a date, the percents of a rule add up to 100
+type RecognitionMilestone struct {
+	Name    string    `json:"name"`
+	Date    time.Time `json:"date"`
+	Percent float64   `json:"percent"`
+}
+
+// RecognitionEntry is the revenue of a line item recognised in one month
+type RecognitionEntry struct {
+	LineItemID string            `json:"line_item_id"`
+	Product    string            `json:"product,omitempty"`
+	Method     RecognitionMethod `json:"method"`
+	Month      string            `json:"month"` // YYYY-MM
+	Milestone  string            `json:"milestone,omitempty"`
+	Amount     float64           `json:"amount"` // In the bill currency
+}
+
+// RevenueReportRequest represents the months to report on, both inclusive in YYYY-MM form
+type RevenueReportRequest struct {
+	From     string   `query:"from"`
+	To       string   `query:"to"`
+	Currency Currency `query:"currency"` // All currencies when empty
+}
+
+// RevenueReportLine represents the revenue of a product in a month
+type RevenueReportLine struct {
+	Month      string   `json:"month"`
+	Product    string   `json:"product"`
+	Currency   Currency `json:"currency"`
+	Recognized float64  `json:"recognized"`
+	Deferred   float64  `json:"deferred"` // Scheduled for the month but not recognised yet
+}
+
+// RevenueReportResponse represents recognised and deferred revenue by month and product
+type RevenueReportResponse struct {
+	TenantID string               `json:"tenant_id"`
+	Lines    []*RevenueReportLine `json:"lines"`
+}
+
+// RecognizeRevenueResponse represents the outcome of a recognition run
+type RecognizeRevenueResponse struct {
+	Month   string `json:"month"`
+	Entries int    `json:"entries"` // Journal entries posted
+}
+
+// RecognitionMonth returns the month a time falls in, in UTC
+func RecognitionMonth(t time.Time) string {
+	return t.UTC().Format(recognitionMonthLayout)
+}
+
+// ParseRecognitionMonth parses a YYYY-MM month
+func ParseRecognitionMonth(month string) (time.Time, error) {
+	parsed, err := time.Parse(recognitionMonthLayout, month)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid month %q, expected YYYY-MM", month)
+	}
+	return parsed, nil
+}
+
+// Validate checks that the rule has what its method needs
+func (r *RecognitionRule) Validate() error {
+	switch r.Method {
+	case RecognizeImmediate:
+		return nil
+	case RecognizeRatable:
+		if r.ServiceStart.IsZero() || !r.ServiceEnd.After(r.ServiceStart) {
+			return fmt.Errorf("ratable recognition needs a service_start before service_end")
+		}
+		return nil
+	case RecognizeMilestone:
+		if len(r.Milestones) == 0 {
+			return fmt.Errorf("milestone recognition needs at least one milestone")
+		}
+		percent := 0.0
+		for _, milestone := range r.Milestones {
+			if milestone.Date.IsZero() || milestone.Percent <= 0 {
+				return fmt.Errorf("milestone %q needs a date and a positive percent", milestone.Name)
+			}
+			percent += milestone.Percent
+		}
+		if math.Abs(percent-100) > 0.001 {
+			return fmt.Errorf("milestone percents add up to %.2f, expected 100", percent)
+		}
+		return nil
+	}
+	return fmt.Errorf("invalid recognition method: %s (supported: immediate, ratable, milestone)", r.Method)
+}
+
+// NewRecognitionSchedule spreads every line item of a closed bill over the months its revenue is earned in.
+// Line items without a rule, such as applied prepaid credits, are recognised when the bill closes, so the
+// schedule always adds up to the bill total.
+func NewRecognitionSchedule(bill *Bill) []*RecognitionEntry {
+	schedule := make([]*RecognitionEntry, 0, len(bill.LineItems))
+	for _, item := range bill.LineItems {
+		total := RoundAmount(item.Amount * float64(item.Quantity))
+		if total == 0 {
+			continue
+		}
+		rule := item.Recognition
+		if rule == nil {
+			rule = &RecognitionRule{Method: RecognizeImmediate}
+		}
+		switch rule.Method {
+		case RecognizeRatable:
+			schedule = append(schedule, ratableEntries(item, total, rule.ServiceStart, rule.ServiceEnd)...)
+		case RecognizeMilestone:
+			schedule = append(schedule, milestoneEntries(item, total, rule.Milestones)...)
+		default:
+			schedule = append(schedule, &RecognitionEntry{
+				LineItemID: item.ID,
+				Product:    item.Product,
+				Method:     RecognizeImmediate,
+				Month:      RecognitionMonth(bill.ClosedAt),
+				Amount:     total,
+			})
+		}
+	}
+	return schedule
+}
+
+// ratableEntries splits the total over the calendar months of the service period by days of service,
+// the last month takes the rounding remainder
+func ratableEntries(item *LineItem, total float64, start, end time.Time) []*RecognitionEntry {
+	start, end = start.UTC(), end.UTC()
+	totalDays := end.Sub(start).Hours() / 24
+	entries := make([]*RecognitionEntry, 0)
+	remaining := total
+	for monthStart := start; monthStart.Before(end); {
+		nextMonth := time.Date(monthStart.Year(), monthStart.Month()+1, 1, 0, 0, 0, 0, time.UTC)
+		monthEnd := nextMonth
+		if end.Before(monthEnd) {
+			monthEnd = end
+		}
+		amount := RoundAmount(total * (monthEnd.Sub(monthStart).Hours() / 24) / totalDays)
+		if !monthEnd.Before(end) {
+			amount = RoundAmount(remaining)
+		}
+		remaining -= amount
+		entries = append(entries, &RecognitionEntry{
+			LineItemID: item.ID,
+			Product:    item.Product,
+			Method:     RecognizeRatable,
+			Month:      RecognitionMonth(monthStart),
+			Amount:     amount,
+		})
+		monthStart = nextMonth
+	}
+	return entries
+}
+
+// milestoneEntries earns each milestone's percent of the total in the month of its date, the last
+// milestone takes the rounding remainder
+func milestoneEntries(item *LineItem, total float64, milestones []RecognitionMilestone) []*RecognitionEntry {
+	entries := make([]*RecognitionEntry, 0, len(milestones))
+	remaining := total
+	for index, milestone := range milestones {
+		amount := RoundAmount(total * milestone.Percent / 100)
+		if index == len(milestones)-1 {
+			amount = RoundAmount(remaining)
+		}
+		remaining -= amount
+		entries = append(entries, &RecognitionEntry{
+			LineItemID: item.ID,
+			Product:    item.Product,
+			Method:     RecognizeMilestone,
+			Month:      RecognitionMonth(milestone.Date),
+			Milestone:  milestone.Name,
+			Amount:     amount,
+		})
+	}
+	return entries
+}
+
+// DeferredRevenue returns the part of the bill's recognition schedule earned after the month it closed in
+func (b *Bill) DeferredRevenue() float64 {
+	closeMonth := RecognitionMonth(b.ClosedAt)
+	deferred := 0.0
+	for _, entry := range b.RecognitionSchedule {
+		if entry.Month > closeMonth {
+			deferred += entry.Amount
+		}
+	}
+	return RoundAmount(deferred)
+}
@@ -0,0 +1,86 @@
+package models
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewRecognitionSchedule(t *testing.T) {
+	closedAt := time.Date(2025, 1, 20, 12, 0, 0, 0, time.UTC)
+	bill := &Bill{
+		ID:       "bill-1",
+		Currency: USD,
+		ClosedAt: closedAt,
+		LineItems: []*LineItem{
+			{ID: "setup", Amount: 50, Quantity: 1, Product: "onboarding"},
+			{ID: "seats", Amount: 30, Quantity: 12, Product: "subscription", Recognition: &RecognitionRule{
+				Method:       RecognizeRatable,
+				ServiceStart: time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC),
+				ServiceEnd:   time.Date(2025, 4, 1, 0, 0, 0, 0, time.UTC),
+			}},
+			{ID: "project", Amount: 1000, Quantity: 1, Product: "services", Recognition: &RecognitionRule{
+				Method: RecognizeMilestone,
+				Milestones: []RecognitionMilestone{
+					{Name: "kickoff", Date: time.Date(2025, 1, 5, 0, 0, 0, 0, time.UTC), Percent: 30},
+					{Name: "go-live", Date: time.Date(2025, 6, 1, 0, 0, 0, 0, time.UTC), Percent: 70},
+				},
+			}},
+			NewPrepaidCreditLineItem("bill-1/close", 20, closedAt),
+		},
+	}
+
+	schedule := NewRecognitionSchedule(bill)
+	months := map[string]float64{}
+	total := 0.0
+	for _, entry := range schedule {
+		months[entry.Product+"/"+entry.Month] += entry.Amount
+		total += entry.Amount
+	}
+
+	assert.Equal(t, 50.0, months["onboarding/2025-01"])
+	// 360 over 90 days: 31, 28 and 31 days
+	assert.Equal(t, 124.0, months["subscription/2025-01"])
+	assert.Equal(t, 112.0, months["subscription/2025-02"])
+	assert.Equal(t, 124.0, months["subscription/2025-03"])
+	assert.Equal(t, 300.0, months["services/2025-01"])
+	assert.Equal(t, 700.0, months["services/2025-06"])
+	assert.Equal(t, -20.0, months["/2025-01"], "Applied credits are recognised at close")
+	assert.InDelta(t, bill.CalculateTotal(), total, 0.001)
+
+	bill.RecognitionSchedule = schedule
+	assert.Equal(t, 936.0, bill.DeferredRevenue())
+}
+
+func TestRecognitionRuleValidate(t *testing.T) {
+	start := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	assert.NoError(t, (&RecognitionRule{Method: RecognizeImmediate}).Validate())
+	assert.EqualError(t, (&RecognitionRule{Method: RecognizeRatable, ServiceStart: start, ServiceEnd: start}).Validate(),
+		"ratable recognition needs a service_start before service_end")
+	assert.EqualError(t, (&RecognitionRule{Method: RecognizeMilestone, Milestones: []RecognitionMilestone{
+		{Name: "kickoff", Date: start, Percent: 40},
+	}}).Validate(), "milestone percents add up to 40.00, expected 100")
+	assert.Error(t, (&RecognitionRule{Method: "on_payment"}).Validate())
+}
+
+func TestBillClosedEntryDefersRevenue(t *testing.T) {
+	bill := &Bill{ID: "bill-1", Currency: USD, LineItems: []*LineItem{
+		{ID: "seats", Amount: 300, Quantity: 1, Recognition: &RecognitionRule{
+			Method:       RecognizeRatable,
+			ServiceStart: time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC),
+			ServiceEnd:   time.Date(2025, 1, 31, 0, 0, 0, 0, time.UTC).AddDate(0, 2, 0),
+		}},
+	}}
+	bill.Close("done", time.Date(2025, 1, 31, 0, 0, 0, 0, time.UTC))
+	entry := NewBillClosedEntry("retail", "cust-1", bill, 0, bill.ClosedAt)
+
+	assert.NoError(t, entry.Validate())
+	assert.Equal(t, 300.0, entry.AccountTotal(AccountsReceivable))
+	assert.Equal(t, -bill.DeferredRevenue(), entry.AccountTotal(AccountDeferred))
+
+	recognition := NewRecognitionJournalEntry("retail", "cust-1", "bill-1", "2025-02", 100, USD, time.Now())
+	assert.NoError(t, recognition.Validate())
+	assert.Equal(t, "recognition/bill-1/2025-02", recognition.EntryID)
+	assert.Equal(t, 100.0, recognition.AccountTotal(AccountDeferred))
+}
@@ -0,0 +1,88 @@
+package models
+
+import (
+	"slices"
+	"time"
+)
+
+const (
+	// DefaultSearchPageSize is the number of billing periods a search returns when the request does not set one
+	DefaultSearchPageSize = 20
+	// MaxSearchPageSize is the largest page a search returns
+	MaxSearchPageSize = 100
+)
+
+// BillSearchAttributes are the fields of a billing period indexed by Temporal visibility
+type BillSearchAttributes struct {
+	TenantID      string     `json:"tenant_id"`
+	CustomerID    string     `json:"customer_id"`
+	Currency      Currency   `json:"currency"`
+	Status        BillStatus `json:"status"`
+	OpenBillCount int        `json:"open_bill_count"`
+	RunningTotal  float64    `json:"running_total"`
+	BillIDs       []string   `json:"bill_ids"`
+}
+
+// NewBillSearchAttributes returns the search attributes of the billing period in the given status
+func NewBillSearchAttributes(input *BillWorkflowInput, status BillStatus) BillSearchAttributes {
+	attributes := BillSearchAttributes{
+		TenantID:     input.TenantID,
+		CustomerID:   input.CustomerID,
+		Currency:     input.Currency,
+		Status:       status,
+		RunningTotal: RoundAmount(PeriodSpend(input.BillStates, input.Currency)),
+		BillIDs:      make([]string, 0, len(input.BillStates)),
+	}
+	for _, bill := range input.BillStates {
+		if bill.Status == StatusOpen {
+			attributes.OpenBillCount++
+		}
+		attributes.BillIDs = append(attributes.BillIDs, bill.ID)
+	}
+	return attributes
+}
+
+// Equal checks if both hold the same values, the workflow only upserts attributes that changed
+func (a BillSearchAttributes) Equal(other BillSearchAttributes) bool {
+	return a.TenantID == other.TenantID &&
+		a.CustomerID == other.CustomerID &&
+		a.Currency == other.Currency &&
+		a.Status == other.Status &&
+		a.OpenBillCount == other.OpenBillCount &&
+		a.RunningTotal == other.RunningTotal &&
+		slices.Equal(a.BillIDs, other.BillIDs)
+}
+
+// SearchBillsRequest represents the filters of a search across the tenant's billing periods, every filter
+// is optional
+type SearchBillsRequest struct {
+	CustomerID   string   `query:"customer_id"`
+	BillID       string   `query:"bill_id"`
+	Currency     Currency `query:"currency"`
+	Status       string   `query:"status"` // OPEN for running billing periods, CLOSED for ended ones
+	MinOpenBills int      `query:"min_open_bills"`
+	MinTotal     float64  `query:"min_total"`
+	MaxTotal     float64  `query:"max_total"` // No upper bound when 0
+	PageSize     int      `query:"page_size"`
+	PageToken    string   `query:"page_token"` // The next_page_token of the previous page
+}
+
+// BillingPeriodSearchResult represents a billing period found by a search
+type BillingPeriodSearchResult struct {
+	WorkflowID    string     `json:"workflow_id"`
+	CustomerID    string     `json:"customer_id"`
+	Currency      Currency   `json:"currency"`
+	Status        BillStatus `json:"status"`
+	OpenBillCount int        `json:"open_bill_count"`
+	RunningTotal  float64    `json:"running_total"`
+	BillIDs       []string   `json:"bill_ids"`
+	StartedAt     time.Time  `json:"started_at"`
+	ClosedAt      *time.Time `json:"closed_at,omitempty"`
+	Bill          *Bill      `json:"bill,omitempty"` // The bill searched for by bill_id
+}
+
+// SearchBillsResponse represents a page of billing periods matching a search
+type SearchBillsResponse struct {
+	Periods       []*BillingPeriodSearchResult `json:"periods"`
+	NextPageToken string                       `json:"next_page_token,omitempty"` // Empty on the last page
+}
@@ -0,0 +1,30 @@
+package models
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewBillSearchAttributes(t *testing.T) {
+	input := &BillWorkflowInput{
+		TenantID:   "retail",
+		CustomerID: "cust-1",
+		Currency:   USD,
+		BillStates: []*Bill{
+			{ID: "bill-1", Status: StatusClosed, Currency: USD, TotalAmount: 10},
+			{ID: "bill-2", Status: StatusOpen, Currency: USD, TotalAmount: 5.255},
+		},
+	}
+	attributes := NewBillSearchAttributes(input, StatusOpen)
+	assert.Equal(t, "retail", attributes.TenantID)
+	assert.Equal(t, StatusOpen, attributes.Status)
+	assert.Equal(t, 1, attributes.OpenBillCount)
+	assert.Equal(t, 15.26, attributes.RunningTotal)
+	assert.Equal(t, []string{"bill-1", "bill-2"}, attributes.BillIDs)
+
+	assert.True(t, attributes.Equal(NewBillSearchAttributes(input, StatusOpen)))
+	assert.False(t, attributes.Equal(NewBillSearchAttributes(input, StatusClosed)))
+	input.BillStates = append(input.BillStates, &Bill{ID: "bill-3", Status: StatusOpen, Currency: USD})
+	assert.False(t, attributes.Equal(NewBillSearchAttributes(input, StatusOpen)))
+}
@@ -0,0 +1,148 @@
+package models
+
+import (
+	"sort"
+	"time"
+)
+
+// DefaultSpendingThresholds are the soft thresholds used when a spending limit does not configure any
+var DefaultSpendingThresholds = []float64{0.5, 0.8, 1.0}
+
+// SpendingLimit represents the budget of a billing period
+type SpendingLimit struct {
+	HardCap    float64   `json:"hard_cap"`
+	Currency   Currency  `json:"currency"`
+	Thresholds []float64 `json:"thresholds,omitempty"` // Fractions of the hard cap that raise an alert when crossed
+}
+
+// SpendingAlert records a soft threshold crossed during the billing period
+type SpendingAlert struct {
+	Threshold float64   `json:"threshold"`
+	Total     float64   `json:"total"`
+	HardCap   float64   `json:"hard_cap"`
+	Currency  Currency  `json:"currency"`
+	BillID    string    `json:"bill_id,omitempty"`
+	CrossedAt time.Time `json:"crossed_at"`
+}
+
+// SpendingStatus represents the spend of a billing period measured against its limit
+type SpendingStatus struct {
+	Limit     *SpendingLimit  `json:"limit,omitempty"`
+	Total     float64         `json:"total"`
+	Currency  Currency        `json:"currency"`
+	Remaining *float64        `json:"remaining,omitempty"`
+	Alerts    []SpendingAlert `json:"alerts"`
+
+	// AccrualFactor scales line items added now, the hard cap is checked against the amount after it
+	AccrualFactor float64 `json:"accrual_factor,omitempty"`
+}
+
+// SpendingAlertEvent is published when a billing period crosses a spending threshold
+type SpendingAlertEvent struct {
+	EventID    string        `json:"event_id"`
+	TenantID   string        `json:"tenant_id,omitempty"`
+	CustomerID string        `json:"customer_id"`
+	WorkflowID string        `json:"workflow_id"`
+	Alert      SpendingAlert `json:"alert"`
+}
+
+// SetSpendingLimitRequest represents the request to set or replace the spending limit of a billing period
+type SetSpendingLimitRequest struct {
+	Limit SpendingLimit `json:"limit"`
+}
+
+// SetSpendingLimitSignal represents the signal to set the spending limit of a billing period
+type SetSpendingLimitSignal struct {
+	Limit SpendingLimit `json:"limit"`
+	Trace TraceContext  `json:"trace,omitempty"`
+}
+
+// SpendingStatusRequest represents the spending status query, workflows of other tenants return an error
+type SpendingStatusRequest struct {
+	TenantID string `json:"tenant_id"`
+}
+
+// SpendingStatusResponse represents the response containing the spend of a billing period
+type SpendingStatusResponse struct {
+	WorkflowID string          `json:"workflow_id"`
+	Status     *SpendingStatus `json:"status"`
+}
+
+// WithDefaults returns a copy of the limit with the period currency and default thresholds filled in
+func (l SpendingLimit) WithDefaults(periodCurrency Currency) *SpendingLimit {
+	if l.Currency == "" {
+		l.Currency = periodCurrency
+	}
+	thresholds := l.Thresholds
+	if len(thresholds) == 0 {
+		thresholds = DefaultSpendingThresholds
+	}
+	l.Thresholds = append([]float64(nil), thresholds...)
+	sort.Float64s(l.Thresholds)
+	return &l
+}
+
+// LineItemSpend returns what the line item adds to the period spend, its line total converted from the bill
+// currency to the currency of the limit. The item's amount is the unit amount after AccruedAmount.
+func (l *SpendingLimit) LineItemSpend(item *LineItem, billCurrency Currency) float64 {
+	return ConvertCurrencyAmount(billCurrency, l.Currency, item.Total())
+}
+
+// WouldExceed returns true if adding amount to total goes over the hard cap
+func (l *SpendingLimit) WouldExceed(total float64, amount float64) bool {
+	return total+amount > l.HardCap
+}
+
+// CrossedThresholds returns the thresholds reached by total that have not alerted yet under the current hard cap
+func (l *SpendingLimit) CrossedThresholds(total float64, alerts []SpendingAlert) []float64 {
+	alerted := make(map[float64]bool, len(alerts))
+	for _, alert := range alerts {
+		if alert.HardCap == l.HardCap && alert.Currency == l.Currency {
+			alerted[alert.Threshold] = true
+		}
+	}
+	crossed := make([]float64, 0)
+	for _, threshold := range l.Thresholds {
+		if !alerted[threshold] && total >= threshold*l.HardCap {
+			crossed = append(crossed, threshold)
+		}
+	}
+	return crossed
+}
+
+// PeriodSpend sums what the bills of a billing period charge in the given currency.
+// Prepaid credits pay for spend rather than reduce it, so credit lines are not subtracted.
+func PeriodSpend(bills []*Bill, currency Currency) float64 {
+	total := 0.0
+	for _, bill := range bills {
+		spend := bill.TotalAmount
+		for _, item := range bill.LineItems {
+			if item.Description == PrepaidCreditDescription {
+				spend -= item.Amount
+			}
+		}
+		total += ConvertCurrencyAmount(bill.Currency, currency, spend)
+	}
+	return total
+}
+
+// NewSpendingStatus builds the spending status of a billing period
+func NewSpendingStatus(limit *SpendingLimit, periodCurrency Currency, bills []*Bill, alerts []SpendingAlert) *SpendingStatus {
+	status := &SpendingStatus{
+		Limit:    limit,
+		Currency: periodCurrency,
+		Alerts:   append([]SpendingAlert{}, alerts...),
+	}
+	if limit != nil {
+		status.Currency = limit.Currency
+	}
+	status.Total = PeriodSpend(bills, status.Currency)
+	if limit != nil {
+		remaining := limit.HardCap - status.Total
+		if remaining < 0 {
+			remaining = 0
+		}
+		status.Remaining = &remaining
+	}
+	return status
+}
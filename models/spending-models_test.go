@@ -0,0 +1,80 @@
+package models
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSpendingLimitWithDefaults(t *testing.T) {
+	t.Run("fills currency and thresholds", func(t *testing.T) {
+		limit := SpendingLimit{HardCap: 100}.WithDefaults(GEL)
+		assert.Equal(t, GEL, limit.Currency)
+		assert.Equal(t, []float64{0.5, 0.8, 1.0}, limit.Thresholds)
+	})
+	t.Run("keeps configured values sorted", func(t *testing.T) {
+		limit := SpendingLimit{HardCap: 100, Currency: USD, Thresholds: []float64{0.9, 0.25}}.WithDefaults(GEL)
+		assert.Equal(t, USD, limit.Currency)
+		assert.Equal(t, []float64{0.25, 0.9}, limit.Thresholds)
+	})
+}
+
+func TestCrossedThresholds(t *testing.T) {
+	limit := SpendingLimit{HardCap: 200}.WithDefaults(USD)
+
+	assert.Empty(t, limit.CrossedThresholds(99, nil))
+	assert.Equal(t, []float64{0.5}, limit.CrossedThresholds(100, nil))
+	assert.Equal(t, []float64{0.5, 0.8}, limit.CrossedThresholds(170, nil))
+	previous := []SpendingAlert{{Threshold: 0.5, HardCap: 200, Currency: USD}}
+	assert.Equal(t, []float64{0.8, 1.0}, limit.CrossedThresholds(200, previous))
+
+	// Alerts raised under an earlier cap do not silence the new one
+	raised := SpendingLimit{HardCap: 400}.WithDefaults(USD)
+	assert.Equal(t, []float64{0.5}, raised.CrossedThresholds(200, previous))
+}
+
+func TestWouldExceed(t *testing.T) {
+	limit := &SpendingLimit{HardCap: 100, Currency: USD}
+	assert.False(t, limit.WouldExceed(60, 40))
+	assert.True(t, limit.WouldExceed(60, 40.01))
+}
+
+func TestLineItemSpend(t *testing.T) {
+	limit := &SpendingLimit{HardCap: 100, Currency: USD}
+	item := &LineItem{Amount: AccruedAmount(10, USD, USD, 1.5), Quantity: 3}
+	assert.Equal(t, 45.0, limit.LineItemSpend(item, USD))
+	assert.Equal(t, ConvertCurrencyAmount(GEL, USD, 45), limit.LineItemSpend(item, GEL))
+}
+
+func TestPeriodSpend(t *testing.T) {
+	bills := []*Bill{
+		{
+			Currency:    USD,
+			TotalAmount: 20,
+			LineItems: []*LineItem{
+				{Amount: 50, Quantity: 1},
+				{Description: PrepaidCreditDescription, Amount: -30, Quantity: 1},
+			},
+		},
+		{Currency: GEL, TotalAmount: 25},
+	}
+	assert.InDelta(t, 60.0, PeriodSpend(bills, USD), 0.0001)
+	assert.InDelta(t, 150.0, PeriodSpend(bills, GEL), 0.0001)
+}
+
+func TestNewSpendingStatus(t *testing.T) {
+	bills := []*Bill{{Currency: USD, TotalAmount: 130}}
+
+	t.Run("without limit", func(t *testing.T) {
+		status := NewSpendingStatus(nil, USD, bills, nil)
+		assert.Equal(t, 130.0, status.Total)
+		assert.Nil(t, status.Remaining)
+		assert.Empty(t, status.Alerts)
+	})
+	t.Run("over limit", func(t *testing.T) {
+		limit := SpendingLimit{HardCap: 100}.WithDefaults(USD)
+		status := NewSpendingStatus(limit, USD, bills, []SpendingAlert{{Threshold: 1.0}})
+		assert.Equal(t, 0.0, *status.Remaining)
+		assert.Len(t, status.Alerts, 1)
+	})
+}
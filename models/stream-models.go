@@ -0,0 +1,94 @@
+package models
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"time"
+)
+
+// BillStreamEvent represents a change applied to a bill, pushed to the customer's update stream. Types are
+// the webhook event types.
+type BillStreamEvent struct {
+	ID          string           `json:"id"` // The event_id of the lifecycle event
+	Type        WebhookEventType `json:"type"`
+	TenantID    string           `json:"-"`
+	CustomerID  string           `json:"customer_id"`
+	BillID      string           `json:"bill_id,omitempty"`
+	Currency    Currency         `json:"currency,omitempty"`
+	TotalAmount float64          `json:"total_amount"`
+	LineItem    *LineItem        `json:"line_item,omitempty"` // The item added by line_item.added
+	Bill        *Bill            `json:"bill,omitempty"`      // The final bill of bill.closed
+	OccurredAt  time.Time        `json:"occurred_at"`
+}
+
+// NewBillCreatedStreamEvent returns the stream event of a bill opened in the billing period
+func NewBillCreatedStreamEvent(event *BillCreatedEvent) *BillStreamEvent {
+	return &BillStreamEvent{
+		ID:         event.EventID,
+		Type:       WebhookBillCreated,
+		TenantID:   event.TenantID,
+		CustomerID: event.CustomerID,
+		BillID:     event.BillID,
+		Currency:   event.Currency,
+		OccurredAt: event.CreatedAt,
+	}
+}
+
+// NewLineItemAddedStreamEvent returns the stream event of a line item added to a bill, with the new total
+func NewLineItemAddedStreamEvent(event *LineItemAddedEvent) *BillStreamEvent {
+	return &BillStreamEvent{
+		ID:          event.EventID,
+		Type:        WebhookLineItemAdded,
+		TenantID:    event.TenantID,
+		CustomerID:  event.CustomerID,
+		BillID:      event.BillID,
+		Currency:    event.Currency,
+		TotalAmount: event.TotalAmount,
+		LineItem:    event.LineItem,
+		OccurredAt:  event.LineItem.AddedAt,
+	}
+}
+
+// NewBillClosedStreamEvent returns the stream event of a closed bill
+func NewBillClosedStreamEvent(event *BillClosedEvent) *BillStreamEvent {
+	return &BillStreamEvent{
+		ID:          event.EventID,
+		Type:        WebhookBillClosed,
+		TenantID:    event.TenantID,
+		CustomerID:  event.CustomerID,
+		BillID:      event.Bill.ID,
+		Currency:    event.Bill.Currency,
+		TotalAmount: event.Bill.TotalAmount,
+		Bill:        event.Bill,
+		OccurredAt:  event.Bill.ClosedAt,
+	}
+}
+
+// NewBillingPeriodClosedStreamEvent returns the stream event of the end of the billing period
+func NewBillingPeriodClosedStreamEvent(event *BillingPeriodClosedEvent) *BillStreamEvent {
+	return &BillStreamEvent{
+		ID:         event.EventID,
+		Type:       WebhookBillingPeriodClosed,
+		TenantID:   event.TenantID,
+		CustomerID: event.CustomerID,
+		OccurredAt: event.ClosedAt,
+	}
+}
+
+// Matches checks if a stream following the bill receives the event, every stream receives the end of the
+// billing period
+func (e *BillStreamEvent) Matches(billID string) bool {
+	return billID == "" || e.BillID == billID || e.Type == WebhookBillingPeriodClosed
+}
+
+// SSE returns the event as a Server-Sent Events message named after its type
+func (e *BillStreamEvent) SSE() ([]byte, error) {
+	data, err := json.Marshal(e)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode stream event %s: %w", e.ID, err)
+	}
+	var message bytes.Buffer
+	fmt.Fprintf(&message, "id: %s\nevent: %s\ndata: %s\n\n", e.ID, e.Type, data)
+	return message.Bytes(), nil
+}
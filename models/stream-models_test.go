@@ -0,0 +1,56 @@
+package models
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBillStreamEvents(t *testing.T) {
+	addedAt := time.Date(2025, 8, 21, 7, 0, 0, 0, time.UTC)
+	added := NewLineItemAddedStreamEvent(&LineItemAddedEvent{
+		EventID:     LineItemAddedEventID("bill-1", "item-1"),
+		TenantID:    "retail",
+		CustomerID:  "cust-1",
+		BillID:      "bill-1",
+		LineItem:    &LineItem{ID: "item-1", Amount: 40, Quantity: 1, AddedAt: addedAt},
+		Currency:    USD,
+		TotalAmount: 40,
+	})
+	assert.Equal(t, WebhookLineItemAdded, added.Type)
+	assert.Equal(t, "retail", added.TenantID)
+	assert.Equal(t, 40.0, added.TotalAmount)
+	assert.Equal(t, addedAt, added.OccurredAt)
+
+	closed := NewBillClosedStreamEvent(&BillClosedEvent{
+		EventID:    BillClosedEventID("bill-1"),
+		CustomerID: "cust-1",
+		Bill:       &Bill{ID: "bill-1", Status: StatusClosed, Currency: USD, TotalAmount: 40, ClosedAt: addedAt},
+	})
+	assert.Equal(t, WebhookBillClosed, closed.Type)
+	assert.Equal(t, "bill-1", closed.BillID)
+	assert.Equal(t, StatusClosed, closed.Bill.Status)
+
+	periodClosed := NewBillingPeriodClosedStreamEvent(&BillingPeriodClosedEvent{EventID: "period", CustomerID: "cust-1"})
+	assert.True(t, added.Matches(""))
+	assert.True(t, added.Matches("bill-1"))
+	assert.False(t, added.Matches("bill-2"))
+	assert.True(t, periodClosed.Matches("bill-2"), "Every stream learns that the billing period ended")
+}
+
+func TestBillStreamEventSSE(t *testing.T) {
+	event := NewBillCreatedStreamEvent(&BillCreatedEvent{
+		EventID:    "bill-created/bill-1",
+		TenantID:   "retail",
+		CustomerID: "cust-1",
+		BillID:     "bill-1",
+		Currency:   GEL,
+		CreatedAt:  time.Date(2025, 8, 21, 7, 0, 0, 0, time.UTC),
+	})
+	message, err := event.SSE()
+	assert.NoError(t, err)
+	assert.Equal(t, "id: bill-created/bill-1\nevent: bill.created\n"+
+		`data: {"id":"bill-created/bill-1","type":"bill.created","customer_id":"cust-1","bill_id":"bill-1","currency":"GEL","total_amount":0,"occurred_at":"2025-08-21T07:00:00Z"}`+"\n\n",
+		string(message))
+}
@@ -0,0 +1,104 @@
+package models
+
+import (
+	"fmt"
+	"regexp"
+	"time"
+)
+
+// DefaultTenantID is the tenant of billing periods and API keys created before tenants existed
+const DefaultTenantID = "default"
+
+// defaultTaskQueue keeps the default tenant on the task queue workers used before tenants existed
+const defaultTaskQueue = "local-bills"
+
+var tenantIDPattern = regexp.MustCompile(`^[a-z0-9][a-z0-9-]{0,62}$`)
+
+// AccrualPolicy scales line items added late in a billing period
+type AccrualPolicy struct {
+	AfterHours int     `json:"after_hours"` // Items added more than this many hours into the period are scaled
+	Factor     float64 `json:"factor"`
+}
+
+// DefaultAccrualPolicy matches the accrual applied before it became configurable per tenant
+var DefaultAccrualPolicy = AccrualPolicy{AfterHours: 24, Factor: 2.5}
+
+// Tenant represents a business unit billed in isolation from the others
+type Tenant struct {
+	TenantID   string        `json:"tenant_id"`
+	Name       string        `json:"name"`
+	Currencies []Currency    `json:"currencies"` // Currencies bills of the tenant may use
+	Accrual    AccrualPolicy `json:"accrual"`
+	TaskQueue  string        `json:"task_queue"`
+	CreatedAt  time.Time     `json:"created_at"`
+	UpdatedAt  time.Time     `json:"updated_at"`
+}
+
+// TenantSettings holds the editable configuration of a tenant
+type TenantSettings struct {
+	Name       string         `json:"name"`
+	Currencies []Currency     `json:"currencies"`
+	Accrual    *AccrualPolicy `json:"accrual,omitempty"` // Defaults to DefaultAccrualPolicy
+}
+
+// CreateTenantRequest represents the request to register a tenant
+type CreateTenantRequest struct {
+	TenantID string `json:"tenant_id"`
+	TenantSettings
+}
+
+// UpdateTenantRequest represents the request to replace a tenant's configuration
+type UpdateTenantRequest struct {
+	TenantSettings
+}
+
+// TenantResponse represents the response for a single tenant
+type TenantResponse struct {
+	Tenant *Tenant `json:"tenant"`
+}
+
+// ListTaskQueuesResponse represents the task queues of all tenants
+type ListTaskQueuesResponse struct {
+	TaskQueues []string `json:"task_queues"`
+}
+
+// IsValidTenantID checks that a tenant ID is a lowercase slug, it ends up in workflow IDs and task queue names
+func IsValidTenantID(tenantID string) bool {
+	return tenantIDPattern.MatchString(tenantID)
+}
+
+// TenantTaskQueue returns the task queue running the billing periods of a tenant
+func TenantTaskQueue(tenantID string) string {
+	if tenantID == DefaultTenantID {
+		return defaultTaskQueue
+	}
+	return defaultTaskQueue + "-" + tenantID
+}
+
+// TenantCustomerKey identifies a customer within a tenant, customer IDs are only unique per tenant
+func TenantCustomerKey(tenantID string, customerID string) string {
+	return tenantID + "/" + customerID
+}
+
+// BillingPeriodWorkflowID returns the workflow ID of a billing period started on the given day
+func BillingPeriodWorkflowID(tenantID string, customerID string, startedAt time.Time) string {
+	return fmt.Sprintf("billing-period-workflow-%s-%s-%s", tenantID, startedAt.Format("20060102"), customerID)
+}
+
+// SupportsCurrency returns true if bills of the tenant may use the currency
+func (t *Tenant) SupportsCurrency(currency Currency) bool {
+	for _, supported := range t.Currencies {
+		if supported == currency {
+			return true
+		}
+	}
+	return false
+}
+
+// FactorAt returns the factor applied to a line item added at currentTime to a period started at startTime
+func (p AccrualPolicy) FactorAt(startTime time.Time, currentTime time.Time) float64 {
+	if startTime.Before(currentTime.Add(-time.Duration(p.AfterHours) * time.Hour)) {
+		return p.Factor
+	}
+	return 1.0
+}
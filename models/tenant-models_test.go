@@ -0,0 +1,47 @@
+package models
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestIsValidTenantID(t *testing.T) {
+	assert.True(t, IsValidTenantID("retail"))
+	assert.True(t, IsValidTenantID("eu-wholesale-2"))
+	assert.False(t, IsValidTenantID(""))
+	assert.False(t, IsValidTenantID("Retail"))
+	assert.False(t, IsValidTenantID("retail/eu"))
+	assert.False(t, IsValidTenantID("-retail"))
+}
+
+func TestTenantTaskQueue(t *testing.T) {
+	assert.Equal(t, "local-bills", TenantTaskQueue(DefaultTenantID))
+	assert.Equal(t, "local-bills-retail", TenantTaskQueue("retail"))
+}
+
+func TestBillingPeriodWorkflowID(t *testing.T) {
+	startedAt := time.Date(2025, 8, 21, 7, 0, 0, 0, time.UTC)
+	retail := BillingPeriodWorkflowID("retail", "cust-1", startedAt)
+	wholesale := BillingPeriodWorkflowID("wholesale", "cust-1", startedAt)
+	assert.Equal(t, "billing-period-workflow-retail-20250821-cust-1", retail)
+	assert.NotEqual(t, retail, wholesale)
+	assert.NotEqual(t, TenantCustomerKey("retail", "cust-1"), TenantCustomerKey("wholesale", "cust-1"))
+}
+
+func TestTenantSupportsCurrency(t *testing.T) {
+	tenant := &Tenant{Currencies: []Currency{GEL}}
+	assert.True(t, tenant.SupportsCurrency(GEL))
+	assert.False(t, tenant.SupportsCurrency(USD))
+}
+
+func TestAccrualPolicyFactorAt(t *testing.T) {
+	startTime := time.Date(2025, 8, 21, 7, 0, 0, 0, time.UTC)
+	assert.Equal(t, 1.0, DefaultAccrualPolicy.FactorAt(startTime, startTime.Add(24*time.Hour)))
+	assert.Equal(t, 2.5, DefaultAccrualPolicy.FactorAt(startTime, startTime.Add(25*time.Hour)))
+
+	policy := AccrualPolicy{AfterHours: 1, Factor: 1.1}
+	assert.Equal(t, 1.0, policy.FactorAt(startTime, startTime.Add(30*time.Minute)))
+	assert.Equal(t, 1.1, policy.FactorAt(startTime, startTime.Add(2*time.Hour)))
+}
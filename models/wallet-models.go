@@ -0,0 +1,160 @@
+package models
+
+import (
+	"sort"
+	"time"
+)
+
+// DrawDownMode controls when prepaid credits are applied to a customer's bills
+type DrawDownMode string
+
+const (
+	DrawDownNone      DrawDownMode = ""
+	DrawDownLineItem  DrawDownMode = "LINE_ITEM"
+	DrawDownBillClose DrawDownMode = "BILL_CLOSE"
+)
+
+// WalletTransactionType represents a movement of prepaid credits
+type WalletTransactionType string
+
+const (
+	TransactionTopUp    WalletTransactionType = "TOP_UP"
+	TransactionDrawDown WalletTransactionType = "DRAW_DOWN"
+	TransactionExpiry   WalletTransactionType = "EXPIRY"
+)
+
+// PrepaidCreditDescription is the description of the negative line added to bills for applied credits
+const PrepaidCreditDescription = "Prepaid credit applied"
+
+// Wallet represents a customer's prepaid credit balance
+type Wallet struct {
+	TenantID     string         `json:"tenant_id"`
+	CustomerID   string         `json:"customer_id"`
+	Currency     Currency       `json:"currency"`
+	DrawDownMode DrawDownMode   `json:"draw_down_mode"`
+	Balance      float64        `json:"balance"`
+	Grants       []*CreditGrant `json:"grants"`
+	CreatedAt    time.Time      `json:"created_at"`
+}
+
+// CreditGrant represents one top-up of prepaid credits and what is left of it
+type CreditGrant struct {
+	GrantID   string     `json:"grant_id"`
+	Amount    float64    `json:"amount"`
+	Remaining float64    `json:"remaining"`
+	ExpiresAt *time.Time `json:"expires_at,omitempty"`
+	CreatedAt time.Time  `json:"created_at"`
+}
+
+// GrantAllocation is the part of a draw-down taken from a single grant
+type GrantAllocation struct {
+	GrantID string  `json:"grant_id"`
+	Amount  float64 `json:"amount"`
+}
+
+// CreateWalletRequest represents the request to open a prepaid wallet for a customer
+type CreateWalletRequest struct {
+	Currency     Currency     `json:"currency"`
+	DrawDownMode DrawDownMode `json:"draw_down_mode"`
+}
+
+// TopUpWalletRequest represents the request to add prepaid credits to a wallet
+type TopUpWalletRequest struct {
+	Amount    float64    `json:"amount"`
+	Currency  Currency   `json:"currency"`
+	ExpiresAt *time.Time `json:"expires_at,omitempty"`
+}
+
+// WalletResponse represents the response containing a wallet and its remaining balance
+type WalletResponse struct {
+	Wallet *Wallet `json:"wallet"`
+}
+
+// FindWalletResponse represents a wallet lookup, Wallet is nil when the customer has none
+type FindWalletResponse struct {
+	Wallet *Wallet `json:"wallet,omitempty"`
+}
+
+// DrawDownRequest represents the request to apply prepaid credits to a bill
+type DrawDownRequest struct {
+	BillID    string   `json:"bill_id"`
+	Reference string   `json:"reference"` // Idempotency key, repeated draw-downs with the same reference apply once
+	Amount    float64  `json:"amount"`
+	Currency  Currency `json:"currency"`
+}
+
+// DrawDownResponse represents the credits applied by a draw-down, in the requested currency
+type DrawDownResponse struct {
+	AppliedAmount    float64  `json:"applied_amount"`
+	RemainingBalance float64  `json:"remaining_balance"`
+	WalletCurrency   Currency `json:"wallet_currency"`
+}
+
+// ExpireCreditsResponse represents the result of expiring lapsed grants
+type ExpireCreditsResponse struct {
+	ExpiredGrants int `json:"expired_grants"`
+}
+
+// IsValid checks if the draw-down mode is supported
+func (m DrawDownMode) IsValid() bool {
+	return m == DrawDownNone || m == DrawDownLineItem || m == DrawDownBillClose
+}
+
+// IsExpired returns true if the grant can no longer be drawn down
+func (g *CreditGrant) IsExpired(now time.Time) bool {
+	return g.ExpiresAt != nil && !g.ExpiresAt.After(now)
+}
+
+// AvailableBalance sums the remaining credits of all grants that have not expired
+func AvailableBalance(grants []*CreditGrant, now time.Time) float64 {
+	balance := 0.0
+	for _, grant := range grants {
+		if !grant.IsExpired(now) {
+			balance += grant.Remaining
+		}
+	}
+	return balance
+}
+
+// AllocateDrawDown takes up to amount from the grants, soonest expiring first, and returns what each grant contributes
+func AllocateDrawDown(grants []*CreditGrant, amount float64, now time.Time) ([]GrantAllocation, float64) {
+	available := make([]*CreditGrant, 0, len(grants))
+	for _, grant := range grants {
+		if grant.Remaining > 0 && !grant.IsExpired(now) {
+			available = append(available, grant)
+		}
+	}
+	sort.SliceStable(available, func(i, j int) bool {
+		a, b := available[i], available[j]
+		if a.ExpiresAt == nil || b.ExpiresAt == nil {
+			return a.ExpiresAt != nil && b.ExpiresAt == nil
+		}
+		return a.ExpiresAt.Before(*b.ExpiresAt)
+	})
+
+	allocations := make([]GrantAllocation, 0)
+	applied := 0.0
+	for _, grant := range available {
+		if applied >= amount {
+			break
+		}
+		take := grant.Remaining
+		if take > amount-applied {
+			take = amount - applied
+		}
+		allocations = append(allocations, GrantAllocation{GrantID: grant.GrantID, Amount: take})
+		applied += take
+	}
+	return allocations, applied
+}
+
+// NewPrepaidCreditLineItem builds the negative line item recording credits applied to a bill
+func NewPrepaidCreditLineItem(id string, appliedAmount float64, addedAt time.Time) *LineItem {
+	return &LineItem{
+		ID:          id,
+		Description: PrepaidCreditDescription,
+		Amount:      -appliedAmount,
+		Quantity:    1,
+		AddedAt:     addedAt,
+	}
+}
@@ -0,0 +1,67 @@
+package models
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAllocateDrawDown(t *testing.T) {
+	now := time.Date(2025, 8, 21, 7, 0, 0, 0, time.UTC)
+	soon := now.Add(24 * time.Hour)
+	later := now.Add(48 * time.Hour)
+	expired := now.Add(-time.Hour)
+	grants := func() []*CreditGrant {
+		return []*CreditGrant{
+			{GrantID: "no-expiry", Remaining: 50},
+			{GrantID: "later", Remaining: 20, ExpiresAt: &later},
+			{GrantID: "expired", Remaining: 100, ExpiresAt: &expired},
+			{GrantID: "soon", Remaining: 10, ExpiresAt: &soon},
+			{GrantID: "used", Remaining: 0},
+		}
+	}
+
+	t.Run("soonest expiring first", func(t *testing.T) {
+		allocations, applied := AllocateDrawDown(grants(), 25, now)
+		assert.Equal(t, 25.0, applied)
+		assert.Equal(t, []GrantAllocation{{GrantID: "soon", Amount: 10}, {GrantID: "later", Amount: 15}}, allocations)
+	})
+	t.Run("partial when balance is insufficient", func(t *testing.T) {
+		allocations, applied := AllocateDrawDown(grants(), 500, now)
+		assert.Equal(t, 80.0, applied)
+		assert.Len(t, allocations, 3)
+		assert.Equal(t, "no-expiry", allocations[2].GrantID)
+	})
+	t.Run("no grants", func(t *testing.T) {
+		allocations, applied := AllocateDrawDown(nil, 10, now)
+		assert.Equal(t, 0.0, applied)
+		assert.Empty(t, allocations)
+	})
+}
+
+func TestAvailableBalance(t *testing.T) {
+	now := time.Date(2025, 8, 21, 7, 0, 0, 0, time.UTC)
+	expired := now
+	grants := []*CreditGrant{
+		{Remaining: 30},
+		{Remaining: 70, ExpiresAt: &expired},
+	}
+	assert.Equal(t, 30.0, AvailableBalance(grants, now))
+}
+
+func TestDrawDownMode_IsValid(t *testing.T) {
+	assert.True(t, DrawDownNone.IsValid())
+	assert.True(t, DrawDownLineItem.IsValid())
+	assert.True(t, DrawDownBillClose.IsValid())
+	assert.False(t, DrawDownMode("ALWAYS").IsValid())
+}
+
+func TestNewPrepaidCreditLineItem(t *testing.T) {
+	addedAt := time.Date(2025, 8, 21, 7, 0, 0, 0, time.UTC)
+	item := NewPrepaidCreditLineItem("credit-1", 12.5, addedAt)
+	assert.Equal(t, -12.5, item.Amount)
+	assert.Equal(t, 1, item.Quantity)
+	assert.Equal(t, PrepaidCreditDescription, item.Description)
+	assert.Equal(t, addedAt, item.AddedAt)
+}
@@ -0,0 +1,189 @@
+package models
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// WebhookEventType identifies the bill lifecycle event a webhook endpoint subscribes to
+type WebhookEventType string
+
+const (
+	WebhookBillCreated         WebhookEventType = "bill.created"
+	WebhookLineItemAdded       WebhookEventType = "line_item.added"
+	WebhookBillClosed          WebhookEventType = "bill.closed"
+	WebhookBillingPeriodClosed WebhookEventType = "billing_period.closed"
+)
+
+// WebhookDeliveryStatus represents the state of a webhook delivery
+type WebhookDeliveryStatus string
+
+const (
+	DeliveryPending      WebhookDeliveryStatus = "PENDING"
+	DeliveryDelivered    WebhookDeliveryStatus = "DELIVERED"
+	DeliveryDeadLettered WebhookDeliveryStatus = "DEAD_LETTERED"
+)
+
+// Headers sent with every webhook delivery
+const (
+	WebhookSignatureHeader = "X-Webhook-Signature"
+	WebhookIDHeader        = "X-Webhook-Id"
+	WebhookEventHeader     = "X-Webhook-Event"
+)
+
+// WebhookEndpoint represents a customer URL receiving bill events
+type WebhookEndpoint struct {
+	EndpointID string             `json:"endpoint_id"`
+	TenantID   string             `json:"tenant_id"`
+	CustomerID string             `json:"customer_id"`
+	URL        string             `json:"url"`
+	EventTypes []WebhookEventType `json:"event_types"`
+	Secret     string             `json:"secret,omitempty"` // Only returned when the endpoint is registered
+	CreatedAt  time.Time          `json:"created_at"`
+}
+
+// WebhookDelivery represents one event sent, or being sent, to an endpoint
+type WebhookDelivery struct {
+	DeliveryID     string                `json:"delivery_id"`
+	EndpointID     string                `json:"endpoint_id"`
+	TenantID       string                `json:"tenant_id"`
+	CustomerID     string                `json:"customer_id"`
+	EventID        string                `json:"event_id"`
+	EventType      WebhookEventType      `json:"event_type"`
+	Payload        json.RawMessage       `json:"payload"`
+	Status         WebhookDeliveryStatus `json:"status"`
+	Attempts       int                   `json:"attempts"`
+	LastStatusCode int                   `json:"last_status_code,omitempty"`
+	LastError      string                `json:"last_error,omitempty"`
+	CreatedAt      time.Time             `json:"created_at"`
+	UpdatedAt      time.Time             `json:"updated_at"`
+	DeliveredAt    *time.Time            `json:"delivered_at,omitempty"`
+}
+
+// WebhookEnvelope is the JSON body posted to webhook endpoints
+type WebhookEnvelope struct {
+	ID        string           `json:"id"`
+	Type      WebhookEventType `json:"type"`
+	CreatedAt time.Time        `json:"created_at"`
+	Data      interface{}      `json:"data"`
+}
+
+// RegisterWebhookEndpointRequest represents the request to register a webhook endpoint
+type RegisterWebhookEndpointRequest struct {
+	URL        string             `json:"url"`
+	EventTypes []WebhookEventType `json:"event_types"`
+}
+
+// WebhookEndpointResponse represents the response containing a webhook endpoint
+type WebhookEndpointResponse struct {
+	Endpoint *WebhookEndpoint `json:"endpoint"`
+}
+
+// ListWebhookEndpointsResponse represents the response when listing webhook endpoints
+type ListWebhookEndpointsResponse struct {
+	Endpoints []*WebhookEndpoint `json:"endpoints"`
+	Total     int64              `json:"total"`
+}
+
+// ListWebhookDeliveriesRequest represents query parameters for listing webhook deliveries
+type ListWebhookDeliveriesRequest struct {
+	Status string `query:"status"`
+}
+
+// ListWebhookDeliveriesResponse represents the response when listing webhook deliveries
+type ListWebhookDeliveriesResponse struct {
+	Deliveries []*WebhookDelivery `json:"deliveries"`
+	Total      int64              `json:"total"`
+}
+
+// WebhookDeliveryResponse represents the response containing a webhook delivery
+type WebhookDeliveryResponse struct {
+	Delivery *WebhookDelivery `json:"delivery"`
+}
+
+// WebhookDeliveryInput represents the input for starting a webhook delivery workflow
+type WebhookDeliveryInput struct {
+	DeliveryID      string        `json:"delivery_id"`
+	MaxAttempts     int32         `json:"max_attempts"`
+	InitialInterval time.Duration `json:"initial_interval"`
+	MaxInterval     time.Duration `json:"max_interval"`
+}
+
+// DeadLetterWebhookInput represents the input of the activity dead-lettering a delivery
+type DeadLetterWebhookInput struct {
+	DeliveryID string `json:"delivery_id"`
+	Reason     string `json:"reason"`
+}
+
+// IsValid checks if the event type is supported
+func (t WebhookEventType) IsValid() bool {
+	return t == WebhookBillCreated || t == WebhookLineItemAdded || t == WebhookBillClosed || t == WebhookBillingPeriodClosed
+}
+
+// IsValid checks if the delivery status is valid
+func (s WebhookDeliveryStatus) IsValid() bool {
+	return s == DeliveryPending || s == DeliveryDelivered || s == DeliveryDeadLettered
+}
+
+// Subscribes returns true if the endpoint receives events of the given type
+func (e *WebhookEndpoint) Subscribes(eventType WebhookEventType) bool {
+	for _, subscribed := range e.EventTypes {
+		if subscribed == eventType {
+			return true
+		}
+	}
+	return false
+}
+
+// SignWebhookPayload returns the signature header value for a payload sent at timestamp.
+// The HMAC-SHA256 covers "<unix timestamp>.<payload>" so receivers can reject replayed requests.
+func SignWebhookPayload(secret string, timestamp time.Time, payload []byte) string {
+	unix := strconv.FormatInt(timestamp.Unix(), 10)
+	return fmt.Sprintf("t=%s,v1=%s", unix, computeWebhookMAC(secret, unix, payload))
+}
+
+// VerifyWebhookSignature checks a signature header against the payload, rejecting signatures older than tolerance
+func VerifyWebhookSignature(secret string, header string, payload []byte, now time.Time, tolerance time.Duration) error {
+	var unix, signature string
+	for _, part := range strings.Split(header, ",") {
+		key, value, found := strings.Cut(part, "=")
+		if !found {
+			continue
+		}
+		switch key {
+		case "t":
+			unix = value
+		case "v1":
+			signature = value
+		}
+	}
+	if unix == "" || signature == "" {
+		return fmt.Errorf("malformed signature header")
+	}
+	seconds, err := strconv.ParseInt(unix, 10, 64)
+	if err != nil {
+		return fmt.Errorf("invalid signature timestamp: %w", err)
+	}
+	if age := now.Sub(time.Unix(seconds, 0)); age > tolerance || age < -tolerance {
+		return fmt.Errorf("signature timestamp outside tolerance")
+	}
+	expected := computeWebhookMAC(secret, unix, payload)
+	if !hmac.Equal([]byte(expected), []byte(signature)) {
+		return fmt.Errorf("signature mismatch")
+	}
+	return nil
+}
+
+func computeWebhookMAC(secret string, unix string, payload []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(unix))
+	mac.Write([]byte("."))
+	mac.Write(payload)
+	return hex.EncodeToString(mac.Sum(nil))
+}
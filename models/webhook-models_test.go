@@ -0,0 +1,44 @@
+package models
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWebhookSignature(t *testing.T) {
+	secret := "whsec_test"
+	payload := []byte(`{"id":"bill-closed/bill-1"}`)
+	sentAt := time.Unix(1755759600, 0)
+
+	header := SignWebhookPayload(secret, sentAt, payload)
+	assert.Regexp(t, `^t=1755759600,v1=[0-9a-f]{64}$`, header)
+
+	t.Run("valid signature", func(t *testing.T) {
+		assert.NoError(t, VerifyWebhookSignature(secret, header, payload, sentAt.Add(time.Minute), 5*time.Minute))
+	})
+	t.Run("tampered payload", func(t *testing.T) {
+		err := VerifyWebhookSignature(secret, header, []byte(`{"id":"bill-closed/bill-2"}`), sentAt, 5*time.Minute)
+		assert.EqualError(t, err, "signature mismatch")
+	})
+	t.Run("wrong secret", func(t *testing.T) {
+		err := VerifyWebhookSignature("whsec_other", header, payload, sentAt, 5*time.Minute)
+		assert.EqualError(t, err, "signature mismatch")
+	})
+	t.Run("replayed request", func(t *testing.T) {
+		err := VerifyWebhookSignature(secret, header, payload, sentAt.Add(time.Hour), 5*time.Minute)
+		assert.EqualError(t, err, "signature timestamp outside tolerance")
+	})
+	t.Run("malformed header", func(t *testing.T) {
+		err := VerifyWebhookSignature(secret, "v1=abc", payload, sentAt, 5*time.Minute)
+		assert.EqualError(t, err, "malformed signature header")
+	})
+}
+
+func TestWebhookEndpointSubscribes(t *testing.T) {
+	endpoint := &WebhookEndpoint{EventTypes: []WebhookEventType{WebhookBillClosed}}
+	assert.True(t, endpoint.Subscribes(WebhookBillClosed))
+	assert.False(t, endpoint.Subscribes(WebhookLineItemAdded))
+	assert.False(t, WebhookEventType("bill.deleted").IsValid())
+}
@@ -6,29 +6,75 @@ import (
 
 // BillWorkflowInput represents the input for starting a bill workflow
 type BillWorkflowInput struct {
-	WorkflowID        string    `json:"workflow_id"`
-	CustomerID        string    `json:"customer_id"`
-	Currency          Currency  `json:"currency"`
-	BillingPeriodDays int       `json:"billing_period_days"`
-	BillStates        []*Bill   `json:"bill_states"`
-	StartedAt         time.Time `json:"started_at"`
+	WorkflowID        string          `json:"workflow_id"`
+	TenantID          string          `json:"tenant_id"`
+	CustomerID        string          `json:"customer_id"`
+	Currency          Currency        `json:"currency"`
+	BillingPeriodDays int             `json:"billing_period_days"`
+	BillStates        []*Bill         `json:"bill_states"`
+	StartedAt         time.Time       `json:"started_at"`
+	DrawDownMode      DrawDownMode    `json:"draw_down_mode,omitempty"` // Set when the customer has a prepaid wallet
+	SpendingLimit     *SpendingLimit  `json:"spending_limit,omitempty"`
+	SpendingAlerts    []SpendingAlert `json:"spending_alerts,omitempty"`
+	Accrual           *AccrualPolicy  `json:"accrual,omitempty"`        // The tenant's policy, DefaultAccrualPolicy when unset
+	ChildAccounts     []*ChildBills   `json:"child_accounts,omitempty"` // Closed by CloseBillingPeriod before this period
 }
 
+// ArchivedBillingPeriod represents a finished billing period with the final state of its bills, kept
+// once its workflow history is gone
+type ArchivedBillingPeriod struct {
+	WorkflowID       string            `json:"workflow_id"`
+	TenantID         string            `json:"tenant_id"`
+	CustomerID       string            `json:"customer_id"`
+	Currency         Currency          `json:"currency"`
+	StartedAt        time.Time         `json:"started_at"`
+	ClosedAt         time.Time         `json:"closed_at"`
+	Bills            []*Bill           `json:"bills"`
+	ConsolidatedBill *ConsolidatedBill `json:"consolidated_bill,omitempty"` // Set when the customer is a parent account
+}
+
+// TraceContext carries the W3C trace context and baggage of the API call sending a signal, the workflow
+// continues the trace where it applies the signal
+type TraceContext map[string]string
+
 // AddLineItemSignal represents the signal to add a line item
 type AddLineItemSignal struct {
-	LineItem *LineItem `json:"line_item"`
-	BillID   string    `json:"bill_id"`
-	Currency Currency  `json:"currency"`
+	LineItem *LineItem    `json:"line_item"`
+	BillID   string       `json:"bill_id"`
+	Currency Currency     `json:"currency"`
+	Audit    AuditContext `json:"audit"`
+	Trace    TraceContext `json:"trace,omitempty"`
 }
 
 // CloseBillSignal represents the signal to close a bill
 type CloseBillSignal struct {
-	Reason string `json:"reason"`
-	BillID string `json:"bill_id"`
+	Reason string       `json:"reason"`
+	BillID string       `json:"bill_id"`
+	Audit  AuditContext `json:"audit"`
+	Trace  TraceContext `json:"trace,omitempty"`
+}
+
+// CloseBillingPeriodSignal represents the signal to close every bill and end the billing period
+type CloseBillingPeriodSignal struct {
+	Audit         AuditContext  `json:"audit"`
+	Trace         TraceContext  `json:"trace,omitempty"`
+	ChildAccounts []*ChildBills `json:"child_accounts,omitempty"` // Closed before the parent, consolidated with it
 }
 
 type CreateBillSignal struct {
-	Currency   Currency `json:"currency"`
-	WorkflowID string   `json:"workflow_id"`
+	Currency   Currency     `json:"currency"`
+	WorkflowID string       `json:"workflow_id"`
+	BillID     string       `json:"bill_id"`
+	Audit      AuditContext `json:"audit"`
+	Trace      TraceContext `json:"trace,omitempty"`
+}
+
+// DrawDownCreditsInput represents the input of the activity applying prepaid credits to a bill
+type DrawDownCreditsInput struct {
+	TenantID   string   `json:"tenant_id,omitempty"`
+	CustomerID string   `json:"customer_id"`
 	BillID     string   `json:"bill_id"`
+	Reference  string   `json:"reference"`
+	Amount     float64  `json:"amount"`
+	Currency   Currency `json:"currency"`
 }
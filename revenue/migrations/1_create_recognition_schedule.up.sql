@@ -0,0 +1,17 @@
+CREATE TABLE recognition_schedule (
+    bill_id       TEXT NOT NULL,
+    line_item_id  TEXT NOT NULL,
+    month         TEXT NOT NULL,
+    milestone     TEXT NOT NULL DEFAULT '',
+    tenant_id     TEXT NOT NULL,
+    customer_id   TEXT NOT NULL,
+    product       TEXT NOT NULL DEFAULT '',
+    method        TEXT NOT NULL,
+    currency      TEXT NOT NULL,
+    amount        DOUBLE PRECISION NOT NULL,
+    recognized_at TIMESTAMPTZ,
+    PRIMARY KEY (bill_id, line_item_id, month, milestone)
+);
+
+CREATE INDEX recognition_schedule_pending_idx ON recognition_schedule (month) WHERE recognized_at IS NULL;
+CREATE INDEX recognition_schedule_report_idx ON recognition_schedule (tenant_id, month);
@@ -0,0 +1,43 @@
+package revenue
+
+import (
+	"fmt"
+
+	"encore.dev/beta/auth"
+	"encore.dev/beta/errs"
+
+	"encore.app/models"
+)
+
+func validateRevenueReportRequest(req *models.RevenueReportRequest) error {
+	from, err := models.ParseRecognitionMonth(req.From)
+	if err != nil {
+		return fmt.Errorf("from: %w", err)
+	}
+	to, err := models.ParseRecognitionMonth(req.To)
+	if err != nil {
+		return fmt.Errorf("to: %w", err)
+	}
+	if to.Before(from) {
+		return fmt.Errorf("to must not be before from")
+	}
+	if req.Currency != "" && !req.Currency.IsValid() {
+		return fmt.Errorf("invalid currency: %s (supported: USD, GEL)", req.Currency)
+	}
+	return nil
+}
+
+// authorizeTenantAdmin checks that the calling API key is an admin key of a tenant and returns the tenant
+func authorizeTenantAdmin() (string, error) {
+	data, _ := auth.Data().(*models.AuthData)
+	if data == nil {
+		return "", &errs.Error{Code: errs.Unauthenticated, Message: "missing API key"}
+	}
+	if data.TenantID == "" {
+		return "", &errs.Error{Code: errs.PermissionDenied, Message: "the operator key cannot read revenue reports, use an admin key of the tenant"}
+	}
+	if err := data.AuthorizeTenant(data.TenantID, models.ScopeAdmin); err != nil {
+		return "", &errs.Error{Code: errs.PermissionDenied, Message: err.Error()}
+	}
+	return data.TenantID, nil
+}
@@ -0,0 +1,37 @@
+package revenue
+
+import (
+	"testing"
+
+	"encore.app/models"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestValidateRevenueReportRequest(t *testing.T) {
+	t.Parallel()
+	cases := []struct {
+		name    string
+		req     models.RevenueReportRequest
+		wantErr string
+	}{
+		{"valid request", models.RevenueReportRequest{From: "2025-01", To: "2025-12"}, ""},
+		{"single month", models.RevenueReportRequest{From: "2025-03", To: "2025-03", Currency: models.GEL}, ""},
+		{"missing from", models.RevenueReportRequest{To: "2025-12"}, "from: invalid month"},
+		{"malformed to", models.RevenueReportRequest{From: "2025-01", To: "2025-13"}, "to: invalid month"},
+		{"reversed range", models.RevenueReportRequest{From: "2025-06", To: "2025-01"}, "to must not be before from"},
+		{"unknown currency", models.RevenueReportRequest{From: "2025-01", To: "2025-12", Currency: "EUR"}, "invalid currency"},
+	}
+	for _, tc := range cases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+			err := validateRevenueReportRequest(&tc.req)
+			if tc.wantErr == "" {
+				assert.NoError(t, err)
+				return
+			}
+			assert.Error(t, err)
+			assert.Contains(t, err.Error(), tc.wantErr)
+		})
+	}
+}
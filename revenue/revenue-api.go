@@ -0,0 +1,70 @@
+package revenue
+
+import (
+	"context"
+	"time"
+
+	"encore.dev/cron"
+	"encore.dev/rlog"
+
+	"encore.app/ledger"
+	"encore.app/models"
+)
+
+// Revenue deferred to later months is recognised early on the first day of each month
+var _ = cron.NewJob("recognize-revenue", cron.JobConfig{
+	Title:    "Recognise deferred revenue",
+	Schedule: "0 2 1 * *",
+	Endpoint: RecognizeRevenue,
+})
+
+// RecognizeRevenue recognises the deferred revenue due up to the current month, moving it from deferred to
+// recognised revenue in the ledger. Running it again in the same month posts nothing new.
+//
+//encore:api private
+func RecognizeRevenue(ctx context.Context) (*models.RecognizeRevenueResponse, error) {
+	now := time.Now()
+	month := models.RecognitionMonth(now)
+	pending, err := listPending(ctx, month)
+	if err != nil {
+		return nil, err
+	}
+	for _, p := range pending {
+		if models.RoundAmount(p.amount) == 0 {
+			if err := markRecognized(ctx, p.billID, p.month, now); err != nil {
+				return nil, err
+			}
+			continue
+		}
+		entry := models.NewRecognitionJournalEntry(p.tenantID, p.customerID, p.billID, p.month, models.RoundAmount(p.amount), p.currency, now)
+		// The ledger keeps the first entry of an ID, so a run failing after posting is safe to repeat
+		if err := ledger.PostEntry(ctx, entry); err != nil {
+			return nil, err
+		}
+		if err := markRecognized(ctx, p.billID, p.month, now); err != nil {
+			return nil, err
+		}
+	}
+	if len(pending) > 0 {
+		rlog.Info("recognised deferred revenue", "month", month, "entries", len(pending))
+	}
+	return &models.RecognizeRevenueResponse{Month: month, Entries: len(pending)}, nil
+}
+
+// GetRevenueReport returns recognised and deferred revenue of the tenant by month and product
+//
+//encore:api auth method=GET path=/revenue/report
+func GetRevenueReport(ctx context.Context, req *models.RevenueReportRequest) (*models.RevenueReportResponse, error) {
+	tenantID, err := authorizeTenantAdmin()
+	if err != nil {
+		return nil, err
+	}
+	if err := validateRevenueReportRequest(req); err != nil {
+		return nil, models.InvalidRequest(err)
+	}
+	lines, err := reportLines(ctx, tenantID, req)
+	if err != nil {
+		return nil, err
+	}
+	return &models.RevenueReportResponse{TenantID: tenantID, Lines: lines}, nil
+}
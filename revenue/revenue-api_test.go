@@ -0,0 +1,89 @@
+package revenue
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"encore.app/models"
+	"encore.dev/beta/auth"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+)
+
+// adminContext authenticates calls with an admin API key of the default tenant
+func adminContext() context.Context {
+	return auth.WithContext(context.Background(), "test-admin", &models.AuthData{
+		KeyID:    "test-admin",
+		TenantID: models.DefaultTenantID,
+		Scopes:   []models.APIKeyScope{models.ScopeAdmin},
+	})
+}
+
+func TestRevenueRecognition(t *testing.T) {
+	now := time.Now().UTC()
+	thisMonth := time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, time.UTC)
+	closedAt := thisMonth.AddDate(0, -1, 0)
+	product := "seats-" + uuid.New().String()
+	billId := uuid.New().String()
+
+	bill := &models.Bill{
+		ID:       billId,
+		Status:   models.StatusClosed,
+		Currency: models.USD,
+		ClosedAt: closedAt,
+		LineItems: []*models.LineItem{{ID: "seats", Amount: 100, Quantity: 1, Product: product, Recognition: &models.RecognitionRule{
+			Method: models.RecognizeMilestone,
+			Milestones: []models.RecognitionMilestone{
+				{Name: "kickoff", Date: closedAt, Percent: 40},
+				{Name: "go-live", Date: thisMonth, Percent: 60},
+			},
+		}}},
+	}
+	bill.RecognitionSchedule = models.NewRecognitionSchedule(bill)
+	event := &models.BillClosedEvent{
+		EventID:    models.BillClosedEventID(billId),
+		TenantID:   models.DefaultTenantID,
+		CustomerID: uuid.New().String(),
+		Bill:       bill,
+	}
+	report := &models.RevenueReportRequest{From: models.RecognitionMonth(closedAt), To: models.RecognitionMonth(thisMonth)}
+	productLines := func(resp *models.RevenueReportResponse) map[string]*models.RevenueReportLine {
+		lines := map[string]*models.RevenueReportLine{}
+		for _, line := range resp.Lines {
+			if line.Product == product {
+				lines[line.Month] = line
+			}
+		}
+		return lines
+	}
+
+	t.Run("Schedules Are Stored Once", func(t *testing.T) {
+		assert.NoError(t, storeSchedule(context.Background(), event))
+		assert.NoError(t, storeSchedule(context.Background(), event))
+
+		resp, err := GetRevenueReport(adminContext(), report)
+		assert.NoError(t, err)
+		lines := productLines(resp)
+		assert.Equal(t, 40.0, lines[report.From].Recognized)
+		assert.Equal(t, 60.0, lines[report.To].Deferred)
+	})
+
+	t.Run("Due Revenue Is Recognised Once", func(t *testing.T) {
+		_, err := RecognizeRevenue(context.Background())
+		assert.NoError(t, err)
+		_, err = RecognizeRevenue(context.Background())
+		assert.NoError(t, err)
+
+		resp, err := GetRevenueReport(adminContext(), report)
+		assert.NoError(t, err)
+		lines := productLines(resp)
+		assert.Equal(t, 60.0, lines[report.To].Recognized)
+		assert.Equal(t, 0.0, lines[report.To].Deferred)
+	})
+
+	t.Run("Only Tenant Admins Read Reports", func(t *testing.T) {
+		_, err := GetRevenueReport(context.Background(), report)
+		assert.Error(t, err)
+	})
+}
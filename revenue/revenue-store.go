@@ -0,0 +1,123 @@
+package revenue
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"encore.dev/storage/sqldb"
+
+	"encore.app/models"
+)
+
+// Recognition schedules of closed bills live in their own database
+var db = sqldb.NewDatabase("revenue", sqldb.DatabaseConfig{
+	Migrations: "./migrations",
+})
+
+// pendingRecognition is the revenue of a bill due in a month that has not been recognised yet
+type pendingRecognition struct {
+	tenantID   string
+	customerID string
+	billID     string
+	month      string
+	currency   models.Currency
+	amount     float64
+}
+
+// insertSchedule stores the recognition schedule of a closed bill. Entries falling in or before the month the
+// bill closed in are recognised at close. Storing a schedule again leaves the stored entries untouched.
+func insertSchedule(ctx context.Context, tenantID, customerID string, bill *models.Bill) error {
+	tx, err := db.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	closeMonth := models.RecognitionMonth(bill.ClosedAt)
+	for _, entry := range bill.RecognitionSchedule {
+		var recognizedAt *time.Time
+		if entry.Month <= closeMonth {
+			recognizedAt = &bill.ClosedAt
+		}
+		_, err := tx.Exec(ctx, `
+			INSERT INTO recognition_schedule (bill_id, line_item_id, month, milestone, tenant_id, customer_id,
+				product, method, currency, amount, recognized_at)
+			VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11)
+			ON CONFLICT (bill_id, line_item_id, month, milestone) DO NOTHING
+		`, bill.ID, entry.LineItemID, entry.Month, entry.Milestone, tenantID, customerID, entry.Product,
+			string(entry.Method), string(bill.Currency), entry.Amount, recognizedAt)
+		if err != nil {
+			return fmt.Errorf("failed to insert recognition entry: %w", err)
+		}
+	}
+	return tx.Commit()
+}
+
+// listPending returns the unrecognised revenue due up to and including the month, per bill and month
+func listPending(ctx context.Context, month string) ([]*pendingRecognition, error) {
+	rows, err := db.Query(ctx, `
+		SELECT tenant_id, customer_id, bill_id, month, currency, SUM(amount)
+		FROM recognition_schedule
+		WHERE recognized_at IS NULL AND month <= $1
+		GROUP BY tenant_id, customer_id, bill_id, month, currency
+		ORDER BY month, bill_id
+	`, month)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list pending recognition: %w", err)
+	}
+	defer rows.Close()
+
+	pending := make([]*pendingRecognition, 0)
+	for rows.Next() {
+		var p pendingRecognition
+		var currency string
+		if err := rows.Scan(&p.tenantID, &p.customerID, &p.billID, &p.month, &currency, &p.amount); err != nil {
+			return nil, fmt.Errorf("failed to scan pending recognition: %w", err)
+		}
+		p.currency = models.Currency(currency)
+		pending = append(pending, &p)
+	}
+	return pending, rows.Err()
+}
+
+func markRecognized(ctx context.Context, billID, month string, recognizedAt time.Time) error {
+	_, err := db.Exec(ctx, `
+		UPDATE recognition_schedule SET recognized_at = $3
+		WHERE bill_id = $1 AND month = $2 AND recognized_at IS NULL
+	`, billID, month, recognizedAt)
+	if err != nil {
+		return fmt.Errorf("failed to mark revenue recognised: %w", err)
+	}
+	return nil
+}
+
+// reportLines totals recognised and deferred revenue of the tenant by month, product and currency
+func reportLines(ctx context.Context, tenantID string, req *models.RevenueReportRequest) ([]*models.RevenueReportLine, error) {
+	rows, err := db.Query(ctx, `
+		SELECT month, product, currency,
+			COALESCE(SUM(amount) FILTER (WHERE recognized_at IS NOT NULL), 0),
+			COALESCE(SUM(amount) FILTER (WHERE recognized_at IS NULL), 0)
+		FROM recognition_schedule
+		WHERE tenant_id = $1 AND month BETWEEN $2 AND $3 AND ($4 = '' OR currency = $4)
+		GROUP BY month, product, currency
+		ORDER BY month, product, currency
+	`, tenantID, req.From, req.To, string(req.Currency))
+	if err != nil {
+		return nil, fmt.Errorf("failed to report revenue: %w", err)
+	}
+	defer rows.Close()
+
+	lines := make([]*models.RevenueReportLine, 0)
+	for rows.Next() {
+		var line models.RevenueReportLine
+		var currency string
+		if err := rows.Scan(&line.Month, &line.Product, &currency, &line.Recognized, &line.Deferred); err != nil {
+			return nil, fmt.Errorf("failed to scan revenue line: %w", err)
+		}
+		line.Currency = models.Currency(currency)
+		line.Recognized, line.Deferred = models.RoundAmount(line.Recognized), models.RoundAmount(line.Deferred)
+		lines = append(lines, &line)
+	}
+	return lines, rows.Err()
+}
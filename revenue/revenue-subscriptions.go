@@ -0,0 +1,27 @@
+package revenue
+
+import (
+	"context"
+
+	"encore.dev/pubsub"
+
+	"encore.app/events"
+	"encore.app/models"
+)
+
+// The recognition schedule of every closed bill is stored when the bill workflow announces it
+var _ = pubsub.NewSubscription(events.BillClosed, "revenue-bill-closed", pubsub.SubscriptionConfig[*models.BillClosedEvent]{
+	Handler: storeSchedule,
+})
+
+// storeSchedule stores the recognition schedule of a closed bill, redelivered events leave it untouched
+func storeSchedule(ctx context.Context, event *models.BillClosedEvent) error {
+	tenantID := event.TenantID
+	if tenantID == "" {
+		tenantID = models.DefaultTenantID
+	}
+	if len(event.Bill.RecognitionSchedule) == 0 {
+		return nil
+	}
+	return insertSchedule(ctx, tenantID, event.CustomerID, event.Bill)
+}
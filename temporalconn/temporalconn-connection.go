@@ -0,0 +1,142 @@
+package temporalconn
+
+import (
+	"errors"
+	"sync"
+	"time"
+
+	"encore.dev/rlog"
+	"go.temporal.io/sdk/client"
+)
+
+// Connection attempts back off exponentially between these bounds while Temporal is unreachable
+var (
+	retryInitial = time.Second
+	retryMax     = 30 * time.Second
+)
+
+// ErrNotConnected is returned by Client before the first connection attempt is over
+var ErrNotConnected = errors.New("not connected to Temporal yet")
+
+// Connection holds the Temporal client of a service. The zero value has no client and does not wait for a
+// connection attempt, tests inject clients into it with Set.
+type Connection struct {
+	mu         sync.RWMutex
+	client     client.Client // Nil until Connect succeeds
+	err        error         // Why the last connection attempt failed
+	connecting chan struct{} // Closed once the first connection attempt is over
+	done       chan struct{} // Closed by Close to stop retrying
+	closeOnce  sync.Once
+}
+
+// New returns a connection whose Client waits for the first attempt of Connect
+func New() *Connection {
+	return &Connection{
+		connecting: make(chan struct{}),
+		done:       make(chan struct{}),
+	}
+}
+
+// Connect dials Temporal until it succeeds or the connection is closed, run it in its own goroutine.
+// onConnected is called once with the client, to start workers on it.
+func (c *Connection) Connect(options client.Options, onConnected func(client.Client)) {
+	backoff := retryInitial
+	for attempt := 0; ; attempt++ {
+		temporalClient, err := client.Dial(options)
+		installed := false
+		if err == nil {
+			// A client injected meanwhile stays in use
+			if installed = c.setIfUnset(temporalClient); !installed {
+				temporalClient.Close()
+			}
+		} else {
+			c.mu.Lock()
+			c.err = err
+			c.mu.Unlock()
+		}
+		if attempt == 0 && c.connecting != nil {
+			close(c.connecting)
+		}
+		if err == nil {
+			if installed {
+				rlog.Info("connected to Temporal", "host_port", options.HostPort, "namespace", options.Namespace)
+				if onConnected != nil {
+					onConnected(temporalClient)
+				}
+			}
+			return
+		}
+		rlog.Warn("failed to connect to Temporal, retrying",
+			"host_port", options.HostPort,
+			"retry_in", backoff.String(),
+			"error", err,
+		)
+		select {
+		case <-c.done:
+			return
+		case <-time.After(backoff):
+		}
+		backoff = nextBackoff(backoff)
+	}
+}
+
+// nextBackoff doubles the wait before the next connection attempt up to retryMax
+func nextBackoff(backoff time.Duration) time.Duration {
+	return min(backoff*2, retryMax)
+}
+
+// Client returns the connected client. While Temporal is unreachable it returns the error of the last attempt,
+// or ErrNotConnected, so callers fail fast instead of waiting on the connection. Only calls made during the
+// first attempt wait for it.
+func (c *Connection) Client() (client.Client, error) {
+	if c.connecting != nil {
+		<-c.connecting
+	}
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	if c.client != nil {
+		return c.client, nil
+	}
+	if c.err != nil {
+		return nil, c.err
+	}
+	return nil, ErrNotConnected
+}
+
+// Current returns the client, nil until it is connected
+func (c *Connection) Current() client.Client {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.client
+}
+
+// Set replaces the client and returns the previous one, tests inject clients with it
+func (c *Connection) Set(temporalClient client.Client) client.Client {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	previous := c.client
+	c.client, c.err = temporalClient, nil
+	return previous
+}
+
+func (c *Connection) setIfUnset(temporalClient client.Client) bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.client != nil {
+		return false
+	}
+	c.client, c.err = temporalClient, nil
+	return true
+}
+
+// Close stops retrying and closes the client, call it on shutdown once the workers are stopped
+func (c *Connection) Close() {
+	c.closeOnce.Do(func() {
+		if c.done != nil {
+			close(c.done)
+		}
+	})
+	if temporalClient := c.Set(nil); temporalClient != nil {
+		temporalClient.Close()
+	}
+}
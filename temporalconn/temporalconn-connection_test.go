@@ -0,0 +1,45 @@
+package temporalconn
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"go.temporal.io/sdk/mocks"
+)
+
+func TestNextBackoff(t *testing.T) {
+	assert.Equal(t, 2*time.Second, nextBackoff(time.Second))
+	assert.Equal(t, 16*time.Second, nextBackoff(8*time.Second))
+	assert.Equal(t, retryMax, nextBackoff(20*time.Second))
+	assert.Equal(t, retryMax, nextBackoff(retryMax))
+}
+
+func TestConnectionClient(t *testing.T) {
+	c := &Connection{}
+
+	t.Run("not connected", func(t *testing.T) {
+		_, err := c.Client()
+		assert.ErrorIs(t, err, ErrNotConnected)
+	})
+
+	t.Run("last attempt failed", func(t *testing.T) {
+		c.err = errors.New("connection refused")
+		_, err := c.Client()
+		assert.EqualError(t, err, "connection refused")
+	})
+
+	t.Run("injected client", func(t *testing.T) {
+		injected := &mocks.Client{}
+		assert.Nil(t, c.Set(injected))
+		temporalClient, err := c.Client()
+		assert.NoError(t, err)
+		assert.Same(t, injected, temporalClient)
+		assert.Same(t, injected, c.Current())
+
+		assert.False(t, c.setIfUnset(&mocks.Client{}), "a connection never replaces an injected client")
+		assert.Same(t, injected, c.Set(nil))
+		assert.Nil(t, c.Current())
+	})
+}
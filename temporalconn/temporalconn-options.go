@@ -0,0 +1,69 @@
+// Package temporalconn connects services to the Temporal frontend of their configuration. The connection is
+// dialled in the background and retried with backoff, so services start while Temporal is unreachable.
+package temporalconn
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+
+	"go.temporal.io/sdk/client"
+
+	"encore.app/metrics"
+)
+
+// Config locates the Temporal frontend, credentials are secrets
+type Config struct {
+	HostPort  string
+	Namespace string
+
+	// TLS is implied by a client certificate or an API key, set it to use TLS with neither
+	TLS           bool
+	TLSServerName string // Overrides the server name verified, empty verifies the host of HostPort
+}
+
+// Credentials holds the secrets of the Temporal connection
+type Credentials struct {
+	APIKey     string // Sent as a bearer token, as Temporal Cloud expects
+	ClientCert string // PEM certificate presented for mTLS
+	ClientKey  string // PEM key of ClientCert
+	CACert     string // PEM bundle verifying the server, the system roots are used when unset
+}
+
+// ClientOptions builds the options of the Temporal client from the configuration and secrets
+func ClientOptions(config Config, creds Credentials) (client.Options, error) {
+	options := client.Options{
+		HostPort:       config.HostPort,
+		Namespace:      config.Namespace,
+		MetricsHandler: metrics.NewTemporalHandler(metrics.Default), // SDK and workflow metrics, served on /metrics
+	}
+	if options.Namespace == "" {
+		options.Namespace = client.DefaultNamespace
+	}
+	if (creds.ClientCert == "") != (creds.ClientKey == "") {
+		return client.Options{}, fmt.Errorf("TemporalClientCert and TemporalClientKey must be set together")
+	}
+	if !config.TLS && creds.ClientCert == "" && creds.APIKey == "" {
+		return options, nil
+	}
+	tlsConfig := &tls.Config{ServerName: config.TLSServerName}
+	if creds.CACert != "" {
+		roots := x509.NewCertPool()
+		if !roots.AppendCertsFromPEM([]byte(creds.CACert)) {
+			return client.Options{}, fmt.Errorf("TemporalCACert contains no PEM certificate")
+		}
+		tlsConfig.RootCAs = roots
+	}
+	if creds.ClientCert != "" {
+		certificate, err := tls.X509KeyPair([]byte(creds.ClientCert), []byte(creds.ClientKey))
+		if err != nil {
+			return client.Options{}, fmt.Errorf("invalid Temporal client certificate: %w", err)
+		}
+		tlsConfig.Certificates = []tls.Certificate{certificate}
+	}
+	options.ConnectionOptions.TLS = tlsConfig
+	if creds.APIKey != "" {
+		options.Credentials = client.NewAPIKeyStaticCredentials(creds.APIKey)
+	}
+	return options, nil
+}
@@ -0,0 +1,86 @@
+package temporalconn
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"go.temporal.io/sdk/client"
+)
+
+// testCertificate returns a self-signed PEM certificate and its key
+func testCertificate(t *testing.T) (string, string) {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	assert.NoError(t, err)
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: "temporal"},
+		NotBefore:    time.Now(),
+		NotAfter:     time.Now().Add(time.Hour),
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	assert.NoError(t, err)
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	assert.NoError(t, err)
+	return string(pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der})),
+		string(pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER}))
+}
+
+func TestTemporalClientOptions(t *testing.T) {
+	cert, key := testCertificate(t)
+
+	t.Run("plain connection", func(t *testing.T) {
+		options, err := ClientOptions(Config{HostPort: "127.0.0.1:7233"}, Credentials{})
+		assert.NoError(t, err)
+		assert.Equal(t, "127.0.0.1:7233", options.HostPort)
+		assert.Equal(t, client.DefaultNamespace, options.Namespace)
+		assert.Nil(t, options.ConnectionOptions.TLS)
+		assert.Nil(t, options.Credentials)
+		assert.NotNil(t, options.MetricsHandler)
+	})
+
+	t.Run("mTLS", func(t *testing.T) {
+		options, err := ClientOptions(Config{
+			HostPort:      "billing.tmprl.cloud:7233",
+			Namespace:     "billing",
+			TLSServerName: "billing.tmprl.cloud",
+		}, Credentials{ClientCert: cert, ClientKey: key, CACert: cert})
+		assert.NoError(t, err)
+		assert.Equal(t, "billing", options.Namespace)
+		if assert.NotNil(t, options.ConnectionOptions.TLS) {
+			assert.Len(t, options.ConnectionOptions.TLS.Certificates, 1)
+			assert.NotNil(t, options.ConnectionOptions.TLS.RootCAs)
+			assert.Equal(t, "billing.tmprl.cloud", options.ConnectionOptions.TLS.ServerName)
+		}
+	})
+
+	t.Run("API key implies TLS", func(t *testing.T) {
+		options, err := ClientOptions(Config{HostPort: "billing.tmprl.cloud:7233"}, Credentials{APIKey: "key"})
+		assert.NoError(t, err)
+		assert.NotNil(t, options.ConnectionOptions.TLS)
+		assert.NotNil(t, options.Credentials)
+	})
+
+	t.Run("TLS without credentials", func(t *testing.T) {
+		options, err := ClientOptions(Config{HostPort: "temporal:7233", TLS: true}, Credentials{})
+		assert.NoError(t, err)
+		assert.NotNil(t, options.ConnectionOptions.TLS)
+	})
+
+	t.Run("invalid credentials", func(t *testing.T) {
+		_, err := ClientOptions(Config{}, Credentials{ClientCert: cert})
+		assert.Error(t, err)
+		_, err = ClientOptions(Config{}, Credentials{ClientCert: cert, ClientKey: "not a key"})
+		assert.Error(t, err)
+		_, err = ClientOptions(Config{TLS: true}, Credentials{CACert: "not a certificate"})
+		assert.Error(t, err)
+	})
+}
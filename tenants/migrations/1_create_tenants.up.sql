@@ -0,0 +1,12 @@
+CREATE TABLE tenants (
+    tenant_id  TEXT PRIMARY KEY,
+    name       TEXT NOT NULL,
+    currencies JSONB NOT NULL,
+    accrual    JSONB NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL,
+    updated_at TIMESTAMPTZ NOT NULL
+);
+
+-- Billing periods and API keys created before tenants existed belong to the default tenant
+INSERT INTO tenants (tenant_id, name, currencies, accrual, created_at, updated_at)
+VALUES ('default', 'Default', '["USD", "GEL"]', '{"after_hours": 24, "factor": 2.5}', NOW(), NOW());
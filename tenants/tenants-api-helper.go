@@ -0,0 +1,72 @@
+package tenants
+
+import (
+	"errors"
+	"fmt"
+
+	"encore.dev/beta/auth"
+	"encore.dev/beta/errs"
+
+	"encore.app/models"
+)
+
+func validateCreateTenantRequest(req *models.CreateTenantRequest) error {
+	if !models.IsValidTenantID(req.TenantID) {
+		return fmt.Errorf("tenant_id must be lowercase letters, digits and dashes, at most 63 characters")
+	}
+	return validateTenantSettings(&req.TenantSettings)
+}
+
+func validateTenantSettings(req *models.TenantSettings) error {
+	if req.Name == "" {
+		return fmt.Errorf("name is required")
+	}
+	if len(req.Currencies) == 0 {
+		return fmt.Errorf("at least one currency is required")
+	}
+	for _, currency := range req.Currencies {
+		if !currency.IsValid() {
+			return fmt.Errorf("invalid currency: %s (supported: USD, GEL)", currency)
+		}
+	}
+	if req.Accrual != nil {
+		if req.Accrual.AfterHours < 0 {
+			return fmt.Errorf("accrual after_hours must be non-negative")
+		}
+		if req.Accrual.Factor <= 0 {
+			return fmt.Errorf("accrual factor must be positive")
+		}
+	}
+	return nil
+}
+
+// applyTenantSettings copies the editable configuration onto the tenant, filling in the default accrual policy
+func applyTenantSettings(tenant *models.Tenant, settings *models.TenantSettings) {
+	tenant.Name = settings.Name
+	tenant.Currencies = settings.Currencies
+	tenant.Accrual = models.DefaultAccrualPolicy
+	if settings.Accrual != nil {
+		tenant.Accrual = *settings.Accrual
+	}
+	tenant.TaskQueue = models.TenantTaskQueue(tenant.TenantID)
+}
+
+// authorizeTenant checks that the calling API key may act on the tenant with the given scope
+func authorizeTenant(tenantID string, scope models.APIKeyScope) error {
+	data, _ := auth.Data().(*models.AuthData)
+	if data == nil {
+		return &errs.Error{Code: errs.Unauthenticated, Message: "missing API key"}
+	}
+	if err := data.AuthorizeTenant(tenantID, scope); err != nil {
+		return &errs.Error{Code: errs.PermissionDenied, Message: err.Error()}
+	}
+	return nil
+}
+
+// tenantError reports a missing tenant as not found, other store failures keep their cause
+func tenantError(err error, format string, args ...interface{}) error {
+	if errors.Is(err, errTenantNotFound) {
+		return models.NewAPIErrorWithCause(errs.NotFound, models.ReasonTenantNotFound, err, format, args...)
+	}
+	return errs.Wrap(err, fmt.Sprintf(format, args...))
+}
@@ -0,0 +1,52 @@
+package tenants
+
+import (
+	"testing"
+
+	"encore.app/models"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestValidateCreateTenantRequest(t *testing.T) {
+	t.Parallel()
+	settings := models.TenantSettings{Name: "Retail", Currencies: []models.Currency{models.USD}}
+	cases := []struct {
+		name    string
+		req     models.CreateTenantRequest
+		wantErr string
+	}{
+		{"valid request", models.CreateTenantRequest{TenantID: "retail", TenantSettings: settings}, ""},
+		{"invalid tenant id", models.CreateTenantRequest{TenantID: "Retail EU", TenantSettings: settings}, "tenant_id must be lowercase"},
+		{"missing name", models.CreateTenantRequest{TenantID: "retail", TenantSettings: models.TenantSettings{Currencies: []models.Currency{models.USD}}}, "name is required"},
+		{"no currencies", models.CreateTenantRequest{TenantID: "retail", TenantSettings: models.TenantSettings{Name: "Retail"}}, "at least one currency is required"},
+		{"unknown currency", models.CreateTenantRequest{TenantID: "retail", TenantSettings: models.TenantSettings{Name: "Retail", Currencies: []models.Currency{"EUR"}}}, "invalid currency"},
+		{"zero accrual factor", models.CreateTenantRequest{TenantID: "retail", TenantSettings: models.TenantSettings{Name: "Retail", Currencies: []models.Currency{models.USD}, Accrual: &models.AccrualPolicy{AfterHours: 24}}}, "accrual factor must be positive"},
+	}
+	for _, tc := range cases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+			err := validateCreateTenantRequest(&tc.req)
+			if tc.wantErr == "" {
+				assert.NoError(t, err)
+				return
+			}
+			assert.Error(t, err)
+			assert.Contains(t, err.Error(), tc.wantErr)
+		})
+	}
+}
+
+func TestApplyTenantSettings(t *testing.T) {
+	tenant := &models.Tenant{TenantID: "retail"}
+	applyTenantSettings(tenant, &models.TenantSettings{Name: "Retail", Currencies: []models.Currency{models.GEL}})
+	assert.Equal(t, models.DefaultAccrualPolicy, tenant.Accrual)
+	assert.Equal(t, "local-bills-retail", tenant.TaskQueue)
+
+	applyTenantSettings(tenant, &models.TenantSettings{
+		Name:       "Retail",
+		Currencies: []models.Currency{models.GEL},
+		Accrual:    &models.AccrualPolicy{AfterHours: 48, Factor: 1.2},
+	})
+	assert.Equal(t, models.AccrualPolicy{AfterHours: 48, Factor: 1.2}, tenant.Accrual)
+}
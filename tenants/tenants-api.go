@@ -0,0 +1,97 @@
+package tenants
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"encore.dev/beta/auth"
+	"encore.dev/beta/errs"
+	"encore.dev/rlog"
+
+	"encore.app/models"
+)
+
+// CreateTenant registers a business unit, only the operator key may create tenants
+//
+//encore:api auth method=POST path=/tenants
+func CreateTenant(ctx context.Context, req *models.CreateTenantRequest) (*models.TenantResponse, error) {
+	if data, _ := auth.Data().(*models.AuthData); data == nil || !data.IsOperator() {
+		return nil, &errs.Error{Code: errs.PermissionDenied, Message: "only the operator key may create tenants"}
+	}
+	if err := validateCreateTenantRequest(req); err != nil {
+		return nil, models.InvalidRequest(err)
+	}
+	if _, err := findTenant(ctx, req.TenantID); err == nil {
+		return nil, models.NewAPIError(errs.AlreadyExists, models.ReasonTenantExists, "tenant %s already exists", req.TenantID)
+	} else if !errors.Is(err, errTenantNotFound) {
+		return nil, err
+	}
+
+	now := time.Now()
+	tenant := &models.Tenant{TenantID: req.TenantID, CreatedAt: now, UpdatedAt: now}
+	applyTenantSettings(tenant, &req.TenantSettings)
+	if err := insertTenant(ctx, tenant); err != nil {
+		return nil, err
+	}
+
+	rlog.Info("created tenant", "tenant_id", tenant.TenantID, "task_queue", tenant.TaskQueue)
+	return &models.TenantResponse{Tenant: tenant}, nil
+}
+
+//encore:api auth method=GET path=/tenants/:tenantId
+func GetTenant(ctx context.Context, tenantId string) (*models.TenantResponse, error) {
+	if err := authorizeTenant(tenantId, models.ScopeAdmin); err != nil {
+		return nil, err
+	}
+	return FindTenant(ctx, tenantId)
+}
+
+//encore:api auth method=PUT path=/tenants/:tenantId
+func UpdateTenant(ctx context.Context, tenantId string, req *models.UpdateTenantRequest) (*models.TenantResponse, error) {
+	if err := authorizeTenant(tenantId, models.ScopeAdmin); err != nil {
+		return nil, err
+	}
+	if err := validateTenantSettings(&req.TenantSettings); err != nil {
+		return nil, models.InvalidRequest(err)
+	}
+	tenant, err := findTenant(ctx, tenantId)
+	if err != nil {
+		return nil, tenantError(err, "failed to update tenant %s", tenantId)
+	}
+	applyTenantSettings(tenant, &req.TenantSettings)
+	tenant.UpdatedAt = time.Now()
+	if err := updateTenant(ctx, tenant); err != nil {
+		return nil, err
+	}
+
+	// Running billing periods keep the accrual policy they started with
+	rlog.Info("updated tenant", "tenant_id", tenantId)
+	return &models.TenantResponse{Tenant: tenant}, nil
+}
+
+// FindTenant returns the configuration billing periods of the tenant run with
+//
+//encore:api private method=GET path=/tenants/:tenantId/find
+func FindTenant(ctx context.Context, tenantId string) (*models.TenantResponse, error) {
+	tenant, err := findTenant(ctx, tenantId)
+	if err != nil {
+		return nil, tenantError(err, "failed to get tenant %s", tenantId)
+	}
+	return &models.TenantResponse{Tenant: tenant}, nil
+}
+
+// ListTaskQueues returns the task queue of every tenant, workers run in their own process poll all of them
+//
+//encore:api private method=GET path=/tenants/taskqueues
+func ListTaskQueues(ctx context.Context) (*models.ListTaskQueuesResponse, error) {
+	tenantIDs, err := listTenantIDs(ctx)
+	if err != nil {
+		return nil, err
+	}
+	taskQueues := make([]string, 0, len(tenantIDs))
+	for _, tenantID := range tenantIDs {
+		taskQueues = append(taskQueues, models.TenantTaskQueue(tenantID))
+	}
+	return &models.ListTaskQueuesResponse{TaskQueues: taskQueues}, nil
+}
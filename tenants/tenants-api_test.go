@@ -0,0 +1,79 @@
+package tenants
+
+import (
+	"context"
+	"testing"
+
+	"encore.app/models"
+	"encore.dev/beta/auth"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+)
+
+// operatorContext authenticates calls with the operator key, which is not bound to a tenant
+func operatorContext() context.Context {
+	return auth.WithContext(context.Background(), "test-operator", &models.AuthData{
+		KeyID:  "test-operator",
+		Scopes: []models.APIKeyScope{models.ScopeAdmin},
+	})
+}
+
+// tenantAdminContext authenticates calls with an admin API key of the tenant
+func tenantAdminContext(tenantId string) context.Context {
+	return auth.WithContext(context.Background(), auth.UID("test-admin-"+tenantId), &models.AuthData{
+		KeyID:    "test-admin-" + tenantId,
+		TenantID: tenantId,
+		Scopes:   []models.APIKeyScope{models.ScopeAdmin},
+	})
+}
+
+func TestTenants(t *testing.T) {
+	tenantId := "test-" + uuid.New().String()[:8]
+
+	t.Run("Default Tenant Exists", func(t *testing.T) {
+		resp, err := FindTenant(context.Background(), models.DefaultTenantID)
+		assert.NoError(t, err)
+		assert.Equal(t, "local-bills", resp.Tenant.TaskQueue)
+		assert.Equal(t, models.DefaultAccrualPolicy, resp.Tenant.Accrual)
+	})
+
+	t.Run("Only The Operator Creates Tenants", func(t *testing.T) {
+		req := &models.CreateTenantRequest{
+			TenantID:       tenantId,
+			TenantSettings: models.TenantSettings{Name: "Wholesale", Currencies: []models.Currency{models.GEL}},
+		}
+		_, err := CreateTenant(tenantAdminContext(models.DefaultTenantID), req)
+		assert.Error(t, err)
+
+		resp, err := CreateTenant(operatorContext(), req)
+		assert.NoError(t, err)
+		assert.Equal(t, "local-bills-"+tenantId, resp.Tenant.TaskQueue)
+
+		_, err = CreateTenant(operatorContext(), req)
+		assert.Error(t, err)
+		assert.Contains(t, err.Error(), "already exists")
+	})
+
+	t.Run("Tenant Admins Manage Only Their Tenant", func(t *testing.T) {
+		resp, err := UpdateTenant(tenantAdminContext(tenantId), tenantId, &models.UpdateTenantRequest{
+			TenantSettings: models.TenantSettings{
+				Name:       "Wholesale",
+				Currencies: []models.Currency{models.GEL, models.USD},
+				Accrual:    &models.AccrualPolicy{AfterHours: 72, Factor: 1.1},
+			},
+		})
+		assert.NoError(t, err)
+		assert.Len(t, resp.Tenant.Currencies, 2)
+
+		_, err = GetTenant(tenantAdminContext(models.DefaultTenantID), tenantId)
+		assert.Error(t, err)
+		assert.Contains(t, err.Error(), "may not act on tenant")
+	})
+
+	t.Run("Task Queues Of All Tenants", func(t *testing.T) {
+		resp, err := ListTaskQueues(context.Background())
+		assert.NoError(t, err)
+		assert.Contains(t, resp.TaskQueues, "local-bills")
+		assert.Contains(t, resp.TaskQueues, "local-bills-"+tenantId)
+	})
+}
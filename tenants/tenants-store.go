@@ -0,0 +1,106 @@
+package tenants
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"encore.dev/storage/sqldb"
+
+	"encore.app/models"
+)
+
+// Tenant configuration lives in its own database, the default tenant is created by the first migration
+var db = sqldb.NewDatabase("tenants", sqldb.DatabaseConfig{
+	Migrations: "./migrations",
+})
+
+// errTenantNotFound is returned when a tenant does not exist
+var errTenantNotFound = errors.New("tenant not found")
+
+const tenantColumns = `tenant_id, name, currencies, accrual, created_at, updated_at`
+
+func insertTenant(ctx context.Context, tenant *models.Tenant) error {
+	currencies, accrual, err := marshalTenantJSON(tenant)
+	if err != nil {
+		return err
+	}
+	_, err = db.Exec(ctx, `
+		INSERT INTO tenants (`+tenantColumns+`)
+		VALUES ($1, $2, $3, $4, $5, $6)
+	`, tenant.TenantID, tenant.Name, currencies, accrual, tenant.CreatedAt, tenant.UpdatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to insert tenant: %w", err)
+	}
+	return nil
+}
+
+func updateTenant(ctx context.Context, tenant *models.Tenant) error {
+	currencies, accrual, err := marshalTenantJSON(tenant)
+	if err != nil {
+		return err
+	}
+	result, err := db.Exec(ctx, `
+		UPDATE tenants SET name = $2, currencies = $3, accrual = $4, updated_at = $5
+		WHERE tenant_id = $1
+	`, tenant.TenantID, tenant.Name, currencies, accrual, tenant.UpdatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to update tenant: %w", err)
+	}
+	if result.RowsAffected() == 0 {
+		return errTenantNotFound
+	}
+	return nil
+}
+
+func findTenant(ctx context.Context, tenantID string) (*models.Tenant, error) {
+	var tenant models.Tenant
+	var currencies, accrual []byte
+	err := db.QueryRow(ctx, `
+		SELECT `+tenantColumns+`
+		FROM tenants
+		WHERE tenant_id = $1
+	`, tenantID).Scan(&tenant.TenantID, &tenant.Name, &currencies, &accrual, &tenant.CreatedAt, &tenant.UpdatedAt)
+	if errors.Is(err, sqldb.ErrNoRows) {
+		return nil, errTenantNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to load tenant: %w", err)
+	}
+	if err := json.Unmarshal(currencies, &tenant.Currencies); err != nil {
+		return nil, fmt.Errorf("failed to decode currencies: %w", err)
+	}
+	if err := json.Unmarshal(accrual, &tenant.Accrual); err != nil {
+		return nil, fmt.Errorf("failed to decode accrual policy: %w", err)
+	}
+	tenant.TaskQueue = models.TenantTaskQueue(tenant.TenantID)
+	return &tenant, nil
+}
+
+func listTenantIDs(ctx context.Context) ([]string, error) {
+	rows, err := db.Query(ctx, `SELECT tenant_id FROM tenants ORDER BY tenant_id`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list tenants: %w", err)
+	}
+	defer rows.Close()
+	tenantIDs := []string{}
+	for rows.Next() {
+		var tenantID string
+		if err := rows.Scan(&tenantID); err != nil {
+			return nil, fmt.Errorf("failed to scan tenant: %w", err)
+		}
+		tenantIDs = append(tenantIDs, tenantID)
+	}
+	return tenantIDs, rows.Err()
+}
+
+func marshalTenantJSON(tenant *models.Tenant) (currencies []byte, accrual []byte, err error) {
+	if currencies, err = json.Marshal(tenant.Currencies); err != nil {
+		return nil, nil, fmt.Errorf("failed to encode currencies: %w", err)
+	}
+	if accrual, err = json.Marshal(tenant.Accrual); err != nil {
+		return nil, nil, fmt.Errorf("failed to encode accrual policy: %w", err)
+	}
+	return currencies, accrual, nil
+}
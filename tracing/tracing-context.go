@@ -0,0 +1,92 @@
+package tracing
+
+import (
+	"context"
+	"net/http"
+	"strings"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/baggage"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// Attribute keys of the billing IDs, the same keys name their baggage members
+const (
+	TenantIDKey   = "billing.tenant_id"
+	CustomerIDKey = "billing.customer_id"
+	BillIDKey     = "billing.bill_id"
+	WorkflowIDKey = "billing.workflow_id"
+
+	baggagePrefix = "billing."
+)
+
+// IDs identify what a span works on, empty IDs are left out
+type IDs struct {
+	TenantID   string
+	CustomerID string
+	BillID     string
+	WorkflowID string
+}
+
+// Attributes returns the IDs that are set by attribute key
+func (ids IDs) Attributes() map[string]string {
+	attributes := map[string]string{}
+	for key, value := range map[string]string{
+		TenantIDKey:   ids.TenantID,
+		CustomerIDKey: ids.CustomerID,
+		BillIDKey:     ids.BillID,
+		WorkflowIDKey: ids.WorkflowID,
+	} {
+		if value != "" {
+			attributes[key] = value
+		}
+	}
+	return attributes
+}
+
+// WithIDs sets the IDs on the current span and adds them to the baggage, so every span started downstream,
+// in the workflow and its activities too, carries them as attributes
+func WithIDs(ctx context.Context, ids IDs) context.Context {
+	attributes := ids.Attributes()
+	span := trace.SpanFromContext(ctx)
+	bag := baggage.FromContext(ctx)
+	for key, value := range attributes {
+		span.SetAttributes(attribute.String(key, value))
+		member, err := baggage.NewMemberRaw(key, value)
+		if err != nil {
+			continue
+		}
+		if withMember, err := bag.SetMember(member); err == nil {
+			bag = withMember
+		}
+	}
+	return baggage.ContextWithBaggage(ctx, bag)
+}
+
+// baggageAttributes returns the billing IDs in the baggage as span attributes
+func baggageAttributes(ctx context.Context) []attribute.KeyValue {
+	var attributes []attribute.KeyValue
+	for _, member := range baggage.FromContext(ctx).Members() {
+		if strings.HasPrefix(member.Key(), baggagePrefix) {
+			attributes = append(attributes, attribute.String(member.Key(), member.Value()))
+		}
+	}
+	return attributes
+}
+
+// Inject returns the trace context and baggage of the context, to send along with a signal. It is nil when
+// the context carries neither a span nor baggage.
+func Inject(ctx context.Context) map[string]string {
+	carrier := propagation.MapCarrier{}
+	propagator.Inject(ctx, carrier)
+	if len(carrier) == 0 {
+		return nil
+	}
+	return carrier
+}
+
+// Extract continues the trace of the caller from the traceparent and baggage request headers
+func Extract(ctx context.Context, headers http.Header) context.Context {
+	return propagator.Extract(ctx, propagation.HeaderCarrier(headers))
+}
@@ -0,0 +1,95 @@
+package tracing
+
+import (
+	"context"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/baggage"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// newTestTracer records every span it starts
+func newTestTracer() (trace.Tracer, *tracetest.SpanRecorder) {
+	recorder := tracetest.NewSpanRecorder()
+	provider := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder))
+	return provider.Tracer("test"), recorder
+}
+
+func TestIDsAttributes(t *testing.T) {
+	assert.Equal(t, map[string]string{
+		TenantIDKey:   "retail",
+		CustomerIDKey: "cust-1",
+	}, IDs{TenantID: "retail", CustomerID: "cust-1"}.Attributes())
+	assert.Empty(t, IDs{}.Attributes())
+}
+
+func TestWithIDs(t *testing.T) {
+	tracer, recorder := newTestTracer()
+	ctx, span := tracer.Start(context.Background(), "bills.AddLineItem")
+	ctx = WithIDs(ctx, IDs{CustomerID: "cust-1", BillID: "bill-1"})
+	span.End()
+
+	ended := recorder.Ended()
+	if assert.Len(t, ended, 1) {
+		assert.ElementsMatch(t, []attribute.KeyValue{
+			attribute.String(CustomerIDKey, "cust-1"),
+			attribute.String(BillIDKey, "bill-1"),
+		}, ended[0].Attributes())
+	}
+	bag := baggage.FromContext(ctx)
+	assert.Equal(t, "cust-1", bag.Member(CustomerIDKey).Value())
+	assert.Equal(t, "bill-1", bag.Member(BillIDKey).Value())
+	assert.ElementsMatch(t, []attribute.KeyValue{
+		attribute.String(CustomerIDKey, "cust-1"),
+		attribute.String(BillIDKey, "bill-1"),
+	}, baggageAttributes(ctx))
+}
+
+func TestInjectExtract(t *testing.T) {
+	assert.Nil(t, Inject(context.Background()))
+
+	tracer, _ := newTestTracer()
+	ctx, span := tracer.Start(context.Background(), "bills.AddLineItem")
+	defer span.End()
+	ctx = WithIDs(ctx, IDs{BillID: "bill-1"})
+	carrier := Inject(ctx)
+	assert.Contains(t, carrier, "traceparent")
+	assert.Equal(t, "billing.bill_id=bill-1", carrier["baggage"])
+
+	headers := http.Header{}
+	for key, value := range carrier {
+		headers.Set(key, value)
+	}
+	extracted := Extract(context.Background(), headers)
+	assert.Equal(t, span.SpanContext().TraceID(), trace.SpanContextFromContext(extracted).TraceID())
+	assert.Equal(t, "bill-1", baggage.FromContext(extracted).Member(BillIDKey).Value())
+}
+
+func TestSampleRatio(t *testing.T) {
+	assert.Equal(t, 1.0, sampleRatio(0))
+	assert.Equal(t, 0.25, sampleRatio(0.25))
+	assert.Equal(t, 1.0, sampleRatio(3))
+}
+
+func TestSetup(t *testing.T) {
+	previous := otel.GetTracerProvider()
+	defer otel.SetTracerProvider(previous)
+
+	// The exporter connects lazily, setup succeeds without a collector
+	shutdown, err := Setup(context.Background(), Config{ServiceName: "bills", Endpoint: "localhost:4317", Insecure: true})
+	assert.NoError(t, err)
+	_, span := Tracer().Start(context.Background(), "bills.Liveness")
+	assert.True(t, span.SpanContext().IsSampled())
+	span.End()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+	_ = shutdown(ctx)
+}
@@ -0,0 +1,65 @@
+// Package tracing exports OpenTelemetry traces of API calls, Temporal workflows and activities over OTLP.
+// The trace of an API call continues through the signals it sends into the workflow code applying them
+// and the activities that code schedules.
+package tracing
+
+import (
+	"context"
+	"fmt"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracegrpc"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// instrumentationName names the tracer of the spans started by this module
+const instrumentationName = "encore.app/tracing"
+
+// Config locates the OTLP collector traces are exported to
+type Config struct {
+	ServiceName string
+	Endpoint    string  // host:port of the collector's OTLP gRPC receiver
+	Insecure    bool    // Plaintext, for a collector running next to the service
+	SampleRatio float64 // Share of new traces sampled, traces started upstream keep their parent's decision
+}
+
+// propagator reads and writes the W3C trace context and baggage, the format the Temporal interceptor uses
+var propagator = propagation.NewCompositeTextMapPropagator(propagation.TraceContext{}, propagation.Baggage{})
+
+// Setup exports traces to the collector and makes the provider global. The returned function flushes
+// pending spans and stops the exporter, call it on shutdown.
+func Setup(ctx context.Context, config Config) (func(context.Context) error, error) {
+	options := []otlptracegrpc.Option{otlptracegrpc.WithEndpoint(config.Endpoint)}
+	if config.Insecure {
+		options = append(options, otlptracegrpc.WithInsecure())
+	}
+	exporter, err := otlptracegrpc.New(ctx, options...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create OTLP trace exporter: %w", err)
+	}
+	provider := sdktrace.NewTracerProvider(
+		sdktrace.WithBatcher(exporter),
+		sdktrace.WithResource(resource.NewSchemaless(attribute.String("service.name", config.ServiceName))),
+		sdktrace.WithSampler(sdktrace.ParentBased(sdktrace.TraceIDRatioBased(sampleRatio(config.SampleRatio)))),
+	)
+	otel.SetTracerProvider(provider)
+	otel.SetTextMapPropagator(propagator)
+	return provider.Shutdown, nil
+}
+
+// sampleRatio samples every trace unless a ratio below one is configured
+func sampleRatio(ratio float64) float64 {
+	if ratio <= 0 || ratio > 1 {
+		return 1
+	}
+	return ratio
+}
+
+// Tracer returns the tracer of the global provider, spans are dropped until Setup is called
+func Tracer() trace.Tracer {
+	return otel.GetTracerProvider().Tracer(instrumentationName)
+}
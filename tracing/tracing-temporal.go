@@ -0,0 +1,85 @@
+package tracing
+
+import (
+	"context"
+	"sync/atomic"
+	"time"
+
+	"go.opentelemetry.io/otel/trace"
+	temporalotel "go.temporal.io/sdk/contrib/opentelemetry"
+	"go.temporal.io/sdk/interceptor"
+	"go.temporal.io/sdk/workflow"
+)
+
+// spanContextKey holds the current span in workflow contexts, shared with the Temporal interceptor so
+// activities scheduled under a signal span become its children
+type spanContextKey struct{}
+
+// temporalTracer is the tracer of the installed interceptor, workflow spans are skipped while it is nil
+var temporalTracer atomic.Pointer[interceptor.Tracer]
+
+// NewTemporalInterceptor returns the interceptor for client.Options.Interceptors, workers created from the
+// client use it too. It starts spans when workflows are started and signalled, and around workflow runs and
+// activities, passing the trace context in the Temporal headers. Signal spans are left to the workflow,
+// which starts them where it applies the signal with StartWorkflowSpan. Spans carry the billing IDs of
+// the baggage as attributes.
+func NewTemporalInterceptor() (interceptor.Interceptor, error) {
+	tracer, err := temporalotel.NewTracer(temporalotel.TracerOptions{
+		Tracer:               Tracer(),
+		DisableSignalTracing: true,
+		TextMapPropagator:    propagator,
+		SpanContextKey:       spanContextKey{},
+		SpanStarter:          startSpanWithIDs,
+	})
+	if err != nil {
+		return nil, err
+	}
+	temporalTracer.Store(&tracer)
+	return interceptor.NewTracingInterceptor(tracer), nil
+}
+
+// startSpanWithIDs starts spans of the Temporal interceptor with the billing IDs of the baggage
+func startSpanWithIDs(ctx context.Context, tracer trace.Tracer, spanName string, opts ...trace.SpanStartOption) trace.Span {
+	opts = append(opts, trace.WithAttributes(baggageAttributes(ctx)...))
+	_, span := tracer.Start(ctx, spanName, opts...)
+	return span
+}
+
+// StartWorkflowSpan starts the span of the workflow code applying a signal, as a child of the span that sent
+// it or of the workflow run when the signal carries no trace context. Activities scheduled with the returned
+// context are children of the span, call finish once the signal is applied. Nothing is traced while the
+// workflow replays.
+func StartWorkflowSpan(ctx workflow.Context, carrier map[string]string, operation string, name string, ids IDs) (workflow.Context, func()) {
+	tracerRef := temporalTracer.Load()
+	if tracerRef == nil || workflow.IsReplaying(ctx) {
+		return ctx, func() {}
+	}
+	tracer := *tracerRef
+	var parent interceptor.TracerSpanRef
+	if len(carrier) > 0 {
+		if ref, err := tracer.UnmarshalSpan(carrier); err == nil {
+			parent = ref
+		}
+	}
+	if parent == nil {
+		if span, ok := ctx.Value(spanContextKey{}).(interceptor.TracerSpan); ok {
+			parent = span
+		}
+	}
+	tags := ids.Attributes()
+	tags["temporalWorkflowID"] = workflow.GetInfo(ctx).WorkflowExecution.ID
+	span, err := tracer.StartSpan(&interceptor.TracerStartSpanOptions{
+		Parent:    parent,
+		Operation: operation,
+		Name:      name,
+		Time:      time.Now(),
+		Tags:      tags,
+	})
+	if err != nil {
+		workflow.GetLogger(ctx).Warn("failed to start workflow span", "operation", operation, "name", name, "error", err)
+		return ctx, func() {}
+	}
+	return workflow.WithValue(ctx, spanContextKey{}, span), func() {
+		span.Finish(&interceptor.TracerFinishSpanOptions{})
+	}
+}
@@ -0,0 +1,93 @@
+package tracing
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.temporal.io/sdk/interceptor"
+	"go.temporal.io/sdk/testsuite"
+	"go.temporal.io/sdk/worker"
+	"go.temporal.io/sdk/workflow"
+)
+
+func recordActivity(ctx context.Context, billID string) error {
+	return nil
+}
+
+// signalWorkflow applies one signal under the span of its sender, like the bill workflow does
+func signalWorkflow(ctx workflow.Context) error {
+	var carrier map[string]string
+	workflow.GetSignalChannel(ctx, "add-line-item").Receive(ctx, &carrier)
+	signalCtx, finish := StartWorkflowSpan(ctx, carrier, "HandleSignal", "add-line-item", IDs{BillID: "bill-1"})
+	defer finish()
+	signalCtx = workflow.WithActivityOptions(signalCtx, workflow.ActivityOptions{StartToCloseTimeout: time.Minute})
+	return workflow.ExecuteActivity(signalCtx, recordActivity, "bill-1").Get(signalCtx, nil)
+}
+
+func spanNamed(spans []sdktrace.ReadOnlySpan, name string) sdktrace.ReadOnlySpan {
+	for _, span := range spans {
+		if span.Name() == name {
+			return span
+		}
+	}
+	return nil
+}
+
+func TestStartWorkflowSpan(t *testing.T) {
+	recorder := tracetest.NewSpanRecorder()
+	previous := otel.GetTracerProvider()
+	otel.SetTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder)))
+	defer otel.SetTracerProvider(previous)
+	tracingInterceptor, err := NewTemporalInterceptor()
+	assert.NoError(t, err)
+	defer temporalTracer.Store(nil)
+
+	// The API call sending the signal
+	ctx, apiSpan := Tracer().Start(context.Background(), "bills.AddLineItem")
+	ctx = WithIDs(ctx, IDs{CustomerID: "cust-1", BillID: "bill-1"})
+	carrier := Inject(ctx)
+	apiSpan.End()
+
+	var suite testsuite.WorkflowTestSuite
+	env := suite.NewTestWorkflowEnvironment()
+	env.SetWorkerOptions(worker.Options{Interceptors: []interceptor.WorkerInterceptor{tracingInterceptor}})
+	env.RegisterWorkflow(signalWorkflow)
+	env.RegisterActivity(recordActivity)
+	env.RegisterDelayedCallback(func() {
+		env.SignalWorkflow("add-line-item", carrier)
+	}, time.Second)
+	env.ExecuteWorkflow(signalWorkflow)
+	assert.NoError(t, env.GetWorkflowError())
+
+	spans := recorder.Ended()
+	signalSpan := spanNamed(spans, "HandleSignal:add-line-item")
+	if assert.NotNil(t, signalSpan) {
+		assert.Equal(t, apiSpan.SpanContext().TraceID(), signalSpan.SpanContext().TraceID())
+		assert.Equal(t, apiSpan.SpanContext().SpanID(), signalSpan.Parent().SpanID())
+		assert.Contains(t, signalSpan.Attributes(), attribute.String(BillIDKey, "bill-1"))
+	}
+	activitySpan := spanNamed(spans, "StartActivity:recordActivity")
+	if assert.NotNil(t, activitySpan) && signalSpan != nil {
+		assert.Equal(t, signalSpan.SpanContext().SpanID(), activitySpan.Parent().SpanID())
+		// The billing IDs travel as baggage from the API call
+		assert.Contains(t, activitySpan.Attributes(), attribute.String(CustomerIDKey, "cust-1"))
+	}
+}
+
+func TestStartWorkflowSpanWithoutTracer(t *testing.T) {
+	var suite testsuite.WorkflowTestSuite
+	env := suite.NewTestWorkflowEnvironment()
+	env.RegisterWorkflow(signalWorkflow)
+	env.RegisterActivity(recordActivity)
+	env.RegisterDelayedCallback(func() {
+		env.SignalWorkflow("add-line-item", map[string]string(nil))
+	}, time.Second)
+	env.ExecuteWorkflow(signalWorkflow)
+	assert.NoError(t, env.GetWorkflowError())
+}
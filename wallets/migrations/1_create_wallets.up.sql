@@ -0,0 +1,37 @@
+CREATE TABLE wallets (
+    customer_id    TEXT PRIMARY KEY,
+    currency       TEXT NOT NULL,
+    draw_down_mode TEXT NOT NULL,
+    created_at     TIMESTAMPTZ NOT NULL
+);
+
+CREATE TABLE credit_grants (
+    grant_id    TEXT PRIMARY KEY,
+    customer_id TEXT NOT NULL REFERENCES wallets (customer_id),
+    amount      DOUBLE PRECISION NOT NULL,
+    remaining   DOUBLE PRECISION NOT NULL,
+    expires_at  TIMESTAMPTZ,
+    created_at  TIMESTAMPTZ NOT NULL
+);
+
+CREATE INDEX credit_grants_customer_id_idx ON credit_grants (customer_id);
+
+CREATE TABLE wallet_transactions (
+    transaction_id TEXT PRIMARY KEY,
+    customer_id    TEXT NOT NULL REFERENCES wallets (customer_id),
+    grant_id       TEXT REFERENCES credit_grants (grant_id),
+    type           TEXT NOT NULL,
+    amount         DOUBLE PRECISION NOT NULL,
+    bill_id        TEXT NOT NULL DEFAULT '',
+    reference      TEXT NOT NULL DEFAULT '',
+    created_at     TIMESTAMPTZ NOT NULL
+);
+
+-- Draw-downs are retried by Temporal activities, the reference makes them idempotent
+CREATE TABLE draw_downs (
+    reference       TEXT PRIMARY KEY,
+    customer_id     TEXT NOT NULL REFERENCES wallets (customer_id),
+    bill_id         TEXT NOT NULL,
+    applied_amount  DOUBLE PRECISION NOT NULL,
+    created_at      TIMESTAMPTZ NOT NULL
+);
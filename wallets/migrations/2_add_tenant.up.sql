@@ -0,0 +1,23 @@
+-- Wallets created before tenants existed belong to the default tenant, wallets are unique per tenant and customer
+ALTER TABLE wallets ADD COLUMN tenant_id TEXT NOT NULL DEFAULT 'default';
+ALTER TABLE credit_grants ADD COLUMN tenant_id TEXT NOT NULL DEFAULT 'default';
+ALTER TABLE wallet_transactions ADD COLUMN tenant_id TEXT NOT NULL DEFAULT 'default';
+ALTER TABLE draw_downs ADD COLUMN tenant_id TEXT NOT NULL DEFAULT 'default';
+
+ALTER TABLE credit_grants DROP CONSTRAINT credit_grants_customer_id_fkey;
+ALTER TABLE wallet_transactions DROP CONSTRAINT wallet_transactions_customer_id_fkey;
+ALTER TABLE draw_downs DROP CONSTRAINT draw_downs_customer_id_fkey;
+
+ALTER TABLE wallets DROP CONSTRAINT wallets_pkey;
+ALTER TABLE wallets ADD PRIMARY KEY (tenant_id, customer_id);
+
+ALTER TABLE credit_grants ADD FOREIGN KEY (tenant_id, customer_id) REFERENCES wallets (tenant_id, customer_id);
+ALTER TABLE wallet_transactions ADD FOREIGN KEY (tenant_id, customer_id) REFERENCES wallets (tenant_id, customer_id);
+ALTER TABLE draw_downs ADD FOREIGN KEY (tenant_id, customer_id) REFERENCES wallets (tenant_id, customer_id);
+
+-- Draw-down references are built from bill and line item IDs, which are only unique per tenant
+ALTER TABLE draw_downs DROP CONSTRAINT draw_downs_pkey;
+ALTER TABLE draw_downs ADD PRIMARY KEY (tenant_id, reference);
+
+DROP INDEX credit_grants_customer_id_idx;
+CREATE INDEX credit_grants_tenant_customer_idx ON credit_grants (tenant_id, customer_id);
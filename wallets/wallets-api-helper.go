@@ -0,0 +1,75 @@
+package wallets
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"encore.dev/beta/auth"
+	"encore.dev/beta/errs"
+
+	"encore.app/models"
+)
+
+// authorizeCustomer checks that the calling API key may act on the customer's wallet with the given scope
+// and returns the key's tenant
+func authorizeCustomer(customerID string, scope models.APIKeyScope) (string, error) {
+	data, _ := auth.Data().(*models.AuthData)
+	if data == nil {
+		return "", &errs.Error{Code: errs.Unauthenticated, Message: "missing API key"}
+	}
+	if data.TenantID == "" {
+		return "", &errs.Error{Code: errs.PermissionDenied, Message: "the operator key cannot act on wallets, use an admin key of the tenant"}
+	}
+	if err := data.Authorize(data.TenantID, customerID, scope); err != nil {
+		return "", &errs.Error{Code: errs.PermissionDenied, Message: err.Error()}
+	}
+	return data.TenantID, nil
+}
+
+func validateCreateWalletRequest(req *models.CreateWalletRequest) error {
+	if !req.Currency.IsValid() {
+		return fmt.Errorf("invalid currency: %s (supported: USD, GEL)", req.Currency)
+	}
+	if !req.DrawDownMode.IsValid() {
+		return fmt.Errorf("invalid draw_down_mode: %s (supported: LINE_ITEM, BILL_CLOSE)", req.DrawDownMode)
+	}
+	return nil
+}
+
+func validateTopUpRequest(req *models.TopUpWalletRequest, now time.Time) error {
+	if req.Amount <= 0 {
+		return fmt.Errorf("amount must be positive")
+	}
+	if !req.Currency.IsValid() {
+		return fmt.Errorf("invalid currency: %s (supported: USD, GEL)", req.Currency)
+	}
+	if req.ExpiresAt != nil && !req.ExpiresAt.After(now) {
+		return fmt.Errorf("expires_at must be in the future")
+	}
+	return nil
+}
+
+func validateDrawDownRequest(req *models.DrawDownRequest) error {
+	if req.BillID == "" {
+		return fmt.Errorf("bill_id is required")
+	}
+	if req.Reference == "" {
+		return fmt.Errorf("reference is required")
+	}
+	if req.Amount <= 0 {
+		return fmt.Errorf("amount must be positive")
+	}
+	if !req.Currency.IsValid() {
+		return fmt.Errorf("invalid currency: %s (supported: USD, GEL)", req.Currency)
+	}
+	return nil
+}
+
+// walletError reports a missing wallet as not found, other store failures keep their cause
+func walletError(err error, format string, args ...interface{}) error {
+	if errors.Is(err, errWalletNotFound) {
+		return models.NewAPIErrorWithCause(errs.NotFound, models.ReasonWalletNotFound, err, format, args...)
+	}
+	return errs.Wrap(err, fmt.Sprintf(format, args...))
+}
@@ -0,0 +1,98 @@
+package wallets
+
+import (
+	"testing"
+	"time"
+
+	"encore.app/models"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestValidateCreateWalletRequest(t *testing.T) {
+	t.Parallel()
+	cases := []struct {
+		name    string
+		req     models.CreateWalletRequest
+		wantErr string
+	}{
+		{"valid request", models.CreateWalletRequest{Currency: models.USD, DrawDownMode: models.DrawDownBillClose}, ""},
+		{"default mode", models.CreateWalletRequest{Currency: models.GEL}, ""},
+		{"invalid currency", models.CreateWalletRequest{Currency: "EUR"}, "invalid currency"},
+		{"invalid mode", models.CreateWalletRequest{Currency: models.USD, DrawDownMode: "MONTHLY"}, "invalid draw_down_mode"},
+	}
+	for _, tc := range cases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+			err := validateCreateWalletRequest(&tc.req)
+			if tc.wantErr == "" {
+				assert.NoError(t, err)
+				return
+			}
+			assert.Error(t, err)
+			assert.Contains(t, err.Error(), tc.wantErr)
+		})
+	}
+}
+
+func TestValidateTopUpRequest(t *testing.T) {
+	t.Parallel()
+	now := time.Now()
+	future := now.Add(24 * time.Hour)
+	past := now.Add(-time.Hour)
+	cases := []struct {
+		name    string
+		req     models.TopUpWalletRequest
+		wantErr string
+	}{
+		{"valid request", models.TopUpWalletRequest{Amount: 100, Currency: models.USD}, ""},
+		{"valid request with expiry", models.TopUpWalletRequest{Amount: 100, Currency: models.USD, ExpiresAt: &future}, ""},
+		{"zero amount", models.TopUpWalletRequest{Amount: 0, Currency: models.USD}, "amount must be positive"},
+		{"invalid currency", models.TopUpWalletRequest{Amount: 100, Currency: "EUR"}, "invalid currency"},
+		{"expiry in the past", models.TopUpWalletRequest{Amount: 100, Currency: models.USD, ExpiresAt: &past}, "expires_at must be in the future"},
+	}
+	for _, tc := range cases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+			err := validateTopUpRequest(&tc.req, now)
+			if tc.wantErr == "" {
+				assert.NoError(t, err)
+				return
+			}
+			assert.Error(t, err)
+			assert.Contains(t, err.Error(), tc.wantErr)
+		})
+	}
+}
+
+func TestValidateDrawDownRequest(t *testing.T) {
+	t.Parallel()
+	valid := models.DrawDownRequest{BillID: "bill-1", Reference: "bill-1/line-1", Amount: 10, Currency: models.USD}
+	cases := []struct {
+		name    string
+		mutate  func(*models.DrawDownRequest)
+		wantErr string
+	}{
+		{"valid request", func(*models.DrawDownRequest) {}, ""},
+		{"missing bill id", func(r *models.DrawDownRequest) { r.BillID = "" }, "bill_id is required"},
+		{"missing reference", func(r *models.DrawDownRequest) { r.Reference = "" }, "reference is required"},
+		{"negative amount", func(r *models.DrawDownRequest) { r.Amount = -1 }, "amount must be positive"},
+		{"invalid currency", func(r *models.DrawDownRequest) { r.Currency = "EUR" }, "invalid currency"},
+	}
+	for _, tc := range cases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+			req := valid
+			tc.mutate(&req)
+			err := validateDrawDownRequest(&req)
+			if tc.wantErr == "" {
+				assert.NoError(t, err)
+				return
+			}
+			assert.Error(t, err)
+			assert.Contains(t, err.Error(), tc.wantErr)
+		})
+	}
+}
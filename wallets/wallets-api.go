@@ -0,0 +1,161 @@
+package wallets
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"encore.dev/beta/errs"
+	"encore.dev/cron"
+	"encore.dev/rlog"
+	"github.com/google/uuid"
+
+	"encore.app/customers"
+	"encore.app/models"
+)
+
+// Lapsed grants are written off once an hour so balances and the transaction history stay in step
+var _ = cron.NewJob("expire-wallet-credits", cron.JobConfig{
+	Title:    "Expire prepaid credits",
+	Every:    1 * cron.Hour,
+	Endpoint: ExpireCredits,
+})
+
+//encore:api auth method=POST path=/wallets/:customerId
+func CreateWallet(ctx context.Context, customerId string, req *models.CreateWalletRequest) (*models.WalletResponse, error) {
+	tenantID, err := authorizeCustomer(customerId, models.ScopeWrite)
+	if err != nil {
+		return nil, err
+	}
+	if err := validateCreateWalletRequest(req); err != nil {
+		return nil, models.InvalidRequest(err)
+	}
+	if _, err := customers.FindCustomer(ctx, tenantID, customerId); err != nil {
+		return nil, err
+	}
+	if _, err := findWallet(ctx, db, tenantID, customerId, false); err == nil {
+		return nil, models.NewAPIError(errs.AlreadyExists, models.ReasonWalletExists, "wallet for customer %s already exists", customerId)
+	} else if !errors.Is(err, errWalletNotFound) {
+		return nil, err
+	}
+
+	mode := req.DrawDownMode
+	if mode == models.DrawDownNone {
+		mode = models.DrawDownLineItem
+	}
+	wallet := &models.Wallet{
+		TenantID:     tenantID,
+		CustomerID:   customerId,
+		Currency:     req.Currency,
+		DrawDownMode: mode,
+		Grants:       make([]*models.CreditGrant, 0),
+		CreatedAt:    time.Now(),
+	}
+	if err := insertWallet(ctx, wallet); err != nil {
+		return nil, err
+	}
+
+	rlog.Info("created wallet", "tenant_id", tenantID, "customer_id", customerId, "currency", wallet.Currency, "draw_down_mode", wallet.DrawDownMode)
+	return &models.WalletResponse{Wallet: wallet}, nil
+}
+
+// TopUpWallet grants prepaid credits, only admin keys of the tenant may top up
+//
+//encore:api auth method=POST path=/wallets/:customerId/topup
+func TopUpWallet(ctx context.Context, customerId string, req *models.TopUpWalletRequest) (*models.WalletResponse, error) {
+	tenantID, err := authorizeCustomer(customerId, models.ScopeAdmin)
+	if err != nil {
+		return nil, err
+	}
+	now := time.Now()
+	if err := validateTopUpRequest(req, now); err != nil {
+		return nil, models.InvalidRequest(err)
+	}
+	wallet, err := findWallet(ctx, db, tenantID, customerId, false)
+	if err != nil {
+		return nil, walletError(err, "failed to top up wallet for customer %s", customerId)
+	}
+	if req.Currency != wallet.Currency {
+		return nil, models.NewAPIError(errs.InvalidArgument, models.ReasonInvalidRequest, "top-up currency %s does not match wallet currency %s", req.Currency, wallet.Currency)
+	}
+
+	grant := &models.CreditGrant{
+		GrantID:   uuid.New().String(),
+		Amount:    req.Amount,
+		Remaining: req.Amount,
+		ExpiresAt: req.ExpiresAt,
+		CreatedAt: now,
+	}
+	if err := insertGrant(ctx, tenantID, customerId, grant); err != nil {
+		return nil, err
+	}
+
+	wallet, err = findWallet(ctx, db, tenantID, customerId, false)
+	if err != nil {
+		return nil, err
+	}
+	rlog.Info("topped up wallet", "tenant_id", tenantID, "customer_id", customerId, "amount", req.Amount, "balance", wallet.Balance)
+	return &models.WalletResponse{Wallet: wallet}, nil
+}
+
+//encore:api auth method=GET path=/wallets/:customerId
+func GetWallet(ctx context.Context, customerId string) (*models.WalletResponse, error) {
+	tenantID, err := authorizeCustomer(customerId, models.ScopeRead)
+	if err != nil {
+		return nil, err
+	}
+	wallet, err := findWallet(ctx, db, tenantID, customerId, false)
+	if err != nil {
+		return nil, walletError(err, "failed to get wallet for customer %s", customerId)
+	}
+	return &models.WalletResponse{Wallet: wallet}, nil
+}
+
+// FindWallet returns the wallet of the tenant's customer, or a nil wallet when the customer does not prepay
+//
+//encore:api private method=GET path=/wallets/find/:tenantId/:customerId
+func FindWallet(ctx context.Context, tenantId string, customerId string) (*models.FindWalletResponse, error) {
+	wallet, err := findWallet(ctx, db, tenantId, customerId, false)
+	if errors.Is(err, errWalletNotFound) {
+		return &models.FindWalletResponse{}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &models.FindWalletResponse{Wallet: wallet}, nil
+}
+
+// DrawDown applies up to the requested amount of credits to a bill, repeated calls with the same reference apply once
+//
+//encore:api private method=POST path=/wallets/drawdown/:tenantId/:customerId
+func DrawDown(ctx context.Context, tenantId string, customerId string, req *models.DrawDownRequest) (*models.DrawDownResponse, error) {
+	if err := validateDrawDownRequest(req); err != nil {
+		return nil, models.InvalidRequest(err)
+	}
+	resp, err := drawDown(ctx, tenantId, customerId, req)
+	if err != nil {
+		return nil, walletError(err, "failed to draw down wallet for customer %s", customerId)
+	}
+	rlog.Info("drew down wallet",
+		"tenant_id", tenantId,
+		"customer_id", customerId,
+		"bill_id", req.BillID,
+		"reference", req.Reference,
+		"applied_amount", resp.AppliedAmount,
+		"remaining_balance", resp.RemainingBalance)
+	return resp, nil
+}
+
+// ExpireCredits writes off the remaining credits of grants past their expiry
+//
+//encore:api private
+func ExpireCredits(ctx context.Context) (*models.ExpireCreditsResponse, error) {
+	expired, err := expireGrants(ctx, time.Now())
+	if err != nil {
+		return nil, err
+	}
+	if expired > 0 {
+		rlog.Info("expired prepaid credits", "grants", expired)
+	}
+	return &models.ExpireCreditsResponse{ExpiredGrants: expired}, nil
+}
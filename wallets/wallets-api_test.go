@@ -0,0 +1,157 @@
+package wallets
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"encore.dev/beta/auth"
+
+	"encore.app/customers"
+	"encore.app/models"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+)
+
+// adminContext authenticates calls with an admin API key of the default tenant
+func adminContext() context.Context {
+	return auth.WithContext(context.Background(), auth.UID("test-admin"), &models.AuthData{
+		KeyID:    "test-admin",
+		TenantID: models.DefaultTenantID,
+		Scopes:   []models.APIKeyScope{models.ScopeAdmin},
+	})
+}
+
+// customerContext authenticates calls with an API key bound to the customer of the default tenant
+func customerContext(customerId string, scopes ...models.APIKeyScope) context.Context {
+	return auth.WithContext(context.Background(), auth.UID("test-"+customerId), &models.AuthData{
+		KeyID:      "test-" + customerId,
+		TenantID:   models.DefaultTenantID,
+		CustomerID: customerId,
+		Scopes:     scopes,
+	})
+}
+
+func createTestCustomer(t *testing.T, customerId string) {
+	t.Helper()
+	_, err := customers.CreateCustomer(adminContext(), &models.CreateCustomerRequest{
+		CustomerID: customerId,
+		CustomerDetails: models.CustomerDetails{
+			LegalName:       "Test Customer",
+			BillingAddress:  models.Address{CountryCode: "GE"},
+			DefaultCurrency: models.USD,
+			Locale:          "en-US",
+			ContactEmails:   []string{"billing@example.com"},
+		},
+	})
+	assert.NoError(t, err)
+}
+
+func TestWalletLifecycle(t *testing.T) {
+	ctx := adminContext()
+	testCustomerId := uuid.New().String()
+	createTestCustomer(t, testCustomerId)
+	billID := uuid.New().String()
+
+	t.Run("Create Wallet", func(t *testing.T) {
+		resp, err := CreateWallet(ctx, testCustomerId, &models.CreateWalletRequest{Currency: models.USD})
+		assert.NoError(t, err)
+		assert.Equal(t, models.DrawDownLineItem, resp.Wallet.DrawDownMode)
+		assert.Equal(t, 0.0, resp.Wallet.Balance)
+	})
+
+	t.Run("Create Duplicate Wallet", func(t *testing.T) {
+		_, err := CreateWallet(ctx, testCustomerId, &models.CreateWalletRequest{Currency: models.USD})
+		assert.Error(t, err)
+	})
+
+	t.Run("Top Up Wallet", func(t *testing.T) {
+		expiresAt := time.Now().Add(24 * time.Hour)
+		_, err := TopUpWallet(ctx, testCustomerId, &models.TopUpWalletRequest{Amount: 100, Currency: models.USD})
+		assert.NoError(t, err)
+		resp, err := TopUpWallet(ctx, testCustomerId, &models.TopUpWalletRequest{Amount: 20, Currency: models.USD, ExpiresAt: &expiresAt})
+		assert.NoError(t, err)
+		assert.Equal(t, 120.0, resp.Wallet.Balance)
+		assert.Len(t, resp.Wallet.Grants, 2)
+	})
+
+	t.Run("Top Up Requires Admin Key", func(t *testing.T) {
+		_, err := TopUpWallet(customerContext(testCustomerId, models.ScopeWrite), testCustomerId, &models.TopUpWalletRequest{Amount: 10, Currency: models.USD})
+		assert.Error(t, err)
+	})
+
+	t.Run("Get Wallet Of Another Customer", func(t *testing.T) {
+		_, err := GetWallet(customerContext(uuid.New().String(), models.ScopeRead), testCustomerId)
+		assert.Error(t, err)
+	})
+
+	t.Run("Get Wallet Without API Key", func(t *testing.T) {
+		_, err := GetWallet(context.Background(), testCustomerId)
+		assert.Error(t, err)
+	})
+
+	t.Run("Top Up In Wrong Currency", func(t *testing.T) {
+		_, err := TopUpWallet(ctx, testCustomerId, &models.TopUpWalletRequest{Amount: 10, Currency: models.GEL})
+		assert.Error(t, err)
+	})
+
+	t.Run("Draw Down Is Idempotent", func(t *testing.T) {
+		req := &models.DrawDownRequest{BillID: billID, Reference: billID + "/line-1", Amount: 30, Currency: models.USD}
+		resp, err := DrawDown(ctx, models.DefaultTenantID, testCustomerId, req)
+		assert.NoError(t, err)
+		assert.Equal(t, 30.0, resp.AppliedAmount)
+		assert.Equal(t, 90.0, resp.RemainingBalance)
+
+		resp, err = DrawDown(ctx, models.DefaultTenantID, testCustomerId, req)
+		assert.NoError(t, err)
+		assert.Equal(t, 30.0, resp.AppliedAmount)
+		assert.Equal(t, 90.0, resp.RemainingBalance)
+	})
+
+	t.Run("Draw Down Expiring Grant First", func(t *testing.T) {
+		resp, err := GetWallet(ctx, testCustomerId)
+		assert.NoError(t, err)
+		for _, grant := range resp.Wallet.Grants {
+			if grant.ExpiresAt != nil {
+				assert.Equal(t, 0.0, grant.Remaining)
+			} else {
+				assert.Equal(t, 90.0, grant.Remaining)
+			}
+		}
+	})
+
+	t.Run("Draw Down In Bill Currency", func(t *testing.T) {
+		resp, err := DrawDown(ctx, models.DefaultTenantID, testCustomerId, &models.DrawDownRequest{
+			BillID: billID, Reference: billID + "/line-2", Amount: 25, Currency: models.GEL,
+		})
+		assert.NoError(t, err)
+		assert.Equal(t, 25.0, resp.AppliedAmount)
+		assert.Equal(t, 80.0, resp.RemainingBalance)
+	})
+
+	t.Run("Draw Down Capped At Balance", func(t *testing.T) {
+		resp, err := DrawDown(ctx, models.DefaultTenantID, testCustomerId, &models.DrawDownRequest{
+			BillID: billID, Reference: billID + "/close", Amount: 500, Currency: models.USD,
+		})
+		assert.NoError(t, err)
+		assert.Equal(t, 80.0, resp.AppliedAmount)
+		assert.Equal(t, 0.0, resp.RemainingBalance)
+	})
+
+	t.Run("Find Missing Wallet", func(t *testing.T) {
+		resp, err := FindWallet(ctx, models.DefaultTenantID, uuid.New().String())
+		assert.NoError(t, err)
+		assert.Nil(t, resp.Wallet)
+	})
+
+	t.Run("Wallet Of Another Tenant Is Not Found", func(t *testing.T) {
+		resp, err := FindWallet(ctx, "other-tenant", testCustomerId)
+		assert.NoError(t, err)
+		assert.Nil(t, resp.Wallet)
+
+		_, err = DrawDown(ctx, "other-tenant", testCustomerId, &models.DrawDownRequest{
+			BillID: billID, Reference: billID + "/line-3", Amount: 10, Currency: models.USD,
+		})
+		assert.Error(t, err)
+	})
+}
@@ -0,0 +1,232 @@
+package wallets
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"encore.dev/storage/sqldb"
+	"github.com/google/uuid"
+
+	"encore.app/models"
+)
+
+// Wallet balances, grants and the transaction history live in their own database
+var db = sqldb.NewDatabase("wallets", sqldb.DatabaseConfig{
+	Migrations: "./migrations",
+})
+
+// errWalletNotFound is returned when the customer has no prepaid wallet
+var errWalletNotFound = errors.New("wallet not found")
+
+type queryer interface {
+	Query(ctx context.Context, query string, args ...interface{}) (*sqldb.Rows, error)
+	QueryRow(ctx context.Context, query string, args ...interface{}) *sqldb.Row
+}
+
+func insertWallet(ctx context.Context, wallet *models.Wallet) error {
+	_, err := db.Exec(ctx, `
+		INSERT INTO wallets (tenant_id, customer_id, currency, draw_down_mode, created_at)
+		VALUES ($1, $2, $3, $4, $5)
+	`, wallet.TenantID, wallet.CustomerID, string(wallet.Currency), string(wallet.DrawDownMode), wallet.CreatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to insert wallet: %w", err)
+	}
+	return nil
+}
+
+// findWallet loads the wallet with all of its grants, lockForUpdate must only be used inside a transaction
+func findWallet(ctx context.Context, q queryer, tenantID string, customerID string, lockForUpdate bool) (*models.Wallet, error) {
+	var wallet models.Wallet
+	var currency, mode string
+	err := q.QueryRow(ctx, `
+		SELECT tenant_id, customer_id, currency, draw_down_mode, created_at
+		FROM wallets
+		WHERE tenant_id = $1 AND customer_id = $2
+	`+lockClause(lockForUpdate), tenantID, customerID).Scan(&wallet.TenantID, &wallet.CustomerID, &currency, &mode, &wallet.CreatedAt)
+	if errors.Is(err, sqldb.ErrNoRows) {
+		return nil, errWalletNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to load wallet: %w", err)
+	}
+	wallet.Currency = models.Currency(currency)
+	wallet.DrawDownMode = models.DrawDownMode(mode)
+
+	rows, err := q.Query(ctx, `
+		SELECT grant_id, amount, remaining, expires_at, created_at
+		FROM credit_grants
+		WHERE tenant_id = $1 AND customer_id = $2
+		ORDER BY created_at, grant_id
+	`+lockClause(lockForUpdate), tenantID, customerID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load credit grants: %w", err)
+	}
+	defer rows.Close()
+
+	wallet.Grants = make([]*models.CreditGrant, 0)
+	for rows.Next() {
+		var grant models.CreditGrant
+		if err := rows.Scan(&grant.GrantID, &grant.Amount, &grant.Remaining, &grant.ExpiresAt, &grant.CreatedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan credit grant: %w", err)
+		}
+		wallet.Grants = append(wallet.Grants, &grant)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	wallet.Balance = models.AvailableBalance(wallet.Grants, time.Now())
+	return &wallet, nil
+}
+
+func insertGrant(ctx context.Context, tenantID string, customerID string, grant *models.CreditGrant) error {
+	tx, err := db.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	_, err = tx.Exec(ctx, `
+		INSERT INTO credit_grants (grant_id, tenant_id, customer_id, amount, remaining, expires_at, created_at)
+		VALUES ($1, $2, $3, $4, $4, $5, $6)
+	`, grant.GrantID, tenantID, customerID, grant.Amount, grant.ExpiresAt, grant.CreatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to insert credit grant: %w", err)
+	}
+	if err := insertTransaction(ctx, tx, tenantID, customerID, grant.GrantID, models.TransactionTopUp, grant.Amount, "", ""); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// drawDown applies credits to a bill exactly once per reference. Grants are drawn in the wallet currency,
+// the applied amount is recorded and returned in the currency of the request.
+func drawDown(ctx context.Context, tenantID string, customerID string, req *models.DrawDownRequest) (*models.DrawDownResponse, error) {
+	tx, err := db.Begin(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	wallet, err := findWallet(ctx, tx, tenantID, customerID, true)
+	if err != nil {
+		return nil, err
+	}
+
+	var previous float64
+	err = tx.QueryRow(ctx, `
+		SELECT applied_amount FROM draw_downs WHERE tenant_id = $1 AND reference = $2
+	`, tenantID, req.Reference).Scan(&previous)
+	if err == nil {
+		return &models.DrawDownResponse{
+			AppliedAmount:    previous,
+			RemainingBalance: wallet.Balance,
+			WalletCurrency:   wallet.Currency,
+		}, nil
+	}
+	if !errors.Is(err, sqldb.ErrNoRows) {
+		return nil, fmt.Errorf("failed to check previous draw-down: %w", err)
+	}
+
+	requested := models.ConvertCurrencyAmount(req.Currency, wallet.Currency, req.Amount)
+	allocations, applied := models.AllocateDrawDown(wallet.Grants, requested, time.Now())
+	for _, allocation := range allocations {
+		_, err := tx.Exec(ctx, `
+			UPDATE credit_grants SET remaining = remaining - $2 WHERE grant_id = $1
+		`, allocation.GrantID, allocation.Amount)
+		if err != nil {
+			return nil, fmt.Errorf("failed to update credit grant: %w", err)
+		}
+		if err := insertTransaction(ctx, tx, tenantID, customerID, allocation.GrantID, models.TransactionDrawDown, -allocation.Amount, req.BillID, req.Reference); err != nil {
+			return nil, err
+		}
+	}
+
+	appliedInRequestCurrency := models.ConvertCurrencyAmount(wallet.Currency, req.Currency, applied)
+	_, err = tx.Exec(ctx, `
+		INSERT INTO draw_downs (tenant_id, reference, customer_id, bill_id, applied_amount, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6)
+	`, tenantID, req.Reference, customerID, req.BillID, appliedInRequestCurrency, time.Now())
+	if err != nil {
+		return nil, fmt.Errorf("failed to record draw-down: %w", err)
+	}
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to commit draw-down: %w", err)
+	}
+
+	return &models.DrawDownResponse{
+		AppliedAmount:    appliedInRequestCurrency,
+		RemainingBalance: wallet.Balance - applied,
+		WalletCurrency:   wallet.Currency,
+	}, nil
+}
+
+// expireGrants zeroes the remaining credits of every lapsed grant and records an expiry transaction for each
+func expireGrants(ctx context.Context, now time.Time) (int, error) {
+	tx, err := db.Begin(ctx)
+	if err != nil {
+		return 0, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	rows, err := tx.Query(ctx, `
+		SELECT grant_id, tenant_id, customer_id, remaining
+		FROM credit_grants
+		WHERE expires_at <= $1 AND remaining > 0
+		FOR UPDATE
+	`, now)
+	if err != nil {
+		return 0, fmt.Errorf("failed to load expired grants: %w", err)
+	}
+	type expiredGrant struct {
+		grantID    string
+		tenantID   string
+		customerID string
+		remaining  float64
+	}
+	var expired []expiredGrant
+	for rows.Next() {
+		var grant expiredGrant
+		if err := rows.Scan(&grant.grantID, &grant.tenantID, &grant.customerID, &grant.remaining); err != nil {
+			rows.Close()
+			return 0, fmt.Errorf("failed to scan expired grant: %w", err)
+		}
+		expired = append(expired, grant)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return 0, err
+	}
+
+	for _, grant := range expired {
+		if _, err := tx.Exec(ctx, `UPDATE credit_grants SET remaining = 0 WHERE grant_id = $1`, grant.grantID); err != nil {
+			return 0, fmt.Errorf("failed to expire grant: %w", err)
+		}
+		if err := insertTransaction(ctx, tx, grant.tenantID, grant.customerID, grant.grantID, models.TransactionExpiry, -grant.remaining, "", ""); err != nil {
+			return 0, err
+		}
+	}
+	if err := tx.Commit(); err != nil {
+		return 0, fmt.Errorf("failed to commit expiries: %w", err)
+	}
+	return len(expired), nil
+}
+
+func insertTransaction(ctx context.Context, tx *sqldb.Tx, tenantID string, customerID string, grantID string, transactionType models.WalletTransactionType, amount float64, billID string, reference string) error {
+	_, err := tx.Exec(ctx, `
+		INSERT INTO wallet_transactions (transaction_id, tenant_id, customer_id, grant_id, type, amount, bill_id, reference, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+	`, uuid.New().String(), tenantID, customerID, grantID, string(transactionType), amount, billID, reference, time.Now())
+	if err != nil {
+		return fmt.Errorf("failed to record wallet transaction: %w", err)
+	}
+	return nil
+}
+
+func lockClause(lockForUpdate bool) string {
+	if lockForUpdate {
+		return " FOR UPDATE"
+	}
+	return ""
+}
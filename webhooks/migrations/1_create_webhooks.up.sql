@@ -0,0 +1,31 @@
+CREATE TABLE webhook_endpoints (
+    endpoint_id TEXT PRIMARY KEY,
+    customer_id TEXT NOT NULL,
+    url         TEXT NOT NULL,
+    secret      TEXT NOT NULL,
+    event_types JSONB NOT NULL,
+    created_at  TIMESTAMPTZ NOT NULL,
+    deleted_at  TIMESTAMPTZ
+);
+
+CREATE INDEX webhook_endpoints_customer_id_idx ON webhook_endpoints (customer_id);
+
+CREATE TABLE webhook_deliveries (
+    delivery_id      TEXT PRIMARY KEY,
+    endpoint_id      TEXT NOT NULL REFERENCES webhook_endpoints (endpoint_id),
+    customer_id      TEXT NOT NULL,
+    event_id         TEXT NOT NULL,
+    event_type       TEXT NOT NULL,
+    payload          BYTEA NOT NULL,
+    status           TEXT NOT NULL,
+    attempts         INT NOT NULL DEFAULT 0,
+    last_status_code INT NOT NULL DEFAULT 0,
+    last_error       TEXT NOT NULL DEFAULT '',
+    created_at       TIMESTAMPTZ NOT NULL,
+    updated_at       TIMESTAMPTZ NOT NULL,
+    delivered_at     TIMESTAMPTZ,
+    -- Redelivered Pub/Sub messages must not fan out to the same endpoint twice
+    UNIQUE (endpoint_id, event_id)
+);
+
+CREATE INDEX webhook_deliveries_customer_id_idx ON webhook_deliveries (customer_id, created_at);
@@ -0,0 +1,9 @@
+-- Endpoints and deliveries recorded before tenants existed belong to the default tenant
+ALTER TABLE webhook_endpoints ADD COLUMN tenant_id TEXT NOT NULL DEFAULT 'default';
+ALTER TABLE webhook_deliveries ADD COLUMN tenant_id TEXT NOT NULL DEFAULT 'default';
+
+DROP INDEX webhook_endpoints_customer_id_idx;
+CREATE INDEX webhook_endpoints_tenant_customer_idx ON webhook_endpoints (tenant_id, customer_id);
+
+DROP INDEX webhook_deliveries_customer_id_idx;
+CREATE INDEX webhook_deliveries_tenant_customer_idx ON webhook_deliveries (tenant_id, customer_id, created_at);
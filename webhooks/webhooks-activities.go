@@ -0,0 +1,96 @@
+package webhooks
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+
+	"encore.dev/rlog"
+	"go.temporal.io/sdk/temporal"
+
+	"encore.app/models"
+)
+
+// webhookRejectedErrorType marks responses that retrying will not fix
+const webhookRejectedErrorType = "WebhookRejected"
+
+// Activities holds the Temporal activities of the webhook delivery workflow
+type Activities struct {
+	httpClient *http.Client
+}
+
+// DeliverWebhook signs and posts one delivery to its endpoint and records the attempt.
+// A returned error makes Temporal retry the attempt with backoff.
+func (a *Activities) DeliverWebhook(ctx context.Context, deliveryID string) error {
+	delivery, err := findDelivery(ctx, deliveryID)
+	if err != nil {
+		return err
+	}
+	if delivery.Status == models.DeliveryDelivered {
+		return nil
+	}
+	endpoint, err := findEndpoint(ctx, delivery.EndpointID)
+	if errors.Is(err, errEndpointNotFound) {
+		return temporal.NewNonRetryableApplicationError(
+			fmt.Sprintf("endpoint %s is no longer registered", delivery.EndpointID), webhookRejectedErrorType, err)
+	}
+	if err != nil {
+		return err
+	}
+
+	now := time.Now()
+	statusCode, attemptErr := a.post(ctx, endpoint, delivery, now)
+	if err := recordAttempt(ctx, deliveryID, statusCode, attemptErr, now); err != nil {
+		return err
+	}
+	if attemptErr != nil {
+		rlog.Warn("webhook attempt failed",
+			"delivery_id", deliveryID,
+			"endpoint_id", endpoint.EndpointID,
+			"status_code", statusCode,
+			"error", attemptErr)
+		if isPermanentFailure(statusCode) || errors.Is(attemptErr, errEndpointAddressBlocked) {
+			return temporal.NewNonRetryableApplicationError(attemptErr.Error(), webhookRejectedErrorType, nil)
+		}
+		return attemptErr
+	}
+
+	rlog.Info("webhook delivered", "delivery_id", deliveryID, "endpoint_id", endpoint.EndpointID, "status_code", statusCode)
+	return nil
+}
+
+// DeadLetterWebhook marks a delivery as given up so it shows up for replay
+func (a *Activities) DeadLetterWebhook(ctx context.Context, input models.DeadLetterWebhookInput) error {
+	if err := updateDeliveryStatus(ctx, input.DeliveryID, models.DeliveryDeadLettered, input.Reason); err != nil {
+		return err
+	}
+	rlog.Warn("webhook dead-lettered", "delivery_id", input.DeliveryID, "reason", input.Reason)
+	return nil
+}
+
+func (a *Activities) post(ctx context.Context, endpoint *models.WebhookEndpoint, delivery *models.WebhookDelivery, now time.Time) (int, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint.URL, bytes.NewReader(delivery.Payload))
+	if err != nil {
+		return 0, fmt.Errorf("failed to build request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(models.WebhookIDHeader, delivery.DeliveryID)
+	req.Header.Set(models.WebhookEventHeader, string(delivery.EventType))
+	req.Header.Set(models.WebhookSignatureHeader, models.SignWebhookPayload(endpoint.Secret, now, delivery.Payload))
+
+	resp, err := a.httpClient.Do(req)
+	if err != nil {
+		return 0, fmt.Errorf("request failed: %w", err)
+	}
+	defer resp.Body.Close()
+	_, _ = io.Copy(io.Discard, io.LimitReader(resp.Body, 64<<10))
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return resp.StatusCode, fmt.Errorf("endpoint returned %d", resp.StatusCode)
+	}
+	return resp.StatusCode, nil
+}
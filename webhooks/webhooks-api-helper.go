@@ -0,0 +1,99 @@
+package webhooks
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+
+	"encore.dev/beta/auth"
+	"encore.dev/beta/errs"
+
+	"encore.app/models"
+)
+
+// authorizeCustomer checks that the calling API key may act on the customer's webhooks with the given scope
+// and returns the key's tenant, endpoints and deliveries are kept per tenant
+func authorizeCustomer(customerID string, scope models.APIKeyScope) (string, error) {
+	data, _ := auth.Data().(*models.AuthData)
+	if data == nil {
+		return "", &errs.Error{Code: errs.Unauthenticated, Message: "missing API key"}
+	}
+	if data.TenantID == "" {
+		return "", &errs.Error{Code: errs.PermissionDenied, Message: "the operator key cannot act on webhooks, use an admin key of the tenant"}
+	}
+	if err := data.Authorize(data.TenantID, customerID, scope); err != nil {
+		return "", &errs.Error{Code: errs.PermissionDenied, Message: err.Error()}
+	}
+	return data.TenantID, nil
+}
+
+func validateRegisterEndpointRequest(req *models.RegisterWebhookEndpointRequest) error {
+	target, err := url.Parse(req.URL)
+	if err != nil || target.Host == "" {
+		return fmt.Errorf("url must be an absolute URL")
+	}
+	switch target.Scheme {
+	case "https":
+	case "http":
+		// Plain HTTP is only accepted for local receivers during development
+		if !isLoopbackHost(target.Hostname()) {
+			return fmt.Errorf("url must use https")
+		}
+	default:
+		return fmt.Errorf("url must use https")
+	}
+	if len(req.EventTypes) == 0 {
+		return fmt.Errorf("at least one event type is required")
+	}
+	for _, eventType := range req.EventTypes {
+		if !eventType.IsValid() {
+			return fmt.Errorf("invalid event type: %s (supported: bill.created, line_item.added, bill.closed, billing_period.closed)", eventType)
+		}
+	}
+	return nil
+}
+
+func validateListDeliveriesRequest(req *models.ListWebhookDeliveriesRequest) error {
+	if req.Status != "" && !models.WebhookDeliveryStatus(req.Status).IsValid() {
+		return fmt.Errorf("invalid status: %s (supported: PENDING, DELIVERED, DEAD_LETTERED)", req.Status)
+	}
+	return nil
+}
+
+// isPermanentFailure returns true for responses that retrying will not fix. Timeouts and
+// rate limiting are retried like server errors.
+func isPermanentFailure(statusCode int) bool {
+	if statusCode == http.StatusRequestTimeout || statusCode == http.StatusTooManyRequests {
+		return false
+	}
+	return statusCode >= 400 && statusCode < 500
+}
+
+// newWebhookSecret generates the secret an endpoint uses to verify signatures
+func newWebhookSecret() (string, error) {
+	secret := make([]byte, 32)
+	if _, err := rand.Read(secret); err != nil {
+		return "", fmt.Errorf("failed to generate webhook secret: %w", err)
+	}
+	return "whsec_" + hex.EncodeToString(secret), nil
+}
+
+func isLoopbackHost(host string) bool {
+	if host == "localhost" {
+		return true
+	}
+	ip := net.ParseIP(host)
+	return ip != nil && ip.IsLoopback()
+}
+
+// webhookError reports a missing endpoint or delivery as not found, other store failures keep their cause
+func webhookError(err error, format string, args ...interface{}) error {
+	if errors.Is(err, errEndpointNotFound) || errors.Is(err, errDeliveryNotFound) {
+		return models.NewAPIErrorWithCause(errs.NotFound, models.ReasonWebhookNotFound, err, format, args...)
+	}
+	return errs.Wrap(err, fmt.Sprintf(format, args...))
+}
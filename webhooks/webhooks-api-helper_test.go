@@ -0,0 +1,66 @@
+package webhooks
+
+import (
+	"strings"
+	"testing"
+
+	"encore.app/models"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestValidateRegisterEndpointRequest(t *testing.T) {
+	t.Parallel()
+	cases := []struct {
+		name    string
+		req     models.RegisterWebhookEndpointRequest
+		wantErr string
+	}{
+		{"valid request", models.RegisterWebhookEndpointRequest{URL: "https://hooks.example.com/bills", EventTypes: []models.WebhookEventType{models.WebhookBillClosed}}, ""},
+		{"local http receiver", models.RegisterWebhookEndpointRequest{URL: "http://127.0.0.1:8080/hook", EventTypes: []models.WebhookEventType{models.WebhookBillClosed}}, ""},
+		{"relative url", models.RegisterWebhookEndpointRequest{URL: "/hook", EventTypes: []models.WebhookEventType{models.WebhookBillClosed}}, "url must be an absolute URL"},
+		{"plain http", models.RegisterWebhookEndpointRequest{URL: "http://hooks.example.com", EventTypes: []models.WebhookEventType{models.WebhookBillClosed}}, "url must use https"},
+		{"no event types", models.RegisterWebhookEndpointRequest{URL: "https://hooks.example.com"}, "at least one event type is required"},
+		{"unknown event type", models.RegisterWebhookEndpointRequest{URL: "https://hooks.example.com", EventTypes: []models.WebhookEventType{"bill.deleted"}}, "invalid event type"},
+	}
+	for _, tc := range cases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+			err := validateRegisterEndpointRequest(&tc.req)
+			if tc.wantErr == "" {
+				assert.NoError(t, err)
+				return
+			}
+			assert.Error(t, err)
+			assert.Contains(t, err.Error(), tc.wantErr)
+		})
+	}
+}
+
+func TestValidateListDeliveriesRequest(t *testing.T) {
+	t.Parallel()
+	assert.NoError(t, validateListDeliveriesRequest(&models.ListWebhookDeliveriesRequest{}))
+	assert.NoError(t, validateListDeliveriesRequest(&models.ListWebhookDeliveriesRequest{Status: string(models.DeliveryDeadLettered)}))
+	assert.Error(t, validateListDeliveriesRequest(&models.ListWebhookDeliveriesRequest{Status: "FAILED"}))
+}
+
+func TestIsPermanentFailure(t *testing.T) {
+	t.Parallel()
+	assert.True(t, isPermanentFailure(400))
+	assert.True(t, isPermanentFailure(410))
+	assert.False(t, isPermanentFailure(408))
+	assert.False(t, isPermanentFailure(429))
+	assert.False(t, isPermanentFailure(503))
+	assert.False(t, isPermanentFailure(0))
+}
+
+func TestNewWebhookSecret(t *testing.T) {
+	t.Parallel()
+	first, err := newWebhookSecret()
+	assert.NoError(t, err)
+	second, err := newWebhookSecret()
+	assert.NoError(t, err)
+	assert.True(t, strings.HasPrefix(first, "whsec_"))
+	assert.Len(t, first, len("whsec_")+64)
+	assert.NotEqual(t, first, second)
+}
@@ -0,0 +1,162 @@
+package webhooks
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"time"
+
+	"encore.dev/beta/errs"
+	"encore.dev/rlog"
+	"github.com/google/uuid"
+	"go.temporal.io/sdk/client"
+
+	"encore.app/customers"
+	"encore.app/models"
+	"encore.app/workflows"
+)
+
+//encore:api auth method=POST path=/webhooks/:customerId/endpoints
+func RegisterEndpoint(ctx context.Context, customerId string, req *models.RegisterWebhookEndpointRequest) (*models.WebhookEndpointResponse, error) {
+	tenantID, err := authorizeCustomer(customerId, models.ScopeWrite)
+	if err != nil {
+		return nil, err
+	}
+	if err := validateRegisterEndpointRequest(req); err != nil {
+		return nil, models.InvalidRequest(err)
+	}
+	if err := checkEndpointURL(ctx, net.DefaultResolver, req.URL, cfg.Delivery.AllowPrivateEndpoints); err != nil {
+		return nil, models.InvalidRequest(err)
+	}
+	if _, err := customers.FindCustomer(ctx, tenantID, customerId); err != nil {
+		return nil, err
+	}
+	secret, err := newWebhookSecret()
+	if err != nil {
+		return nil, err
+	}
+
+	endpoint := &models.WebhookEndpoint{
+		EndpointID: uuid.New().String(),
+		TenantID:   tenantID,
+		CustomerID: customerId,
+		URL:        req.URL,
+		EventTypes: req.EventTypes,
+		Secret:     secret,
+		CreatedAt:  time.Now(),
+	}
+	if err := insertEndpoint(ctx, endpoint); err != nil {
+		return nil, err
+	}
+
+	rlog.Info("registered webhook endpoint", "tenant_id", tenantID, "customer_id", customerId, "endpoint_id", endpoint.EndpointID)
+	// The secret is only returned here, it is needed to verify the signature of every delivery
+	return &models.WebhookEndpointResponse{Endpoint: endpoint}, nil
+}
+
+//encore:api auth method=GET path=/webhooks/:customerId/endpoints
+func ListEndpoints(ctx context.Context, customerId string) (*models.ListWebhookEndpointsResponse, error) {
+	tenantID, err := authorizeCustomer(customerId, models.ScopeRead)
+	if err != nil {
+		return nil, err
+	}
+	endpoints, err := listEndpoints(ctx, tenantID, customerId)
+	if err != nil {
+		return nil, err
+	}
+	for _, endpoint := range endpoints {
+		endpoint.Secret = ""
+	}
+	return &models.ListWebhookEndpointsResponse{
+		Endpoints: endpoints,
+		Total:     int64(len(endpoints)),
+	}, nil
+}
+
+//encore:api auth method=DELETE path=/webhooks/:customerId/endpoints/:endpointId
+func DeleteEndpoint(ctx context.Context, customerId string, endpointId string) error {
+	tenantID, err := authorizeCustomer(customerId, models.ScopeWrite)
+	if err != nil {
+		return err
+	}
+	if err := softDeleteEndpoint(ctx, tenantID, customerId, endpointId); err != nil {
+		return webhookError(err, "failed to delete webhook endpoint %s", endpointId)
+	}
+	rlog.Info("deleted webhook endpoint", "tenant_id", tenantID, "customer_id", customerId, "endpoint_id", endpointId)
+	return nil
+}
+
+//encore:api auth method=GET path=/webhooks/:customerId/deliveries
+func ListDeliveries(ctx context.Context, customerId string, req *models.ListWebhookDeliveriesRequest) (*models.ListWebhookDeliveriesResponse, error) {
+	tenantID, err := authorizeCustomer(customerId, models.ScopeRead)
+	if err != nil {
+		return nil, err
+	}
+	if err := validateListDeliveriesRequest(req); err != nil {
+		return nil, models.InvalidRequest(err)
+	}
+	deliveries, err := listDeliveries(ctx, tenantID, customerId, models.WebhookDeliveryStatus(req.Status))
+	if err != nil {
+		return nil, err
+	}
+	return &models.ListWebhookDeliveriesResponse{
+		Deliveries: deliveries,
+		Total:      int64(len(deliveries)),
+	}, nil
+}
+
+//encore:api auth method=POST path=/webhooks/:customerId/deliveries/:deliveryId/replay
+func ReplayDelivery(ctx context.Context, customerId string, deliveryId string) (*models.WebhookDeliveryResponse, error) {
+	tenantID, err := authorizeCustomer(customerId, models.ScopeWrite)
+	if err != nil {
+		return nil, err
+	}
+	delivery, err := findDelivery(ctx, deliveryId)
+	if err != nil || delivery.TenantID != tenantID || delivery.CustomerID != customerId {
+		return nil, webhookError(errDeliveryNotFound, "failed to replay webhook delivery %s", deliveryId)
+	}
+	if delivery.Status != models.DeliveryDeadLettered {
+		return nil, models.NewAPIError(errs.FailedPrecondition, models.ReasonDeliveryNotDeadLettered, "only dead-lettered deliveries can be replayed, delivery %s is %s", deliveryId, delivery.Status)
+	}
+	if _, err := findEndpoint(ctx, delivery.EndpointID); err != nil {
+		return nil, webhookError(err, "failed to replay webhook delivery %s", deliveryId)
+	}
+	// Fail before the delivery leaves the dead letters, it could not be replayed again otherwise
+	if _, err := service.temporal(); err != nil {
+		return nil, err
+	}
+
+	if err := updateDeliveryStatus(ctx, deliveryId, models.DeliveryPending, ""); err != nil {
+		return nil, err
+	}
+	if err := startDelivery(ctx, deliveryId); err != nil {
+		return nil, err
+	}
+	rlog.Info("replaying webhook delivery", "tenant_id", tenantID, "customer_id", customerId, "delivery_id", deliveryId)
+
+	delivery, err = findDelivery(ctx, deliveryId)
+	if err != nil {
+		return nil, err
+	}
+	return &models.WebhookDeliveryResponse{Delivery: delivery}, nil
+}
+
+// startDelivery starts the delivery workflow. The workflow ID is derived from the delivery so a
+// delivery that is already being sent is not started twice, finished ones can be started again on replay.
+func startDelivery(ctx context.Context, deliveryID string) error {
+	workflowOptions := client.StartWorkflowOptions{
+		ID:        fmt.Sprintf("webhook-delivery-%s", deliveryID),
+		TaskQueue: cfg.Worker.TaskQueue,
+	}
+	temporalClient, err := service.temporal()
+	if err != nil {
+		return err
+	}
+	_, err = temporalClient.ExecuteWorkflow(ctx, workflowOptions, workflows.WebhookDeliveryWorkflow, &models.WebhookDeliveryInput{
+		DeliveryID: deliveryID,
+	})
+	if err != nil {
+		return models.NewAPIErrorWithCause(errs.Unavailable, models.ReasonWorkflowUnavailable, err, "failed to start webhook delivery workflow")
+	}
+	return nil
+}
@@ -0,0 +1,202 @@
+package webhooks
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"encore.dev/beta/auth"
+
+	"encore.app/customers"
+	"encore.app/models"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+)
+
+// tenantAdminContext authenticates calls with an admin API key of the tenant
+func tenantAdminContext(tenantId string) context.Context {
+	return auth.WithContext(context.Background(), auth.UID("test-admin-"+tenantId), &models.AuthData{
+		KeyID:    "test-admin-" + tenantId,
+		TenantID: tenantId,
+		Scopes:   []models.APIKeyScope{models.ScopeAdmin},
+	})
+}
+
+// customerContext authenticates calls with an API key bound to the customer of the default tenant
+func customerContext(customerId string, scopes ...models.APIKeyScope) context.Context {
+	return auth.WithContext(context.Background(), auth.UID("test-"+customerId), &models.AuthData{
+		KeyID:      "test-" + customerId,
+		TenantID:   models.DefaultTenantID,
+		CustomerID: customerId,
+		Scopes:     scopes,
+	})
+}
+
+func createTestCustomer(t *testing.T, customerId string) {
+	t.Helper()
+	_, err := customers.CreateCustomer(tenantAdminContext(models.DefaultTenantID), &models.CreateCustomerRequest{
+		CustomerID: customerId,
+		CustomerDetails: models.CustomerDetails{
+			LegalName:       "Test Customer",
+			BillingAddress:  models.Address{CountryCode: "GE"},
+			DefaultCurrency: models.USD,
+			Locale:          "en-US",
+			ContactEmails:   []string{"billing@example.com"},
+		},
+	})
+	assert.NoError(t, err)
+}
+
+func TestWebhookEndpoints(t *testing.T) {
+	ctx := tenantAdminContext(models.DefaultTenantID)
+	testCustomerId := uuid.New().String()
+	createTestCustomer(t, testCustomerId)
+	var endpointId string
+
+	t.Run("Register Endpoint", func(t *testing.T) {
+		resp, err := RegisterEndpoint(ctx, testCustomerId, &models.RegisterWebhookEndpointRequest{
+			URL:        "https://hooks.example.com/bills",
+			EventTypes: []models.WebhookEventType{models.WebhookBillClosed},
+		})
+		assert.NoError(t, err)
+		assert.NotEmpty(t, resp.Endpoint.Secret)
+		endpointId = resp.Endpoint.EndpointID
+	})
+
+	t.Run("List Endpoints Hides Secret", func(t *testing.T) {
+		resp, err := ListEndpoints(ctx, testCustomerId)
+		assert.NoError(t, err)
+		assert.Len(t, resp.Endpoints, 1)
+		assert.Empty(t, resp.Endpoints[0].Secret)
+	})
+
+	t.Run("List Endpoints Of Another Customer", func(t *testing.T) {
+		_, err := ListEndpoints(customerContext(uuid.New().String(), models.ScopeRead), testCustomerId)
+		assert.Error(t, err)
+	})
+
+	t.Run("List Endpoints Without API Key", func(t *testing.T) {
+		_, err := ListEndpoints(context.Background(), testCustomerId)
+		assert.Error(t, err)
+	})
+
+	t.Run("Endpoints Are Kept Per Tenant", func(t *testing.T) {
+		resp, err := ListEndpoints(tenantAdminContext("other"), testCustomerId)
+		assert.NoError(t, err)
+		assert.Empty(t, resp.Endpoints)
+		assert.Error(t, DeleteEndpoint(tenantAdminContext("other"), testCustomerId, endpointId))
+	})
+
+	t.Run("Register Endpoint For Unknown Customer", func(t *testing.T) {
+		_, err := RegisterEndpoint(ctx, uuid.New().String(), &models.RegisterWebhookEndpointRequest{
+			URL:        "https://hooks.example.com/bills",
+			EventTypes: []models.WebhookEventType{models.WebhookBillClosed},
+		})
+		assert.Error(t, err)
+	})
+
+	t.Run("Delete Endpoint", func(t *testing.T) {
+		assert.NoError(t, DeleteEndpoint(ctx, testCustomerId, endpointId))
+		resp, err := ListEndpoints(ctx, testCustomerId)
+		assert.NoError(t, err)
+		assert.Empty(t, resp.Endpoints)
+		assert.Error(t, DeleteEndpoint(ctx, testCustomerId, endpointId))
+	})
+}
+
+func TestDeliverWebhook(t *testing.T) {
+	ctx := tenantAdminContext(models.DefaultTenantID)
+	testCustomerId := uuid.New().String()
+	createTestCustomer(t, testCustomerId)
+
+	var received *http.Request
+	var receivedBody []byte
+	statusCode := http.StatusServiceUnavailable
+	receiver := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		received = r
+		receivedBody, _ = io.ReadAll(r.Body)
+		w.WriteHeader(statusCode)
+	}))
+	defer receiver.Close()
+
+	endpointResp, err := RegisterEndpoint(ctx, testCustomerId, &models.RegisterWebhookEndpointRequest{
+		URL:        receiver.URL,
+		EventTypes: []models.WebhookEventType{models.WebhookBillClosed},
+	})
+	assert.NoError(t, err)
+	endpoint := endpointResp.Endpoint
+
+	payload, _ := json.Marshal(models.WebhookEnvelope{ID: "bill-closed/bill-1", Type: models.WebhookBillClosed, CreatedAt: time.Now()})
+	delivery, err := insertDelivery(ctx, &models.WebhookDelivery{
+		DeliveryID: uuid.New().String(),
+		EndpointID: endpoint.EndpointID,
+		TenantID:   models.DefaultTenantID,
+		CustomerID: testCustomerId,
+		EventID:    "bill-closed/bill-1",
+		EventType:  models.WebhookBillClosed,
+		Payload:    payload,
+		Status:     models.DeliveryPending,
+		CreatedAt:  time.Now(),
+	})
+	assert.NoError(t, err)
+	activities := &Activities{httpClient: receiver.Client()}
+
+	t.Run("Failed Attempt Is Recorded", func(t *testing.T) {
+		err := activities.DeliverWebhook(ctx, delivery.DeliveryID)
+		assert.Error(t, err)
+		stored, err := findDelivery(ctx, delivery.DeliveryID)
+		assert.NoError(t, err)
+		assert.Equal(t, models.DeliveryPending, stored.Status)
+		assert.Equal(t, 1, stored.Attempts)
+		assert.Equal(t, http.StatusServiceUnavailable, stored.LastStatusCode)
+	})
+
+	t.Run("Signed Delivery", func(t *testing.T) {
+		statusCode = http.StatusOK
+		assert.NoError(t, activities.DeliverWebhook(ctx, delivery.DeliveryID))
+		assert.Equal(t, delivery.DeliveryID, received.Header.Get(models.WebhookIDHeader))
+		assert.Equal(t, string(models.WebhookBillClosed), received.Header.Get(models.WebhookEventHeader))
+		err := models.VerifyWebhookSignature(endpoint.Secret, received.Header.Get(models.WebhookSignatureHeader), receivedBody, time.Now(), 5*time.Minute)
+		assert.NoError(t, err)
+
+		stored, err := findDelivery(ctx, delivery.DeliveryID)
+		assert.NoError(t, err)
+		assert.Equal(t, models.DeliveryDelivered, stored.Status)
+		assert.Equal(t, 2, stored.Attempts)
+		assert.NotNil(t, stored.DeliveredAt)
+	})
+
+	t.Run("Duplicate Event Reuses Delivery", func(t *testing.T) {
+		again, err := insertDelivery(ctx, &models.WebhookDelivery{
+			DeliveryID: uuid.New().String(),
+			EndpointID: endpoint.EndpointID,
+			TenantID:   models.DefaultTenantID,
+			CustomerID: testCustomerId,
+			EventID:    "bill-closed/bill-1",
+			EventType:  models.WebhookBillClosed,
+			Payload:    payload,
+			Status:     models.DeliveryPending,
+			CreatedAt:  time.Now(),
+		})
+		assert.NoError(t, err)
+		assert.Equal(t, delivery.DeliveryID, again.DeliveryID)
+		assert.Equal(t, models.DeliveryDelivered, again.Status)
+	})
+
+	t.Run("Only Dead-Lettered Deliveries Replay", func(t *testing.T) {
+		_, err := ReplayDelivery(ctx, testCustomerId, delivery.DeliveryID)
+		assert.Error(t, err)
+
+		assert.NoError(t, activities.DeadLetterWebhook(ctx, models.DeadLetterWebhookInput{DeliveryID: delivery.DeliveryID, Reason: "test"}))
+		resp, err := ListDeliveries(ctx, testCustomerId, &models.ListWebhookDeliveriesRequest{Status: string(models.DeliveryDeadLettered)})
+		assert.NoError(t, err)
+		assert.Len(t, resp.Deliveries, 1)
+
+		_, err = ReplayDelivery(tenantAdminContext("other"), testCustomerId, delivery.DeliveryID)
+		assert.Error(t, err)
+	})
+}
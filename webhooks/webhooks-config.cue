@@ -0,0 +1,29 @@
+// Configuration of the webhooks service, see Config in webhooks-config.go.
+// Keep Temporal in step with bills/bills-config.cue, both services share the secrets of the connection.
+
+Temporal: {
+	HostPort:      string | *"127.0.0.1:7233"
+	Namespace:     string | *"default"
+	TLS:           bool | *false
+	TLSServerName: string | *""
+}
+
+Worker: {
+	// Enable it in the environment running the bills worker
+	RunWorker: bool | *true
+
+	// Give each environment sharing a Temporal namespace a queue of its own
+	TaskQueue: string | *"local-webhooks"
+
+	StopTimeoutSeconds: int | *30
+}
+
+Delivery: {
+	// Endpoints on loopback or private addresses are only accepted when running locally, so deployed
+	// environments cannot be made to post to internal services
+	AllowPrivateEndpoints: bool | *false
+}
+
+if #Meta.Environment.Cloud == "local" {
+	Delivery: AllowPrivateEndpoints: true
+}
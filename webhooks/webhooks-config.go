@@ -0,0 +1,62 @@
+package webhooks
+
+import (
+	"time"
+
+	"encore.dev/config"
+	"go.temporal.io/sdk/worker"
+)
+
+// Config is the per-environment configuration of the webhooks service, set in webhooks-config.cue
+type Config struct {
+	Temporal TemporalConfig
+	Worker   WorkerConfig
+	Delivery DeliveryConfig
+}
+
+// TemporalConfig locates the Temporal frontend, credentials are the secrets the bills service reads too.
+// It converts to temporalconn.Config.
+type TemporalConfig struct {
+	HostPort  string
+	Namespace string
+
+	// TLS is implied by a client certificate or an API key, set it to use TLS with neither
+	TLS           bool
+	TLSServerName string // Overrides the server name verified, empty verifies the host of HostPort
+}
+
+// WorkerConfig controls the worker sending deliveries
+type WorkerConfig struct {
+	// RunWorker sends deliveries from this process, disable it where only the API is served
+	RunWorker bool
+
+	TaskQueue          string // Task queue the delivery workflows are started on and the worker polls
+	StopTimeoutSeconds int
+}
+
+// DeliveryConfig controls where deliveries may be sent
+type DeliveryConfig struct {
+	// AllowPrivateEndpoints lets endpoints resolve to loopback and private addresses, for local receivers.
+	// Link-local and metadata addresses stay blocked.
+	AllowPrivateEndpoints bool
+}
+
+var cfg = config.Load[*Config]()
+
+var secrets struct {
+	TemporalAPIKey     string // Sent as a bearer token, as Temporal Cloud expects
+	TemporalClientCert string // PEM certificate presented for mTLS
+	TemporalClientKey  string // PEM key of TemporalClientCert
+	TemporalCACert     string // PEM bundle verifying the server, the system roots are used when unset
+}
+
+// workerOptions builds the options of the delivery worker from the configuration
+func workerOptions(w WorkerConfig) worker.Options {
+	options := worker.Options{
+		WorkerStopTimeout: time.Duration(w.StopTimeoutSeconds) * time.Second,
+	}
+	if options.WorkerStopTimeout == 0 {
+		options.WorkerStopTimeout = 30 * time.Second
+	}
+	return options
+}
@@ -0,0 +1,104 @@
+package webhooks
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/netip"
+	"net/url"
+	"syscall"
+	"time"
+)
+
+// errEndpointAddressBlocked is returned when an endpoint resolves to an address webhooks may not be sent to
+var errEndpointAddressBlocked = errors.New("endpoint address is not publicly routable")
+
+// alwaysBlockedPrefixes are never reachable by webhooks, not even where private endpoints are allowed
+var alwaysBlockedPrefixes = []netip.Prefix{
+	netip.MustParsePrefix("0.0.0.0/8"),          // "This" network
+	netip.MustParsePrefix("192.0.0.0/24"),       // IETF protocol assignments
+	netip.MustParsePrefix("198.18.0.0/15"),      // Benchmarking
+	netip.MustParsePrefix("240.0.0.0/4"),        // Reserved, broadcast included
+	netip.MustParsePrefix("fd00:ec2::254/128"),  // AWS instance metadata over IPv6
+	netip.MustParsePrefix("64:ff9b::/96"),       // NAT64, embeds any IPv4 address
+	netip.MustParsePrefix("2002::/16"),          // 6to4, embeds any IPv4 address
+	netip.MustParsePrefix("2001::/32"),          // Teredo, embeds any IPv4 address
+	netip.MustParsePrefix("100.100.100.200/32"), // Alibaba Cloud instance metadata
+}
+
+// privatePrefixes complete netip's private ranges with the shared address space carriers and clusters use
+var privatePrefixes = []netip.Prefix{
+	netip.MustParsePrefix("100.64.0.0/10"),
+}
+
+// isBlockedAddress reports whether webhooks may not be sent to the address. Link-local addresses, which hold
+// the 169.254.169.254 cloud metadata endpoint, are always blocked. Loopback and private addresses are only
+// reachable where allowPrivate is set, for local receivers during development.
+func isBlockedAddress(addr netip.Addr, allowPrivate bool) bool {
+	addr = addr.Unmap()
+	if !addr.IsValid() || addr.IsUnspecified() || addr.IsLinkLocalUnicast() || addr.IsLinkLocalMulticast() ||
+		addr.IsInterfaceLocalMulticast() || addr.IsMulticast() {
+		return true
+	}
+	for _, prefix := range alwaysBlockedPrefixes {
+		if prefix.Contains(addr) {
+			return true
+		}
+	}
+	private := addr.IsLoopback() || addr.IsPrivate()
+	for _, prefix := range privatePrefixes {
+		private = private || prefix.Contains(addr)
+	}
+	return private && !allowPrivate
+}
+
+// checkEndpointURL resolves the host of an endpoint URL and rejects it unless every address it resolves to
+// may receive webhooks. The delivery client checks the address it dials again, as DNS may change meanwhile.
+func checkEndpointURL(ctx context.Context, resolver *net.Resolver, rawURL string, allowPrivate bool) error {
+	target, err := url.Parse(rawURL)
+	if err != nil || target.Hostname() == "" {
+		return fmt.Errorf("url must be an absolute URL")
+	}
+	addrs, err := resolver.LookupNetIP(ctx, "ip", target.Hostname())
+	if err != nil {
+		return fmt.Errorf("url host %s cannot be resolved", target.Hostname())
+	}
+	for _, addr := range addrs {
+		if isBlockedAddress(addr, allowPrivate) {
+			return fmt.Errorf("url must not point to a private, loopback, link-local or metadata address")
+		}
+	}
+	return nil
+}
+
+// newDeliveryClient returns the HTTP client posting deliveries. It refuses to connect to blocked addresses at
+// dial time, so a host re-resolving to an internal address after registration is not reached, and it does
+// not follow redirects, so a receiver cannot bounce a delivery to one either.
+func newDeliveryClient(allowPrivate bool) *http.Client {
+	dialer := &net.Dialer{
+		Timeout: 10 * time.Second,
+		Control: func(network, address string, _ syscall.RawConn) error {
+			addrPort, err := netip.ParseAddrPort(address)
+			if err != nil {
+				return fmt.Errorf("%w: %s", errEndpointAddressBlocked, address)
+			}
+			if isBlockedAddress(addrPort.Addr(), allowPrivate) {
+				return fmt.Errorf("%w: %s", errEndpointAddressBlocked, addrPort.Addr())
+			}
+			return nil
+		},
+	}
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	// A proxy would be dialed instead of the endpoint and defeat the address check
+	transport.Proxy = nil
+	transport.DialContext = dialer.DialContext
+	return &http.Client{
+		Timeout:   15 * time.Second,
+		Transport: transport,
+		CheckRedirect: func(*http.Request, []*http.Request) error {
+			return http.ErrUseLastResponse
+		},
+	}
+}
@@ -0,0 +1,83 @@
+package webhooks
+
+import (
+	"context"
+	"errors"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/netip"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestIsBlockedAddress(t *testing.T) {
+	t.Parallel()
+	cases := []struct {
+		addr         string
+		blocked      bool
+		allowPrivate bool // Blocked unless private endpoints are allowed
+	}{
+		{"93.184.216.34", false, false},
+		{"2606:2800:220:1:248:1893:25c8:1946", false, false},
+		{"169.254.169.254", true, false},
+		{"::ffff:169.254.169.254", true, false},
+		{"fd00:ec2::254", true, false},
+		{"100.100.100.200", true, false},
+		{"fe80::1", true, false},
+		{"0.0.0.0", true, false},
+		{"::", true, false},
+		{"224.0.0.1", true, false},
+		{"255.255.255.255", true, false},
+		{"64:ff9b::a9fe:a9fe", true, false},
+		{"127.0.0.1", true, true},
+		{"::1", true, true},
+		{"10.0.0.1", true, true},
+		{"172.16.5.4", true, true},
+		{"192.168.1.1", true, true},
+		{"100.64.0.1", true, true},
+		{"fc00::1", true, true},
+	}
+	for _, tc := range cases {
+		addr := netip.MustParseAddr(tc.addr)
+		assert.Equal(t, tc.blocked, isBlockedAddress(addr, false), tc.addr)
+		assert.Equal(t, tc.blocked && !tc.allowPrivate, isBlockedAddress(addr, true), "%s with private endpoints allowed", tc.addr)
+	}
+}
+
+func TestCheckEndpointURL(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	assert.NoError(t, checkEndpointURL(ctx, net.DefaultResolver, "https://93.184.216.34/hooks", false))
+	assert.Error(t, checkEndpointURL(ctx, net.DefaultResolver, "https://169.254.169.254/latest/meta-data", false))
+	assert.Error(t, checkEndpointURL(ctx, net.DefaultResolver, "https://169.254.169.254/latest/meta-data", true))
+	assert.Error(t, checkEndpointURL(ctx, net.DefaultResolver, "http://127.0.0.1:8080/hook", false))
+	assert.NoError(t, checkEndpointURL(ctx, net.DefaultResolver, "http://127.0.0.1:8080/hook", true))
+	assert.Error(t, checkEndpointURL(ctx, net.DefaultResolver, "https://[::ffff:10.0.0.1]/hook", false))
+}
+
+func TestDeliveryClient(t *testing.T) {
+	t.Parallel()
+	internal := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer internal.Close()
+	redirecting := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Redirect(w, r, internal.URL, http.StatusFound)
+	}))
+	defer redirecting.Close()
+
+	t.Run("Refuses To Dial Private Addresses", func(t *testing.T) {
+		_, err := newDeliveryClient(false).Post(internal.URL, "application/json", nil)
+		assert.True(t, errors.Is(err, errEndpointAddressBlocked), "unexpected error: %v", err)
+	})
+
+	t.Run("Does Not Follow Redirects", func(t *testing.T) {
+		resp, err := newDeliveryClient(true).Post(redirecting.URL, "application/json", nil)
+		if assert.NoError(t, err) {
+			defer resp.Body.Close()
+			assert.Equal(t, http.StatusFound, resp.StatusCode)
+		}
+	})
+}
@@ -0,0 +1,110 @@
+package webhooks
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	"encore.dev/beta/errs"
+	"encore.dev/rlog"
+	"go.temporal.io/sdk/activity"
+	"go.temporal.io/sdk/client"
+	"go.temporal.io/sdk/worker"
+
+	"encore.app/constants"
+	"encore.app/models"
+	"encore.app/temporalconn"
+	"encore.app/workflows"
+)
+
+// Service delivers bill events to customer webhook endpoints through Temporal workflows
+//
+//encore:service
+type Service struct {
+	connection *temporalconn.Connection // Read through temporal()
+	worker     worker.Worker            // Nil until connected, and unless Worker.RunWorker is set
+	workerMu   sync.Mutex
+}
+
+var service *Service
+
+// Initialize the service when the package loads
+func init() {
+	var err error
+	service, err = initService()
+	if err != nil {
+		// Only invalid Temporal credentials fail here, an unreachable Temporal is retried in the background
+		panic(fmt.Sprintf("Failed to initialize webhooks service: %v", err))
+	}
+}
+
+// Initialize the service and connect to Temporal in the background, the delivery worker starts once the
+// connection is up where Worker.RunWorker is set
+func initService() (*Service, error) {
+	options, err := temporalconn.ClientOptions(temporalconn.Config(cfg.Temporal), temporalconn.Credentials{
+		APIKey:     secrets.TemporalAPIKey,
+		ClientCert: secrets.TemporalClientCert,
+		ClientKey:  secrets.TemporalClientKey,
+		CACert:     secrets.TemporalCACert,
+	})
+	if err != nil {
+		return nil, err
+	}
+	s := &Service{connection: temporalconn.New()}
+	go s.connection.Connect(options, s.onTemporalConnected)
+	return s, nil
+}
+
+// onTemporalConnected starts the delivery worker once the client is connected
+func (s *Service) onTemporalConnected(temporalClient client.Client) {
+	if !cfg.Worker.RunWorker {
+		return
+	}
+	w, err := newDeliveryWorker(temporalClient)
+	if err != nil {
+		rlog.Error("failed to start the webhook delivery worker", "error", err)
+		return
+	}
+	s.workerMu.Lock()
+	s.worker = w
+	s.workerMu.Unlock()
+}
+
+// newDeliveryWorker registers the delivery workflow and its activities, then starts polling the task queue
+func newDeliveryWorker(temporalClient client.Client) (worker.Worker, error) {
+	activities := &Activities{
+		httpClient: newDeliveryClient(cfg.Delivery.AllowPrivateEndpoints),
+	}
+	w := worker.New(temporalClient, cfg.Worker.TaskQueue, workerOptions(cfg.Worker))
+	w.RegisterWorkflow(workflows.WebhookDeliveryWorkflow)
+	w.RegisterActivityWithOptions(activities.DeliverWebhook, activity.RegisterOptions{
+		Name: constants.DeliverWebhookActivityName,
+	})
+	w.RegisterActivityWithOptions(activities.DeadLetterWebhook, activity.RegisterOptions{
+		Name: constants.DeadLetterWebhookActivityName,
+	})
+	if err := w.Start(); err != nil {
+		return nil, fmt.Errorf("failed to start worker: %w", err)
+	}
+	return w, nil
+}
+
+// temporal returns the connected client, or an Unavailable error while Temporal is unreachable. Deliveries
+// enqueued meanwhile fail their Pub/Sub handler and are retried with the message.
+func (s *Service) temporal() (client.Client, error) {
+	temporalClient, err := s.connection.Client()
+	if err != nil {
+		return nil, models.NewAPIErrorWithCause(errs.Unavailable, models.ReasonWorkflowUnavailable, err, "not connected to Temporal")
+	}
+	return temporalClient, nil
+}
+
+// Shutdown gracefully closes the service
+func (s *Service) Shutdown(force context.Context) {
+	s.workerMu.Lock()
+	if s.worker != nil {
+		s.worker.Stop()
+	}
+	s.workerMu.Unlock()
+	s.connection.Close()
+}
@@ -0,0 +1,218 @@
+package webhooks
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"encore.dev/storage/sqldb"
+
+	"encore.app/models"
+)
+
+// Webhook endpoints and the delivery log live in their own database
+var db = sqldb.NewDatabase("webhooks", sqldb.DatabaseConfig{
+	Migrations: "./migrations",
+})
+
+var (
+	// errEndpointNotFound is returned when an endpoint does not exist or has been deleted
+	errEndpointNotFound = errors.New("webhook endpoint not found")
+	// errDeliveryNotFound is returned when a delivery does not exist for the customer
+	errDeliveryNotFound = errors.New("webhook delivery not found")
+)
+
+const endpointColumns = `endpoint_id, tenant_id, customer_id, url, secret, event_types, created_at`
+
+const deliveryColumns = `delivery_id, endpoint_id, tenant_id, customer_id, event_id, event_type, payload, status, attempts,
+	last_status_code, last_error, created_at, updated_at, delivered_at`
+
+type rowScanner interface {
+	Scan(dest ...interface{}) error
+}
+
+func insertEndpoint(ctx context.Context, endpoint *models.WebhookEndpoint) error {
+	eventTypes, err := json.Marshal(endpoint.EventTypes)
+	if err != nil {
+		return fmt.Errorf("failed to encode event types: %w", err)
+	}
+	_, err = db.Exec(ctx, `
+		INSERT INTO webhook_endpoints (`+endpointColumns+`)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)
+	`, endpoint.EndpointID, endpoint.TenantID, endpoint.CustomerID, endpoint.URL, endpoint.Secret, eventTypes, endpoint.CreatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to insert webhook endpoint: %w", err)
+	}
+	return nil
+}
+
+// findEndpoint returns the endpoint including its signing secret
+func findEndpoint(ctx context.Context, endpointID string) (*models.WebhookEndpoint, error) {
+	row := db.QueryRow(ctx, `
+		SELECT `+endpointColumns+`
+		FROM webhook_endpoints
+		WHERE endpoint_id = $1 AND deleted_at IS NULL
+	`, endpointID)
+	endpoint, err := scanEndpoint(row)
+	if errors.Is(err, sqldb.ErrNoRows) {
+		return nil, errEndpointNotFound
+	}
+	return endpoint, err
+}
+
+// listEndpoints returns the endpoints of the tenant's customer including their signing secrets
+func listEndpoints(ctx context.Context, tenantID string, customerID string) ([]*models.WebhookEndpoint, error) {
+	rows, err := db.Query(ctx, `
+		SELECT `+endpointColumns+`
+		FROM webhook_endpoints
+		WHERE tenant_id = $1 AND customer_id = $2 AND deleted_at IS NULL
+		ORDER BY created_at, endpoint_id
+	`, tenantID, customerID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list webhook endpoints: %w", err)
+	}
+	defer rows.Close()
+
+	endpoints := make([]*models.WebhookEndpoint, 0)
+	for rows.Next() {
+		endpoint, err := scanEndpoint(rows)
+		if err != nil {
+			return nil, err
+		}
+		endpoints = append(endpoints, endpoint)
+	}
+	return endpoints, rows.Err()
+}
+
+// softDeleteEndpoint keeps the row so the delivery log still resolves the endpoint
+func softDeleteEndpoint(ctx context.Context, tenantID string, customerID string, endpointID string) error {
+	result, err := db.Exec(ctx, `
+		UPDATE webhook_endpoints SET deleted_at = NOW()
+		WHERE endpoint_id = $1 AND tenant_id = $2 AND customer_id = $3 AND deleted_at IS NULL
+	`, endpointID, tenantID, customerID)
+	if err != nil {
+		return fmt.Errorf("failed to delete webhook endpoint: %w", err)
+	}
+	if result.RowsAffected() == 0 {
+		return errEndpointNotFound
+	}
+	return nil
+}
+
+// insertDelivery records a pending delivery unless the endpoint already has one for the event,
+// in which case the existing delivery is returned
+func insertDelivery(ctx context.Context, delivery *models.WebhookDelivery) (*models.WebhookDelivery, error) {
+	row := db.QueryRow(ctx, `
+		INSERT INTO webhook_deliveries (delivery_id, endpoint_id, tenant_id, customer_id, event_id, event_type, payload, status, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $9)
+		ON CONFLICT (endpoint_id, event_id) DO UPDATE SET endpoint_id = EXCLUDED.endpoint_id
+		RETURNING `+deliveryColumns+`
+	`, delivery.DeliveryID, delivery.EndpointID, delivery.TenantID, delivery.CustomerID, delivery.EventID, string(delivery.EventType),
+		[]byte(delivery.Payload), string(delivery.Status), delivery.CreatedAt)
+	stored, err := scanDelivery(row)
+	if err != nil {
+		return nil, fmt.Errorf("failed to insert webhook delivery: %w", err)
+	}
+	return stored, nil
+}
+
+func findDelivery(ctx context.Context, deliveryID string) (*models.WebhookDelivery, error) {
+	row := db.QueryRow(ctx, `
+		SELECT `+deliveryColumns+`
+		FROM webhook_deliveries
+		WHERE delivery_id = $1
+	`, deliveryID)
+	delivery, err := scanDelivery(row)
+	if errors.Is(err, sqldb.ErrNoRows) {
+		return nil, errDeliveryNotFound
+	}
+	return delivery, err
+}
+
+func listDeliveries(ctx context.Context, tenantID string, customerID string, status models.WebhookDeliveryStatus) ([]*models.WebhookDelivery, error) {
+	rows, err := db.Query(ctx, `
+		SELECT `+deliveryColumns+`
+		FROM webhook_deliveries
+		WHERE tenant_id = $1 AND customer_id = $2 AND ($3 = '' OR status = $3)
+		ORDER BY created_at DESC, delivery_id
+	`, tenantID, customerID, string(status))
+	if err != nil {
+		return nil, fmt.Errorf("failed to list webhook deliveries: %w", err)
+	}
+	defer rows.Close()
+
+	deliveries := make([]*models.WebhookDelivery, 0)
+	for rows.Next() {
+		delivery, err := scanDelivery(rows)
+		if err != nil {
+			return nil, err
+		}
+		deliveries = append(deliveries, delivery)
+	}
+	return deliveries, rows.Err()
+}
+
+// recordAttempt stores the outcome of one delivery attempt
+func recordAttempt(ctx context.Context, deliveryID string, statusCode int, attemptErr error, now time.Time) error {
+	status := models.DeliveryPending
+	lastError := ""
+	var deliveredAt *time.Time
+	if attemptErr == nil {
+		status = models.DeliveryDelivered
+		deliveredAt = &now
+	} else {
+		lastError = attemptErr.Error()
+	}
+	_, err := db.Exec(ctx, `
+		UPDATE webhook_deliveries
+		SET attempts = attempts + 1, status = $2, last_status_code = $3, last_error = $4, updated_at = $5,
+			delivered_at = COALESCE($6, delivered_at)
+		WHERE delivery_id = $1
+	`, deliveryID, string(status), statusCode, lastError, now, deliveredAt)
+	if err != nil {
+		return fmt.Errorf("failed to record webhook attempt: %w", err)
+	}
+	return nil
+}
+
+func updateDeliveryStatus(ctx context.Context, deliveryID string, status models.WebhookDeliveryStatus, reason string) error {
+	_, err := db.Exec(ctx, `
+		UPDATE webhook_deliveries SET status = $2, last_error = CASE WHEN $3 = '' THEN last_error ELSE $3 END, updated_at = $4
+		WHERE delivery_id = $1
+	`, deliveryID, string(status), reason, time.Now())
+	if err != nil {
+		return fmt.Errorf("failed to update webhook delivery: %w", err)
+	}
+	return nil
+}
+
+func scanEndpoint(row rowScanner) (*models.WebhookEndpoint, error) {
+	var endpoint models.WebhookEndpoint
+	var eventTypes []byte
+	err := row.Scan(&endpoint.EndpointID, &endpoint.TenantID, &endpoint.CustomerID, &endpoint.URL, &endpoint.Secret, &eventTypes, &endpoint.CreatedAt)
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(eventTypes, &endpoint.EventTypes); err != nil {
+		return nil, fmt.Errorf("failed to decode event types: %w", err)
+	}
+	return &endpoint, nil
+}
+
+func scanDelivery(row rowScanner) (*models.WebhookDelivery, error) {
+	var delivery models.WebhookDelivery
+	var eventType, status string
+	var payload []byte
+	err := row.Scan(&delivery.DeliveryID, &delivery.EndpointID, &delivery.TenantID, &delivery.CustomerID, &delivery.EventID, &eventType,
+		&payload, &status, &delivery.Attempts, &delivery.LastStatusCode, &delivery.LastError,
+		&delivery.CreatedAt, &delivery.UpdatedAt, &delivery.DeliveredAt)
+	if err != nil {
+		return nil, err
+	}
+	delivery.Payload = payload
+	delivery.EventType = models.WebhookEventType(eventType)
+	delivery.Status = models.WebhookDeliveryStatus(status)
+	return &delivery, nil
+}
@@ -0,0 +1,93 @@
+package webhooks
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"encore.dev/pubsub"
+	"encore.dev/rlog"
+	"github.com/google/uuid"
+
+	"encore.app/events"
+	"encore.app/models"
+)
+
+var _ = pubsub.NewSubscription(events.BillCreated, "webhooks-bill-created", pubsub.SubscriptionConfig[*models.BillCreatedEvent]{
+	Handler: func(ctx context.Context, event *models.BillCreatedEvent) error {
+		return enqueueDeliveries(ctx, event.TenantID, event.CustomerID, event.EventID, models.WebhookBillCreated, event.CreatedAt, event)
+	},
+})
+
+var _ = pubsub.NewSubscription(events.LineItemAdded, "webhooks-line-item-added", pubsub.SubscriptionConfig[*models.LineItemAddedEvent]{
+	Handler: func(ctx context.Context, event *models.LineItemAddedEvent) error {
+		return enqueueDeliveries(ctx, event.TenantID, event.CustomerID, event.EventID, models.WebhookLineItemAdded, event.LineItem.AddedAt, event)
+	},
+})
+
+var _ = pubsub.NewSubscription(events.BillClosed, "webhooks-bill-closed", pubsub.SubscriptionConfig[*models.BillClosedEvent]{
+	Handler: func(ctx context.Context, event *models.BillClosedEvent) error {
+		return enqueueDeliveries(ctx, event.TenantID, event.CustomerID, event.EventID, models.WebhookBillClosed, event.Bill.ClosedAt, event)
+	},
+})
+
+var _ = pubsub.NewSubscription(events.BillingPeriodClosed, "webhooks-billing-period-closed", pubsub.SubscriptionConfig[*models.BillingPeriodClosedEvent]{
+	Handler: func(ctx context.Context, event *models.BillingPeriodClosedEvent) error {
+		return enqueueDeliveries(ctx, event.TenantID, event.CustomerID, event.EventID, models.WebhookBillingPeriodClosed, event.ClosedAt, event)
+	},
+})
+
+// enqueueDeliveries records a delivery for every endpoint of the tenant's customer subscribed to the event
+// and starts its delivery workflow. Events published before tenants existed belong to the default tenant. Redelivered messages find the existing deliveries, pending ones are
+// started again in case the first handler failed after recording them.
+func enqueueDeliveries(ctx context.Context, tenantID string, customerID string, eventID string, eventType models.WebhookEventType, occurredAt time.Time, data interface{}) error {
+	if tenantID == "" {
+		tenantID = models.DefaultTenantID
+	}
+	endpoints, err := listEndpoints(ctx, tenantID, customerID)
+	if err != nil {
+		return err
+	}
+	payload, err := json.Marshal(models.WebhookEnvelope{
+		ID:        eventID,
+		Type:      eventType,
+		CreatedAt: occurredAt,
+		Data:      data,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to encode webhook payload: %w", err)
+	}
+
+	for _, endpoint := range endpoints {
+		if !endpoint.Subscribes(eventType) {
+			continue
+		}
+		delivery := &models.WebhookDelivery{
+			DeliveryID: uuid.New().String(),
+			EndpointID: endpoint.EndpointID,
+			TenantID:   tenantID,
+			CustomerID: customerID,
+			EventID:    eventID,
+			EventType:  eventType,
+			Payload:    payload,
+			Status:     models.DeliveryPending,
+			CreatedAt:  time.Now(),
+		}
+		stored, err := insertDelivery(ctx, delivery)
+		if err != nil {
+			return err
+		}
+		if stored.Status != models.DeliveryPending {
+			continue
+		}
+		if err := startDelivery(ctx, stored.DeliveryID); err != nil {
+			return err
+		}
+		rlog.Info("enqueued webhook delivery",
+			"delivery_id", stored.DeliveryID,
+			"endpoint_id", endpoint.EndpointID,
+			"event_id", eventID)
+	}
+	return nil
+}
@@ -1,9 +1,15 @@
 package workflows
 
 import (
+	"math"
 	"time"
 
+	"encore.app/constants"
 	"encore.app/models"
+	"encore.app/tracing"
+
+	"go.temporal.io/sdk/temporal"
+	"go.temporal.io/sdk/workflow"
 )
 
 func FindBillState(billStates []*models.Bill, billID string) *models.Bill {
@@ -15,16 +21,83 @@ func FindBillState(billStates []*models.Bill, billID string) *models.Bill {
 	return nil
 }
 
-func getAccrualFactor(startTime time.Time, currentTime time.Time) float64 {
+// changeApplied reports whether the billing period runs with the change, those started before it replay the
+// code they ran with. Every command the workflow schedules since the first release is gated by a change ID.
+func changeApplied(ctx workflow.Context, changeID string) bool {
+	return workflow.GetVersion(ctx, changeID, workflow.DefaultVersion, 1) != workflow.DefaultVersion
+}
 
-	// Simulate fetching accrual factor
-	// In a real implementation, this might involve complex calculations or external API calls
-	accrualFactor := 1.0 // Default factor
+// addLineItem adds the item to the bill and returns its line total, the amount times the quantity, which both
+// the running total and the credits drawn down for it use. Billing periods started before the running total
+// counted quantities keep adding the unit amount so their histories replay.
+func addLineItem(ctx workflow.Context, billState *models.Bill, item *models.LineItem) float64 {
+	if !changeApplied(ctx, constants.LineTotalChangeID) {
+		billState.LineItems = append(billState.LineItems, item)
+		billState.TotalAmount += item.Amount
+		return item.Total()
+	}
+	billState.AddLineItem(item)
+	return item.Total()
+}
 
-	// Example logic: if the billing period started more than 24 hours ago, increase the factor
-	if startTime.Before(currentTime.Add(-1 * 24 * time.Hour)) {
-		accrualFactor = 2.5
+// spendingCapAmount returns what the line item adds to the spend checked against the hard cap, its line total
+// as the API computes it. Billing periods started before the check counted quantities check the unit amount.
+func spendingCapAmount(ctx workflow.Context, limit *models.SpendingLimit, item *models.LineItem, billCurrency models.Currency) float64 {
+	if !changeApplied(ctx, constants.SpendingCapLineTotalChangeID) {
+		return models.ConvertCurrencyAmount(billCurrency, limit.Currency, item.Amount)
 	}
+	return limit.LineItemSpend(item, billCurrency)
+}
+
+// getAccrualFactor applies the tenant's accrual policy, periods started before policies were configurable use the default
+func getAccrualFactor(policy *models.AccrualPolicy, startTime time.Time, currentTime time.Time) float64 {
+	if policy == nil {
+		policy = &models.DefaultAccrualPolicy
+	}
+	return policy.FactorAt(startTime, currentTime)
+}
+
+// Search attribute keys of billing period workflows, the search API reads them back by the same names
+var (
+	TenantIDSearchAttribute      = temporal.NewSearchAttributeKeyKeyword(constants.TenantIDSearchAttribute)
+	CustomerIDSearchAttribute    = temporal.NewSearchAttributeKeyKeyword(constants.CustomerIDSearchAttribute)
+	CurrencySearchAttribute      = temporal.NewSearchAttributeKeyKeyword(constants.CurrencySearchAttribute)
+	PeriodStatusSearchAttribute  = temporal.NewSearchAttributeKeyKeyword(constants.PeriodStatusSearchAttribute)
+	OpenBillCountSearchAttribute = temporal.NewSearchAttributeKeyInt64(constants.OpenBillCountSearchAttribute)
+	RunningTotalSearchAttribute  = temporal.NewSearchAttributeKeyFloat64(constants.RunningTotalSearchAttribute)
+	BillIDsSearchAttribute       = temporal.NewSearchAttributeKeyKeywordList(constants.BillIDsSearchAttribute)
+)
+
+// searchAttributeUpdates returns the updates setting every search attribute to the given values
+func searchAttributeUpdates(attributes models.BillSearchAttributes) []temporal.SearchAttributeUpdate {
+	return []temporal.SearchAttributeUpdate{
+		TenantIDSearchAttribute.ValueSet(attributes.TenantID),
+		CustomerIDSearchAttribute.ValueSet(attributes.CustomerID),
+		CurrencySearchAttribute.ValueSet(string(attributes.Currency)),
+		PeriodStatusSearchAttribute.ValueSet(string(attributes.Status)),
+		OpenBillCountSearchAttribute.ValueSet(int64(attributes.OpenBillCount)),
+		RunningTotalSearchAttribute.ValueSet(attributes.RunningTotal),
+		BillIDsSearchAttribute.ValueSet(attributes.BillIDs),
+	}
+}
+
+// incrementCounter adds to a counter of the worker's metrics handler, which drops it while the workflow replays
+func incrementCounter(ctx workflow.Context, name string, tags map[string]string, delta int64) {
+	workflow.GetMetricsHandler(ctx).WithTags(tags).Counter(name).Inc(delta)
+}
+
+// minorUnits rounds an amount to the minor units of its currency, every supported currency has two decimals
+func minorUnits(amount float64) int64 {
+	return int64(math.Round(amount * 100))
+}
 
-	return accrualFactor
+// startSignalSpan continues the trace of the API call that sent the signal while the workflow applies it, the
+// activities the signal schedules are traced under it
+func startSignalSpan(ctx workflow.Context, workflowState *models.BillWorkflowInput, signalName string, trace models.TraceContext, billID string) (workflow.Context, func()) {
+	return tracing.StartWorkflowSpan(ctx, trace, "HandleSignal", signalName, tracing.IDs{
+		TenantID:   workflowState.TenantID,
+		CustomerID: workflowState.CustomerID,
+		BillID:     billID,
+		WorkflowID: workflowState.WorkflowID,
+	})
 }
@@ -6,6 +6,7 @@ import (
 
 	"encore.app/models"
 	"github.com/stretchr/testify/assert"
+	"go.temporal.io/sdk/temporal"
 )
 
 func TestFindBillState(t *testing.T) {
@@ -29,21 +30,56 @@ func TestGetAccrualFactor(t *testing.T) {
 	t.Run("returns default factor for recent startTime", func(t *testing.T) {
 		startTime := time.Now()
 		currentTime := time.Now()
-		factor := getAccrualFactor(startTime, currentTime)
+		factor := getAccrualFactor(nil, startTime, currentTime)
 		assert.Equal(t, 1.0, factor)
 	})
 
 	t.Run("returns 2.5 for startTime older than 24 hours", func(t *testing.T) {
 		startTime := time.Now().Add(-25 * time.Hour)
 		currentTime := time.Now()
-		factor := getAccrualFactor(startTime, currentTime)
+		factor := getAccrualFactor(nil, startTime, currentTime)
 		assert.Equal(t, 2.5, factor)
 	})
 
 	t.Run("returns 1.0 for startTime exactly 24 hours ago", func(t *testing.T) {
 		startTime := time.Now().Add(-24 * time.Hour)
 		currentTime := time.Now()
-		factor := getAccrualFactor(startTime, currentTime)
+		factor := getAccrualFactor(nil, startTime, currentTime)
 		assert.Equal(t, 1.0, factor)
 	})
+
+	t.Run("applies the tenant policy", func(t *testing.T) {
+		startTime := time.Now().Add(-2 * time.Hour)
+		currentTime := time.Now()
+		factor := getAccrualFactor(&models.AccrualPolicy{AfterHours: 1, Factor: 1.5}, startTime, currentTime)
+		assert.Equal(t, 1.5, factor)
+	})
+}
+
+func TestSearchAttributeUpdates(t *testing.T) {
+	attributes := temporal.NewSearchAttributes(searchAttributeUpdates(models.BillSearchAttributes{
+		TenantID:      "retail",
+		CustomerID:    "cust-1",
+		Currency:      models.GEL,
+		Status:        models.StatusOpen,
+		OpenBillCount: 2,
+		RunningTotal:  12.5,
+		BillIDs:       []string{"bill-1", "bill-2"},
+	})...)
+
+	assert.Equal(t, 7, attributes.Size())
+	currency, _ := attributes.GetKeyword(CurrencySearchAttribute)
+	assert.Equal(t, "GEL", currency)
+	openBills, _ := attributes.GetInt64(OpenBillCountSearchAttribute)
+	assert.Equal(t, int64(2), openBills)
+	billIDs, _ := attributes.GetKeywordList(BillIDsSearchAttribute)
+	assert.Equal(t, []string{"bill-1", "bill-2"}, billIDs)
+}
+
+func TestMinorUnits(t *testing.T) {
+	t.Parallel()
+	assert.Equal(t, int64(1234), minorUnits(12.34))
+	assert.Equal(t, int64(30), minorUnits(0.1+0.2))
+	assert.Equal(t, int64(0), minorUnits(0.004))
+	assert.Equal(t, int64(-250), minorUnits(-2.5))
 }
@@ -7,6 +7,7 @@ import (
 	"encore.app/constants"
 	"encore.app/models"
 
+	"go.temporal.io/sdk/temporal"
 	"go.temporal.io/sdk/workflow"
 )
 
@@ -23,23 +24,32 @@ func BillWorkflow(ctx workflow.Context, input *models.BillWorkflowInput) error {
 		logger.Error("Failed to set list bills query handler", "error", err)
 		return err
 	}
+	if err := setSpendingStatusQueryHandler(ctx, input); err != nil {
+		logger.Error("Failed to set spending status query handler", "error", err)
+		return err
+	}
 
 	// Set up signal channels
 	addLineItemCh := workflow.GetSignalChannel(ctx, constants.AddLineItemSignalName)
 	closeBillCh := workflow.GetSignalChannel(ctx, constants.CloseBillSignalName)
 	createBillCh := workflow.GetSignalChannel(ctx, constants.CreateBillSignalName)
 	closeBillingPeriodCh := workflow.GetSignalChannel(ctx, constants.CloseBillingPeriodSignalName)
+	setSpendingLimitCh := workflow.GetSignalChannel(ctx, constants.SetSpendingLimitSignalName)
 
 	// Calculate billing period duration
 	billingDuration := time.Duration(input.BillingPeriodDays) * 24 * time.Hour
 
 	logger.Info("Bill workflow started",
+		"tenant_id", input.TenantID,
 		"customer_id", input.CustomerID,
 		"billing_period", billingDuration,
 	)
 
 	// Start periodic fee accrual (simulate progressive billing)
 
+	// Search attributes make the billing period findable across customers, they follow every signal
+	searchAttributes := upsertSearchAttributes(ctx, input, models.StatusOpen, models.BillSearchAttributes{})
+
 	// Main workflow loop - listen for signals until billing period ends
 	timerFired := false
 	timerFuture := workflow.NewTimer(ctx, billingDuration)
@@ -50,35 +60,60 @@ func BillWorkflow(ctx workflow.Context, input *models.BillWorkflowInput) error {
 		selector.AddReceive(addLineItemCh, func(c workflow.ReceiveChannel, more bool) {
 			var signal models.AddLineItemSignal
 			c.Receive(ctx, &signal)
-			handleAddLineItemSignal(ctx, input, signal)
+			incrementCounter(ctx, constants.SignalsHandledMetric, map[string]string{"signal": constants.AddLineItemSignalName}, 1)
+			signalCtx, finish := startSignalSpan(ctx, input, constants.AddLineItemSignalName, signal.Trace, signal.BillID)
+			defer finish()
+			handleAddLineItemSignal(signalCtx, input, signal)
 		})
 
 		// Handle close bill signals
 		selector.AddReceive(closeBillCh, func(c workflow.ReceiveChannel, more bool) {
 			var signal models.CloseBillSignal
 			c.Receive(ctx, &signal)
-			handleCloseBillSignal(ctx, input, signal)
+			incrementCounter(ctx, constants.SignalsHandledMetric, map[string]string{"signal": constants.CloseBillSignalName}, 1)
+			signalCtx, finish := startSignalSpan(ctx, input, constants.CloseBillSignalName, signal.Trace, signal.BillID)
+			defer finish()
+			handleCloseBillSignal(signalCtx, input, signal)
 		})
 
 		selector.AddReceive(createBillCh, func(c workflow.ReceiveChannel, more bool) {
 			var signal models.CreateBillSignal
 			c.Receive(ctx, &signal)
-			handleCreateBillSignal(ctx, input, signal)
+			incrementCounter(ctx, constants.SignalsHandledMetric, map[string]string{"signal": constants.CreateBillSignalName}, 1)
+			signalCtx, finish := startSignalSpan(ctx, input, constants.CreateBillSignalName, signal.Trace, signal.BillID)
+			defer finish()
+			handleCreateBillSignal(signalCtx, input, signal)
 		})
 
 		selector.AddReceive(closeBillingPeriodCh, func(c workflow.ReceiveChannel, more bool) {
-			c.Receive(ctx, nil)
-			closeAllBillsDueToTimeout(ctx, input)
+			var signal models.CloseBillingPeriodSignal
+			c.Receive(ctx, &signal)
+			incrementCounter(ctx, constants.SignalsHandledMetric, map[string]string{"signal": constants.CloseBillingPeriodSignalName}, 1)
+			signalCtx, finish := startSignalSpan(ctx, input, constants.CloseBillingPeriodSignalName, signal.Trace, "")
+			defer finish()
+			input.ChildAccounts = append(input.ChildAccounts, signal.ChildAccounts...)
+			closeAllBillsDueToTimeout(signalCtx, input, signal.Audit)
+		})
+
+		selector.AddReceive(setSpendingLimitCh, func(c workflow.ReceiveChannel, more bool) {
+			var signal models.SetSpendingLimitSignal
+			c.Receive(ctx, &signal)
+			incrementCounter(ctx, constants.SignalsHandledMetric, map[string]string{"signal": constants.SetSpendingLimitSignalName}, 1)
+			signalCtx, finish := startSignalSpan(ctx, input, constants.SetSpendingLimitSignalName, signal.Trace, "")
+			defer finish()
+			handleSetSpendingLimitSignal(signalCtx, input, signal)
 		})
 
 		// Handle billing period timeout for all bills
 		selector.AddFuture(timerFuture, func(f workflow.Future) {
 			timerFired = true
-			closeAllBillsDueToTimeout(ctx, input)
+			closeAllBillsDueToTimeout(ctx, input, models.AuditContext{Actor: models.SystemActor})
 		})
 
 		selector.Select(ctx)
+		searchAttributes = upsertSearchAttributes(ctx, input, models.StatusOpen, searchAttributes)
 	}
+	upsertSearchAttributes(ctx, input, models.StatusClosed, searchAttributes)
 
 	// Finalize all bills at the end of the workflow
 	for _, bill := range input.BillStates {
@@ -89,6 +124,17 @@ func BillWorkflow(ctx workflow.Context, input *models.BillWorkflowInput) error {
 			"status", bill.Status,
 		)
 	}
+	consolidated := consolidateBillingPeriod(ctx, input)
+	publishEvent(ctx, constants.LifecycleEventsChangeID, constants.PublishBillingPeriodClosedActivityName, models.BillingPeriodClosedEvent{
+		EventID:          models.BillingPeriodClosedEventID(input.WorkflowID),
+		TenantID:         input.TenantID,
+		CustomerID:       input.CustomerID,
+		WorkflowID:       input.WorkflowID,
+		Bills:            input.BillStates,
+		ClosedAt:         workflow.Now(ctx),
+		ConsolidatedBill: consolidated,
+	})
+	archiveBillingPeriod(ctx, input, consolidated)
 
 	return nil
 }
@@ -96,6 +142,10 @@ func BillWorkflow(ctx workflow.Context, input *models.BillWorkflowInput) error {
 // setGetBillQueryHandler sets up the query handler for getting a single bill by ID
 func setGetBillQueryHandler(ctx workflow.Context, workflowState *models.BillWorkflowInput) error {
 	return workflow.SetQueryHandler(ctx, constants.GetBillQuery, func(req models.GetBillRequest) (*models.Bill, error) {
+		// Bills of another tenant are reported as missing rather than forbidden
+		if req.TenantID != workflowState.TenantID {
+			return nil, fmt.Errorf("bill not found")
+		}
 		for index := range workflowState.BillStates {
 			if workflowState.BillStates[index].ID == req.BillID {
 				return workflowState.BillStates[index], nil
@@ -105,17 +155,26 @@ func setGetBillQueryHandler(ctx workflow.Context, workflowState *models.BillWork
 	})
 }
 
-// setListBillsQueryHandler sets up the query handler for listing bills with optional filtering
+// setListBillsQueryHandler sets up the query handler for listing bills. Filters, sort order and the page are
+// applied here so only the requested page leaves the workflow.
 func setListBillsQueryHandler(ctx workflow.Context, workflowState *models.BillWorkflowInput) error {
 	return workflow.SetQueryHandler(ctx, constants.ListBillsQuery, func(req models.ListBillsRequest) ([]*models.Bill, error) {
-		filteredBills := make([]*models.Bill, 0)
-		for index := range workflowState.BillStates {
-			if workflowState.BillStates[index].Status != models.BillStatus(req.Status) {
-				continue
-			}
-			filteredBills = append(filteredBills, workflowState.BillStates[index])
+		if req.TenantID != workflowState.TenantID {
+			return make([]*models.Bill, 0), nil
+		}
+		return models.SelectBills(req, workflowState.BillStates)
+	})
+}
+
+// setSpendingStatusQueryHandler sets up the query handler for the spend of the billing period against its limit
+func setSpendingStatusQueryHandler(ctx workflow.Context, workflowState *models.BillWorkflowInput) error {
+	return workflow.SetQueryHandler(ctx, constants.GetSpendingStatusQuery, func(req models.SpendingStatusRequest) (*models.SpendingStatus, error) {
+		if req.TenantID != workflowState.TenantID {
+			return nil, fmt.Errorf("billing period not found")
 		}
-		return filteredBills, nil
+		status := models.NewSpendingStatus(workflowState.SpendingLimit, workflowState.Currency, workflowState.BillStates, workflowState.SpendingAlerts)
+		status.AccrualFactor = getAccrualFactor(workflowState.Accrual, workflowState.StartedAt, workflow.Now(ctx))
+		return status, nil
 	})
 }
 
@@ -132,11 +191,28 @@ func handleCreateBillSignal(ctx workflow.Context, workflowState *models.BillWork
 		WorkflowID:  signal.WorkflowID,
 	}
 	workflowState.BillStates = append(workflowState.BillStates, newBill)
+	incrementCounter(ctx, constants.BillsCreatedMetric, map[string]string{"currency": string(newBill.Currency)}, 1)
 	logger.Info("New bill created",
 		"bill_id", newBill.ID,
 		"currency", newBill.Currency,
 		"created_at", newBill.CreatedAt,
 	)
+	publishEvent(ctx, constants.LifecycleEventsChangeID, constants.PublishBillCreatedActivityName, models.BillCreatedEvent{
+		EventID:    models.BillCreatedEventID(newBill.ID),
+		TenantID:   workflowState.TenantID,
+		CustomerID: workflowState.CustomerID,
+		WorkflowID: workflowState.WorkflowID,
+		BillID:     newBill.ID,
+		Currency:   newBill.Currency,
+		CreatedAt:  newBill.CreatedAt,
+	})
+	appendAuditRecord(ctx, workflowState, signal.Audit, models.AuditRecord{
+		RecordID:   models.WorkflowAuditRecordID(workflowState.WorkflowID, models.AuditCreateBill, newBill.ID),
+		BillID:     newBill.ID,
+		Action:     models.AuditCreateBill,
+		TotalAfter: auditTotal(newBill.TotalAmount),
+		Currency:   newBill.Currency,
+	})
 }
 
 // handleCloseBillSignal processes a CloseBillSignal and updates the bill state
@@ -150,13 +226,22 @@ func handleCloseBillSignal(ctx workflow.Context, workflowState *models.BillWorkf
 		)
 		return
 	}
-	billState.Close(signal.Reason)
+	totalBefore := billState.TotalAmount
+	if billState.Status != models.StatusClosed {
+		if workflowState.DrawDownMode == models.DrawDownBillClose {
+			applyPrepaidCredits(ctx, workflowState, billState, billState.ID+"/close", billState.CalculateTotal())
+		}
+		countBillClosed(ctx, billState, "signal")
+	}
+	billState.Close(signal.Reason, workflow.Now(ctx))
 
 	logger.Info("Bill closed via signal",
 		"bill_id", billState.ID,
 		"reason", signal.Reason,
 		"final_total", billState.TotalAmount,
 	)
+	publishBillClosed(ctx, workflowState, billState)
+	auditBillClosed(ctx, workflowState, signal.Audit, billState, totalBefore, signal.Reason)
 }
 
 // handleAddLineItemSignal processes an AddLineItemSignal and updates the bill state
@@ -164,13 +249,51 @@ func handleAddLineItemSignal(ctx workflow.Context, workflowState *models.BillWor
 	// Add timestamp to line item
 	logger := workflow.GetLogger(ctx)
 	signal.LineItem.AddedAt = workflow.Now(ctx)
-	accrualFactor := getAccrualFactor(workflowState.StartedAt, signal.LineItem.AddedAt)
+	accrualFactor := getAccrualFactor(workflowState.Accrual, workflowState.StartedAt, signal.LineItem.AddedAt)
 	billState := FindBillState(workflowState.BillStates, signal.BillID)
-	signal.LineItem.Amount = models.ConvertCurrencyAmount(signal.Currency, billState.Currency, signal.LineItem.Amount) * accrualFactor
+	if billState == nil {
+		logger.Error("Bill not found for add line item signal",
+			"bill_id", signal.BillID,
+			"item_id", signal.LineItem.ID,
+		)
+		return
+	}
+	signal.LineItem.Amount = models.AccruedAmount(signal.LineItem.Amount, signal.Currency, billState.Currency, accrualFactor)
+	converted := signal.Currency != "" && signal.Currency != billState.Currency
+	// The API checks the hard cap before signalling and reports items rejected here, which raced past it
+	if limit := workflowState.SpendingLimit; limit != nil {
+		spend := models.PeriodSpend(workflowState.BillStates, limit.Currency)
+		if limit.WouldExceed(spend, spendingCapAmount(ctx, limit, signal.LineItem, billState.Currency)) {
+			logger.Warn("Line item rejected, spending limit would be exceeded",
+				"bill_id", billState.ID,
+				"item_id", signal.LineItem.ID,
+				"amount", signal.LineItem.Amount,
+				"period_spend", spend,
+				"hard_cap", limit.HardCap,
+			)
+			appendAuditRecord(ctx, workflowState, signal.Audit, models.AuditRecord{
+				RecordID:    models.WorkflowAuditRecordID(workflowState.WorkflowID, models.AuditRejectLineItem, billState.ID+"/"+signal.LineItem.ID),
+				BillID:      billState.ID,
+				Action:      models.AuditRejectLineItem,
+				TotalBefore: auditTotal(billState.TotalAmount),
+				TotalAfter:  auditTotal(billState.TotalAmount),
+				Currency:    billState.Currency,
+				Reason:      "spending limit would be exceeded",
+			})
+			return
+		}
+	}
 	// Add line item to bill state
+	totalBefore := billState.TotalAmount
 	itemCopy := *signal.LineItem
-	billState.LineItems = append(billState.LineItems, &itemCopy)
-	billState.TotalAmount += signal.LineItem.Amount
+	lineTotal := addLineItem(ctx, billState, &itemCopy)
+	countLineItemAdded(ctx, billState, lineTotal)
+	if converted {
+		incrementCounter(ctx, constants.FXConversionsMetric, map[string]string{
+			"from_currency": string(signal.Currency),
+			"to_currency":   string(billState.Currency),
+		}, 1)
+	}
 
 	logger.Info("Line item added to bill",
 		"bill_id", billState.ID,
@@ -178,20 +301,336 @@ func handleAddLineItemSignal(ctx workflow.Context, workflowState *models.BillWor
 		"amount", signal.LineItem.Amount,
 		"new_total", billState.TotalAmount,
 	)
+
+	publishLineItemAdded(ctx, constants.LifecycleEventsChangeID, workflowState, billState, &itemCopy)
+
+	if workflowState.DrawDownMode == models.DrawDownLineItem {
+		applyPrepaidCredits(ctx, workflowState, billState, billState.ID+"/"+signal.LineItem.ID, lineTotal)
+	}
+	appendAuditRecord(ctx, workflowState, signal.Audit, models.AuditRecord{
+		RecordID:    models.WorkflowAuditRecordID(workflowState.WorkflowID, models.AuditAddLineItem, billState.ID+"/"+itemCopy.ID),
+		BillID:      billState.ID,
+		Action:      models.AuditAddLineItem,
+		TotalBefore: auditTotal(totalBefore),
+		TotalAfter:  auditTotal(billState.TotalAmount),
+		Currency:    billState.Currency,
+	})
+	evaluateSpendingThresholds(ctx, workflowState, billState.ID)
+}
+
+// handleSetSpendingLimitSignal replaces the spending limit and alerts on thresholds the period already exceeds
+func handleSetSpendingLimitSignal(ctx workflow.Context, workflowState *models.BillWorkflowInput, signal models.SetSpendingLimitSignal) {
+	logger := workflow.GetLogger(ctx)
+	workflowState.SpendingLimit = signal.Limit.WithDefaults(workflowState.Currency)
+
+	logger.Info("Spending limit set",
+		"hard_cap", workflowState.SpendingLimit.HardCap,
+		"currency", workflowState.SpendingLimit.Currency,
+		"thresholds", workflowState.SpendingLimit.Thresholds,
+	)
+	evaluateSpendingThresholds(ctx, workflowState, "")
 }
 
-func closeAllBillsDueToTimeout(ctx workflow.Context, input *models.BillWorkflowInput) {
+// evaluateSpendingThresholds records and emits an alert for every threshold the period spend has newly crossed
+func evaluateSpendingThresholds(ctx workflow.Context, workflowState *models.BillWorkflowInput, billID string) {
+	logger := workflow.GetLogger(ctx)
+	limit := workflowState.SpendingLimit
+	if limit == nil {
+		return
+	}
+	spend := models.PeriodSpend(workflowState.BillStates, limit.Currency)
+	for _, threshold := range limit.CrossedThresholds(spend, workflowState.SpendingAlerts) {
+		alert := models.SpendingAlert{
+			Threshold: threshold,
+			Total:     spend,
+			HardCap:   limit.HardCap,
+			Currency:  limit.Currency,
+			BillID:    billID,
+			CrossedAt: workflow.Now(ctx),
+		}
+		workflowState.SpendingAlerts = append(workflowState.SpendingAlerts, alert)
+		logger.Info("Spending threshold crossed",
+			"threshold", threshold,
+			"period_spend", spend,
+			"hard_cap", limit.HardCap,
+		)
+
+		publishEvent(ctx, constants.SpendingAlertsChangeID, constants.EmitSpendingAlertActivityName, models.SpendingAlertEvent{
+			EventID:    models.SpendingAlertEventID(workflowState.WorkflowID, alert),
+			TenantID:   workflowState.TenantID,
+			CustomerID: workflowState.CustomerID,
+			WorkflowID: workflowState.WorkflowID,
+			Alert:      alert,
+		})
+	}
+}
+
+// applyPrepaidCredits draws down up to amount from the customer's wallet and records the applied credits
+// as a negative line item. It runs as a local activity so the credit line is in place before the signal
+// handling completes and a following query sees it.
+func applyPrepaidCredits(ctx workflow.Context, workflowState *models.BillWorkflowInput, billState *models.Bill, reference string, amount float64) {
+	logger := workflow.GetLogger(ctx)
+	if amount <= 0 || !changeApplied(ctx, constants.PrepaidCreditsChangeID) {
+		return
+	}
+
+	ctx = workflow.WithLocalActivityOptions(ctx, workflow.LocalActivityOptions{
+		StartToCloseTimeout: 10 * time.Second,
+		RetryPolicy: &temporal.RetryPolicy{
+			InitialInterval: time.Second,
+			MaximumAttempts: 5,
+		},
+	})
+	var result models.DrawDownResponse
+	err := workflow.ExecuteLocalActivity(ctx, constants.DrawDownCreditsActivityName, models.DrawDownCreditsInput{
+		TenantID:   workflowState.TenantID,
+		CustomerID: workflowState.CustomerID,
+		BillID:     billState.ID,
+		Reference:  reference,
+		Amount:     amount,
+		Currency:   billState.Currency,
+	}).Get(ctx, &result)
+	if err != nil {
+		// The bill is still charged in full, credits can be applied manually later
+		logger.Error("Failed to draw down prepaid credits",
+			"bill_id", billState.ID,
+			"reference", reference,
+			"error", err,
+		)
+		return
+	}
+	if result.AppliedAmount <= 0 {
+		return
+	}
+
+	creditLine := models.NewPrepaidCreditLineItem(reference, result.AppliedAmount, workflow.Now(ctx))
+	billState.LineItems = append(billState.LineItems, creditLine)
+	billState.TotalAmount += creditLine.Amount
+
+	logger.Info("Prepaid credits applied to bill",
+		"bill_id", billState.ID,
+		"reference", reference,
+		"applied_amount", result.AppliedAmount,
+		"remaining_balance", result.RemainingBalance,
+		"new_total", billState.TotalAmount,
+	)
+	publishLineItemAdded(ctx, constants.PrepaidCreditEventsChangeID, workflowState, billState, creditLine)
+}
+
+// closeAllBillsDueToTimeout closes every open bill when the billing period ends or is closed on request
+func closeAllBillsDueToTimeout(ctx workflow.Context, input *models.BillWorkflowInput, audit models.AuditContext) {
 	logger := workflow.GetLogger(ctx)
 
 	for index := range input.BillStates {
 		if input.BillStates[index].Status != models.StatusClosed {
-			input.BillStates[index].Close("Billing period timed out")
+			totalBefore := input.BillStates[index].TotalAmount
+			if input.DrawDownMode == models.DrawDownBillClose {
+				applyPrepaidCredits(ctx, input, input.BillStates[index], input.BillStates[index].ID+"/close", input.BillStates[index].CalculateTotal())
+			}
+			input.BillStates[index].Close("Billing period timed out", workflow.Now(ctx))
+			countBillClosed(ctx, input.BillStates[index], "period_end")
 			// Optionally recalculate total if needed
 			// bill.TotalAmount = calculateTotal(bill.LineItems)
 			logger.Info("Bill closed due to billing period completion",
 				"bill_id", input.BillStates[index].ID,
 				"final_total", input.BillStates[index].TotalAmount,
 			)
+			publishBillClosed(ctx, input, input.BillStates[index])
+			auditBillClosed(ctx, input, audit, input.BillStates[index], totalBefore, input.BillStates[index].CloseReason)
 		}
 	}
+	appendAuditRecord(ctx, input, audit, models.AuditRecord{
+		RecordID: models.WorkflowAuditRecordID(input.WorkflowID, models.AuditCloseBillingPeriod, ""),
+		Action:   models.AuditCloseBillingPeriod,
+		Currency: input.Currency,
+		Reason:   "Billing period timed out",
+	})
+}
+
+// countBillClosed records a bill closed by a close signal or by the end of its billing period
+func countBillClosed(ctx workflow.Context, billState *models.Bill, trigger string) {
+	incrementCounter(ctx, constants.BillsClosedMetric, map[string]string{
+		"currency": string(billState.Currency),
+		"trigger":  trigger,
+	}, 1)
+}
+
+// countLineItemAdded records the line item and its amount in the currency of the bill, credits are
+// negative and only count as items since the amount billed only goes up
+func countLineItemAdded(ctx workflow.Context, billState *models.Bill, amount float64) {
+	tags := map[string]string{"currency": string(billState.Currency)}
+	incrementCounter(ctx, constants.LineItemsAddedMetric, tags, 1)
+	if minor := minorUnits(amount); minor > 0 {
+		incrementCounter(ctx, constants.AmountBilledMetric, tags, minor)
+	}
+}
+
+// publishLineItemAdded announces a line item added to the bill, credit lines included
+func publishLineItemAdded(ctx workflow.Context, changeID string, workflowState *models.BillWorkflowInput, billState *models.Bill, item *models.LineItem) {
+	publishEvent(ctx, changeID, constants.PublishLineItemAddedActivityName, models.LineItemAddedEvent{
+		EventID:     models.LineItemAddedEventID(billState.ID, item.ID),
+		TenantID:    workflowState.TenantID,
+		CustomerID:  workflowState.CustomerID,
+		WorkflowID:  workflowState.WorkflowID,
+		BillID:      billState.ID,
+		LineItem:    item,
+		Currency:    billState.Currency,
+		TotalAmount: billState.TotalAmount,
+	})
+}
+
+func publishBillClosed(ctx workflow.Context, workflowState *models.BillWorkflowInput, billState *models.Bill) {
+	publishEvent(ctx, constants.LifecycleEventsChangeID, constants.PublishBillClosedActivityName, models.BillClosedEvent{
+		EventID:    models.BillClosedEventID(billState.ID),
+		TenantID:   workflowState.TenantID,
+		CustomerID: workflowState.CustomerID,
+		WorkflowID: workflowState.WorkflowID,
+		Bill:       billState,
+	})
+}
+
+func auditBillClosed(ctx workflow.Context, workflowState *models.BillWorkflowInput, audit models.AuditContext, billState *models.Bill, totalBefore float64, reason string) {
+	appendAuditRecord(ctx, workflowState, audit, models.AuditRecord{
+		RecordID:    models.WorkflowAuditRecordID(workflowState.WorkflowID, models.AuditCloseBill, billState.ID),
+		BillID:      billState.ID,
+		Action:      models.AuditCloseBill,
+		TotalBefore: auditTotal(totalBefore),
+		TotalAfter:  auditTotal(billState.TotalAmount),
+		Currency:    billState.Currency,
+		Reason:      reason,
+	})
+}
+
+// appendAuditRecord appends a mutation applied by the workflow to the audit log. Records are written like
+// events, their IDs are derived from the mutation so a retried activity does not append them twice.
+func appendAuditRecord(ctx workflow.Context, workflowState *models.BillWorkflowInput, audit models.AuditContext, record models.AuditRecord) {
+	record.TenantID = workflowState.TenantID
+	record.CustomerID = workflowState.CustomerID
+	record.WorkflowID = workflowState.WorkflowID
+	record.Source = models.AuditSourceWorkflow
+	record.Actor = audit.Actor
+	if record.Actor == "" {
+		record.Actor = models.SystemActor
+	}
+	record.RequestID = audit.RequestID
+	record.OccurredAt = workflow.Now(ctx)
+	publishEvent(ctx, constants.AuditLogChangeID, constants.AppendAuditRecordActivityName, record)
+}
+
+func auditTotal(total float64) *float64 {
+	return &total
+}
+
+// consolidateBillingPeriod rolls the bills of a parent account up with those of its child accounts when the
+// billing period ends, by its timer or on request. It returns nil for customers without child accounts and is
+// retried until it succeeds, like the archive that keeps the consolidated bill.
+func consolidateBillingPeriod(ctx workflow.Context, input *models.BillWorkflowInput) *models.ConsolidatedBill {
+	logger := workflow.GetLogger(ctx)
+	if !changeApplied(ctx, constants.ConsolidationChangeID) {
+		return nil
+	}
+	consolidateCtx, _ := workflow.NewDisconnectedContext(ctx)
+	consolidateCtx = workflow.WithActivityOptions(consolidateCtx, workflow.ActivityOptions{
+		StartToCloseTimeout: time.Minute,
+		RetryPolicy: &temporal.RetryPolicy{
+			InitialInterval:    time.Second,
+			BackoffCoefficient: 2,
+			MaximumInterval:    5 * time.Minute,
+		},
+	})
+	request := models.ConsolidateBillingPeriodInput{
+		TenantID:      input.TenantID,
+		CustomerID:    input.CustomerID,
+		WorkflowID:    input.WorkflowID,
+		Bills:         input.BillStates,
+		ChildAccounts: input.ChildAccounts,
+		StartedAt:     input.StartedAt,
+		ClosedAt:      workflow.Now(ctx),
+	}
+	var consolidated *models.ConsolidatedBill
+	if err := workflow.ExecuteActivity(consolidateCtx, constants.ConsolidateBillingPeriodActivityName, request).Get(consolidateCtx, &consolidated); err != nil {
+		logger.Error("Failed to consolidate billing period", "workflow_id", input.WorkflowID, "error", err)
+		return nil
+	}
+	if consolidated != nil {
+		logger.Info("Consolidated bill generated",
+			"workflow_id", input.WorkflowID,
+			"accounts", len(consolidated.ChildSubtotals),
+			"total_amount", consolidated.TotalAmount,
+		)
+	}
+	return consolidated
+}
+
+// archiveBillingPeriod writes the final state of the billing period to the archive, the read APIs fall back
+// to it once the workflow is gone. It is retried until it succeeds so no finished period goes unarchived.
+func archiveBillingPeriod(ctx workflow.Context, input *models.BillWorkflowInput, consolidated *models.ConsolidatedBill) {
+	logger := workflow.GetLogger(ctx)
+	if !changeApplied(ctx, constants.ArchiveChangeID) {
+		return
+	}
+	archiveCtx, _ := workflow.NewDisconnectedContext(ctx)
+	archiveCtx = workflow.WithActivityOptions(archiveCtx, workflow.ActivityOptions{
+		StartToCloseTimeout: 30 * time.Second,
+		RetryPolicy: &temporal.RetryPolicy{
+			InitialInterval:    time.Second,
+			BackoffCoefficient: 2,
+			MaximumInterval:    5 * time.Minute,
+		},
+	})
+	period := models.ArchivedBillingPeriod{
+		WorkflowID:       input.WorkflowID,
+		TenantID:         input.TenantID,
+		CustomerID:       input.CustomerID,
+		Currency:         input.Currency,
+		StartedAt:        input.StartedAt,
+		ClosedAt:         workflow.Now(ctx),
+		Bills:            input.BillStates,
+		ConsolidatedBill: consolidated,
+	}
+	if err := workflow.ExecuteActivity(archiveCtx, constants.ArchiveBillingPeriodActivityName, period).Get(archiveCtx, nil); err != nil {
+		logger.Error("Failed to archive billing period", "workflow_id", input.WorkflowID, "error", err)
+		return
+	}
+	logger.Info("Billing period archived", "workflow_id", input.WorkflowID, "bills", len(input.BillStates))
+}
+
+// upsertSearchAttributes indexes the billing period in its current state unless it is unchanged since the last
+// upsert, and returns the attributes now indexed
+func upsertSearchAttributes(ctx workflow.Context, input *models.BillWorkflowInput, status models.BillStatus, last models.BillSearchAttributes) models.BillSearchAttributes {
+	if !changeApplied(ctx, constants.SearchAttributesChangeID) {
+		return last
+	}
+	attributes := models.NewBillSearchAttributes(input, status)
+	if attributes.Equal(last) {
+		return last
+	}
+	if err := workflow.UpsertTypedSearchAttributes(ctx, searchAttributeUpdates(attributes)...); err != nil {
+		// Billing carries on, the next change retries the upsert
+		workflow.GetLogger(ctx).Error("Failed to upsert search attributes", "workflow_id", input.WorkflowID, "error", err)
+		return last
+	}
+	return attributes
+}
+
+// publishEvent runs the activity publishing a lifecycle event, unless the billing period started before the
+// change adding it. Events are published on a disconnected context so bills closed while the billing period
+// is being cancelled are still announced.
+func publishEvent(ctx workflow.Context, changeID string, activityName string, event interface{}) {
+	logger := workflow.GetLogger(ctx)
+	if !changeApplied(ctx, changeID) {
+		return
+	}
+	publishCtx, _ := workflow.NewDisconnectedContext(ctx)
+	publishCtx = workflow.WithActivityOptions(publishCtx, workflow.ActivityOptions{
+		StartToCloseTimeout: 10 * time.Second,
+		RetryPolicy: &temporal.RetryPolicy{
+			InitialInterval: time.Second,
+			MaximumAttempts: 10,
+		},
+	})
+	if err := workflow.ExecuteActivity(publishCtx, activityName, event).Get(publishCtx, nil); err != nil {
+		// Billing carries on, the event can be republished from the bill state
+		logger.Error("Failed to publish event", "activity", activityName, "error", err)
+	}
 }
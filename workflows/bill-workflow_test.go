@@ -1,14 +1,22 @@
 package workflows
 
 import (
+	"context"
+	"strings"
 	"testing"
 	"time"
 
 	"encore.app/constants"
+	"encore.app/metrics"
 	"encore.app/models"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"go.temporal.io/sdk/activity"
+	"go.temporal.io/sdk/converter"
+	"go.temporal.io/sdk/temporal"
 	"go.temporal.io/sdk/testsuite"
+	"go.temporal.io/sdk/workflow"
 )
 
 type BillWorkflowTestSuite struct {
@@ -29,12 +37,462 @@ func TestBillWorkflowTestSuite(t *testing.T) {
 
 	suite.env = suite.NewTestWorkflowEnvironment()
 	t.Run("Create, Add Items, Close with Timer", suite.TestBillWorkflowLifecycleTimer)
+
+	suite.env = suite.NewTestWorkflowEnvironment()
+	t.Run("Draw Down Prepaid Credits per Line Item", suite.TestBillWorkflowLineItemDrawDown)
+
+	suite.env = suite.NewTestWorkflowEnvironment()
+	t.Run("Draw Down Prepaid Credits on Close", suite.TestBillWorkflowBillCloseDrawDown)
+
+	suite.env = suite.NewTestWorkflowEnvironment()
+	t.Run("Spending Limit Alerts and Hard Cap", suite.TestBillWorkflowSpendingLimit)
+
+	suite.env = suite.NewTestWorkflowEnvironment()
+	t.Run("Queries Scoped to the Tenant", suite.TestBillWorkflowTenantIsolation)
+
+	suite.env = suite.NewTestWorkflowEnvironment()
+	t.Run("Audit Records of Every Signal", suite.TestBillWorkflowAuditRecords)
+
+	suite.env = suite.NewTestWorkflowEnvironment()
+	t.Run("Archive the Finished Billing Period", suite.TestBillWorkflowArchive)
+
+	suite.env = suite.NewTestWorkflowEnvironment()
+	t.Run("Consolidate the Billing Period When It Ends", suite.TestBillWorkflowConsolidation)
+
+	suite.env = suite.NewTestWorkflowEnvironment()
+	t.Run("Search Attributes Follow the Billing Period", suite.TestBillWorkflowSearchAttributes)
+
+	suite.env = suite.NewTestWorkflowEnvironment()
+	t.Run("Billing Periods Started Before the Changes Replay", suite.TestBillWorkflowBeforeChanges)
+
+	registry := metrics.NewRegistry()
+	suite.SetMetricsHandler(metrics.NewTemporalHandler(registry))
+	suite.env = suite.NewTestWorkflowEnvironment()
+	t.Run("Metrics of Signals, Bills and Amounts", func(t *testing.T) { suite.TestBillWorkflowMetrics(t, registry) })
+	suite.SetMetricsHandler(nil)
+}
+
+// stubPublishEvent stands in for the bills service activities publishing lifecycle events
+func stubPublishEvent(ctx context.Context, event map[string]interface{}) error {
+	return nil
+}
+
+var eventActivityNames = []string{
+	constants.PublishBillCreatedActivityName,
+	constants.PublishLineItemAddedActivityName,
+	constants.PublishBillClosedActivityName,
+	constants.PublishBillingPeriodClosedActivityName,
+}
+
+// registerActivities registers every bill workflow activity and collects the IDs of the published events.
+// Mocks can only be added after all activities are registered.
+func (s *BillWorkflowTestSuite) registerActivities() *[]string {
+	s.env.RegisterActivityWithOptions(stubDrawDownCredits, activity.RegisterOptions{
+		Name: constants.DrawDownCreditsActivityName,
+	})
+	s.env.RegisterActivityWithOptions(stubEmitSpendingAlert, activity.RegisterOptions{
+		Name: constants.EmitSpendingAlertActivityName,
+	})
+	for _, name := range eventActivityNames {
+		s.env.RegisterActivityWithOptions(stubPublishEvent, activity.RegisterOptions{Name: name})
+	}
+	s.env.RegisterActivityWithOptions(stubAppendAuditRecord, activity.RegisterOptions{
+		Name: constants.AppendAuditRecordActivityName,
+	})
+	s.env.RegisterActivityWithOptions(stubArchiveBillingPeriod, activity.RegisterOptions{
+		Name: constants.ArchiveBillingPeriodActivityName,
+	})
+	s.env.RegisterActivityWithOptions(stubConsolidateBillingPeriod, activity.RegisterOptions{
+		Name: constants.ConsolidateBillingPeriodActivityName,
+	})
+
+	published := make([]string, 0)
+	for _, name := range eventActivityNames {
+		s.env.OnActivity(name, mock.Anything, mock.Anything).Return(
+			func(ctx context.Context, event map[string]interface{}) error {
+				published = append(published, event["event_id"].(string))
+				return nil
+			})
+	}
+	return &published
+}
+
+// stubDrawDownCredits stands in for the bills service activity, the tests mock its result
+func stubDrawDownCredits(ctx context.Context, input models.DrawDownCreditsInput) (*models.DrawDownResponse, error) {
+	return nil, nil
+}
+
+// stubAppendAuditRecord stands in for the bills service activity appending to the audit log
+func stubAppendAuditRecord(ctx context.Context, record models.AuditRecord) error {
+	return nil
+}
+
+// stubArchiveBillingPeriod stands in for the bills service activity writing finished periods to the archive
+func stubArchiveBillingPeriod(ctx context.Context, period models.ArchivedBillingPeriod) error {
+	return nil
+}
+
+// stubConsolidateBillingPeriod stands in for the bills service activity consolidating parent accounts
+func stubConsolidateBillingPeriod(ctx context.Context, input models.ConsolidateBillingPeriodInput) (*models.ConsolidatedBill, error) {
+	return nil, nil
+}
+
+// stubEmitSpendingAlert stands in for the bills service activity publishing spending alerts
+func stubEmitSpendingAlert(ctx context.Context, event models.SpendingAlertEvent) error {
+	return nil
+}
+
+func (s *BillWorkflowTestSuite) TestBillWorkflowSpendingLimit(t *testing.T) {
+	start := time.Date(2025, 8, 21, 7, 0, 0, 0, time.UTC)
+	s.env.SetStartTime(start)
+	s.registerActivities()
+
+	var emitted []float64
+	s.env.OnActivity(constants.EmitSpendingAlertActivityName, mock.Anything, mock.Anything).Return(
+		func(ctx context.Context, event models.SpendingAlertEvent) error {
+			emitted = append(emitted, event.Alert.Threshold)
+			return nil
+		})
+
+	input := &models.BillWorkflowInput{
+		WorkflowID:        "wf-1",
+		CustomerID:        "cust-1",
+		Currency:          models.USD,
+		BillingPeriodDays: 1,
+		StartedAt:         start,
+		SpendingLimit:     models.SpendingLimit{HardCap: 100}.WithDefaults(models.USD),
+		BillStates:        []*models.Bill{{ID: "bill-1", Status: models.StatusOpen, Currency: models.USD}},
+	}
+
+	addItem := func(id string, amount float64, quantity int, delay time.Duration) {
+		s.env.RegisterDelayedCallback(func() {
+			s.env.SignalWorkflow(constants.AddLineItemSignalName, models.AddLineItemSignal{
+				BillID:   "bill-1",
+				Currency: models.USD,
+				LineItem: &models.LineItem{ID: id, Description: id, Amount: amount, Quantity: quantity},
+			})
+		}, delay)
+	}
+	addItem("item-1", 40, 1, time.Second)
+	addItem("item-2", 45, 1, 2*time.Second) // crosses 50% and 80% at once
+	addItem("item-3", 15, 2, 3*time.Second) // its line total of 30 would exceed the hard cap
+	addItem("item-4", 15, 1, 4*time.Second) // lands exactly on the hard cap
+
+	s.env.RegisterDelayedCallback(func() {
+		res, err := s.env.QueryWorkflow(constants.GetSpendingStatusQuery, models.SpendingStatusRequest{})
+		assert.NoError(t, err)
+		var status *models.SpendingStatus
+		assert.NoError(t, res.Get(&status))
+		assert.Equal(t, 100.0, status.Total)
+		assert.Equal(t, 0.0, *status.Remaining)
+		assert.Equal(t, 1.0, status.AccrualFactor)
+		assert.Len(t, status.Alerts, 3)
+		assert.Equal(t, "bill-1", status.Alerts[0].BillID)
+
+		res, err = s.env.QueryWorkflow(constants.GetBillQuery, &models.GetBillRequest{BillID: "bill-1"})
+		assert.NoError(t, err)
+		var bill *models.Bill
+		assert.NoError(t, res.Get(&bill))
+		assert.Len(t, bill.LineItems, 3, "Expected the item over the hard cap to be rejected")
+
+		s.env.SignalWorkflow(constants.SetSpendingLimitSignalName, models.SetSpendingLimitSignal{
+			Limit: models.SpendingLimit{HardCap: 150},
+		})
+	}, 5*time.Second)
+
+	s.env.ExecuteWorkflow(BillWorkflow, input)
+
+	assert.True(t, s.env.IsWorkflowCompleted())
+	assert.NoError(t, s.env.GetWorkflowError())
+	// Raising the cap to 150 re-alerts at 50% of the new cap
+	assert.Equal(t, []float64{0.5, 0.8, 1.0, 0.5}, emitted)
+}
+
+func (s *BillWorkflowTestSuite) TestBillWorkflowTenantIsolation(t *testing.T) {
+	start := time.Date(2025, 8, 21, 7, 0, 0, 0, time.UTC)
+	s.env.SetStartTime(start)
+	s.registerActivities()
+
+	input := &models.BillWorkflowInput{
+		WorkflowID:        "billing-period-workflow-retail-20250821-cust-1",
+		TenantID:          "retail",
+		CustomerID:        "cust-1",
+		Currency:          models.USD,
+		BillingPeriodDays: 1,
+		StartedAt:         start,
+		Accrual:           &models.AccrualPolicy{AfterHours: 1, Factor: 1.5},
+		BillStates:        []*models.Bill{{ID: "bill-1", Status: models.StatusOpen, Currency: models.USD}},
+	}
+
+	// Added two hours into the period, so the tenant's accrual policy applies
+	s.env.RegisterDelayedCallback(func() {
+		s.env.SignalWorkflow(constants.AddLineItemSignalName, models.AddLineItemSignal{
+			BillID:   "bill-1",
+			Currency: models.USD,
+			LineItem: &models.LineItem{ID: "item-1", Description: "item-1", Amount: 100, Quantity: 1},
+		})
+	}, 2*time.Hour)
+
+	s.env.RegisterDelayedCallback(func() {
+		res, err := s.env.QueryWorkflow(constants.GetBillQuery, &models.GetBillRequest{TenantID: "retail", BillID: "bill-1"})
+		assert.NoError(t, err)
+		var bill *models.Bill
+		assert.NoError(t, res.Get(&bill))
+		assert.Equal(t, 150.0, bill.TotalAmount)
+
+		// Another tenant cannot read the bill, even knowing the workflow and bill IDs
+		_, err = s.env.QueryWorkflow(constants.GetBillQuery, &models.GetBillRequest{TenantID: "wholesale", BillID: "bill-1"})
+		assert.Error(t, err)
+
+		res, err = s.env.QueryWorkflow(constants.ListBillsQuery, &models.ListBillsRequest{TenantID: "wholesale", Status: string(models.StatusOpen)})
+		assert.NoError(t, err)
+		var bills []*models.Bill
+		assert.NoError(t, res.Get(&bills))
+		assert.Empty(t, bills)
+
+		_, err = s.env.QueryWorkflow(constants.GetSpendingStatusQuery, models.SpendingStatusRequest{TenantID: "wholesale"})
+		assert.Error(t, err)
+	}, 3*time.Hour)
+
+	s.env.ExecuteWorkflow(BillWorkflow, input)
+
+	assert.True(t, s.env.IsWorkflowCompleted())
+	assert.NoError(t, s.env.GetWorkflowError())
+}
+
+func (s *BillWorkflowTestSuite) TestBillWorkflowAuditRecords(t *testing.T) {
+	start := time.Date(2025, 8, 21, 7, 0, 0, 0, time.UTC)
+	s.env.SetStartTime(start)
+	s.registerActivities()
+
+	var records []models.AuditRecord
+	s.env.OnActivity(constants.AppendAuditRecordActivityName, mock.Anything, mock.Anything).Return(
+		func(ctx context.Context, record models.AuditRecord) error {
+			records = append(records, record)
+			return nil
+		})
+
+	input := &models.BillWorkflowInput{
+		WorkflowID:        "wf-1",
+		TenantID:          "retail",
+		CustomerID:        "cust-1",
+		Currency:          models.USD,
+		BillingPeriodDays: 1,
+		StartedAt:         start,
+		SpendingLimit:     models.SpendingLimit{HardCap: 100}.WithDefaults(models.USD),
+	}
+	alice := models.AuditContext{Actor: "key-alice", RequestID: "req-1"}
+
+	s.env.RegisterDelayedCallback(func() {
+		s.env.SignalWorkflow(constants.CreateBillSignalName, models.CreateBillSignal{
+			BillID: "bill-1", WorkflowID: "wf-1", Currency: models.USD, Audit: alice,
+		})
+	}, time.Second)
+	s.env.RegisterDelayedCallback(func() {
+		s.env.SignalWorkflow(constants.CreateBillSignalName, models.CreateBillSignal{
+			BillID: "bill-2", WorkflowID: "wf-1", Currency: models.USD, Audit: alice,
+		})
+	}, 2*time.Second)
+	s.env.RegisterDelayedCallback(func() {
+		s.env.SignalWorkflow(constants.AddLineItemSignalName, models.AddLineItemSignal{
+			BillID: "bill-1", Currency: models.USD, Audit: alice,
+			LineItem: &models.LineItem{ID: "item-1", Description: "item-1", Amount: 80, Quantity: 1},
+		})
+	}, 3*time.Second)
+	s.env.RegisterDelayedCallback(func() {
+		s.env.SignalWorkflow(constants.AddLineItemSignalName, models.AddLineItemSignal{
+			BillID: "bill-1", Currency: models.USD, Audit: alice,
+			LineItem: &models.LineItem{ID: "item-2", Description: "item-2", Amount: 40, Quantity: 1},
+		})
+	}, 4*time.Second)
+	s.env.RegisterDelayedCallback(func() {
+		s.env.SignalWorkflow(constants.CloseBillSignalName, models.CloseBillSignal{
+			BillID: "bill-1", Reason: "paid", Audit: alice,
+		})
+	}, 5*time.Second)
+	s.env.RegisterDelayedCallback(func() {
+		s.env.SignalWorkflow(constants.CloseBillingPeriodSignalName, models.CloseBillingPeriodSignal{
+			Audit: models.AuditContext{Actor: "key-bob", RequestID: "req-2"},
+		})
+	}, 6*time.Second)
+
+	s.env.ExecuteWorkflow(BillWorkflow, input)
+
+	assert.True(t, s.env.IsWorkflowCompleted())
+	assert.NoError(t, s.env.GetWorkflowError())
+
+	// The timer closing the period again finds nothing open, its period record repeats the ID of the first
+	ids := make([]string, 0, len(records))
+	for _, record := range records {
+		ids = append(ids, record.RecordID)
+		assert.Equal(t, "retail", record.TenantID)
+		assert.Equal(t, "cust-1", record.CustomerID)
+		assert.Equal(t, models.AuditSourceWorkflow, record.Source)
+	}
+	assert.Equal(t, []string{
+		"wf-1/bill.create/bill-1",
+		"wf-1/bill.create/bill-2",
+		"wf-1/line_item.add/bill-1/item-1",
+		"wf-1/line_item.reject/bill-1/item-2",
+		"wf-1/bill.close/bill-1",
+		"wf-1/bill.close/bill-2",
+		"wf-1/billing_period.close",
+		"wf-1/billing_period.close",
+	}, ids)
+
+	added := records[2]
+	assert.Equal(t, "key-alice", added.Actor)
+	assert.Equal(t, "req-1", added.RequestID)
+	assert.Equal(t, 0.0, *added.TotalBefore)
+	assert.Equal(t, 80.0, *added.TotalAfter)
+
+	rejected := records[3]
+	assert.Equal(t, 80.0, *rejected.TotalAfter)
+	assert.Equal(t, "spending limit would be exceeded", rejected.Reason)
+
+	assert.Equal(t, "paid", records[4].Reason)
+	assert.Equal(t, "key-bob", records[5].Actor)
+	assert.Equal(t, "req-2", records[5].RequestID)
+	assert.Equal(t, "key-bob", records[6].Actor)
+	assert.Equal(t, models.SystemActor, records[7].Actor)
+}
+
+func (s *BillWorkflowTestSuite) TestBillWorkflowLineItemDrawDown(t *testing.T) {
+	start := time.Date(2025, 8, 21, 7, 0, 0, 0, time.UTC)
+	s.env.SetStartTime(start)
+	published := s.registerActivities()
+
+	// The wallet only covers part of the second line item
+	s.env.OnActivity(constants.DrawDownCreditsActivityName, mock.Anything, models.DrawDownCreditsInput{
+		CustomerID: "cust-1", BillID: "bill-1", Reference: "bill-1/item-1", Amount: 60, Currency: models.USD,
+	}).Return(&models.DrawDownResponse{AppliedAmount: 60, RemainingBalance: 20, WalletCurrency: models.USD}, nil).Once()
+	s.env.OnActivity(constants.DrawDownCreditsActivityName, mock.Anything, models.DrawDownCreditsInput{
+		CustomerID: "cust-1", BillID: "bill-1", Reference: "bill-1/item-2", Amount: 50, Currency: models.USD,
+	}).Return(&models.DrawDownResponse{AppliedAmount: 20, RemainingBalance: 0, WalletCurrency: models.USD}, nil).Once()
+
+	input := &models.BillWorkflowInput{
+		WorkflowID:        "wf-1",
+		CustomerID:        "cust-1",
+		Currency:          models.USD,
+		BillingPeriodDays: 1,
+		StartedAt:         start,
+		DrawDownMode:      models.DrawDownLineItem,
+		BillStates:        []*models.Bill{{ID: "bill-1", Status: models.StatusOpen, Currency: models.USD}},
+	}
+
+	s.env.RegisterDelayedCallback(func() {
+		s.env.SignalWorkflow(constants.AddLineItemSignalName, models.AddLineItemSignal{
+			BillID:   "bill-1",
+			Currency: models.USD,
+			LineItem: &models.LineItem{ID: "item-1", Description: "Seats", Amount: 30, Quantity: 2},
+		})
+	}, time.Second)
+
+	s.env.RegisterDelayedCallback(func() {
+		s.env.SignalWorkflow(constants.AddLineItemSignalName, models.AddLineItemSignal{
+			BillID:   "bill-1",
+			Currency: models.USD,
+			LineItem: &models.LineItem{ID: "item-2", Description: "Storage", Amount: 50, Quantity: 1},
+		})
+	}, 2*time.Second)
+
+	// Line items for a bill the period does not hold are dropped
+	s.env.RegisterDelayedCallback(func() {
+		s.env.SignalWorkflow(constants.AddLineItemSignalName, models.AddLineItemSignal{
+			BillID:   "bill-unknown",
+			Currency: models.USD,
+			LineItem: &models.LineItem{ID: "item-3", Description: "Seats", Amount: 10, Quantity: 1},
+		})
+	}, 2500*time.Millisecond)
+
+	s.env.RegisterDelayedCallback(func() {
+		res, err := s.env.QueryWorkflow(constants.GetBillQuery, &models.GetBillRequest{BillID: "bill-1"})
+		assert.NoError(t, err)
+		var bill *models.Bill
+		assert.NoError(t, res.Get(&bill))
+		assert.Len(t, bill.LineItems, 4, "Expected a credit line after each line item")
+		assert.Equal(t, "bill-1/item-1", bill.LineItems[1].ID)
+		assert.Equal(t, -60.0, bill.LineItems[1].Amount)
+		assert.Equal(t, models.PrepaidCreditDescription, bill.LineItems[3].Description)
+		assert.Equal(t, -20.0, bill.LineItems[3].Amount)
+		assert.Equal(t, 30.0, bill.TotalAmount, "Expected the running total to count quantities like the close")
+
+		s.env.SignalWorkflow(constants.CloseBillSignalName, models.CloseBillSignal{BillID: "bill-1", Reason: "done"})
+	}, 3*time.Second)
+
+	s.env.ExecuteWorkflow(BillWorkflow, input)
+
+	assert.True(t, s.env.IsWorkflowCompleted())
+	assert.NoError(t, s.env.GetWorkflowError())
+	res, err := s.env.QueryWorkflow(constants.GetBillQuery, &models.GetBillRequest{BillID: "bill-1"})
+	assert.NoError(t, err)
+	var bill *models.Bill
+	assert.NoError(t, res.Get(&bill))
+	assert.Equal(t, models.StatusClosed, bill.Status)
+	assert.Equal(t, 30.0, bill.TotalAmount, "Expected 110 charged less 80 of credits")
+	assert.Contains(t, *published, models.LineItemAddedEventID("bill-1", "bill-1/item-1"), "Expected an event for each credit line")
+	assert.Contains(t, *published, models.LineItemAddedEventID("bill-1", "bill-1/item-2"))
+}
+
+func (s *BillWorkflowTestSuite) TestBillWorkflowBillCloseDrawDown(t *testing.T) {
+	start := time.Date(2025, 8, 21, 7, 0, 0, 0, time.UTC)
+	s.env.SetStartTime(start)
+	s.registerActivities()
+
+	s.env.OnActivity(constants.DrawDownCreditsActivityName, mock.Anything, models.DrawDownCreditsInput{
+		CustomerID: "cust-1", BillID: "bill-1", Reference: "bill-1/close", Amount: 100, Currency: models.GEL,
+	}).Return(&models.DrawDownResponse{AppliedAmount: 100, RemainingBalance: 15, WalletCurrency: models.USD}, nil).Once()
+
+	input := &models.BillWorkflowInput{
+		WorkflowID:        "wf-1",
+		CustomerID:        "cust-1",
+		Currency:          models.GEL,
+		BillingPeriodDays: 1,
+		StartedAt:         start,
+		DrawDownMode:      models.DrawDownBillClose,
+		BillStates: []*models.Bill{
+			{ID: "bill-1", Status: models.StatusOpen, Currency: models.GEL},
+			// Empty bills are closed without touching the wallet
+			{ID: "bill-2", Status: models.StatusOpen, Currency: models.GEL},
+		},
+	}
+
+	s.env.RegisterDelayedCallback(func() {
+		s.env.SignalWorkflow(constants.AddLineItemSignalName, models.AddLineItemSignal{
+			BillID:   "bill-1",
+			Currency: models.GEL,
+			LineItem: &models.LineItem{ID: "item-1", Description: "Usage", Amount: 100, Quantity: 1},
+		})
+	}, time.Second)
+
+	s.env.RegisterDelayedCallback(func() {
+		res, err := s.env.QueryWorkflow(constants.GetBillQuery, &models.GetBillRequest{BillID: "bill-1"})
+		assert.NoError(t, err)
+		var bill *models.Bill
+		assert.NoError(t, res.Get(&bill))
+		assert.Len(t, bill.LineItems, 1, "Expected no credits before the bill closes")
+	}, 2*time.Second)
+
+	s.env.ExecuteWorkflow(BillWorkflow, input)
+
+	assert.True(t, s.env.IsWorkflowCompleted())
+	assert.NoError(t, s.env.GetWorkflowError())
+	res, err := s.env.QueryWorkflow(constants.ListBillsQuery, &models.ListBillsRequest{Status: string(models.StatusClosed)})
+	assert.NoError(t, err)
+	var closedBills []*models.Bill
+	assert.NoError(t, res.Get(&closedBills))
+	assert.Len(t, closedBills, 2)
+	assert.Len(t, closedBills[0].LineItems, 2)
+	assert.Equal(t, "bill-1/close", closedBills[0].LineItems[1].ID)
+	assert.Equal(t, 0.0, closedBills[0].TotalAmount)
+	assert.Empty(t, closedBills[1].LineItems)
 }
 
 func (s *BillWorkflowTestSuite) TestBillWorkflowLifecycle(t *testing.T) {
 	// 1) Seed clock so workflow.Now() is deterministic
 	start := time.Date(2025, 8, 21, 7, 0, 0, 0, time.UTC)
 	s.env.SetStartTime(start)
+	published := s.registerActivities()
 
 	// 2) Prepare initial input
 	input := &models.BillWorkflowInput{
@@ -113,12 +571,20 @@ func (s *BillWorkflowTestSuite) TestBillWorkflowLifecycle(t *testing.T) {
 	// 5) Assertions
 	assert.True(t, s.env.IsWorkflowCompleted())
 	assert.NoError(t, s.env.GetWorkflowError())
+	assert.Equal(t, []string{
+		"bill-created/bill-2",
+		"line-item-added/bill-1/item-xyz",
+		"bill-closed/bill-1",
+		"bill-closed/bill-2",
+		"billing-period-closed/wf-1",
+	}, *published)
 }
 
 func (s *BillWorkflowTestSuite) TestBillWorkflowLifecycleTimer(t *testing.T) {
 	// 1) Seed clock so workflow.Now() is deterministic
 	start := time.Date(2025, 8, 21, 7, 0, 0, 0, time.UTC)
 	s.env.SetStartTime(start)
+	s.registerActivities()
 
 	// 2) Prepare initial input
 	input := &models.BillWorkflowInput{
@@ -202,6 +668,191 @@ func (s *BillWorkflowTestSuite) TestBillWorkflowLifecycleTimer(t *testing.T) {
 	assert.NoError(t, s.env.GetWorkflowError())
 }
 
+func (s *BillWorkflowTestSuite) TestBillWorkflowArchive(t *testing.T) {
+	start := time.Date(2025, 8, 21, 7, 0, 0, 0, time.UTC)
+	s.env.SetStartTime(start)
+	s.registerActivities()
+
+	var archived []models.ArchivedBillingPeriod
+	s.env.OnActivity(constants.ArchiveBillingPeriodActivityName, mock.Anything, mock.Anything).Return(
+		func(ctx context.Context, period models.ArchivedBillingPeriod) error {
+			archived = append(archived, period)
+			return nil
+		})
+
+	input := &models.BillWorkflowInput{
+		WorkflowID:        "wf-1",
+		TenantID:          "retail",
+		CustomerID:        "cust-1",
+		Currency:          models.USD,
+		BillingPeriodDays: 30,
+		StartedAt:         start,
+		BillStates:        []*models.Bill{{ID: "bill-1", Status: models.StatusOpen, Currency: models.USD, CreatedAt: start}},
+	}
+	s.env.RegisterDelayedCallback(func() {
+		s.env.SignalWorkflow(constants.AddLineItemSignalName, models.AddLineItemSignal{
+			BillID:   "bill-1",
+			Currency: models.USD,
+			LineItem: &models.LineItem{ID: "item-1", Description: "Seats", Amount: 40, Quantity: 1},
+		})
+	}, time.Minute)
+	s.env.RegisterDelayedCallback(func() {
+		s.env.SignalWorkflow(constants.CloseBillingPeriodSignalName, models.CloseBillingPeriodSignal{})
+	}, time.Hour)
+
+	s.env.ExecuteWorkflow(BillWorkflow, input)
+
+	assert.True(t, s.env.IsWorkflowCompleted())
+	assert.NoError(t, s.env.GetWorkflowError())
+	if assert.Len(t, archived, 1, "The period is archived once, when the workflow returns") {
+		period := archived[0]
+		assert.Equal(t, "wf-1", period.WorkflowID)
+		assert.Equal(t, "retail", period.TenantID)
+		assert.Equal(t, start, period.StartedAt)
+		assert.Len(t, period.Bills, 1)
+		assert.Equal(t, models.StatusClosed, period.Bills[0].Status)
+		assert.Len(t, period.Bills[0].LineItems, 1)
+		assert.NotEmpty(t, period.Bills[0].CloseReason)
+	}
+}
+
+// TestBillWorkflowConsolidation ends the period by its timer after child accounts were handed over by a
+// CloseBillingPeriod signal, the consolidated bill is announced and archived with the period
+func (s *BillWorkflowTestSuite) TestBillWorkflowConsolidation(t *testing.T) {
+	start := time.Date(2025, 8, 21, 7, 0, 0, 0, time.UTC)
+	s.env.SetStartTime(start)
+	s.registerActivities()
+
+	childAccounts := []*models.ChildBills{{
+		CustomerID: "child-1",
+		WorkflowID: "wf-child-1",
+		Bills:      []*models.Bill{{ID: "child-bill-1", Status: models.StatusClosed, Currency: models.USD, TotalAmount: 20}},
+	}}
+	var consolidations []models.ConsolidateBillingPeriodInput
+	s.env.OnActivity(constants.ConsolidateBillingPeriodActivityName, mock.Anything, mock.Anything).Return(
+		func(ctx context.Context, input models.ConsolidateBillingPeriodInput) (*models.ConsolidatedBill, error) {
+			consolidations = append(consolidations, input)
+			return &models.ConsolidatedBill{
+				ParentCustomerID: input.CustomerID,
+				WorkflowID:       input.WorkflowID,
+				Currency:         models.USD,
+				TotalAmount:      60,
+				GeneratedAt:      input.ClosedAt,
+			}, nil
+		})
+	var archived []models.ArchivedBillingPeriod
+	s.env.OnActivity(constants.ArchiveBillingPeriodActivityName, mock.Anything, mock.Anything).Return(
+		func(ctx context.Context, period models.ArchivedBillingPeriod) error {
+			archived = append(archived, period)
+			return nil
+		})
+
+	input := &models.BillWorkflowInput{
+		WorkflowID:        "wf-1",
+		TenantID:          "retail",
+		CustomerID:        "parent-1",
+		Currency:          models.USD,
+		BillingPeriodDays: 30,
+		StartedAt:         start,
+		BillStates:        []*models.Bill{{ID: "bill-1", Status: models.StatusOpen, Currency: models.USD, CreatedAt: start}},
+	}
+	s.env.RegisterDelayedCallback(func() {
+		s.env.SignalWorkflow(constants.AddLineItemSignalName, models.AddLineItemSignal{
+			BillID:   "bill-1",
+			Currency: models.USD,
+			LineItem: &models.LineItem{ID: "item-1", Description: "Seats", Amount: 40, Quantity: 1},
+		})
+	}, time.Minute)
+	s.env.RegisterDelayedCallback(func() {
+		s.env.SignalWorkflow(constants.CloseBillingPeriodSignalName, models.CloseBillingPeriodSignal{ChildAccounts: childAccounts})
+	}, time.Hour)
+
+	s.env.ExecuteWorkflow(BillWorkflow, input)
+
+	assert.True(t, s.env.IsWorkflowCompleted())
+	assert.NoError(t, s.env.GetWorkflowError())
+	if assert.Len(t, consolidations, 1, "The period is consolidated once, when it ends") {
+		consolidation := consolidations[0]
+		assert.Equal(t, "retail", consolidation.TenantID)
+		assert.Equal(t, "parent-1", consolidation.CustomerID)
+		assert.Equal(t, start, consolidation.StartedAt)
+		assert.Equal(t, start.Add(30*24*time.Hour), consolidation.ClosedAt)
+		assert.Len(t, consolidation.Bills, 1)
+		assert.Equal(t, models.StatusClosed, consolidation.Bills[0].Status)
+		assert.Equal(t, start.Add(time.Hour), consolidation.Bills[0].ClosedAt, "Bills close at workflow time")
+		if assert.Len(t, consolidation.ChildAccounts, 1) {
+			assert.Equal(t, "child-1", consolidation.ChildAccounts[0].CustomerID)
+		}
+	}
+	if assert.Len(t, archived, 1) && assert.NotNil(t, archived[0].ConsolidatedBill) {
+		assert.Equal(t, "wf-1", archived[0].ConsolidatedBill.WorkflowID)
+		assert.Equal(t, 60.0, archived[0].ConsolidatedBill.TotalAmount)
+	}
+}
+
+func (s *BillWorkflowTestSuite) TestBillWorkflowSearchAttributes(t *testing.T) {
+	start := time.Date(2025, 8, 21, 7, 0, 0, 0, time.UTC)
+	s.env.SetStartTime(start)
+	s.registerActivities()
+	s.env.OnActivity(constants.ArchiveBillingPeriodActivityName, mock.Anything, mock.Anything).Return(nil)
+
+	var upserts []temporal.SearchAttributes
+	s.env.OnUpsertTypedSearchAttributes(mock.Anything).Return(nil).Run(func(args mock.Arguments) {
+		upserts = append(upserts, args.Get(0).(temporal.SearchAttributes))
+	})
+
+	input := &models.BillWorkflowInput{
+		WorkflowID:        "wf-1",
+		TenantID:          "retail",
+		CustomerID:        "cust-1",
+		Currency:          models.USD,
+		BillingPeriodDays: 30,
+		StartedAt:         start,
+		BillStates:        []*models.Bill{{ID: "bill-1", Status: models.StatusOpen, Currency: models.USD, CreatedAt: start}},
+	}
+	s.env.RegisterDelayedCallback(func() {
+		s.env.SignalWorkflow(constants.AddLineItemSignalName, models.AddLineItemSignal{
+			BillID:   "bill-1",
+			Currency: models.USD,
+			LineItem: &models.LineItem{ID: "item-1", Description: "Seats", Amount: 40, Quantity: 1},
+		})
+	}, time.Minute)
+	s.env.RegisterDelayedCallback(func() {
+		// A query changes nothing, no attributes are upserted for it
+		_, err := s.env.QueryWorkflow(constants.ListBillsQuery, &models.ListBillsRequest{TenantID: "retail"})
+		assert.NoError(t, err)
+		s.env.SignalWorkflow(constants.CloseBillingPeriodSignalName, models.CloseBillingPeriodSignal{})
+	}, time.Hour)
+
+	s.env.ExecuteWorkflow(BillWorkflow, input)
+
+	assert.True(t, s.env.IsWorkflowCompleted())
+	assert.NoError(t, s.env.GetWorkflowError())
+	if assert.Len(t, upserts, 4, "Upserted on start, after the line item, after the bills closed and when the period ends") {
+		first := upserts[0]
+		tenantID, _ := first.GetKeyword(TenantIDSearchAttribute)
+		customerID, _ := first.GetKeyword(CustomerIDSearchAttribute)
+		openBills, _ := first.GetInt64(OpenBillCountSearchAttribute)
+		assert.Equal(t, "retail", tenantID)
+		assert.Equal(t, "cust-1", customerID)
+		assert.Equal(t, int64(1), openBills)
+
+		total, _ := upserts[1].GetFloat64(RunningTotalSearchAttribute)
+		assert.Equal(t, 40.0, total)
+
+		status, _ := upserts[2].GetKeyword(PeriodStatusSearchAttribute)
+		assert.Equal(t, string(models.StatusOpen), status)
+
+		last := upserts[3]
+		status, _ = last.GetKeyword(PeriodStatusSearchAttribute)
+		openBills, _ = last.GetInt64(OpenBillCountSearchAttribute)
+		billIDs, _ := last.GetKeywordList(BillIDsSearchAttribute)
+		assert.Equal(t, string(models.StatusClosed), status)
+		assert.Equal(t, int64(0), openBills)
+		assert.Equal(t, []string{"bill-1"}, billIDs)
+	}
+}
+
 func computeAccrualFactor(now, start time.Time) float64 {
 	var factor float64 = 1.0
 	if now.Sub(start) < 30*24*time.Hour {
@@ -214,3 +865,138 @@ func computeAccrualFactor(now, start time.Time) float64 {
 func GetAccrualFactorForTest(now, start time.Time) float64 {
 	return computeAccrualFactor(now, start)
 }
+
+func (s *BillWorkflowTestSuite) TestBillWorkflowMetrics(t *testing.T, registry *metrics.Registry) {
+	start := time.Date(2025, 8, 21, 7, 0, 0, 0, time.UTC)
+	s.env.SetStartTime(start)
+	s.registerActivities()
+
+	input := &models.BillWorkflowInput{
+		WorkflowID:        "wf-metrics",
+		CustomerID:        "cust-1",
+		Currency:          models.USD,
+		BillingPeriodDays: 1,
+		StartedAt:         start,
+		BillStates:        []*models.Bill{{ID: "bill-1", Status: models.StatusOpen, Currency: models.USD}},
+	}
+
+	s.env.RegisterDelayedCallback(func() {
+		s.env.SignalWorkflow(constants.CreateBillSignalName, models.CreateBillSignal{
+			Currency:   models.GEL,
+			WorkflowID: "wf-metrics",
+			BillID:     "bill-2",
+		})
+	}, time.Second)
+	s.env.RegisterDelayedCallback(func() {
+		s.env.SignalWorkflow(constants.AddLineItemSignalName, &models.AddLineItemSignal{
+			BillID:   "bill-1",
+			Currency: models.USD,
+			LineItem: &models.LineItem{ID: "item-1", Amount: 12.34, Quantity: 1},
+		})
+	}, 2*time.Second)
+	s.env.RegisterDelayedCallback(func() {
+		s.env.SignalWorkflow(constants.AddLineItemSignalName, &models.AddLineItemSignal{
+			BillID:   "bill-2",
+			Currency: models.USD,
+			LineItem: &models.LineItem{ID: "item-2", Amount: 10, Quantity: 1},
+		})
+	}, 3*time.Second)
+	s.env.RegisterDelayedCallback(func() {
+		s.env.SignalWorkflow(constants.CloseBillSignalName, models.CloseBillSignal{BillID: "bill-1", Reason: "paid"})
+	}, 4*time.Second)
+
+	s.env.ExecuteWorkflow(BillWorkflow, input)
+	assert.True(t, s.env.IsWorkflowCompleted())
+	assert.NoError(t, s.env.GetWorkflowError())
+
+	var out strings.Builder
+	assert.NoError(t, registry.WriteText(&out))
+	series := map[string]string{}
+	for _, line := range strings.Split(out.String(), "\n") {
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		// The SDK tags every workflow metric with the namespace, task queue and workflow type
+		name, value, _ := strings.Cut(line, " ")
+		if open := strings.Index(name, "{"); open >= 0 {
+			labels := name[open+1 : len(name)-1]
+			kept := []string{}
+			for _, pair := range strings.Split(labels, ",") {
+				if !strings.HasPrefix(pair, "namespace=") && !strings.HasPrefix(pair, "task_queue=") && !strings.HasPrefix(pair, "workflow_type=") {
+					kept = append(kept, pair)
+				}
+			}
+			name = name[:open] + "{" + strings.Join(kept, ",") + "}"
+		}
+		series[name] = value
+	}
+
+	assert.Equal(t, "1", series[`billing_signals_handled_total{signal="create-bill"}`])
+	assert.Equal(t, "2", series[`billing_signals_handled_total{signal="add-line-item"}`])
+	assert.Equal(t, "1", series[`billing_signals_handled_total{signal="close-bill"}`])
+	assert.Equal(t, "1", series[`billing_bills_created_total{currency="GEL"}`])
+	assert.Equal(t, "1", series[`billing_bills_closed_total{currency="USD",trigger="signal"}`])
+	assert.Equal(t, "1", series[`billing_bills_closed_total{currency="GEL",trigger="period_end"}`])
+	assert.Equal(t, "1", series[`billing_line_items_added_total{currency="USD"}`])
+	assert.Equal(t, "1", series[`billing_line_items_added_total{currency="GEL"}`])
+	assert.Equal(t, "1234", series[`billing_amount_billed_minor_units_total{currency="USD"}`])
+	assert.Equal(t, "2500", series[`billing_amount_billed_minor_units_total{currency="GEL"}`])
+	assert.Equal(t, "1", series[`billing_fx_conversions_total{from_currency="USD",to_currency="GEL"}`])
+}
+
+// TestBillWorkflowBeforeChanges runs the workflow as billing periods started before any change gate, which
+// must schedule no command the first release did not, so their histories replay
+func (s *BillWorkflowTestSuite) TestBillWorkflowBeforeChanges(t *testing.T) {
+	start := time.Date(2025, 8, 21, 7, 0, 0, 0, time.UTC)
+	s.env.SetStartTime(start)
+	published := s.registerActivities()
+	s.env.OnGetVersion(mock.Anything, workflow.DefaultVersion, 1).Return(workflow.DefaultVersion)
+
+	var activities []string
+	s.env.SetOnActivityStartedListener(func(info *activity.Info, ctx context.Context, args converter.EncodedValues) {
+		activities = append(activities, info.ActivityType.Name)
+	})
+	s.env.SetOnLocalActivityStartedListener(func(info *activity.Info, ctx context.Context, args []interface{}) {
+		activities = append(activities, info.ActivityType.Name)
+	})
+
+	input := &models.BillWorkflowInput{
+		WorkflowID:        "wf-1",
+		CustomerID:        "cust-1",
+		Currency:          models.USD,
+		BillingPeriodDays: 1,
+		StartedAt:         start,
+		DrawDownMode:      models.DrawDownLineItem,
+		SpendingLimit:     models.SpendingLimit{HardCap: 100}.WithDefaults(models.USD),
+	}
+
+	s.env.RegisterDelayedCallback(func() {
+		s.env.SignalWorkflow(constants.CreateBillSignalName, models.CreateBillSignal{
+			BillID: "bill-1", WorkflowID: "wf-1", Currency: models.USD,
+		})
+	}, time.Second)
+	s.env.RegisterDelayedCallback(func() {
+		s.env.SignalWorkflow(constants.AddLineItemSignalName, models.AddLineItemSignal{
+			BillID: "bill-1", Currency: models.USD,
+			LineItem: &models.LineItem{ID: "item-1", Description: "Seats", Amount: 30, Quantity: 2},
+		})
+	}, 2*time.Second)
+	s.env.RegisterDelayedCallback(func() {
+		res, err := s.env.QueryWorkflow(constants.GetBillQuery, &models.GetBillRequest{BillID: "bill-1"})
+		assert.NoError(t, err)
+		var bill *models.Bill
+		assert.NoError(t, res.Get(&bill))
+		// The running total of the first release added the unit amount
+		assert.Equal(t, 30.0, bill.TotalAmount)
+		assert.Len(t, bill.LineItems, 1)
+
+		s.env.SignalWorkflow(constants.CloseBillSignalName, models.CloseBillSignal{BillID: "bill-1", Reason: "paid"})
+	}, 3*time.Second)
+
+	s.env.ExecuteWorkflow(BillWorkflow, input)
+
+	assert.True(t, s.env.IsWorkflowCompleted())
+	assert.NoError(t, s.env.GetWorkflowError())
+	assert.Empty(t, *published)
+	assert.Empty(t, activities)
+}
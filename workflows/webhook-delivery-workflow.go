@@ -0,0 +1,68 @@
+package workflows
+
+import (
+	"errors"
+	"time"
+
+	"encore.app/constants"
+	"encore.app/models"
+
+	"go.temporal.io/sdk/temporal"
+	"go.temporal.io/sdk/workflow"
+)
+
+// Retry schedule used when the delivery input does not override it
+const (
+	defaultWebhookMaxAttempts     = 8
+	defaultWebhookInitialInterval = 10 * time.Second
+	defaultWebhookMaxInterval     = time.Hour
+)
+
+// WebhookDeliveryWorkflow posts a webhook delivery to its endpoint, retrying with exponential backoff
+// and dead-lettering the delivery once the attempts are exhausted or the endpoint rejects it
+func WebhookDeliveryWorkflow(ctx workflow.Context, input *models.WebhookDeliveryInput) error {
+	logger := workflow.GetLogger(ctx)
+
+	maxAttempts := input.MaxAttempts
+	if maxAttempts <= 0 {
+		maxAttempts = defaultWebhookMaxAttempts
+	}
+	initialInterval := input.InitialInterval
+	if initialInterval <= 0 {
+		initialInterval = defaultWebhookInitialInterval
+	}
+	maxInterval := input.MaxInterval
+	if maxInterval <= 0 {
+		maxInterval = defaultWebhookMaxInterval
+	}
+
+	// Each attempt is recorded by the activity itself, Temporal owns the backoff between attempts
+	deliverCtx := workflow.WithActivityOptions(ctx, workflow.ActivityOptions{
+		StartToCloseTimeout: 30 * time.Second,
+		RetryPolicy: &temporal.RetryPolicy{
+			InitialInterval:    initialInterval,
+			BackoffCoefficient: 2.0,
+			MaximumInterval:    maxInterval,
+			MaximumAttempts:    maxAttempts,
+		},
+	})
+	err := workflow.ExecuteActivity(deliverCtx, constants.DeliverWebhookActivityName, input.DeliveryID).Get(ctx, nil)
+	if err == nil {
+		logger.Info("Webhook delivered", "delivery_id", input.DeliveryID)
+		return nil
+	}
+
+	reason := err.Error()
+	var appErr *temporal.ApplicationError
+	if errors.As(err, &appErr) {
+		reason = appErr.Message()
+	}
+	logger.Warn("Webhook delivery failed, dead-lettering", "delivery_id", input.DeliveryID, "error", reason)
+	deadLetterCtx := workflow.WithActivityOptions(ctx, workflow.ActivityOptions{
+		StartToCloseTimeout: 10 * time.Second,
+	})
+	return workflow.ExecuteActivity(deadLetterCtx, constants.DeadLetterWebhookActivityName, models.DeadLetterWebhookInput{
+		DeliveryID: input.DeliveryID,
+		Reason:     reason,
+	}).Get(ctx, nil)
+}
@@ -0,0 +1,89 @@
+package workflows
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"encore.app/constants"
+	"encore.app/models"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"go.temporal.io/sdk/activity"
+	"go.temporal.io/sdk/temporal"
+	"go.temporal.io/sdk/testsuite"
+)
+
+func stubDeliverWebhook(ctx context.Context, deliveryID string) error {
+	return nil
+}
+
+func stubDeadLetterWebhook(ctx context.Context, input models.DeadLetterWebhookInput) error {
+	return nil
+}
+
+func newWebhookDeliveryTestEnv() *testsuite.TestWorkflowEnvironment {
+	env := (&testsuite.WorkflowTestSuite{}).NewTestWorkflowEnvironment()
+	env.RegisterWorkflow(WebhookDeliveryWorkflow)
+	env.RegisterActivityWithOptions(stubDeliverWebhook, activity.RegisterOptions{Name: constants.DeliverWebhookActivityName})
+	env.RegisterActivityWithOptions(stubDeadLetterWebhook, activity.RegisterOptions{Name: constants.DeadLetterWebhookActivityName})
+	return env
+}
+
+func TestWebhookDeliveryWorkflow(t *testing.T) {
+	input := &models.WebhookDeliveryInput{
+		DeliveryID:      "delivery-1",
+		MaxAttempts:     3,
+		InitialInterval: time.Second,
+		MaxInterval:     10 * time.Second,
+	}
+
+	t.Run("delivers after transient failures", func(t *testing.T) {
+		env := newWebhookDeliveryTestEnv()
+		env.OnActivity(constants.DeliverWebhookActivityName, mock.Anything, "delivery-1").
+			Return(errors.New("endpoint returned 503")).Twice()
+		env.OnActivity(constants.DeliverWebhookActivityName, mock.Anything, "delivery-1").
+			Return(nil).Once()
+
+		env.ExecuteWorkflow(WebhookDeliveryWorkflow, input)
+
+		assert.True(t, env.IsWorkflowCompleted())
+		assert.NoError(t, env.GetWorkflowError())
+		env.AssertExpectations(t)
+		env.AssertNotCalled(t, constants.DeadLetterWebhookActivityName, mock.Anything, mock.Anything)
+	})
+
+	t.Run("dead-letters when attempts are exhausted", func(t *testing.T) {
+		env := newWebhookDeliveryTestEnv()
+		env.OnActivity(constants.DeliverWebhookActivityName, mock.Anything, "delivery-1").
+			Return(errors.New("endpoint returned 503")).Times(3)
+		env.OnActivity(constants.DeadLetterWebhookActivityName, mock.Anything, models.DeadLetterWebhookInput{
+			DeliveryID: "delivery-1",
+			Reason:     "endpoint returned 503",
+		}).Return(nil).Once()
+
+		env.ExecuteWorkflow(WebhookDeliveryWorkflow, input)
+
+		assert.True(t, env.IsWorkflowCompleted())
+		assert.NoError(t, env.GetWorkflowError())
+		env.AssertExpectations(t)
+	})
+
+	t.Run("dead-letters rejected deliveries without retrying", func(t *testing.T) {
+		env := newWebhookDeliveryTestEnv()
+		env.OnActivity(constants.DeliverWebhookActivityName, mock.Anything, "delivery-1").
+			Return(temporal.NewNonRetryableApplicationError("endpoint returned 410", "WebhookRejected", nil)).Once()
+		env.OnActivity(constants.DeadLetterWebhookActivityName, mock.Anything, models.DeadLetterWebhookInput{
+			DeliveryID: "delivery-1",
+			Reason:     "endpoint returned 410",
+		}).Return(nil).Once()
+
+		env.ExecuteWorkflow(WebhookDeliveryWorkflow, input)
+
+		assert.True(t, env.IsWorkflowCompleted())
+		assert.NoError(t, env.GetWorkflowError())
+		env.AssertExpectations(t)
+	})
+}